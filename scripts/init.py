@@ -5,6 +5,7 @@ import sys
 import getopt
 import os
 import shutil
+import binascii
 from string import Template
 
 
@@ -16,7 +17,8 @@ def generate_config_file(
         rd_server_v, db_name_v, redis_ip_v, redis_port_v,
         redis_pass_v, mongo_ip_v, mongo_port_v, mongo_user_v, mongo_pass_v,
         cc_url_v, paas_url_v, full_text_search, es_url_v, es_user_v, es_pass_v, auth_address, auth_app_code,
-        auth_app_secret, auth_enabled, auth_scheme, auth_sync_workers, auth_sync_interval_minutes, log_level
+        auth_app_secret, auth_enabled, auth_scheme, auth_sync_workers, auth_sync_interval_minutes, log_level,
+        event_secret_key
 ):
     output = os.getcwd() + "/cmdb_adminserver/configures/"
     context = dict(
@@ -44,7 +46,8 @@ def generate_config_file(
         auth_scheme=auth_scheme,
         auth_sync_workers=auth_sync_workers,
         auth_sync_interval_minutes=auth_sync_interval_minutes,
-        full_text_search=full_text_search
+        full_text_search=full_text_search,
+        event_secret_key=event_secret_key
     )
     if not os.path.exists(output):
         os.mkdir(output)
@@ -130,6 +133,11 @@ database = 0
 port = $redis_port
 maxOpenConns = 3000
 maxIDleConns = 1000
+
+[event]
+pausedBufferLimit = 10000
+suspendAfterFailures = 100
+secretKey = $event_secret_key
 '''
 
     template = FileTemplate(eventserver_file_template_str)
@@ -454,6 +462,7 @@ def main(argv):
     es_user = ''
     es_pass = ''
     log_level = '3'
+    event_secret_key = ''
 
     server_ports = {
         "cmdb_adminserver": 60004,
@@ -476,7 +485,8 @@ def main(argv):
         "mongo_user=", "mongo_pass=", "blueking_cmdb_url=",
         "blueking_paas_url=", "listen_port=", "es_url=", "es_user=", "es_pass=", "auth_address=",
         "auth_app_code=", "auth_app_secret=", "auth_enabled=",
-        "auth_scheme=", "auth_sync_workers=", "auth_sync_interval_minutes=", "full_text_search=", "log_level="
+        "auth_scheme=", "auth_sync_workers=", "auth_sync_interval_minutes=", "full_text_search=", "log_level=",
+        "event_secret_key="
     ]
     usage = '''
     usage:
@@ -502,6 +512,7 @@ def main(argv):
       --es_user            <es_user>              the es user name
       --es_pass            <es_pass>              the es password
       --log_level          <log_level>            log level to start cmdb process, default: 3
+      --event_secret_key   <event_secret_key>     the key to encrypt the event subscription secrets, generated randomly if not set, keep it unchanged once the secrets are saved
 
 
     demo:
@@ -529,7 +540,8 @@ def main(argv):
       --es_url             http://127.0.0.1:9200 \\
       --es_user            cc \\
       --es_pass            cc \\
-      --log_level          3
+      --log_level          3 \\
+      --event_secret_key   xxxxxxx
     '''
     try:
         opts, _ = getopt.getopt(argv, "hd:D:r:p:x:s:m:P:X:S:u:U:a:l:es:v", arr)
@@ -619,6 +631,9 @@ def main(argv):
         elif opt in("-v","--log_level",):
             log_level = arg
             print('log_level:', log_level)
+        elif opt in ("--event_secret_key",):
+            event_secret_key = arg
+            print('event_secret_key:', event_secret_key)
 
     if 0 == len(rd_server):
         print('please input the ZooKeeper address, eg:127.0.0.1:2181')
@@ -686,6 +701,10 @@ def main(argv):
             print("auth_app_secret can't be empty when iam auth enabled")
             sys.exit()
 
+    if 0 == len(event_secret_key):
+        event_secret_key = binascii.hexlify(os.urandom(16)).decode()
+        print('event_secret_key is generated:', event_secret_key)
+
     availableLogLevel = [str(i) for i in range(0, 10)]
     if log_level not in availableLogLevel:
         print("available log_level value are: %s" %  availableLogLevel)
@@ -708,6 +727,7 @@ def main(argv):
         es_user_v=es_user,
         es_pass_v=es_pass,
         log_level=log_level,
+        event_secret_key=event_secret_key,
         **auth
     )
     update_start_script(rd_server, server_ports, auth['auth_enabled'], log_level)
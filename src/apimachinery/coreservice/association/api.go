@@ -285,6 +285,20 @@ func (asst *association) ReadInstAssociation(ctx context.Context, h http.Header,
 	return
 }
 
+func (asst *association) SearchInstIDsByAsstFilter(ctx context.Context, h http.Header, objID string, input *metadata.SearchInstIDsByAsstFilterOption) (resp *metadata.SearchInstIDsByAsstFilterResult, err error) {
+	resp = new(metadata.SearchInstIDsByAsstFilterResult)
+	subPath := "/read/instanceassociation/filter/object/%s"
+
+	err = asst.client.Post().
+		WithContext(ctx).
+		Body(input).
+		SubResourcef(subPath, objID).
+		WithHeaders(h).
+		Do().
+		Into(resp)
+	return
+}
+
 func (asst *association) DeleteInstAssociation(ctx context.Context, h http.Header, input *metadata.DeleteOption) (resp *metadata.DeletedOptionResult, err error) {
 	resp = new(metadata.DeletedOptionResult)
 	subPath := "/delete/instanceassociation"
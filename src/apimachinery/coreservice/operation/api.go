@@ -146,3 +146,17 @@ func (s *operation) TimerFreshData(ctx context.Context, h http.Header, data inte
 		Into(resp)
 	return
 }
+
+func (s *operation) AggregateInstances(ctx context.Context, h http.Header, objID string, option *metadata.InstAggregationOption) (resp *metadata.InstAggregationResponse, err error) {
+	resp = new(metadata.InstAggregationResponse)
+	subPath := "/read/operation/aggregation/object/%s"
+
+	err = s.client.Post().
+		WithContext(ctx).
+		Body(option).
+		SubResourcef(subPath, objID).
+		WithHeaders(h).
+		Do().
+		Into(resp)
+	return
+}
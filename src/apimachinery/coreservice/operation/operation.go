@@ -19,6 +19,7 @@ type OperationClientInterface interface {
 	UpdateChartPosition(ctx context.Context, h http.Header, data interface{}) (resp *metadata.Response, err error)
 	SearchChartCommon(ctx context.Context, h http.Header, data interface{}) (resp *metadata.SearchChartCommon, err error)
 	TimerFreshData(ctx context.Context, h http.Header, data interface{}) (resp *metadata.BoolResponse, err error)
+	AggregateInstances(ctx context.Context, h http.Header, objID string, option *metadata.InstAggregationOption) (resp *metadata.InstAggregationResponse, err error)
 }
 
 func NewOperationClientInterface(client rest.ClientInterface) OperationClientInterface {
@@ -16,11 +16,13 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	"configcenter/src/common"
 	"configcenter/src/common/blog"
 	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
 
 	"github.com/emicklei/go-restful"
 )
@@ -62,6 +64,10 @@ func (s *service) Do(req *restful.Request, resp *restful.Response) {
 			proxyReq.Header.Set(k, v[0])
 		}
 	}
+	// a stream lasts until the client closes it, so the proxy request should be canceled along with it
+	if strings.Contains(req.Request.Header.Get("Accept"), eventStreamMIME) {
+		proxyReq = proxyReq.WithContext(req.Request.Context())
+	}
 
 	response, err := s.client.Do(proxyReq)
 	if err != nil {
@@ -88,6 +94,13 @@ func (s *service) Do(req *restful.Request, resp *restful.Response) {
 
 	resp.ResponseWriter.WriteHeader(response.StatusCode)
 
+	if strings.HasPrefix(response.Header.Get("Content-Type"), eventStreamMIME) {
+		if err := copyStream(resp, response.Body); err != nil {
+			blog.Errorf("response stream request[url: %s] failed, err: %v", req.Request.RequestURI, err)
+		}
+		return
+	}
+
 	if _, err := io.Copy(resp, response.Body); err != nil {
 		blog.Errorf("response request[url: %s] failed, err: %v", req.Request.RequestURI, err)
 		return
@@ -98,3 +111,31 @@ func (s *service) Do(req *restful.Request, resp *restful.Response) {
 		req.Request.Method, response.StatusCode, url)
 	return
 }
+
+const eventStreamMIME = "text/event-stream"
+
+// copyStream copy the stream to the client, and flush every piece of it at once
+func copyStream(resp *restful.Response, body io.Reader) error {
+	flusher, ok := util.GetHTTPFlusher(resp.ResponseWriter)
+	if !ok {
+		_, err := io.Copy(resp, body)
+		return err
+	}
+
+	buf := make([]byte, 4096)
+	for {
+		n, err := body.Read(buf)
+		if n > 0 {
+			if _, writeErr := resp.Write(buf[:n]); writeErr != nil {
+				return writeErr
+			}
+			flusher.Flush()
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -284,6 +284,40 @@ func (am *AuthManager) AuthorizeByInstanceID(ctx context.Context, header http.He
 	return am.AuthorizeByInstances(ctx, header, action, instances...)
 }
 
+// AuthorizeFindInstancesByObject checks whether the user could find the instances of the model in the business
+func (am *AuthManager) AuthorizeFindInstancesByObject(ctx context.Context, header http.Header, businessID int64, objID string) error {
+	rid := util.ExtractRequestIDFromContext(ctx)
+
+	if !am.Enabled() {
+		return nil
+	}
+
+	if am.SkipReadAuthorization {
+		blog.V(4).Infof("skip authorization for reading, model: %s, rid: %s", objID, rid)
+		return nil
+	}
+
+	resource := meta.ResourceAttribute{
+		BusinessID: businessID,
+		Basic: meta.Basic{
+			Type:   meta.ModelInstance,
+			Action: meta.FindMany,
+		},
+	}
+	switch objID {
+	case common.BKInnerObjIDApp:
+		resource.Type = meta.Business
+		resource.InstanceID = businessID
+	case common.BKInnerObjIDSet:
+		resource.Type = meta.ModelSet
+	case common.BKInnerObjIDModule:
+		resource.Type = meta.ModelModule
+	default:
+		resource.Layers = []meta.Item{{Type: meta.Model, Name: objID}}
+	}
+	return am.authorize(ctx, header, businessID, resource)
+}
+
 func (am *AuthManager) AuthorizeByInstances(ctx context.Context, header http.Header, action meta.Action, instances ...InstanceSimplify) error {
 	rid := util.ExtractRequestIDFromContext(ctx)
 
@@ -26,7 +26,10 @@ func (ps *parseStream) eventRelated() *parseStream {
 		return ps
 	}
 
-	ps.subscribe()
+	ps.subscribe().
+		deadLetter().
+		deliveryLog().
+		watch()
 
 	return ps
 }
@@ -36,6 +39,8 @@ var (
 	createSubscribeRegexp = regexp.MustCompile(`^/api/v3/event/subscribe/\S+/\d+/?$`)
 	updateSubscribeRegexp = regexp.MustCompile(`^/api/v3/event/subscribe/\S+/\d+/\d+/?$`)
 	deleteSubscribeRegexp = regexp.MustCompile(`^/api/v3/event/subscribe/\S+/\d+/\d+/?$`)
+	pauseSubscribeRegexp  = regexp.MustCompile(`^/api/v3/event/subscribe/\S+/\d+/\d+/pause/?$`)
+	resumeSubscribeRegexp = regexp.MustCompile(`^/api/v3/event/subscribe/\S+/\d+/\d+/resume/?$`)
 )
 
 const (
@@ -112,6 +117,25 @@ func (ps *parseStream) subscribe() *parseStream {
 		return ps
 	}
 
+	// pause or resume a subscription
+	if ps.hitRegexp(pauseSubscribeRegexp, http.MethodPost) || ps.hitRegexp(resumeSubscribeRegexp, http.MethodPost) {
+		subscribeID, err := strconv.ParseInt(ps.RequestCtx.Elements[6], 10, 64)
+		if err != nil {
+			ps.err = fmt.Errorf("change subscription status, but got invalid subscription id: %s", ps.RequestCtx.Elements[6])
+			return ps
+		}
+		ps.Attribute.Resources = []meta.ResourceAttribute{
+			meta.ResourceAttribute{
+				Basic: meta.Basic{
+					Type:       meta.EventPushing,
+					Action:     meta.Update,
+					InstanceID: subscribeID,
+				},
+			},
+		}
+		return ps
+	}
+
 	// telnet event for testing.
 	if ps.hitPattern(telnetEventTestPattern, http.MethodPost) {
 		ps.Attribute.Resources = []meta.ResourceAttribute{
@@ -140,3 +164,120 @@ func (ps *parseStream) subscribe() *parseStream {
 
 	return ps
 }
+
+var (
+	findDeadLetterRegexp      = regexp.MustCompile(`^/api/v3/event/deadletter/search/\S+/\d+/?$`)
+	getDeadLetterRegexp       = regexp.MustCompile(`^/api/v3/event/deadletter/\S+/\d+/\d+/?$`)
+	redeliverDeadLetterRegexp = regexp.MustCompile(`^/api/v3/event/deadletter/redeliver/\S+/\d+/?$`)
+)
+
+func (ps *parseStream) deadLetter() *parseStream {
+	if ps.shouldReturn() {
+		return ps
+	}
+
+	// find the dead letters of the subscriptions
+	if ps.hitRegexp(findDeadLetterRegexp, http.MethodPost) {
+		ps.Attribute.Resources = []meta.ResourceAttribute{
+			meta.ResourceAttribute{
+				Basic: meta.Basic{
+					Type:   meta.EventPushing,
+					Action: meta.FindMany,
+				},
+			},
+		}
+		return ps
+	}
+
+	// get a dead letter
+	if ps.hitRegexp(getDeadLetterRegexp, http.MethodGet) {
+		ps.Attribute.Resources = []meta.ResourceAttribute{
+			meta.ResourceAttribute{
+				Basic: meta.Basic{
+					Type:   meta.EventPushing,
+					Action: meta.FindMany,
+				},
+			},
+		}
+		return ps
+	}
+
+	// redeliver the dead letters
+	if ps.hitRegexp(redeliverDeadLetterRegexp, http.MethodPost) {
+		ps.Attribute.Resources = []meta.ResourceAttribute{
+			meta.ResourceAttribute{
+				Basic: meta.Basic{
+					Type:   meta.EventPushing,
+					Action: meta.UpdateMany,
+				},
+			},
+		}
+		return ps
+	}
+
+	return ps
+}
+
+var (
+	findDeliveryLogRegexp = regexp.MustCompile(`^/api/v3/event/delivery/search/\S+/\d+/?$`)
+)
+
+func (ps *parseStream) deliveryLog() *parseStream {
+	if ps.shouldReturn() {
+		return ps
+	}
+
+	// find the delivery logs of a subscription
+	if ps.hitRegexp(findDeliveryLogRegexp, http.MethodPost) {
+		ps.Attribute.Resources = []meta.ResourceAttribute{
+			meta.ResourceAttribute{
+				Basic: meta.Basic{
+					Type:   meta.EventPushing,
+					Action: meta.FindMany,
+				},
+			},
+		}
+		return ps
+	}
+
+	return ps
+}
+
+var (
+	watchEventRegexp  = regexp.MustCompile(`^/api/v3/event/watch/\S+/\d+/?$`)
+	streamEventRegexp = regexp.MustCompile(`^/api/v3/event/stream/\S+/\d+/?$`)
+)
+
+func (ps *parseStream) watch() *parseStream {
+	if ps.shouldReturn() {
+		return ps
+	}
+
+	// watch the events
+	if ps.hitRegexp(watchEventRegexp, http.MethodPost) {
+		ps.Attribute.Resources = []meta.ResourceAttribute{
+			meta.ResourceAttribute{
+				Basic: meta.Basic{
+					Type:   meta.EventPushing,
+					Action: meta.FindMany,
+				},
+			},
+		}
+		return ps
+	}
+
+	// stream the events, the business to filter by is in the query, it's authorized by the event server
+	if ps.hitRegexp(streamEventRegexp, http.MethodGet) {
+		ps.Attribute.Resources = []meta.ResourceAttribute{
+			meta.ResourceAttribute{
+				Basic: meta.Basic{
+					Type:   meta.EventPushing,
+					Action: meta.FindMany,
+				},
+			},
+		}
+		return ps
+	}
+
+	return ps
+}
@@ -193,9 +193,9 @@ func (ps *parseStream) userAPI() *parseStream {
 			meta.ResourceAttribute{
 				BusinessID: bizID,
 				Basic: meta.Basic{
-					Type:   meta.DynamicGrouping,
-					Action: meta.Execute,
-					Name:   ps.RequestCtx.Elements[5],
+					Type:         meta.DynamicGrouping,
+					Action:       meta.Execute,
+					InstanceIDEx: ps.RequestCtx.Elements[5],
 				},
 			},
 		}
@@ -25,6 +25,7 @@ import (
  http.MethodPost,  "/update/operation/chart"
  http.MethodGet,  "/search/operation/chart"
  http.MethodPost,  "/search/operation/chart/data"
+ http.MethodPost,  "/search/operation/aggregation/object/{bk_obj_id}"
 */
 var OperationStatisticAuthConfigs = []AuthConfig{
 	{
@@ -81,6 +82,15 @@ var OperationStatisticAuthConfigs = []AuthConfig{
 		ResourceType:   meta.OperationStatistic,
 		ResourceAction: meta.Update,
 	},
+	{
+		Name:           "AggregateOperationStatisticInstancesRegex",
+		Description:    "运营统计实例分组聚合",
+		Regex:          regexp.MustCompile(`^/api/v3/search/operation/aggregation/object/[^\s/]+/?$`),
+		HTTPMethod:     http.MethodPost,
+		BizIDGetter:    nil,
+		ResourceType:   meta.OperationStatistic,
+		ResourceAction: meta.Find,
+	},
 }
 
 func (ps *parseStream) OperationStatistic() *parseStream {
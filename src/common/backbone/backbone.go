@@ -30,6 +30,7 @@ import (
 	"configcenter/src/common/language"
 	"configcenter/src/common/metrics"
 	"configcenter/src/common/types"
+	"configcenter/src/storage/dal/instrument"
 )
 
 // connect svcManager retry connect time
@@ -106,12 +107,23 @@ func validateParameter(input *BackboneParameter) error {
 	return nil
 }
 
+// onProcessUpdate updates the dal instrument config of the mongodb section before the process handles the config
+func onProcessUpdate(handler cc.ProcHandlerFunc) cc.ProcHandlerFunc {
+	return func(previous, current cc.ProcessConfig) {
+		instrument.SetConfig(instrument.ParseConfigFromKV("mongodb", current.ConfigMap))
+		handler(previous, current)
+	}
+}
+
 func NewBackbone(ctx context.Context, input *BackboneParameter) (*Engine, error) {
 	if err := validateParameter(input); err != nil {
 		return nil, err
 	}
 
 	metricService := metrics.NewService(metrics.Config{ProcessName: common.GetIdentification(), ProcessInstance: input.SrvInfo.Instance()})
+	if err := instrument.Register(metricService.Registry()); err != nil {
+		return nil, fmt.Errorf("register dal metrics failed, err: %v", err)
+	}
 
 	common.SetServerInfo(input.SrvInfo)
 	client, err := newSvcManagerClient(ctx, input.Regdiscv)
@@ -148,7 +160,7 @@ func NewBackbone(ctx context.Context, input *BackboneParameter) (*Engine, error)
 	engine.metric = metricService
 
 	handler := &cc.CCHandler{
-		OnProcessUpdate:  input.ConfigUpdate,
+		OnProcessUpdate:  onProcessUpdate(input.ConfigUpdate),
 		OnLanguageUpdate: engine.onLanguageUpdate,
 		OnErrorUpdate:    engine.onErrorUpdate,
 	}
@@ -16,6 +16,7 @@ import (
 	"configcenter/src/apimachinery"
 	"configcenter/src/common/errors"
 	"configcenter/src/common/language"
+	"configcenter/src/common/metrics"
 	"configcenter/src/common/types"
 )
 
@@ -25,6 +26,7 @@ func NewMockBackbone(c *Config) (*Engine, error) {
 		SvcDisc:  &mockDisc{},
 		Language: language.NewFromCtx(language.EmptyLanguageSetting),
 		CCErr:    errors.NewFromCtx(errors.EmptyErrorsSetting),
+		metric:   metrics.NewService(metrics.Config{}),
 	}
 
 	return engine, nil
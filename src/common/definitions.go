@@ -837,10 +837,12 @@ const (
 )
 
 // event cache keys
+// the event queue keys share the {event} hash tag, so that the multi-key operations
+// between them (e.g. RPOPLPUSH) stay in the same slot when redis runs in cluster mode.
 const (
 	EventCacheEventIDKey          = BKCacheKeyV3Prefix + "event:inst_id"
-	EventCacheEventQueueKey       = BKCacheKeyV3Prefix + "event:inst_queue"
-	EventCacheEventTxnQueuePrefix = BKCacheKeyV3Prefix + "event:inst_txn_queue:"
+	EventCacheEventQueueKey       = BKCacheKeyV3Prefix + "{event}:inst_queue"
+	EventCacheEventTxnQueuePrefix = BKCacheKeyV3Prefix + "{event}:inst_txn_queue:"
 	EventCacheEventTxnSet         = BKCacheKeyV3Prefix + "event:txn_set"
 	RedisSnapKeyPrefix            = BKCacheKeyV3Prefix + "snapshot:"
 )
@@ -496,6 +496,18 @@ const (
 	CCErrEventSubscribeTelnetFailed = 1103005
 	// CCErrEventOperateSuccessBUtSentEventFailed failed to sent event
 	CCErrEventPushEventFailed = 1103006
+	// CCErrEventDeadLetterSelectFailed failed to select the dead letter events
+	CCErrEventDeadLetterSelectFailed = 1103007
+	// CCErrEventDeadLetterRedeliverFailed failed to redeliver the dead letter events
+	CCErrEventDeadLetterRedeliverFailed = 1103008
+	// CCErrEventSubscribeSecretUnavailable the subscription secret can not be saved
+	CCErrEventSubscribeSecretUnavailable = 1103009
+	// CCErrEventWatchFailed failed to watch the events
+	CCErrEventWatchFailed = 1103010
+	// CCErrEventWatchCursorExpired the events after the watch cursor have been expired
+	CCErrEventWatchCursorExpired = 1103011
+	// CCErrEventDeliveryLogSelectFailed failed to select the delivery logs
+	CCErrEventDeliveryLogSelectFailed = 1103012
 
 	// host 1104XXX
 	CCErrHostModuleRelationAddFailed = 1104000
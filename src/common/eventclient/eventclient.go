@@ -25,6 +25,7 @@ import (
 	"configcenter/src/common/metadata"
 	"configcenter/src/common/util"
 	"configcenter/src/storage/dal"
+	ccredis "configcenter/src/storage/dal/redis"
 
 	"github.com/google/go-cmp/cmp"
 	"gopkg.in/redis.v5"
@@ -46,13 +47,13 @@ func NewEventWithHeader(header http.Header) *metadata.EventInst {
 
 type ClientViaRedis struct {
 	rdb       dal.RDB
-	cache     *redis.Client
+	cache     ccredis.Client
 	queue     chan *eventtmp
 	pending   *eventtmp
 	queueLock sync.Mutex
 }
 
-func NewClientViaRedis(cache *redis.Client, rdb dal.RDB) *ClientViaRedis {
+func NewClientViaRedis(cache ccredis.Client, rdb dal.RDB) *ClientViaRedis {
 	// we limit the queue size to 4k*2500=10M， assume that 4k per event
 	const queueSize = 2500
 
@@ -5,13 +5,13 @@ import (
 	"time"
 
 	"github.com/rs/xid"
-	redis "gopkg.in/redis.v5"
 
 	"configcenter/src/common"
+	"configcenter/src/storage/dal/redis"
 )
 
 type lock struct {
-	cache *redis.Client
+	cache redis.Client
 	key   string
 	// 是否需要释放key
 	needUnlock bool
@@ -25,7 +25,7 @@ type Locker interface {
 	Unlock() error
 }
 
-func NewLocker(cache *redis.Client) Locker {
+func NewLocker(cache redis.Client) Locker {
 
 	return &lock{
 		isFirst:    false,
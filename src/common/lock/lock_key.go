@@ -11,6 +11,9 @@ const (
 
 	// CreateModuleAttrFormat create model  attribute format
 	CreateModuleAttrFormat = "coreservice:create:model:%s:attr:%s"
+
+	// EventWatchSequenceKey event watch record sequence key
+	EventWatchSequenceKey = "eventserver:watch:sequence"
 )
 
 // StrFormat  build  lock key format
@@ -13,7 +13,10 @@
 package metadata
 
 import (
+	"fmt"
+
 	"configcenter/src/common/mapstr"
+	"configcenter/src/common/querybuilder"
 )
 
 const (
@@ -493,3 +496,78 @@ type NodeTopoPath struct {
 	Node  TopoNode                    `json:"topo_node" mapstructure:"topo_node"`
 	Path  []*TopoInstanceNodeSimplify `json:"topo_path" mapstructure:"topo_path"`
 }
+
+// InstAsstFilterDirection 关联过滤中被查询实例在关联关系中的方向
+type InstAsstFilterDirection string
+
+const (
+	// InstAsstFilterDirectionSrc 被查询实例是关联关系的源实例
+	InstAsstFilterDirectionSrc InstAsstFilterDirection = "src"
+	// InstAsstFilterDirectionDest 被查询实例是关联关系的目标实例
+	InstAsstFilterDirectionDest InstAsstFilterDirection = "dest"
+	// InstAsstFilterDirectionBoth 不区分方向
+	InstAsstFilterDirectionBoth InstAsstFilterDirection = "both"
+)
+
+// InstAsstFilterMaxHops 关联过滤最多可以跨越的关联层数
+const InstAsstFilterMaxHops = 3
+
+// InstAsstFilterMaxInstances 关联过滤每一层最多可以关联到的实例数，以及最多返回的实例ID数
+const InstAsstFilterMaxInstances = 10000
+
+// InstAsstFilter 根据关联实例的属性过滤实例，被查询的实例需要经过不超过MaxHops层的关联关系关联到
+// 模型ObjectID中满足Condition的实例
+type InstAsstFilter struct {
+	// 关联实例的模型ID
+	ObjectID string `json:"bk_obj_id"`
+	// 关联类型ID，为空则不限制关联类型
+	AsstKindID string `json:"bk_asst_id,omitempty"`
+	// 被查询实例在关联关系中的方向，为空则不区分方向
+	Direction InstAsstFilterDirection `json:"direction,omitempty"`
+	// 最多跨越的关联层数，默认为1
+	MaxHops int `json:"max_hops,omitempty"`
+	// 关联实例需要满足的条件，为空则不限制关联实例
+	Condition *querybuilder.QueryFilter `json:"condition,omitempty"`
+}
+
+// Validate 校验关联过滤条件，并设置默认值，返回出错的字段名
+func (f *InstAsstFilter) Validate() (string, error) {
+	if len(f.ObjectID) == 0 {
+		return "bk_obj_id", fmt.Errorf("bk_obj_id is required")
+	}
+
+	switch f.Direction {
+	case "":
+		f.Direction = InstAsstFilterDirectionBoth
+	case InstAsstFilterDirectionSrc, InstAsstFilterDirectionDest, InstAsstFilterDirectionBoth:
+	default:
+		return "direction", fmt.Errorf("unknown direction %s", f.Direction)
+	}
+
+	if f.MaxHops == 0 {
+		f.MaxHops = 1
+	}
+	if f.MaxHops < 0 || f.MaxHops > InstAsstFilterMaxHops {
+		return "max_hops", fmt.Errorf("max_hops should be in range [1, %d]", InstAsstFilterMaxHops)
+	}
+
+	if f.Condition != nil {
+		if key, err := f.Condition.Validate(); err != nil {
+			return "condition." + key, err
+		}
+	}
+	return "", nil
+}
+
+// SearchInstIDsByAsstFilterOption 根据关联实例的属性查询实例ID的参数，多个过滤条件之间是与的关系
+type SearchInstIDsByAsstFilterOption struct {
+	// 业务ID，不为0时关联实例和关联关系都限制在该业务内
+	BizID   int64            `json:"bk_biz_id,omitempty"`
+	Filters []InstAsstFilter `json:"filters"`
+}
+
+// SearchInstIDsByAsstFilterResult 根据关联实例的属性查询实例ID的结果
+type SearchInstIDsByAsstFilterResult struct {
+	BaseResp `json:",inline"`
+	Data     []int64 `json:"data"`
+}
@@ -20,6 +20,7 @@ import (
 	"configcenter/src/common"
 	"configcenter/src/common/errors"
 	"configcenter/src/common/mapstr"
+	"configcenter/src/common/querybuilder"
 	"configcenter/src/common/util"
 
 	"github.com/coccyx/timeparser"
@@ -113,6 +114,8 @@ type QueryInput struct {
 	Start     int         `json:"start,omitempty"`
 	Limit     int         `json:"limit,omitempty"`
 	Sort      string      `json:"sort,omitempty"`
+	// PropertyFilter is the querybuilder rules or expression combined with the condition, optional
+	PropertyFilter *querybuilder.QueryFilter `json:"property_filter,omitempty"`
 }
 
 // ConvTime cc_type key
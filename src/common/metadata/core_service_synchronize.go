@@ -95,6 +95,8 @@ type SynchronizeFindInfoParameter struct {
 	Condition    mapstr.MapStr              `json:"condition"`
 	Start        uint64                     `json:"start"`
 	Limit        uint64                     `json:"limit"`
+	// Sort the comma separated sort fields, the data could be paged by the last fetched keys with it
+	Sort string `json:"sort,omitempty"`
 }
 
 // SynchronizeResult synchronize result
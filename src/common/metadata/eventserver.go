@@ -13,12 +13,17 @@
 package metadata
 
 import (
+	"crypto/sha256"
 	"database/sql/driver"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"sort"
 	"strings"
 	"time"
+
+	"configcenter/src/common/querybuilder"
 )
 
 type RspSubscriptionCreate struct {
@@ -67,8 +72,159 @@ type Subscription struct {
 	OwnerID          string      `bson:"bk_supplier_account" json:"bk_supplier_account"`
 	LastTime         Time        `bson:"last_time" json:"last_time"`
 	Statistics       *Statistics `bson:"-" json:"operation"`
+	// RetryPolicy defines how failed callbacks are retried, nil means never retry
+	RetryPolicy *RetryPolicy `bson:"retry_policy" json:"retry_policy"`
+	// Secret is used to sign the callback payloads, it's plain text in request and encrypted in storage
+	Secret string `bson:"secret" json:"secret,omitempty"`
+	// PreviousSecret is the encrypted secret before the last rotation, callbacks are also signed with it
+	// until PreviousSecretExpireTime, so that the subscriber could switch to the new secret smoothly.
+	PreviousSecret           string `bson:"previous_secret" json:"previous_secret,omitempty"`
+	PreviousSecretExpireTime Time   `bson:"previous_secret_expire_time" json:"previous_secret_expire_time"`
+	// SecretOverlapSeconds is only used in request, it's the seconds the previous secret is still valid after rotation
+	SecretOverlapSeconds int64 `bson:"-" json:"secret_overlap_seconds,omitempty"`
+	// ClearSecret is only used in request, it removes the secret and the previous secret of the subscription
+	ClearSecret bool `bson:"-" json:"clear_secret,omitempty"`
+	HasSecret   bool `bson:"-" json:"has_secret"`
+	// Filter is a rule in querybuilder format, only the events match it are sent to the subscriber.
+	// the rule is checked against every data of the event, available fields are cur_data.xxx,
+	// pre_data.xxx and changed_fields, which is the list of fields changed in this event.
+	Filter map[string]interface{} `bson:"filter" json:"filter"`
+	// BatchPolicy makes the events sent to the subscriber in batches, nil means sending the events one by one
+	BatchPolicy *BatchPolicy `bson:"batch_policy" json:"batch_policy"`
+	// Status is the status of the subscription, the events are only sent to an active subscription,
+	// empty status means active for the subscriptions created before the status is introduced.
+	Status string `bson:"status" json:"status"`
+	// PayloadOption defines the shape of the callback payloads, nil means the full documents in v1 format
+	PayloadOption *PayloadOption `bson:"payload_option" json:"payload_option"`
+}
+
+// subscription status
+const (
+	SubscriptionStatusActive = "active"
+	// SubscriptionStatusPaused means the subscription is paused by the user
+	SubscriptionStatusPaused = "paused"
+	// SubscriptionStatusSuspended means the subscription is suspended after too many consecutive failures
+	SubscriptionStatusSuspended = "suspended"
+)
+
+// BatchPolicy define how the events are batched, in batch mode the callback payload is a json array
+// of the events in order, and the confirm mode is checked against the response of the whole batch.
+type BatchPolicy struct {
+	// MaxSize is the max number of events in a batch
+	MaxSize int64 `bson:"max_size" json:"max_size"`
+	// MaxWaitMilliseconds is the longest time to wait for more events once there is an event to send
+	MaxWaitMilliseconds int64 `bson:"max_wait_ms" json:"max_wait_ms"`
+}
+
+// batch policy limits
+const (
+	BatchMaxSizeLimit             = 1000
+	BatchMaxWaitMillisecondsLimit = 60000
+)
+
+// Validate validate the batch policy, returns the invalid field if any
+func (b BatchPolicy) Validate() (string, error) {
+	if b.MaxSize < 0 || b.MaxSize > BatchMaxSizeLimit {
+		return "max_size", fmt.Errorf("max_size should between 0 and %d", BatchMaxSizeLimit)
+	}
+	if b.MaxWaitMilliseconds < 0 || b.MaxWaitMilliseconds > BatchMaxWaitMillisecondsLimit {
+		return "max_wait_ms", fmt.Errorf("max_wait_ms should between 0 and %d", BatchMaxWaitMillisecondsLimit)
+	}
+	return "", nil
+}
+
+// PayloadOption define the shape of the callback payloads of a subscription
+type PayloadOption struct {
+	// Version is the version of the payload shape, empty means v1, which is the full cur_data and pre_data.
+	// Mode and Fields are only available in v2, the payloads of v2 carry the version in payload_version.
+	Version string `bson:"version" json:"version"`
+	// Mode is full or diff, full sends cur_data and pre_data, diff sends only the changed fields
+	// with their old and new values, empty means full
+	Mode string `bson:"mode" json:"mode"`
+	// Fields is the projection list, only these fields are sent, empty means all the fields
+	Fields []string `bson:"fields" json:"fields"`
+}
+
+// callback payload versions
+const (
+	EventPayloadVersionV1 = "v1"
+	EventPayloadVersionV2 = "v2"
+)
+
+// callback payload modes
+const (
+	EventPayloadModeFull = "full"
+	EventPayloadModeDiff = "diff"
+)
+
+// EventPayloadFieldsLimit is the max number of fields in the projection list
+const EventPayloadFieldsLimit = 200
+
+// Validate validate the payload option, returns the invalid field if any
+func (p PayloadOption) Validate() (string, error) {
+	switch p.Version {
+	case "", EventPayloadVersionV1:
+		if p.Mode != "" || len(p.Fields) != 0 {
+			return "version", errors.New("mode and fields are only available in version v2")
+		}
+		return "", nil
+	case EventPayloadVersionV2:
+	default:
+		return "version", fmt.Errorf("version should be %s or %s", EventPayloadVersionV1, EventPayloadVersionV2)
+	}
+
+	if p.Mode != "" && p.Mode != EventPayloadModeFull && p.Mode != EventPayloadModeDiff {
+		return "mode", fmt.Errorf("mode should be %s or %s", EventPayloadModeFull, EventPayloadModeDiff)
+	}
+	if len(p.Fields) > EventPayloadFieldsLimit {
+		return "fields", fmt.Errorf("fields exceed the limit %d", EventPayloadFieldsLimit)
+	}
+	for _, field := range p.Fields {
+		if field == "" {
+			return "fields", errors.New("field should not be empty")
+		}
+	}
+	return "", nil
+}
+
+// RetryPolicy define the retry strategy of a failed callback
+type RetryPolicy struct {
+	// MaxAttempts is the max times an event will be sent, the first attempt included
+	MaxAttempts int64 `bson:"max_attempts" json:"max_attempts"`
+	// BackoffSeconds is the interval before the first retry, it doubles on every retry
+	BackoffSeconds int64 `bson:"backoff_seconds" json:"backoff_seconds"`
+	// MaxBackoffSeconds is the upper limit of the interval between two attempts
+	MaxBackoffSeconds int64 `bson:"max_backoff_seconds" json:"max_backoff_seconds"`
+	// Jitter is a ratio in [0, 1], the interval is randomly shortened by at most this ratio
+	Jitter float64 `bson:"jitter" json:"jitter"`
+}
+
+// retry policy limits
+const (
+	RetryMaxAttemptsLimit       = 10
+	RetryMaxBackoffSecondsLimit = 3600
+)
+
+// Validate validate the retry policy, returns the invalid field if any
+func (r RetryPolicy) Validate() (string, error) {
+	if r.MaxAttempts < 0 || r.MaxAttempts > RetryMaxAttemptsLimit {
+		return "max_attempts", fmt.Errorf("max_attempts should between 0 and %d", RetryMaxAttemptsLimit)
+	}
+	if r.BackoffSeconds < 0 || r.BackoffSeconds > RetryMaxBackoffSecondsLimit {
+		return "backoff_seconds", fmt.Errorf("backoff_seconds should between 0 and %d", RetryMaxBackoffSecondsLimit)
+	}
+	if r.MaxBackoffSeconds < 0 || r.MaxBackoffSeconds > RetryMaxBackoffSecondsLimit {
+		return "max_backoff_seconds", fmt.Errorf("max_backoff_seconds should between 0 and %d", RetryMaxBackoffSecondsLimit)
+	}
+	if r.Jitter < 0 || r.Jitter > 1 {
+		return "jitter", errors.New("jitter should between 0 and 1")
+	}
+	return "", nil
 }
 
+// SecretOverlapSecondsLimit is the upper limit of the seconds the previous secret is still valid after rotation
+const SecretOverlapSecondsLimit = 7 * 24 * 60 * 60
+
 // Report define sending statistic
 type Statistics struct {
 	Total   int64 `json:"total"`
@@ -90,15 +246,67 @@ func (s Subscription) GetCacheKey() string {
 		ConfirmPattern:   s.ConfirmPattern,
 		SubscriptionForm: s.SubscriptionForm,
 		TimeOutSeconds:   s.TimeOutSeconds,
+		OwnerID:          s.OwnerID,
+		RetryPolicy:      s.RetryPolicy,
+
+		Filter:        s.Filter,
+		BatchPolicy:   s.BatchPolicy,
+		Status:        s.Status,
+		PayloadOption: s.PayloadOption,
 	}
 	b, _ := json.Marshal(ns)
 	return string(b)
 }
 
+// GetSecretDigest returns the digest of the secrets, the secrets are not in the cache key which may be logged,
+// so the changes of the secrets are compared with this digest.
+func (s Subscription) GetSecretDigest() string {
+	digest := sha256.Sum256([]byte(s.Secret + "\n" + s.PreviousSecret + "\n" + s.PreviousSecretExpireTime.UTC().Format(time.RFC3339Nano)))
+	return hex.EncodeToString(digest[:])
+}
+
 func (s Subscription) GetTimeout() time.Duration {
 	return time.Second * time.Duration(s.TimeOutSeconds)
 }
 
+// IsActive returns true if the events should be sent to the subscriber
+func (s Subscription) IsActive() bool {
+	return s.Status == "" || s.Status == SubscriptionStatusActive
+}
+
+// IsBatchMode returns true if the events are sent to the subscriber in batches
+func (s Subscription) IsBatchMode() bool {
+	return s.BatchPolicy != nil && s.BatchPolicy.MaxSize > 1
+}
+
+// IsCustomPayload returns true if the callback payloads are not in the default v1 format
+func (s Subscription) IsCustomPayload() bool {
+	return s.PayloadOption != nil && s.PayloadOption.Version == EventPayloadVersionV2
+}
+
+// ParseFilter parse the filter of the subscription, returns nil if no filter is set
+func (s Subscription) ParseFilter() (querybuilder.Rule, string, error) {
+	if len(s.Filter) == 0 {
+		return nil, "", nil
+	}
+	return querybuilder.ParseRule(s.Filter)
+}
+
+// HideSecrets remove the secrets so that the subscription could be returned to the user
+func (s *Subscription) HideSecrets() {
+	s.HasSecret = len(s.Secret) > 0
+	s.Secret = ""
+	s.PreviousSecret = ""
+}
+
+// GetMaxAttempts returns the max times an event will be sent to the subscriber
+func (s Subscription) GetMaxAttempts() int64 {
+	if s.RetryPolicy == nil || s.RetryPolicy.MaxAttempts <= 0 {
+		return 1
+	}
+	return s.RetryPolicy.MaxAttempts
+}
+
 type EventInst struct {
 	ID          int64       `json:"event_id,omitempty"`
 	TxnID       string      `json:"txn_id"`
@@ -142,11 +350,215 @@ type DistInst struct {
 	SubscriptionID int64 `json:"subscription_id"`
 }
 
+// event callback headers
+const (
+	// EventSignatureHeader contains the signatures of the payload, separated by comma, each signature
+	// is sha256=hex(hmac-sha256(secret, timestamp + "." + payload)). there are two signatures while the
+	// previous secret is still valid after the secret rotated.
+	EventSignatureHeader = "X-Bkcmdb-Signature"
+	// EventTimestampHeader is the unix timestamp when the payload is signed
+	EventTimestampHeader = "X-Bkcmdb-Timestamp"
+	// EventDeliveryIDHeader is a unique id of every delivery, retries of the same event share the same id
+	EventDeliveryIDHeader = "X-Bkcmdb-Delivery"
+)
+
+// fields of the document a subscription filter is checked against
+const (
+	EventFilterCurDataField       = "cur_data"
+	EventFilterPreDataField       = "pre_data"
+	EventFilterChangedFieldsField = "changed_fields"
+)
+
+// DistInstPayload is a distribution in v2 payload format, its data is shaped by the payload option
+type DistInstPayload struct {
+	DistInst
+	PayloadVersion string             `json:"payload_version"`
+	Data           []EventPayloadData `json:"data"`
+}
+
+// EventPayloadData is a data of the event in v2 payload format, cur_data and pre_data are sent in full mode,
+// inst_id and changed are sent in diff mode.
+type EventPayloadData struct {
+	CurData map[string]interface{} `json:"cur_data,omitempty"`
+	PreData map[string]interface{} `json:"pre_data,omitempty"`
+	// InstID is the value of the instance id field, it's omitted if the event has no such field
+	InstID  interface{}                   `json:"inst_id,omitempty"`
+	Changed map[string]EventPayloadChange `json:"changed,omitempty"`
+}
+
+// EventPayloadChange is the old and new value of a changed field
+type EventPayloadChange struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
 type DistInstCtx struct {
 	DistInst
 	Raw string
 }
 
+// EventDeadLetter is an event which still failed to be sent to the subscriber after all retries
+type EventDeadLetter struct {
+	ID             int64  `bson:"id" json:"id"`
+	SubscriptionID int64  `bson:"subscription_id" json:"subscription_id"`
+	DstbID         int64  `bson:"distribution_id" json:"distribution_id"`
+	EventID        int64  `bson:"event_id" json:"event_id"`
+	EventType      string `bson:"event_type" json:"event_type"`
+	ObjType        string `bson:"obj_type" json:"obj_type"`
+	Action         string `bson:"action" json:"action"`
+	// Payload is the raw distribution which should be sent to the subscriber
+	Payload    string `bson:"payload" json:"payload"`
+	Attempts   int64  `bson:"attempts" json:"attempts"`
+	LastError  string `bson:"last_error" json:"last_error"`
+	OwnerID    string `bson:"bk_supplier_account" json:"bk_supplier_account"`
+	CreateTime Time   `bson:"create_time" json:"create_time"`
+}
+
+type ParamDeadLetterSearch struct {
+	Fields    []string               `json:"fields"`
+	Condition map[string]interface{} `json:"condition"`
+	Page      BasePage               `json:"page"`
+}
+
+type RspDeadLetterSearch struct {
+	Count uint64            `json:"count"`
+	Info  []EventDeadLetter `json:"info"`
+}
+
+type ParamDeadLetterRedeliver struct {
+	IDs []int64 `json:"ids"`
+}
+
+// RspDeadLetterRedeliver is the result of each dead letter to be redelivered
+type RspDeadLetterRedeliver struct {
+	Redelivered []int64                   `json:"redelivered"`
+	Failed      []DeadLetterRedeliverFail `json:"failed"`
+}
+
+// DeadLetterRedeliverFail is a dead letter which is not redelivered and the reason
+type DeadLetterRedeliverFail struct {
+	ID    int64  `json:"id"`
+	Error string `json:"error"`
+}
+
+// EventDeliveryLog is the record of a callback request sent to the subscriber, every attempt has its own log
+type EventDeliveryLog struct {
+	SubscriptionID int64  `json:"subscription_id"`
+	DeliveryID     string `json:"delivery_id"`
+	Attempt        int64  `json:"attempt"`
+	// DistributionIDs are the ids of the dists sent in the request, there are more than one in batch mode
+	DistributionIDs []int64 `json:"distribution_ids"`
+	CallbackURL     string  `json:"callback_url"`
+	// Request and Response are the bodies truncated to EventDeliveryLogBodyLimit
+	Request              string `json:"request"`
+	StatusCode           int    `json:"status_code"`
+	Response             string `json:"response"`
+	Success              bool   `json:"success"`
+	Error                string `json:"error"`
+	DurationMilliseconds int64  `json:"duration_ms"`
+	CreateTime           Time   `json:"create_time"`
+}
+
+// delivery log limits
+const (
+	// EventDeliveryLogCapacity is the max number of delivery logs kept for a subscription
+	EventDeliveryLogCapacity  = 1000
+	EventDeliveryLogBodyLimit = 1024
+)
+
+// ParamDeliveryLogSearch search the delivery logs of a subscription, the logs are returned newest first
+type ParamDeliveryLogSearch struct {
+	SubscriptionID int64    `json:"subscription_id"`
+	DeliveryID     string   `json:"delivery_id"`
+	DistributionID int64    `json:"distribution_id"`
+	Success        *bool    `json:"success"`
+	StatusCode     int      `json:"status_code"`
+	StartTime      *Time    `json:"start_time"`
+	EndTime        *Time    `json:"end_time"`
+	Page           BasePage `json:"page"`
+}
+
+// Match returns true if the log matches all the conditions
+func (p ParamDeliveryLogSearch) Match(log *EventDeliveryLog) bool {
+	if p.DeliveryID != "" && p.DeliveryID != log.DeliveryID {
+		return false
+	}
+	if p.DistributionID > 0 {
+		found := false
+		for _, id := range log.DistributionIDs {
+			if id == p.DistributionID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if p.Success != nil && *p.Success != log.Success {
+		return false
+	}
+	if p.StatusCode != 0 && p.StatusCode != log.StatusCode {
+		return false
+	}
+	if p.StartTime != nil && log.CreateTime.Before(p.StartTime.Time) {
+		return false
+	}
+	if p.EndTime != nil && log.CreateTime.After(p.EndTime.Time) {
+		return false
+	}
+	return true
+}
+
+type RspDeliveryLogSearch struct {
+	Count uint64             `json:"count"`
+	Info  []EventDeliveryLog `json:"info"`
+}
+
+// EventWatchRecord is an event kept for the watch api, records are removed by the ttl index on create_time
+type EventWatchRecord struct {
+	// Sequence is given when the record is saved, the records are saved in the order of the sequence,
+	// it's the cursor of the watchers
+	Sequence  int64  `bson:"sequence" json:"sequence"`
+	EventID   int64  `bson:"event_id" json:"event_id"`
+	EventType string `bson:"event_type" json:"event_type"`
+	ObjType   string `bson:"obj_type" json:"obj_type"`
+	Action    string `bson:"action" json:"action"`
+	OwnerID   string `bson:"bk_supplier_account" json:"bk_supplier_account"`
+	// BizIDs are the business ids found in the data of the event
+	BizIDs []int64 `bson:"bk_biz_id" json:"bk_biz_id"`
+	// Event is the raw event in json
+	Event      string `bson:"event" json:"event"`
+	CreateTime Time   `bson:"create_time" json:"create_time"`
+}
+
+// event watch limits
+const (
+	EventWatchDefaultLimit          = 200
+	EventWatchMaxLimit              = 1000
+	EventWatchDefaultTimeoutSeconds = 30
+	EventWatchMaxTimeoutSeconds     = 60
+)
+
+// ParamEventWatch watch the events after the cursor, the request is held until there are events
+// or the timeout is reached.
+type ParamEventWatch struct {
+	// Cursor is the cursor returned by the last watch, 0 means watching from the oldest event kept
+	Cursor int64 `json:"cursor"`
+	// Resources are the object types to watch, such as host, module or a custom object id, empty means all
+	Resources []string `json:"resources"`
+	// Limit is the max number of events returned at once
+	Limit int64 `json:"limit"`
+	// TimeoutSeconds is the longest time to wait for events
+	TimeoutSeconds int64 `json:"timeout"`
+}
+
+// RspEventWatch returns the events in order, the watcher should use Cursor in the next watch request
+type RspEventWatch struct {
+	Cursor int64       `json:"cursor"`
+	Events []EventInst `json:"events"`
+}
+
 // EventAction
 const (
 	EventActionCreate = "create"
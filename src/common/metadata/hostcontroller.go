@@ -13,9 +13,11 @@
 package metadata
 
 import (
+	"fmt"
 	"time"
 
 	"configcenter/src/common/mapstr"
+	"configcenter/src/common/querybuilder"
 )
 
 type ID struct {
@@ -142,6 +144,7 @@ type ModuleHostConfigParams struct {
 type UserConfig struct {
 	Info       string    `json:"info" bson:"info"`
 	Name       string    `json:"name" bson:"name"`
+	ObjectID   string    `json:"bk_obj_id" bson:"bk_obj_id"`
 	ID         string    `json:"id" bson:"id"`
 	CreateTime time.Time `json:"create_time" bson:"create_time"`
 	UpdateTime time.Time `json:"last_time" bson:"last_time"`
@@ -197,6 +200,7 @@ type UserConfigMeta struct {
 	AppID      int64     `json:"bk_biz_id,omitempty" bson:"bk_biz_id,omitempty"`
 	Info       string    `json:"info,omitempty" bson:"info,omitempty"`
 	Name       string    `json:"name,omitempty" bson:"name,omitempty"`
+	ObjectID   string    `json:"bk_obj_id,omitempty" bson:"bk_obj_id,omitempty"`
 	ID         string    `json:"id,omitempty" bson:"id,omitempty"`
 	CreateTime time.Time `json:"create_time" bson:"create_time,omitempty"`
 	CreateUser string    `json:"create_user" bson:"create_user,omitempty"`
@@ -209,9 +213,36 @@ type AddConfigQuery struct {
 	AppID      int64  `json:"bk_biz_id,omitempty"`
 	Info       string `json:"info,omitempty"`
 	Name       string `json:"name,omitempty"`
+	ObjectID   string `json:"bk_obj_id,omitempty"`
 	CreateUser string `json:"create_user,omitempty"`
 }
 
+// DynamicGroupInfo is the info of a dynamic group of the instances of a model other than host,
+// the info of a host dynamic group is HostCommonSearch.
+type DynamicGroupInfo struct {
+	// Condition is the querybuilder rules or expression of the instance attributes
+	Condition *querybuilder.QueryFilter `json:"condition,omitempty"`
+	// AssociationFilters filters the instances by the attributes of their associated instances
+	AssociationFilters []InstAsstFilter `json:"association_filters,omitempty"`
+	// Fields is the returned fields of the instances, all the fields are returned if it's empty
+	Fields []string `json:"fields,omitempty"`
+}
+
+// Validate validates the dynamic group info, the invalid key is returned if it's not valid
+func (d *DynamicGroupInfo) Validate() (string, error) {
+	if d.Condition != nil {
+		if key, err := d.Condition.Validate(); err != nil {
+			return "condition." + key, err
+		}
+	}
+	for idx := range d.AssociationFilters {
+		if key, err := d.AssociationFilters[idx].Validate(); err != nil {
+			return fmt.Sprintf("association_filters[%d].%s", idx, key), err
+		}
+	}
+	return "", nil
+}
+
 type CloudTaskSearch struct {
 	Count uint64          `json:"count"`
 	Info  []CloudTaskInfo `json:"info"`
@@ -0,0 +1,71 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metadata
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDynamicGroupInfoValidate(t *testing.T) {
+	testCases := []struct {
+		name string
+		info string
+		key  string
+	}{
+		{name: "empty", info: `{}`},
+		{
+			name: "condition",
+			info: `{"condition": {"condition": "AND", "rules": [{"field": "bk_inst_name", "operator": "equal", "value": "a"}]}, "fields": ["bk_inst_name"]}`,
+		},
+		{
+			name: "condition not combined",
+			info: `{"condition": {"field": "bk_inst_name", "operator": "equal", "value": "a"}}`,
+			key:  "condition.",
+		},
+		{
+			name: "association filter",
+			info: `{"association_filters": [{"bk_obj_id": "host", "direction": "src", "max_hops": 2}]}`,
+		},
+		{
+			name: "association filter without object",
+			info: `{"association_filters": [{"bk_obj_id": "host"}, {"direction": "dest"}]}`,
+			key:  "association_filters[1].bk_obj_id",
+		},
+		{
+			name: "association filter exceeds max hops",
+			info: `{"association_filters": [{"bk_obj_id": "host", "max_hops": 4}]}`,
+			key:  "association_filters[0].max_hops",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			info := DynamicGroupInfo{}
+			require.NoError(t, json.Unmarshal([]byte(testCase.info), &info))
+			key, err := info.Validate()
+			require.Equal(t, testCase.key, key)
+			if len(testCase.key) == 0 {
+				require.NoError(t, err)
+				for _, filter := range info.AssociationFilters {
+					require.NotEmpty(t, filter.Direction)
+					require.NotZero(t, filter.MaxHops)
+				}
+				return
+			}
+			require.Error(t, err)
+		})
+	}
+}
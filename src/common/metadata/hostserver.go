@@ -113,6 +113,8 @@ type HostCommonSearch struct {
 	Condition []SearchCondition `json:"condition"`
 	Page      BasePage          `json:"page"`
 	Pattern   string            `json:"pattern,omitempty"`
+	// AssociationFilters filter the hosts by the attributes of the associated instances
+	AssociationFilters []InstAsstFilter `json:"association_filters,omitempty"`
 }
 
 type HostModuleFind struct {
@@ -13,9 +13,12 @@
 package metadata
 
 import (
+	"fmt"
+	"regexp"
 	"time"
 
 	"configcenter/src/common"
+	"configcenter/src/common/querybuilder"
 )
 
 type ChartConfig struct {
@@ -247,3 +250,137 @@ var (
 		common.ModelInstChangeChart,
 	}
 )
+
+// 模型实例分组聚合的统计方法
+const (
+	AggregationOpCount         = "count"
+	AggregationOpDistinctCount = "distinct_count"
+	AggregationOpSum           = "sum"
+	AggregationOpAvg           = "avg"
+	AggregationOpMin           = "min"
+	AggregationOpMax           = "max"
+)
+
+const (
+	// InstAggregationMaxGroupBy 分组字段的最大个数
+	InstAggregationMaxGroupBy = 5
+	// InstAggregationMaxMetrics 统计指标的最大个数
+	InstAggregationMaxMetrics = 10
+)
+
+var aggregationMetricNameRegexp = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_]*$`)
+
+// AggregationMetric 分组聚合的统计指标
+type AggregationMetric struct {
+	// 指标名，即结果中指标的key，默认为 op 或 op_field
+	Name string `json:"name,omitempty"`
+	// 统计方法，count, distinct_count, sum, avg, min, max
+	Op string `json:"op"`
+	// 统计的字段，count 不需要，sum, avg, min, max 只支持数字类型的字段
+	Field string `json:"field,omitempty"`
+}
+
+// InstAggregationOption 模型实例的分组聚合参数
+type InstAggregationOption struct {
+	// 业务ID，不为空时只统计该业务下的实例
+	BizID int64 `json:"bk_biz_id,omitempty"`
+	// 实例的过滤条件
+	Filter *querybuilder.QueryFilter `json:"filter,omitempty"`
+	// 分组字段，主机可以按拓扑字段 bk_biz_id, bk_set_id, bk_module_id 分组，为空则所有实例为一组
+	GroupBy []string `json:"group_by"`
+	// 统计指标，为空则统计实例个数
+	Metrics []AggregationMetric `json:"metrics"`
+	// 分组的分页，可以按分组字段或指标名排序，默认按分组字段升序
+	Page BasePage `json:"page"`
+}
+
+// Validate 校验参数并设置默认值，返回出错的字段名
+func (o *InstAggregationOption) Validate() (string, error) {
+	if len(o.GroupBy) > InstAggregationMaxGroupBy {
+		return "group_by", fmt.Errorf("exceed max group by fields %d", InstAggregationMaxGroupBy)
+	}
+	groupBy := make(map[string]bool)
+	for _, field := range o.GroupBy {
+		if len(field) == 0 || groupBy[field] {
+			return "group_by", fmt.Errorf("group by field %s is empty or duplicated", field)
+		}
+		groupBy[field] = true
+	}
+
+	if len(o.Metrics) == 0 {
+		o.Metrics = []AggregationMetric{{Op: AggregationOpCount}}
+	}
+	if len(o.Metrics) > InstAggregationMaxMetrics {
+		return "metrics", fmt.Errorf("exceed max metrics %d", InstAggregationMaxMetrics)
+	}
+	names := make(map[string]bool)
+	for index := range o.Metrics {
+		metric := &o.Metrics[index]
+		switch metric.Op {
+		case AggregationOpCount:
+		case AggregationOpDistinctCount, AggregationOpSum, AggregationOpAvg, AggregationOpMin, AggregationOpMax:
+			if len(metric.Field) == 0 {
+				return "metrics.field", fmt.Errorf("field is required for metric %s", metric.Op)
+			}
+		default:
+			return "metrics.op", fmt.Errorf("unsupported metric %s", metric.Op)
+		}
+
+		if len(metric.Name) == 0 {
+			metric.Name = metric.Op
+			if len(metric.Field) > 0 {
+				metric.Name = metric.Op + "_" + metric.Field
+			}
+		}
+		if !aggregationMetricNameRegexp.MatchString(metric.Name) || names[metric.Name] {
+			return "metrics.name", fmt.Errorf("metric name %s is invalid or duplicated", metric.Name)
+		}
+		names[metric.Name] = true
+	}
+
+	if key, err := o.Page.Validate(false); err != nil {
+		return "page." + key, err
+	}
+	if o.Page.Start < 0 || o.Page.Limit < 0 {
+		return "page", fmt.Errorf("page start and limit should not be negative")
+	}
+	if o.Page.Limit == 0 {
+		o.Page.Limit = common.BKDefaultLimit
+	}
+	if len(o.Page.Sort) > 0 {
+		sort := o.Page.Sort
+		if sort[0] == '-' {
+			sort = sort[1:]
+		}
+		if !groupBy[sort] && !names[sort] {
+			return "page.sort", fmt.Errorf("sort field %s is neither a group by field nor a metric", sort)
+		}
+	}
+
+	if o.Filter != nil {
+		if key, err := o.Filter.Validate(); err != nil {
+			return "filter." + key, err
+		}
+	}
+	return "", nil
+}
+
+// AggregationBucket 分组聚合的一个分组
+type AggregationBucket struct {
+	// 分组字段的值
+	Keys map[string]interface{} `json:"keys"`
+	// 统计指标的值
+	Metrics map[string]interface{} `json:"metrics"`
+}
+
+// InstAggregationResult 模型实例的分组聚合结果
+type InstAggregationResult struct {
+	// 分组的总数
+	Count int64               `json:"count"`
+	Info  []AggregationBucket `json:"info"`
+}
+
+type InstAggregationResponse struct {
+	BaseResp `json:",inline"`
+	Data     InstAggregationResult `json:"data"`
+}
@@ -17,6 +17,7 @@ import (
 
 	"configcenter/src/common"
 	"configcenter/src/common/mapstr"
+	"configcenter/src/common/querybuilder"
 )
 
 // Deprecated: SearchLimit sub condition
@@ -37,6 +38,8 @@ type QueryCondition struct {
 	Limit     SearchLimit   `json:"limit"`
 	SortArr   []SearchSort  `json:"sort"`
 	Condition mapstr.MapStr `json:"condition"`
+	// PropertyFilter is the querybuilder rules or expression combined with the condition, optional
+	PropertyFilter *querybuilder.QueryFilter `json:"property_filter,omitempty"`
 }
 
 // IsIllegal  limit is illegal, if limit = 0; change to default page size
@@ -76,7 +79,7 @@ func NewSearchSortParse() SearchSortParse {
 	return &searchSortParse{}
 }
 
-//  String convert string sort to cc SearchSort struct array
+// String convert string sort to cc SearchSort struct array
 func (ss *searchSortParse) String(sort string) SearchSortParse {
 	if sort == "" {
 		return ss
@@ -96,7 +99,7 @@ func (ss *searchSortParse) String(sort string) SearchSortParse {
 	return ss
 }
 
-//  Field   cc SearchSort struct array
+// Field   cc SearchSort struct array
 func (ss *searchSortParse) Field(field string, isDesc bool) SearchSortParse {
 
 	ssInst := SearchSort{
@@ -0,0 +1,153 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package querybuilder
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/util"
+)
+
+const (
+	// the value layouts of the date and time typed attributes, which are stored as strings
+	dateLayout = "2006-01-02"
+	timeLayout = "2006-01-02 15:04:05"
+)
+
+var (
+	// datetimeLayouts the layouts of the datetime string values, the ones without timezone are parsed in
+	// the timezone of the rule
+	datetimeLayouts = []string{time.RFC3339Nano, timeLayout, dateLayout}
+
+	datePattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+	timePattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2}$`)
+
+	datetimeMgoOperators = map[Operator]string{
+		OperatorDatetimeLess:           common.BKDBLT,
+		OperatorDatetimeLessOrEqual:    common.BKDBLTE,
+		OperatorDatetimeGreater:        common.BKDBGT,
+		OperatorDatetimeGreaterOrEqual: common.BKDBGTE,
+	}
+)
+
+// location returns the timezone of the rule, local timezone if it's not set
+func (r AtomRule) location() (*time.Location, error) {
+	if r.TimeZone == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(r.TimeZone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time zone: %s, err: %v", r.TimeZone, err)
+	}
+	return loc, nil
+}
+
+// datetimeValue parse the value of the datetime rule, returns the time in the timezone of the rule
+func (r AtomRule) datetimeValue() (time.Time, error) {
+	loc, err := r.location()
+	if err != nil {
+		return time.Time{}, err
+	}
+	t, err := parseDatetime(r.Value, loc)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.In(loc), nil
+}
+
+// parseDatetime parse a RFC3339 string, a date or time string, or a unix timestamp in seconds
+func parseDatetime(value interface{}, loc *time.Location) (time.Time, error) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, nil
+	case string:
+		for _, layout := range datetimeLayouts {
+			if t, err := time.ParseInLocation(layout, v, loc); err == nil {
+				return t, nil
+			}
+		}
+		return time.Time{}, fmt.Errorf("invalid datetime: %s, should be RFC3339, %s or %s format", v, dateLayout, timeLayout)
+	}
+
+	if t := getType(value); t != TypeNumeric {
+		return time.Time{}, fmt.Errorf("unknow value type: %s, value: %+v", t, value)
+	}
+	ts, err := util.GetInt64ByInterface(value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(ts, 0), nil
+}
+
+// datetimeToMgo generate mongo filter of the datetime rule, the datetime fields like create_time are stored as dates,
+// while the date and time typed attributes are stored as strings in the local timezone, so all of them are compared.
+// the strings are compared with t in the local timezone rather than the timezone of the rule.
+func (r AtomRule) datetimeToMgo() (map[string]interface{}, error) {
+	t, err := r.datetimeValue()
+	if err != nil {
+		return nil, err
+	}
+	operator := datetimeMgoOperators[r.Operator]
+	local := t.In(time.Local)
+
+	// a date string stands for the midnight of the day, compare it with the day of t
+	day := local.Format(dateLayout)
+	dateOperator := operator
+	if local.Format(timeLayout) != day+" 00:00:00" {
+		switch r.Operator {
+		case OperatorDatetimeLess:
+			dateOperator = common.BKDBLTE
+		case OperatorDatetimeGreaterOrEqual:
+			dateOperator = common.BKDBGT
+		}
+	}
+
+	filter := map[string]interface{}{
+		common.BKDBOR: []map[string]interface{}{
+			{r.Field: map[string]interface{}{operator: t.UTC()}},
+			{r.Field: map[string]interface{}{operator: local.Format(timeLayout), common.BKDBLIKE: timePattern.String()}},
+			{r.Field: map[string]interface{}{dateOperator: day, common.BKDBLIKE: datePattern.String()}},
+		},
+	}
+	return filter, nil
+}
+
+// datetimeCompare compare the datetime value of a record with the datetime of the rule, the record value could be
+// a date, or a string as the dates are encoded as RFC3339 strings in json. the date and time typed attributes are
+// stored as strings in the local timezone, so they are parsed in it rather than the timezone of the rule.
+func datetimeCompare(op Operator, value interface{}, expect time.Time) bool {
+	switch value.(type) {
+	case string, time.Time:
+	default:
+		return false
+	}
+	t, err := parseDatetime(value, time.Local)
+	if err != nil {
+		return false
+	}
+	switch op {
+	case OperatorDatetimeLess:
+		return t.Before(expect)
+	case OperatorDatetimeLessOrEqual:
+		return !t.After(expect)
+	case OperatorDatetimeGreater:
+		return t.After(expect)
+	case OperatorDatetimeGreaterOrEqual:
+		return !t.Before(expect)
+	default:
+		return false
+	}
+}
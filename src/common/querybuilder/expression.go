@@ -0,0 +1,389 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package querybuilder
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// the expression syntax is like: bk_os_type = "Linux" AND (bk_cpu >= 8 OR bk_mem > 32000)
+// - rules are combined with AND/OR (case insensitive), AND binds tighter than OR, parentheses group rules
+// - an atom rule is `field operator value`, the operator could be a symbol or an operator name such as begins_with
+// - the values are double quoted strings, numbers, true/false, or arrays of them like [1, 2, 3]
+// - the operators that take no value, such as is_null and exist, are written without value
+
+// symbolOperators the operators written as symbols
+var symbolOperators = map[string]Operator{
+	"=":  OperatorEqual,
+	"==": OperatorEqual,
+	"!=": OperatorNotEqual,
+	"<":  OperatorLess,
+	"<=": OperatorLessOrEqual,
+	">":  OperatorGreater,
+	">=": OperatorGreaterOrEqual,
+}
+
+// operatorSymbols the symbols used to print the operators
+var operatorSymbols = map[Operator]string{
+	OperatorEqual:          "=",
+	OperatorNotEqual:       "!=",
+	OperatorLess:           "<",
+	OperatorLessOrEqual:    "<=",
+	OperatorGreater:        ">",
+	OperatorGreaterOrEqual: ">=",
+}
+
+// noValueOperators the operators that take no value
+var noValueOperators = map[Operator]bool{
+	OperatorIsEmpty:    true,
+	OperatorIsNotEmpty: true,
+	OperatorIsNull:     true,
+	OperatorIsNotNull:  true,
+	OperatorExist:      true,
+	OperatorNotExist:   true,
+}
+
+// ExpressionError is the error of parsing an expression, Pos is the position of the character it occurs at,
+// which starts from 1
+type ExpressionError struct {
+	Pos int
+	Msg string
+}
+
+func (e *ExpressionError) Error() string {
+	return fmt.Sprintf("%s at position %d", e.Msg, e.Pos)
+}
+
+type tokenType int
+
+const (
+	tokenEOF tokenType = iota
+	tokenIdent
+	tokenString
+	tokenNumber
+	tokenSymbol
+)
+
+type token struct {
+	typ  tokenType
+	text string
+	pos  int
+}
+
+func (t token) String() string {
+	if t.typ == tokenEOF {
+		return "end of expression"
+	}
+	return strconv.Quote(t.text)
+}
+
+// tokenize split the expression into tokens
+func tokenize(expr string) ([]token, error) {
+	tokens := make([]token, 0)
+	runes := []rune(expr)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		start := i
+		switch {
+		case unicode.IsSpace(r):
+			i++
+			continue
+		case unicode.IsLetter(r):
+			for i < len(runes) && isIdentRune(runes[i]) {
+				i++
+			}
+			tokens = append(tokens, token{typ: tokenIdent, text: string(runes[start:i]), pos: start + 1})
+		case unicode.IsDigit(r) || (r == '-' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			i++
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || strings.ContainsRune(".eE", runes[i]) ||
+				(strings.ContainsRune("+-", runes[i]) && strings.ContainsRune("eE", runes[i-1]))) {
+				i++
+			}
+			tokens = append(tokens, token{typ: tokenNumber, text: string(runes[start:i]), pos: start + 1})
+		case r == '"':
+			i++
+			for i < len(runes) && runes[i] != '"' {
+				if runes[i] == '\\' {
+					i++
+				}
+				i++
+			}
+			if i >= len(runes) {
+				return nil, &ExpressionError{Pos: start + 1, Msg: "unterminated string"}
+			}
+			i++
+			text, err := strconv.Unquote(string(runes[start:i]))
+			if err != nil {
+				return nil, &ExpressionError{Pos: start + 1, Msg: "invalid string " + string(runes[start:i])}
+			}
+			tokens = append(tokens, token{typ: tokenString, text: text, pos: start + 1})
+		case strings.ContainsRune("()[],", r):
+			i++
+			tokens = append(tokens, token{typ: tokenSymbol, text: string(r), pos: start + 1})
+		case strings.ContainsRune("=!<>", r):
+			i++
+			if i < len(runes) && runes[i] == '=' {
+				i++
+			}
+			text := string(runes[start:i])
+			if _, ok := symbolOperators[text]; !ok {
+				return nil, &ExpressionError{Pos: start + 1, Msg: "unknown operator " + strconv.Quote(text)}
+			}
+			tokens = append(tokens, token{typ: tokenSymbol, text: text, pos: start + 1})
+		default:
+			return nil, &ExpressionError{Pos: start + 1, Msg: "unexpected character " + strconv.QuoteRune(r)}
+		}
+	}
+	tokens = append(tokens, token{typ: tokenEOF, pos: len(runes) + 1})
+	return tokens, nil
+}
+
+func isIdentRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-' || r == '.'
+}
+
+type expressionParser struct {
+	tokens []token
+	index  int
+}
+
+func (p *expressionParser) peek() token {
+	return p.tokens[p.index]
+}
+
+func (p *expressionParser) next() token {
+	t := p.tokens[p.index]
+	if t.typ != tokenEOF {
+		p.index++
+	}
+	return t
+}
+
+func (p *expressionParser) errorf(t token, format string, args ...interface{}) error {
+	return &ExpressionError{Pos: t.pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+// isKeyword check whether the token is the keyword, keywords are case insensitive
+func isKeyword(t token, keyword string) bool {
+	return t.typ == tokenIdent && strings.EqualFold(t.text, keyword)
+}
+
+// parseCombined parse rules combined with the condition, the operands are parsed by parseOperand
+func (p *expressionParser) parseCombined(condition Condition, parseOperand func() (Rule, error)) (Rule, error) {
+	rule, err := parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	if !isKeyword(p.peek(), string(condition)) {
+		return rule, nil
+	}
+	combined := CombinedRule{Condition: condition, Rules: []Rule{rule}}
+	for isKeyword(p.peek(), string(condition)) {
+		p.next()
+		rule, err := parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		combined.Rules = append(combined.Rules, rule)
+	}
+	return combined, nil
+}
+
+func (p *expressionParser) parseOr() (Rule, error) {
+	return p.parseCombined(ConditionOr, p.parseAnd)
+}
+
+func (p *expressionParser) parseAnd() (Rule, error) {
+	return p.parseCombined(ConditionAnd, p.parseOperand)
+}
+
+func (p *expressionParser) parseOperand() (Rule, error) {
+	t := p.next()
+	switch {
+	case t.typ == tokenSymbol && t.text == "(":
+		rule, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		// the parenthesized rules are always a combined rule, so that they could be printed back as is
+		if _, ok := rule.(CombinedRule); !ok {
+			rule = CombinedRule{Condition: ConditionAnd, Rules: []Rule{rule}}
+		}
+		if end := p.next(); end.typ != tokenSymbol || end.text != ")" {
+			return nil, p.errorf(end, "expect \")\" but got %s", end)
+		}
+		return rule, nil
+	case t.typ == tokenIdent && !isKeyword(t, string(ConditionAnd)) && !isKeyword(t, string(ConditionOr)):
+		return p.parseAtom(t.text)
+	default:
+		return nil, p.errorf(t, "expect field or \"(\" but got %s", t)
+	}
+}
+
+func (p *expressionParser) parseAtom(field string) (Rule, error) {
+	t := p.next()
+	var operator Operator
+	switch t.typ {
+	case tokenSymbol:
+		op, ok := symbolOperators[t.text]
+		if !ok {
+			return nil, p.errorf(t, "expect operator but got %s", t)
+		}
+		operator = op
+	case tokenIdent:
+		operator = Operator(strings.ToLower(t.text))
+		if _, ok := SupportOperators[operator]; !ok {
+			return nil, p.errorf(t, "unknown operator %s", t)
+		}
+	default:
+		return nil, p.errorf(t, "expect operator but got %s", t)
+	}
+
+	rule := AtomRule{Field: field, Operator: operator}
+	if noValueOperators[operator] {
+		return rule, nil
+	}
+	value, err := p.parseValue(true)
+	if err != nil {
+		return nil, err
+	}
+	rule.Value = value
+	return rule, nil
+}
+
+func (p *expressionParser) parseValue(allowArray bool) (interface{}, error) {
+	t := p.next()
+	switch {
+	case t.typ == tokenString:
+		return t.text, nil
+	case t.typ == tokenNumber:
+		if i, err := strconv.ParseInt(t.text, 10, 64); err == nil {
+			return i, nil
+		}
+		f, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, p.errorf(t, "invalid number %s", t)
+		}
+		return f, nil
+	case isKeyword(t, "true"):
+		return true, nil
+	case isKeyword(t, "false"):
+		return false, nil
+	case allowArray && t.typ == tokenSymbol && t.text == "[":
+		values := make([]interface{}, 0)
+		if end := p.peek(); end.typ == tokenSymbol && end.text == "]" {
+			p.next()
+			return values, nil
+		}
+		for {
+			value, err := p.parseValue(false)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+			sep := p.next()
+			if sep.typ == tokenSymbol && sep.text == "]" {
+				return values, nil
+			}
+			if sep.typ != tokenSymbol || sep.text != "," {
+				return nil, p.errorf(sep, "expect \",\" or \"]\" but got %s", sep)
+			}
+		}
+	default:
+		return nil, p.errorf(t, "expect value but got %s", t)
+	}
+}
+
+// ParseExpression compile the expression into a rule, see the syntax above, the returned rule is not validated
+func ParseExpression(expr string) (Rule, error) {
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &expressionParser{tokens: tokens}
+	rule, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.typ != tokenEOF {
+		return nil, p.errorf(t, "unexpected %s", t)
+	}
+	return rule, nil
+}
+
+// FormatRule print the rule as an expression, which could be parsed back into the same rule by ParseExpression,
+// except that the time zone of the datetime rules is not printed.
+func FormatRule(rule Rule) string {
+	return formatRule(rule, false)
+}
+
+func formatRule(rule Rule, nested bool) string {
+	switch r := rule.(type) {
+	case AtomRule:
+		return formatAtomRule(r)
+	case CombinedRule:
+		return formatCombinedRule(r, nested)
+	default:
+		return fmt.Sprintf("%v", rule)
+	}
+}
+
+func formatCombinedRule(r CombinedRule, nested bool) string {
+	rules := make([]string, len(r.Rules))
+	for idx, child := range r.Rules {
+		rules[idx] = formatRule(child, true)
+	}
+	expr := strings.Join(rules, " "+string(r.Condition)+" ")
+	// a single rule is parenthesized so that it's parsed back as a combined rule
+	if nested || len(r.Rules) == 1 {
+		return "(" + expr + ")"
+	}
+	return expr
+}
+
+func formatAtomRule(r AtomRule) string {
+	operator, ok := operatorSymbols[r.Operator]
+	if !ok {
+		operator = string(r.Operator)
+	}
+	if noValueOperators[r.Operator] {
+		return r.Field + " " + operator
+	}
+	return r.Field + " " + operator + " " + formatValue(r.Value)
+}
+
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return strconv.Quote(v)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+	if value != nil {
+		if kind := reflect.TypeOf(value).Kind(); kind == reflect.Slice || kind == reflect.Array {
+			v := reflect.ValueOf(value)
+			values := make([]string, v.Len())
+			for i := 0; i < v.Len(); i++ {
+				values[i] = formatValue(v.Index(i).Interface())
+			}
+			return "[" + strings.Join(values, ", ") + "]"
+		}
+	}
+	return fmt.Sprintf("%v", value)
+}
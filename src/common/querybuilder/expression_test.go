@@ -0,0 +1,130 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package querybuilder_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"configcenter/src/common/querybuilder"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseExpression(t *testing.T) {
+	rule, err := querybuilder.ParseExpression(`bk_os_type = "Linux" AND (bk_cpu >= 8 OR bk_mem > 32000.5) and bk_host_name begins_with "web"`)
+	assert.Nil(t, err)
+	assert.Equal(t, querybuilder.CombinedRule{
+		Condition: querybuilder.ConditionAnd,
+		Rules: []querybuilder.Rule{
+			querybuilder.AtomRule{Field: "bk_os_type", Operator: querybuilder.OperatorEqual, Value: "Linux"},
+			querybuilder.CombinedRule{
+				Condition: querybuilder.ConditionOr,
+				Rules: []querybuilder.Rule{
+					querybuilder.AtomRule{Field: "bk_cpu", Operator: querybuilder.OperatorGreaterOrEqual, Value: int64(8)},
+					querybuilder.AtomRule{Field: "bk_mem", Operator: querybuilder.OperatorGreater, Value: 32000.5},
+				},
+			},
+			querybuilder.AtomRule{Field: "bk_host_name", Operator: querybuilder.OperatorBeginsWith, Value: "web"},
+		},
+	}, rule)
+
+	// AND binds tighter than OR
+	rule, err = querybuilder.ParseExpression(`a = 1 OR b in ["x", "y"] AND c is_null`)
+	assert.Nil(t, err)
+	assert.Equal(t, querybuilder.CombinedRule{
+		Condition: querybuilder.ConditionOr,
+		Rules: []querybuilder.Rule{
+			querybuilder.AtomRule{Field: "a", Operator: querybuilder.OperatorEqual, Value: int64(1)},
+			querybuilder.CombinedRule{
+				Condition: querybuilder.ConditionAnd,
+				Rules: []querybuilder.Rule{
+					querybuilder.AtomRule{Field: "b", Operator: querybuilder.OperatorIn, Value: []interface{}{"x", "y"}},
+					querybuilder.AtomRule{Field: "c", Operator: querybuilder.OperatorIsNull},
+				},
+			},
+		},
+	}, rule)
+
+	rule, err = querybuilder.ParseExpression(`last_time datetime_greater "2019-08-04"`)
+	assert.Nil(t, err)
+	assert.Equal(t, querybuilder.AtomRule{Field: "last_time", Operator: querybuilder.OperatorDatetimeGreater, Value: "2019-08-04"}, rule)
+}
+
+func TestParseExpressionError(t *testing.T) {
+	testCases := []struct {
+		expr string
+		pos  int
+	}{
+		{expr: ``, pos: 1},
+		{expr: `bk_cpu >`, pos: 9},
+		{expr: `bk_cpu => 1`, pos: 9},
+		{expr: `bk_cpu ~ 1`, pos: 8},
+		{expr: `bk_cpu like 1`, pos: 8},
+		{expr: `bk_os_type = "Linux`, pos: 14},
+		{expr: `(bk_cpu = 1 OR bk_mem = 2`, pos: 26},
+		{expr: `bk_cpu = 1 bk_mem = 2`, pos: 12},
+		{expr: `bk_cpu in [1 2]`, pos: 14},
+		{expr: `bk_cpu = 1 AND OR bk_mem = 2`, pos: 16},
+	}
+	for _, testCase := range testCases {
+		_, err := querybuilder.ParseExpression(testCase.expr)
+		exprErr, ok := err.(*querybuilder.ExpressionError)
+		if assert.True(t, ok, "expr: %s, err: %v", testCase.expr, err) {
+			assert.Equal(t, testCase.pos, exprErr.Pos, "expr: %s, err: %v", testCase.expr, err)
+		}
+	}
+}
+
+func TestFormatRule(t *testing.T) {
+	exprs := []string{
+		`bk_os_type = "Linux" AND (bk_cpu >= 8 OR bk_mem > 32000.5) AND bk_host_name begins_with "web"`,
+		`a != "中文\"" OR (b in [1, 2.5, true] AND c not_exist) OR (d datetime_less "2019-08-04T14:08:00Z")`,
+		`(a = -1)`,
+	}
+	for _, expr := range exprs {
+		rule, err := querybuilder.ParseExpression(expr)
+		assert.Nil(t, err)
+		assert.Equal(t, expr, querybuilder.FormatRule(rule))
+	}
+
+	rule := querybuilder.CombinedRule{
+		Condition: querybuilder.ConditionAnd,
+		Rules: []querybuilder.Rule{
+			querybuilder.AtomRule{Field: "bk_biz_id", Operator: querybuilder.OperatorIn, Value: []int64{1, 2}},
+			querybuilder.AtomRule{Field: "bk_cpu", Operator: querybuilder.OperatorLess, Value: float64(8)},
+		},
+	}
+	assert.Equal(t, `bk_biz_id in [1, 2] AND bk_cpu < 8`, querybuilder.FormatRule(rule))
+}
+
+func TestQueryFilterFromExpression(t *testing.T) {
+	filter := new(querybuilder.QueryFilter)
+	err := json.Unmarshal([]byte(`"bk_cpu >= 8"`), filter)
+	assert.Nil(t, err)
+	errKey, err := filter.Validate()
+	assert.Nil(t, err)
+	assert.Empty(t, errKey)
+
+	mgoFilter, errKey, err := filter.ToMgo()
+	assert.Nil(t, err)
+	assert.Empty(t, errKey)
+	assert.Equal(t, map[string]interface{}{
+		"$and": []map[string]interface{}{
+			{"bk_cpu": map[string]interface{}{"$gte": int64(8)}},
+		},
+	}, mgoFilter)
+
+	err = json.Unmarshal([]byte(`"bk_cpu >= "`), filter)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,197 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package querybuilder
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/util"
+)
+
+// Match check whether the data matches the rule, it follows the semantic of ToMgo, which means
+// a field of array value matches if any of its elements matches, the string operators use regex.
+func (r AtomRule) Match(data map[string]interface{}) (bool, error) {
+	if key, err := r.Validate(); err != nil {
+		return false, fmt.Errorf("validate failed, key: %s, err: %s", key, err)
+	}
+
+	value, exist := getFieldValue(data, r.Field)
+	switch r.Operator {
+	case OperatorEqual:
+		return matchAny(value, func(item interface{}) bool { return valueEqual(item, r.Value) }), nil
+	case OperatorNotEqual:
+		return !matchAny(value, func(item interface{}) bool { return valueEqual(item, r.Value) }), nil
+	case OperatorIn:
+		return matchAny(value, func(item interface{}) bool { return valueIn(item, r.Value) }), nil
+	case OperatorNotIn:
+		return !matchAny(value, func(item interface{}) bool { return valueIn(item, r.Value) }), nil
+	case OperatorLess, OperatorLessOrEqual, OperatorGreater, OperatorGreaterOrEqual:
+		return matchAny(value, func(item interface{}) bool { return numericCompare(r.Operator, item, r.Value) }), nil
+	case OperatorDatetimeLess, OperatorDatetimeLessOrEqual, OperatorDatetimeGreater, OperatorDatetimeGreaterOrEqual:
+		expect, err := r.datetimeValue()
+		if err != nil {
+			return false, err
+		}
+		return matchAny(value, func(item interface{}) bool { return datetimeCompare(r.Operator, item, expect) }), nil
+	case OperatorBeginsWith, OperatorContains, OperatorsEndsWith:
+		pattern, err := r.regexPattern()
+		if err != nil {
+			return false, err
+		}
+		return matchAny(value, func(item interface{}) bool { return regexMatch(pattern, item) }), nil
+	case OperatorNotBeginsWith, OperatorNotContains, OperatorNotEndsWith:
+		pattern, err := r.regexPattern()
+		if err != nil {
+			return false, err
+		}
+		return !matchAny(value, func(item interface{}) bool { return regexMatch(pattern, item) }), nil
+	case OperatorIsEmpty, OperatorIsNotEmpty:
+		empty := value != nil && reflect.TypeOf(value).Kind() == reflect.Slice && reflect.ValueOf(value).Len() == 0
+		return empty == (r.Operator == OperatorIsEmpty), nil
+	case OperatorIsNull:
+		return value == nil, nil
+	case OperatorIsNotNull:
+		return value != nil, nil
+	case OperatorExist:
+		return exist, nil
+	case OperatorNotExist:
+		return !exist, nil
+	default:
+		return false, fmt.Errorf("unsupported operator: %s", r.Operator)
+	}
+}
+
+// Match check whether the data matches the combined rules
+func (r CombinedRule) Match(data map[string]interface{}) (bool, error) {
+	if err := r.Condition.Validate(); err != nil {
+		return false, err
+	}
+	if len(r.Rules) == 0 {
+		return false, fmt.Errorf("combined rules shouldn't be empty")
+	}
+
+	for idx, rule := range r.Rules {
+		matched, err := rule.Match(data)
+		if err != nil {
+			return false, fmt.Errorf("rules[%d] match failed, err: %v", idx, err)
+		}
+		if r.Condition == ConditionOr && matched {
+			return true, nil
+		}
+		if r.Condition == ConditionAnd && !matched {
+			return false, nil
+		}
+	}
+	return r.Condition == ConditionAnd, nil
+}
+
+// regexPattern compiles the same regex as ToMgo uses for the string operators
+func (r AtomRule) regexPattern() (*regexp.Regexp, error) {
+	switch r.Operator {
+	case OperatorBeginsWith, OperatorNotBeginsWith:
+		return regexp.Compile(fmt.Sprintf("^%s", r.Value))
+	case OperatorsEndsWith, OperatorNotEndsWith:
+		return regexp.Compile(fmt.Sprintf("%s$", r.Value))
+	default:
+		return regexp.Compile(fmt.Sprintf("%s", r.Value))
+	}
+}
+
+// getFieldValue get the value of field from data, field could be separated by dot to get a nested value
+func getFieldValue(data map[string]interface{}, field string) (interface{}, bool) {
+	var value interface{} = data
+	for _, key := range strings.Split(field, ".") {
+		var m map[string]interface{}
+		switch v := value.(type) {
+		case map[string]interface{}:
+			m = v
+		case mapstr.MapStr:
+			m = v
+		default:
+			return nil, false
+		}
+		var exist bool
+		if value, exist = m[key]; !exist {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// matchAny check the value itself, or any of its elements if it's an array
+func matchAny(value interface{}, match func(item interface{}) bool) bool {
+	if value != nil {
+		kind := reflect.TypeOf(value).Kind()
+		if kind == reflect.Slice || kind == reflect.Array {
+			v := reflect.ValueOf(value)
+			for i := 0; i < v.Len(); i++ {
+				if match(v.Index(i).Interface()) {
+					return true
+				}
+			}
+			return false
+		}
+	}
+	return match(value)
+}
+
+func valueEqual(value, expect interface{}) bool {
+	if getType(value) == TypeNumeric && getType(expect) == TypeNumeric {
+		v, _ := util.GetFloat64ByInterface(value)
+		e, _ := util.GetFloat64ByInterface(expect)
+		return v == e
+	}
+	return value == expect
+}
+
+func valueIn(value, expects interface{}) bool {
+	v := reflect.ValueOf(expects)
+	for i := 0; i < v.Len(); i++ {
+		if valueEqual(value, v.Index(i).Interface()) {
+			return true
+		}
+	}
+	return false
+}
+
+func numericCompare(op Operator, value, expect interface{}) bool {
+	if getType(value) != TypeNumeric {
+		return false
+	}
+	v, _ := util.GetFloat64ByInterface(value)
+	e, _ := util.GetFloat64ByInterface(expect)
+	switch op {
+	case OperatorLess:
+		return v < e
+	case OperatorLessOrEqual:
+		return v <= e
+	case OperatorGreater:
+		return v > e
+	case OperatorGreaterOrEqual:
+		return v >= e
+	default:
+		return false
+	}
+}
+
+func regexMatch(pattern *regexp.Regexp, value interface{}) bool {
+	s, ok := value.(string)
+	if !ok {
+		return false
+	}
+	return pattern.MatchString(s)
+}
@@ -0,0 +1,123 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package querybuilder_test
+
+import (
+	"testing"
+	"time"
+
+	"configcenter/src/common/querybuilder"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAtomRuleMatch(t *testing.T) {
+	data := map[string]interface{}{
+		"bk_biz_id":      float64(3),
+		"bk_host_name":   "web-01",
+		"bk_os_type":     nil,
+		"changed_fields": []interface{}{"bk_os_type", "bk_cpu"},
+		"cur_data": map[string]interface{}{
+			"bk_cpu": float64(8),
+		},
+	}
+
+	testCases := []struct {
+		rule    querybuilder.AtomRule
+		matched bool
+	}{
+		{rule: querybuilder.AtomRule{Field: "bk_biz_id", Operator: querybuilder.OperatorEqual, Value: 3}, matched: true},
+		{rule: querybuilder.AtomRule{Field: "bk_biz_id", Operator: querybuilder.OperatorEqual, Value: 4}, matched: false},
+		{rule: querybuilder.AtomRule{Field: "bk_biz_id", Operator: querybuilder.OperatorNotEqual, Value: 4}, matched: true},
+		{rule: querybuilder.AtomRule{Field: "bk_biz_id", Operator: querybuilder.OperatorIn, Value: []interface{}{1, 3}}, matched: true},
+		{rule: querybuilder.AtomRule{Field: "bk_biz_id", Operator: querybuilder.OperatorNotIn, Value: []interface{}{1, 3}}, matched: false},
+		{rule: querybuilder.AtomRule{Field: "cur_data.bk_cpu", Operator: querybuilder.OperatorGreaterOrEqual, Value: 8}, matched: true},
+		{rule: querybuilder.AtomRule{Field: "cur_data.bk_cpu", Operator: querybuilder.OperatorLess, Value: 8}, matched: false},
+		{rule: querybuilder.AtomRule{Field: "cur_data.bk_mem", Operator: querybuilder.OperatorLess, Value: 8}, matched: false},
+		{rule: querybuilder.AtomRule{Field: "bk_host_name", Operator: querybuilder.OperatorBeginsWith, Value: "web"}, matched: true},
+		{rule: querybuilder.AtomRule{Field: "bk_host_name", Operator: querybuilder.OperatorNotEndsWith, Value: "01"}, matched: false},
+		{rule: querybuilder.AtomRule{Field: "bk_host_name", Operator: querybuilder.OperatorContains, Value: "b-0"}, matched: true},
+		{rule: querybuilder.AtomRule{Field: "changed_fields", Operator: querybuilder.OperatorEqual, Value: "bk_os_type"}, matched: true},
+		{rule: querybuilder.AtomRule{Field: "changed_fields", Operator: querybuilder.OperatorEqual, Value: "bk_mem"}, matched: false},
+	}
+	for _, testCase := range testCases {
+		matched, err := testCase.rule.Match(data)
+		assert.Nil(t, err)
+		assert.Equal(t, testCase.matched, matched, "rule: %+v", testCase.rule)
+	}
+}
+
+func TestDatetimeRuleMatch(t *testing.T) {
+	setLocal(t, "Asia/Shanghai")
+	data := map[string]interface{}{
+		"create_time": time.Date(2019, 8, 4, 6, 8, 0, 0, time.UTC),
+		"last_time":   "2019-08-04T14:08:00+08:00",
+		"bk_date":     "2019-08-04",
+		"bk_time":     "2019-08-04 14:08:00",
+	}
+
+	testCases := []struct {
+		rule    querybuilder.AtomRule
+		matched bool
+	}{
+		{rule: querybuilder.AtomRule{Field: "create_time", Operator: querybuilder.OperatorDatetimeLess, Value: "2019-08-04T14:08:00+08:00"}, matched: false},
+		{rule: querybuilder.AtomRule{Field: "create_time", Operator: querybuilder.OperatorDatetimeLessOrEqual, Value: "2019-08-04T06:08:00Z"}, matched: true},
+		{rule: querybuilder.AtomRule{Field: "create_time", Operator: querybuilder.OperatorDatetimeGreater, Value: 1564898880 - 1}, matched: true},
+		{rule: querybuilder.AtomRule{Field: "last_time", Operator: querybuilder.OperatorDatetimeGreaterOrEqual, Value: "2019-08-04", TimeZone: "Asia/Shanghai"}, matched: true},
+		{rule: querybuilder.AtomRule{Field: "bk_date", Operator: querybuilder.OperatorDatetimeLess, Value: "2019-08-04"}, matched: false},
+		{rule: querybuilder.AtomRule{Field: "bk_date", Operator: querybuilder.OperatorDatetimeLess, Value: "2019-08-04 00:00:01"}, matched: true},
+		// the date and time strings of the records are in the local timezone rather than the timezone of the rule
+		{rule: querybuilder.AtomRule{Field: "bk_date", Operator: querybuilder.OperatorDatetimeLess, Value: "2019-08-04", TimeZone: "UTC"}, matched: true},
+		{rule: querybuilder.AtomRule{Field: "bk_time", Operator: querybuilder.OperatorDatetimeLessOrEqual, Value: "2019-08-04 06:08:00", TimeZone: "UTC"}, matched: true},
+		{rule: querybuilder.AtomRule{Field: "bk_time", Operator: querybuilder.OperatorDatetimeGreater, Value: "2019-08-04T06:07:59Z", TimeZone: "Asia/Shanghai"}, matched: true},
+		{rule: querybuilder.AtomRule{Field: "bk_time", Operator: querybuilder.OperatorDatetimeGreater, Value: "2019-08-04T06:08:00Z", TimeZone: "Asia/Shanghai"}, matched: false},
+		{rule: querybuilder.AtomRule{Field: "not_exist", Operator: querybuilder.OperatorDatetimeGreater, Value: "2019-08-04"}, matched: false},
+	}
+	for _, testCase := range testCases {
+		matched, err := testCase.rule.Match(data)
+		assert.Nil(t, err)
+		assert.Equal(t, testCase.matched, matched, "rule: %+v", testCase.rule)
+	}
+}
+
+func TestCombinedRuleMatch(t *testing.T) {
+	data := map[string]interface{}{
+		"bk_biz_id":  float64(3),
+		"bk_os_type": "1",
+	}
+	rule := querybuilder.CombinedRule{
+		Condition: querybuilder.ConditionAnd,
+		Rules: []querybuilder.Rule{
+			querybuilder.AtomRule{Field: "bk_biz_id", Operator: querybuilder.OperatorEqual, Value: 3},
+			querybuilder.CombinedRule{
+				Condition: querybuilder.ConditionOr,
+				Rules: []querybuilder.Rule{
+					querybuilder.AtomRule{Field: "bk_os_type", Operator: querybuilder.OperatorEqual, Value: "2"},
+					querybuilder.AtomRule{Field: "bk_os_type", Operator: querybuilder.OperatorEqual, Value: "1"},
+				},
+			},
+		},
+	}
+	matched, err := rule.Match(data)
+	assert.Nil(t, err)
+	assert.True(t, matched)
+
+	data["bk_biz_id"] = float64(4)
+	matched, err = rule.Match(data)
+	assert.Nil(t, err)
+	assert.False(t, matched)
+
+	invalid := querybuilder.AtomRule{Field: "bk_biz_id", Operator: querybuilder.Operator("unknown"), Value: 3}
+	_, err = invalid.Match(data)
+	assert.NotNil(t, err)
+}
@@ -13,6 +13,7 @@
 package querybuilder
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 
@@ -91,7 +92,25 @@ func (qf *QueryFilter) MarshalJSON() ([]byte, error) {
 	return nil, nil
 }
 
+// UnmarshalJSON decode the query filter from the json rules, or from an expression string, see ParseExpression
 func (qf *QueryFilter) UnmarshalJSON(raw []byte) error {
+	if trimmed := bytes.TrimSpace(raw); len(trimmed) > 0 && trimmed[0] == '"' {
+		expr := ""
+		if err := json.Unmarshal(trimmed, &expr); err != nil {
+			return err
+		}
+		rule, err := ParseExpression(expr)
+		if err != nil {
+			return fmt.Errorf("parse expression failed, err: %v", err)
+		}
+		// query filter must be combined rules
+		if _, ok := rule.(CombinedRule); !ok {
+			rule = CombinedRule{Condition: ConditionAnd, Rules: []Rule{rule}}
+		}
+		qf.Rule = rule
+		return nil
+	}
+
 	rule, errKey, err := ParseRuleFromBytes(raw)
 	if err != nil {
 		return fmt.Errorf("UnmarshalJSON failed, key: %s, err: %+v", errKey, err)
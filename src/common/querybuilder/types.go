@@ -15,7 +15,6 @@ package querybuilder
 import (
 	"fmt"
 	"regexp"
-	"time"
 
 	"configcenter/src/common"
 )
@@ -24,6 +23,7 @@ type Rule interface {
 	GetDeep() int
 	Validate() (string, error)
 	ToMgo() (mgoFilter map[string]interface{}, errKey string, err error)
+	Match(data map[string]interface{}) (bool, error)
 }
 
 // *************** define condition ************************
@@ -108,10 +108,10 @@ var SupportOperators = map[Operator]bool{
 	OperatorGreater:        true,
 	OperatorGreaterOrEqual: true,
 
-	OperatorDatetimeLess:           false,
-	OperatorDatetimeLessOrEqual:    false,
-	OperatorDatetimeGreater:        false,
-	OperatorDatetimeGreaterOrEqual: false,
+	OperatorDatetimeLess:           true,
+	OperatorDatetimeLessOrEqual:    true,
+	OperatorDatetimeGreater:        true,
+	OperatorDatetimeGreaterOrEqual: true,
 
 	OperatorBeginsWith:    true,
 	OperatorNotBeginsWith: true,
@@ -142,6 +142,9 @@ type AtomRule struct {
 	Field    string      `json:"field"`
 	Operator Operator    `json:"operator"`
 	Value    interface{} `json:"value"`
+	// TimeZone is the timezone of the datetime values without timezone, local timezone by default. it doesn't
+	// apply to the date and time strings of the records, which are always in the local timezone
+	TimeZone string `json:"time_zone,omitempty"`
 }
 
 func (r AtomRule) GetDeep() int {
@@ -155,6 +158,9 @@ func (r AtomRule) Validate() (string, error) {
 	if err := r.validateField(); err != nil {
 		return "field", err
 	}
+	if _, err := r.location(); err != nil {
+		return "time_zone", err
+	}
 	if err := r.validateValue(); err != nil {
 		return "value", err
 	}
@@ -182,7 +188,7 @@ func (r AtomRule) validateValue() error {
 	case OperatorLess, OperatorLessOrEqual, OperatorGreater, OperatorGreaterOrEqual:
 		return validateNumericType(r.Value)
 	case OperatorDatetimeLess, OperatorDatetimeLessOrEqual, OperatorDatetimeGreater, OperatorDatetimeGreaterOrEqual:
-		return validateDatetimeStringType(r.Value)
+		return validateDatetimeType(r.Value)
 	case OperatorBeginsWith, OperatorNotBeginsWith, OperatorContains, OperatorNotContains, OperatorsEndsWith, OperatorNotEndsWith:
 		return validateNotEmptyStringType(r.Value)
 	case OperatorIsEmpty, OperatorIsNotEmpty:
@@ -236,38 +242,12 @@ func (r AtomRule) ToMgo() (mgoFiler map[string]interface{}, key string, err erro
 		filter[r.Field] = map[string]interface{}{
 			common.BKDBGTE: r.Value,
 		}
-	case OperatorDatetimeLess:
-		t, err := time.Parse(time.RFC3339, r.Value.(string))
-		if err != nil {
-			return nil, "value", err
-		}
-		filter[r.Field] = map[string]interface{}{
-			common.BKDBLT: t,
-		}
-	case OperatorDatetimeLessOrEqual:
-		t, err := time.Parse(time.RFC3339, r.Value.(string))
-		if err != nil {
-			return nil, "value", err
-		}
-		filter[r.Field] = map[string]interface{}{
-			common.BKDBLTE: t,
-		}
-	case OperatorDatetimeGreater:
-		t, err := time.Parse(time.RFC3339, r.Value.(string))
-		if err != nil {
-			return nil, "value", err
-		}
-		filter[r.Field] = map[string]interface{}{
-			common.BKDBGT: t,
-		}
-	case OperatorDatetimeGreaterOrEqual:
-		t, err := time.Parse(time.RFC3339, r.Value.(string))
+	case OperatorDatetimeLess, OperatorDatetimeLessOrEqual, OperatorDatetimeGreater, OperatorDatetimeGreaterOrEqual:
+		datetimeFilter, err := r.datetimeToMgo()
 		if err != nil {
 			return nil, "value", err
 		}
-		filter[r.Field] = map[string]interface{}{
-			common.BKDBGTE: t,
-		}
+		filter = datetimeFilter
 	case OperatorBeginsWith:
 		filter[r.Field] = map[string]interface{}{
 			common.BKDBLIKE: fmt.Sprintf("^%s", r.Value),
@@ -14,6 +14,7 @@ package querybuilder_test
 
 import (
 	"testing"
+	"time"
 
 	"configcenter/src/common/querybuilder"
 
@@ -114,6 +115,19 @@ func TestNormalAtomRule(t *testing.T) {
 			Operator: querybuilder.OperatorDatetimeGreaterOrEqual,
 			Field:    "field",
 			Value:    "2019-08-04T14:08:00.00Z",
+		}, {
+			Operator: querybuilder.OperatorDatetimeLess,
+			Field:    "field",
+			Value:    "2019-08-04 14:08:00",
+			TimeZone: "Asia/Shanghai",
+		}, {
+			Operator: querybuilder.OperatorDatetimeGreater,
+			Field:    "field",
+			Value:    "2019-08-04",
+		}, {
+			Operator: querybuilder.OperatorDatetimeGreater,
+			Field:    "field",
+			Value:    1564927680,
 		}, {
 			Operator: querybuilder.OperatorBeginsWith,
 			Field:    "field",
@@ -258,7 +272,16 @@ func TestInvalidateValueAtomRule(t *testing.T) {
 		}, {
 			Operator: querybuilder.OperatorDatetimeLess,
 			Field:    "field",
-			Value:    1,
+			Value:    true,
+		}, {
+			Operator: querybuilder.OperatorDatetimeLess,
+			Field:    "field",
+			Value:    "2019-08-04 14:08",
+		}, {
+			Operator: querybuilder.OperatorDatetimeLess,
+			Field:    "field",
+			Value:    "2019-08-04",
+			TimeZone: "unknown/zone",
 		}, {
 			Operator: querybuilder.OperatorDatetimeLess,
 			Field:    "field",
@@ -419,3 +442,70 @@ func TestExceedMaxDeep(t *testing.T) {
 		assert.NotNil(t, err)
 	}
 }
+
+// setLocal sets the local timezone during the test, which the date and time strings are stored in
+func setLocal(t *testing.T, name string) {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	local := time.Local
+	time.Local = loc
+	t.Cleanup(func() { time.Local = local })
+}
+
+func TestDatetimeAtomRuleToMgo(t *testing.T) {
+	setLocal(t, "Asia/Shanghai")
+	rule := querybuilder.AtomRule{
+		Operator: querybuilder.OperatorDatetimeLess,
+		Field:    "create_time",
+		Value:    "2019-08-04 14:08:00",
+		TimeZone: "Asia/Shanghai",
+	}
+	filter, errKey, err := rule.ToMgo()
+	assert.Nil(t, err)
+	assert.Empty(t, errKey)
+	assert.Equal(t, map[string]interface{}{
+		"$or": []map[string]interface{}{
+			{"create_time": map[string]interface{}{"$lt": time.Date(2019, 8, 4, 6, 8, 0, 0, time.UTC)}},
+			{"create_time": map[string]interface{}{"$lt": "2019-08-04 14:08:00", "$regex": `^\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2}$`}},
+			{"create_time": map[string]interface{}{"$lte": "2019-08-04", "$regex": `^\d{4}-\d{2}-\d{2}$`}},
+		},
+	}, filter)
+
+	// the date and time strings are compared in the local timezone rather than the timezone of the rule
+	rule = querybuilder.AtomRule{
+		Operator: querybuilder.OperatorDatetimeGreaterOrEqual,
+		Field:    "create_time",
+		Value:    1564927680,
+		TimeZone: "UTC",
+	}
+	filter, errKey, err = rule.ToMgo()
+	assert.Nil(t, err)
+	assert.Empty(t, errKey)
+	assert.Equal(t, map[string]interface{}{
+		"$or": []map[string]interface{}{
+			{"create_time": map[string]interface{}{"$gte": time.Date(2019, 8, 4, 14, 8, 0, 0, time.UTC)}},
+			{"create_time": map[string]interface{}{"$gte": "2019-08-04 22:08:00", "$regex": `^\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2}$`}},
+			{"create_time": map[string]interface{}{"$gt": "2019-08-04", "$regex": `^\d{4}-\d{2}-\d{2}$`}},
+		},
+	}, filter)
+
+	setLocal(t, "UTC")
+	rule = querybuilder.AtomRule{
+		Operator: querybuilder.OperatorDatetimeLess,
+		Field:    "create_time",
+		Value:    "2019-08-05",
+		TimeZone: "Asia/Shanghai",
+	}
+	filter, errKey, err = rule.ToMgo()
+	assert.Nil(t, err)
+	assert.Empty(t, errKey)
+	assert.Equal(t, map[string]interface{}{
+		"$or": []map[string]interface{}{
+			{"create_time": map[string]interface{}{"$lt": time.Date(2019, 8, 4, 16, 0, 0, 0, time.UTC)}},
+			{"create_time": map[string]interface{}{"$lt": "2019-08-04 16:00:00", "$regex": `^\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2}$`}},
+			{"create_time": map[string]interface{}{"$lte": "2019-08-04", "$regex": `^\d{4}-\d{2}-\d{2}$`}},
+		},
+	}, filter)
+}
@@ -77,11 +77,8 @@ func validateNotEmptyStringType(value interface{}) error {
 	return nil
 }
 
-func validateDatetimeStringType(value interface{}) error {
-	if err := validateStringType(value); err != nil {
-		return err
-	}
-	if _, err := time.Parse(time.RFC3339, value.(string)); err != nil {
+func validateDatetimeType(value interface{}) error {
+	if _, err := parseDatetime(value, time.UTC); err != nil {
 		return err
 	}
 	return nil
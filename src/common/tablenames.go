@@ -64,6 +64,8 @@ const (
 	BKTableNameHostFavorite     = "cc_HostFavourite"
 	BKTableNameOperationLog     = "cc_OperationLog"
 	BKTableNameSubscription     = "cc_Subscription"
+	BKTableNameEventDeadLetter  = "cc_EventDeadLetter"
+	BKTableNameEventWatch       = "cc_EventWatch"
 	BKTableNameUserAPI          = "cc_UserAPI"
 	BKTableNameUserCustom       = "cc_UserCustom"
 	BKTableNameObjAsst          = "cc_ObjAsst"
@@ -130,6 +132,8 @@ var AllTables = []string{
 	BKTableNameHostFavorite,
 	BKTableNameOperationLog,
 	BKTableNameSubscription,
+	BKTableNameEventDeadLetter,
+	BKTableNameEventWatch,
 	BKTableNameUserAPI,
 	BKTableNameUserCustom,
 	BKTableNameObjAsst,
@@ -0,0 +1,42 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"testing"
+)
+
+func TestEncryptAES(t *testing.T) {
+	cipherText, err := EncryptAES("key", "secret")
+	if err != nil {
+		t.Fatalf("EncryptAES() error = %v", err)
+	}
+	if cipherText == "secret" {
+		t.Errorf("EncryptAES() returns the plain text")
+	}
+
+	plainText, err := DecryptAES("key", cipherText)
+	if err != nil {
+		t.Fatalf("DecryptAES() error = %v", err)
+	}
+	if plainText != "secret" {
+		t.Errorf("DecryptAES() = %v, want %v", plainText, "secret")
+	}
+
+	if _, err := DecryptAES("another key", cipherText); err == nil {
+		t.Errorf("DecryptAES() with wrong key should fail")
+	}
+	if _, err := EncryptAES("", "secret"); err == nil {
+		t.Errorf("EncryptAES() with empty key should fail")
+	}
+}
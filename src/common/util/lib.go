@@ -59,6 +59,20 @@ func SetOwnerIDAndAccount(req *restful.Request) {
 	}
 }
 
+// GetHTTPFlusher finds the http.Flusher under the response writers wrapped by go-restful
+func GetHTTPFlusher(w http.ResponseWriter) (http.Flusher, bool) {
+	for {
+		if flusher, ok := w.(http.Flusher); ok {
+			return flusher, true
+		}
+		resp, ok := w.(*restful.Response)
+		if !ok {
+			return nil, false
+		}
+		w = resp.ResponseWriter
+	}
+}
+
 // GetHTTPCCRequestID return config center request id from http header
 func GetHTTPCCRequestID(header http.Header) string {
 	rid := header.Get(common.BKHTTPCCRequestID)
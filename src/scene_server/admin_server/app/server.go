@@ -89,7 +89,7 @@ func Run(ctx context.Context, cancel context.CancelFunc, op *options.ServerOptio
 		if process.Config.MongoDB.Enable == "true" {
 			db, err = local.NewMgo(process.Config.MongoDB.BuildURI(), time.Minute)
 		} else {
-			db, err = remote.NewWithDiscover(process.Core)
+			db, err = remote.NewWithDiscover(process.Core, process.Config.MongoDB.RPCTLS)
 		}
 		if err != nil {
 			return fmt.Errorf("connect mongo server failed %s", err.Error())
@@ -148,7 +148,11 @@ func (h *MigrateServer) onHostConfigUpdate(previous, current cc.ProcessConfig) {
 		out, _ := json.MarshalIndent(current.ConfigMap, "", "  ") // ignore err, because ConfigMap is map[string]string
 		blog.V(3).Infof("config updated: \n%s", out)
 
-		mongoConf := mongo.ParseConfigFromKV("mongodb", current.ConfigMap)
+		mongoConf, err := mongo.ParseConfigFromKV("mongodb", current.ConfigMap)
+		if err != nil {
+			blog.Errorf("parse mongodb config failed, err: %v", err)
+			return
+		}
 		h.Config.MongoDB = mongoConf
 
 		h.Config.Errors.Res = current.ConfigMap["errors.res"]
@@ -159,7 +163,6 @@ func (h *MigrateServer) onHostConfigUpdate(previous, current cc.ProcessConfig) {
 
 		h.Config.ProcSrvConfig.CCApiSrvAddr, _ = current.ConfigMap["procsrv.cc_api"]
 
-		var err error
 		h.Config.AuthCenter, err = authcenter.ParseConfigFromKV("auth", current.ConfigMap)
 		if err != nil && auth.IsAuthed() {
 			blog.Errorf("parse authcenter error: %v, config: %+v", err, current.ConfigMap)
@@ -45,6 +45,10 @@ func Parse(args []string) error {
 		scope          string
 	)
 
+	if len(args) > 1 && args[1] == indexCmdName {
+		return parseIndex(ctx, args)
+	}
+
 	if len(args) <= 1 || args[1] != bkbizCmdName {
 		return nil
 	}
@@ -70,7 +74,10 @@ func Parse(args []string) error {
 	if nil != err {
 		return fmt.Errorf("parse config file error %s", err.Error())
 	}
-	mongoConfig := mongo.ParseConfigFromKV("mongodb", config.ConfigMap)
+	mongoConfig, err := mongo.ParseConfigFromKV("mongodb", config.ConfigMap)
+	if err != nil {
+		return fmt.Errorf("parse mongodb config error %s", err.Error())
+	}
 
 	// connect to mongo db
 	db, err := local.NewMgo(mongoConfig.BuildURI(), 0)
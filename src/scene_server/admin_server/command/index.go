@@ -0,0 +1,90 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"configcenter/src/common/backbone/configcenter"
+	"configcenter/src/scene_server/admin_server/index"
+	"configcenter/src/storage/dal/mongo"
+	"configcenter/src/storage/dal/mongo/local"
+
+	"github.com/spf13/pflag"
+)
+
+const indexCmdName = "index"
+
+// parseIndex run the index command, which diffs the declared indexes against the database
+// and creates the missing indexes or drops the extra indexes if required.
+func parseIndex(ctx context.Context, args []string) error {
+	var (
+		createFlag     bool
+		dropFlag       bool
+		tables         string
+		configPosition string
+	)
+
+	cmdFlags := pflag.NewFlagSet(indexCmdName, pflag.ExitOnError)
+	cmdFlags.BoolVar(&createFlag, "create", false, "create the missing indexes, and recreate the mismatched indexes if drop is set too")
+	cmdFlags.BoolVar(&dropFlag, "drop", false, "drop the extra indexes, and recreate the mismatched indexes if create is set too")
+	cmdFlags.StringVar(&tables, "tables", "", "the tables to be checked separated by comma, default all the tables with declared indexes")
+	cmdFlags.StringVar(&configPosition, "config", "conf/api.conf", "The config path. e.g conf/api.conf")
+	if err := cmdFlags.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	config, err := configcenter.ParseConfigWithFile(configPosition)
+	if nil != err {
+		return fmt.Errorf("parse config file error %s", err.Error())
+	}
+	mongoConfig, err := mongo.ParseConfigFromKV("mongodb", config.ConfigMap)
+	if err != nil {
+		return fmt.Errorf("parse mongodb config error %s", err.Error())
+	}
+	db, err := local.NewMgo(mongoConfig.BuildURI(), 0)
+	if err != nil {
+		return fmt.Errorf("connect mongo server failed %s", err.Error())
+	}
+
+	opt := index.ReconcileOption{Create: createFlag, Drop: dropFlag}
+	if tables != "" {
+		opt.Tables = strings.Split(tables, ",")
+	}
+	diffs, err := index.Reconcile(ctx, db, opt)
+	if err != nil {
+		fmt.Printf("reconcile indexes error: %s\n", err.Error())
+		os.Exit(2)
+	}
+
+	inconsistent := 0
+	for _, diff := range diffs {
+		if diff.IsConsistent() {
+			continue
+		}
+		inconsistent++
+		out, _ := json.MarshalIndent(diff, "", "  ")
+		fmt.Println(string(out))
+	}
+	fmt.Printf("%d of %d tables have inconsistent indexes\n", inconsistent, len(diffs))
+	if inconsistent > 0 && (createFlag || dropFlag) {
+		fmt.Println("the indexes have been reconciled")
+	}
+
+	os.Exit(0)
+	return nil
+}
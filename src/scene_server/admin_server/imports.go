@@ -76,4 +76,8 @@ import (
 	_ "configcenter/src/scene_server/admin_server/upgrader/y3.6.201911141516"
 	_ "configcenter/src/scene_server/admin_server/upgrader/y3.6.201911261109"
 	_ "configcenter/src/scene_server/admin_server/upgrader/y3.6.201912241627"
+	_ "configcenter/src/scene_server/admin_server/upgrader/y3.6.202003021030"
+	_ "configcenter/src/scene_server/admin_server/upgrader/y3.6.202003051500"
+	_ "configcenter/src/scene_server/admin_server/upgrader/y3.6.202003101000"
+	_ "configcenter/src/scene_server/admin_server/upgrader/y3.6.202003121000"
 )
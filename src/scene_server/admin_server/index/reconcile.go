@@ -0,0 +1,242 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package index
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"configcenter/src/common/blog"
+	"configcenter/src/storage/dal"
+	"configcenter/src/storage/mongodb"
+)
+
+// idIndexName is the name of the default _id index, which is never reported or dropped
+const idIndexName = "_id_"
+
+// Mismatch is a declared index and the existing index with the same keys or name but different options
+type Mismatch struct {
+	Expected dal.Index `json:"expected"`
+	Actual   dal.Index `json:"actual"`
+}
+
+// TableDiff is the difference between the declared and the existing indexes of a table
+type TableDiff struct {
+	Table      string      `json:"table"`
+	Missing    []dal.Index `json:"missing"`
+	Extra      []dal.Index `json:"extra"`
+	Mismatched []Mismatch  `json:"mismatched"`
+}
+
+// IsConsistent returns whether the existing indexes are the same as the declared ones
+func (d TableDiff) IsConsistent() bool {
+	return len(d.Missing) == 0 && len(d.Extra) == 0 && len(d.Mismatched) == 0
+}
+
+// ReconcileOption is the option of reconciling the indexes, only the difference is reported if nothing is set
+type ReconcileOption struct {
+	// Tables is the tables to be reconciled, all the declared tables if it's empty
+	Tables []string `json:"tables"`
+	// Create creates the missing indexes
+	Create bool `json:"create"`
+	// Drop drops the extra indexes
+	Drop bool `json:"drop"`
+}
+
+// Reconcile diffs the declared indexes of the tables against the existing ones, creates the missing indexes
+// and drops the extra indexes as the option says, the mismatched indexes are dropped and recreated
+// only if both Create and Drop are set. it returns the difference found before reconciling.
+func Reconcile(ctx context.Context, db dal.RDB, opt ReconcileOption) ([]TableDiff, error) {
+	tables := opt.Tables
+	if len(tables) == 0 {
+		for table := range Tables {
+			tables = append(tables, table)
+		}
+		sort.Strings(tables)
+	}
+
+	diffs := make([]TableDiff, 0, len(tables))
+	for _, table := range tables {
+		expected, ok := Tables[table]
+		if !ok {
+			return nil, fmt.Errorf("table %s has no declared indexes", table)
+		}
+
+		actual := make([]dal.Index, 0)
+		exists, err := db.HasTable(table)
+		if err != nil {
+			return nil, fmt.Errorf("check table %s exists failed, err: %v", table, err)
+		}
+		if exists {
+			if actual, err = db.Table(table).Indexes(ctx); err != nil {
+				return nil, fmt.Errorf("list indexes of table %s failed, err: %v", table, err)
+			}
+		}
+
+		diff := Diff(table, expected, actual)
+		if err := apply(ctx, db, diff, opt); err != nil {
+			return nil, err
+		}
+		diffs = append(diffs, diff)
+	}
+	return diffs, nil
+}
+
+func apply(ctx context.Context, db dal.RDB, diff TableDiff, opt ReconcileOption) error {
+	if opt.Drop {
+		for _, index := range diff.Extra {
+			blog.Infof("drop extra index %s of table %s", index.Name, diff.Table)
+			if err := db.Table(diff.Table).DropIndex(ctx, index.Name); err != nil {
+				return fmt.Errorf("drop index %s of table %s failed, err: %v", index.Name, diff.Table, err)
+			}
+		}
+	}
+
+	if opt.Create && opt.Drop {
+		for _, mismatch := range diff.Mismatched {
+			if err := recreate(ctx, db, diff.Table, mismatch); err != nil {
+				return err
+			}
+		}
+	}
+
+	if opt.Create {
+		for _, index := range diff.Missing {
+			blog.Infof("create missing index %v of table %s", index.Keys, diff.Table)
+			if err := db.Table(diff.Table).CreateIndex(ctx, index); err != nil {
+				return fmt.Errorf("create index %v of table %s failed, err: %v", index.Keys, diff.Table, err)
+			}
+		}
+	}
+	return nil
+}
+
+// recreate replaces the mismatched index with the declared one, the existing index is restored if the
+// declared one can't be created, e.g. a unique index over duplicated data.
+func recreate(ctx context.Context, db dal.RDB, table string, mismatch Mismatch) error {
+	blog.Infof("recreate mismatched index %s of table %s", mismatch.Actual.Name, table)
+	if err := db.Table(table).DropIndex(ctx, mismatch.Actual.Name); err != nil {
+		return fmt.Errorf("drop index %s of table %s failed, err: %v", mismatch.Actual.Name, table, err)
+	}
+
+	createErr := db.Table(table).CreateIndex(ctx, mismatch.Expected)
+	if createErr == nil {
+		return nil
+	}
+	blog.Errorf("create index %v of table %s failed, restore index %s, err: %v", mismatch.Expected.Keys, table,
+		mismatch.Actual.Name, createErr)
+	if err := db.Table(table).CreateIndex(ctx, mismatch.Actual); err != nil {
+		return fmt.Errorf("create index %v of table %s failed, err: %v, and restore index %s failed, err: %v",
+			mismatch.Expected.Keys, table, createErr, mismatch.Actual.Name, err)
+	}
+	return fmt.Errorf("create index %v of table %s failed, index %s is restored, err: %v", mismatch.Expected.Keys,
+		table, mismatch.Actual.Name, createErr)
+}
+
+// Diff compares the declared indexes with the existing indexes of the table.
+// a declared index is matched to the existing index with the same key fields, or the same name if it's named.
+// it's mismatched if the key directions, the key order, the name, unique or ttl is different, the key order is
+// compared only if it's declared, and the default _id index is ignored.
+func Diff(table string, expected, actual []dal.Index) TableDiff {
+	diff := TableDiff{
+		Table:      table,
+		Missing:    make([]dal.Index, 0),
+		Extra:      make([]dal.Index, 0),
+		Mismatched: make([]Mismatch, 0),
+	}
+
+	matched := make(map[int]bool)
+	for _, index := range expected {
+		found := -1
+		for position, exist := range actual {
+			if !matched[position] && sameKeyFields(index, exist) {
+				found = position
+				break
+			}
+		}
+		if found < 0 && index.Name != "" {
+			for position, exist := range actual {
+				if !matched[position] && exist.Name == index.Name {
+					found = position
+					break
+				}
+			}
+		}
+
+		if found < 0 {
+			diff.Missing = append(diff.Missing, index)
+			continue
+		}
+		matched[found] = true
+		if !sameOptions(index, actual[found]) {
+			diff.Mismatched = append(diff.Mismatched, Mismatch{Expected: index, Actual: actual[found]})
+		}
+	}
+
+	for position, exist := range actual {
+		if !matched[position] && exist.Name != idIndexName {
+			diff.Extra = append(diff.Extra, exist)
+		}
+	}
+	return diff
+}
+
+func sameKeyFields(expected, actual dal.Index) bool {
+	if len(expected.Keys) != len(actual.Keys) {
+		return false
+	}
+	for key := range expected.Keys {
+		if _, ok := actual.Keys[key]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// sameKeys compares the key fields and directions, and the key order if it's declared,
+// the direction of a text key is ignored.
+func sameKeys(expected, actual dal.Index) bool {
+	if !sameKeyFields(expected, actual) {
+		return false
+	}
+	for key, direction := range expected.Keys {
+		if strings.HasPrefix(key, mongodb.TextIndexKeyPrefix) {
+			continue
+		}
+		if (direction < 0) != (actual.Keys[key] < 0) {
+			return false
+		}
+	}
+
+	if len(expected.KeyOrder) == 0 || len(actual.KeyOrder) == 0 {
+		return true
+	}
+	expectedOrder := mongodb.Index(expected).OrderedKeys()
+	actualOrder := mongodb.Index(actual).OrderedKeys()
+	for position := range expectedOrder {
+		if expectedOrder[position] != actualOrder[position] {
+			return false
+		}
+	}
+	return true
+}
+
+func sameOptions(expected, actual dal.Index) bool {
+	if expected.Name != "" && expected.Name != actual.Name {
+		return false
+	}
+	return sameKeys(expected, actual) && expected.Unique == actual.Unique &&
+		expected.ExpireAfterSeconds == actual.ExpireAfterSeconds
+}
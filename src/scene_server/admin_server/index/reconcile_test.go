@@ -0,0 +1,155 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package index
+
+import (
+	"context"
+	"testing"
+
+	"configcenter/src/storage/dal"
+	"configcenter/src/storage/dal/mongo/memory"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	idIndex := dal.Index{Name: idIndexName, Keys: map[string]int32{"_id": 1}, KeyOrder: []string{"_id"}}
+	tests := []struct {
+		name     string
+		expected []dal.Index
+		actual   []dal.Index
+		want     TableDiff
+	}{
+		{
+			name:     "same keys in any order if it's not declared",
+			expected: []dal.Index{{Keys: map[string]int32{"a": 1, "b": 1}}},
+			actual:   []dal.Index{idIndex, {Name: "b_1_a_1", Keys: map[string]int32{"a": 1, "b": 1}, KeyOrder: []string{"b", "a"}}},
+			want:     TableDiff{},
+		},
+		{
+			name:     "same keys in the declared order",
+			expected: []dal.Index{{Name: "idx_a_b", Keys: map[string]int32{"a": 1, "b": -1}, KeyOrder: []string{"a", "b"}}},
+			actual:   []dal.Index{{Name: "idx_a_b", Keys: map[string]int32{"a": 1, "b": -1}, KeyOrder: []string{"a", "b"}}},
+			want:     TableDiff{},
+		},
+		{
+			name:     "missing and extra",
+			expected: []dal.Index{{Keys: map[string]int32{"a": 1}}},
+			actual:   []dal.Index{idIndex, {Name: "b_1", Keys: map[string]int32{"b": 1}}},
+			want: TableDiff{
+				Missing: []dal.Index{{Keys: map[string]int32{"a": 1}}},
+				Extra:   []dal.Index{{Name: "b_1", Keys: map[string]int32{"b": 1}}},
+			},
+		},
+		{
+			name:     "different direction",
+			expected: []dal.Index{{Name: "op_time_-1", Keys: map[string]int32{"op_time": -1}}},
+			actual:   []dal.Index{{Name: "op_time_-1", Keys: map[string]int32{"op_time": 1}}},
+			want: TableDiff{Mismatched: []Mismatch{{
+				Expected: dal.Index{Name: "op_time_-1", Keys: map[string]int32{"op_time": -1}},
+				Actual:   dal.Index{Name: "op_time_-1", Keys: map[string]int32{"op_time": 1}},
+			}}},
+		},
+		{
+			name:     "different order",
+			expected: []dal.Index{{Keys: map[string]int32{"a": 1, "b": 1}, KeyOrder: []string{"a", "b"}}},
+			actual:   []dal.Index{{Name: "b_1_a_1", Keys: map[string]int32{"a": 1, "b": 1}, KeyOrder: []string{"b", "a"}}},
+			want: TableDiff{Mismatched: []Mismatch{{
+				Expected: dal.Index{Keys: map[string]int32{"a": 1, "b": 1}, KeyOrder: []string{"a", "b"}},
+				Actual:   dal.Index{Name: "b_1_a_1", Keys: map[string]int32{"a": 1, "b": 1}, KeyOrder: []string{"b", "a"}},
+			}}},
+		},
+		{
+			name:     "different name of the same keys",
+			expected: []dal.Index{{Name: "idx_a", Keys: map[string]int32{"a": 1}}},
+			actual:   []dal.Index{{Name: "a_1", Keys: map[string]int32{"a": 1}}},
+			want: TableDiff{Mismatched: []Mismatch{{
+				Expected: dal.Index{Name: "idx_a", Keys: map[string]int32{"a": 1}},
+				Actual:   dal.Index{Name: "a_1", Keys: map[string]int32{"a": 1}},
+			}}},
+		},
+		{
+			name:     "different keys of the same name",
+			expected: []dal.Index{{Name: "idx_a", Keys: map[string]int32{"a": 1}}},
+			actual:   []dal.Index{{Name: "idx_a", Keys: map[string]int32{"b": 1}}},
+			want: TableDiff{Mismatched: []Mismatch{{
+				Expected: dal.Index{Name: "idx_a", Keys: map[string]int32{"a": 1}},
+				Actual:   dal.Index{Name: "idx_a", Keys: map[string]int32{"b": 1}},
+			}}},
+		},
+		{
+			name:     "different unique and ttl",
+			expected: []dal.Index{{Keys: map[string]int32{"id": 1}, Unique: true}, {Keys: map[string]int32{"time": 1}, ExpireAfterSeconds: 60}},
+			actual:   []dal.Index{{Name: "id_1", Keys: map[string]int32{"id": 1}}, {Name: "time_1", Keys: map[string]int32{"time": 1}}},
+			want: TableDiff{Mismatched: []Mismatch{
+				{Expected: dal.Index{Keys: map[string]int32{"id": 1}, Unique: true}, Actual: dal.Index{Name: "id_1", Keys: map[string]int32{"id": 1}}},
+				{Expected: dal.Index{Keys: map[string]int32{"time": 1}, ExpireAfterSeconds: 60}, Actual: dal.Index{Name: "time_1", Keys: map[string]int32{"time": 1}}},
+			}},
+		},
+		{
+			name:     "text index",
+			expected: []dal.Index{{Name: "idx_fullText", Keys: map[string]int32{"$text:$**": 1}}},
+			actual:   []dal.Index{{Name: "idx_fullText", Keys: map[string]int32{"$text:$**": 1}, KeyOrder: []string{"$text:$**"}}},
+			want:     TableDiff{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.want.Table = "cc_test"
+			if tt.want.Missing == nil {
+				tt.want.Missing = make([]dal.Index, 0)
+			}
+			if tt.want.Extra == nil {
+				tt.want.Extra = make([]dal.Index, 0)
+			}
+			if tt.want.Mismatched == nil {
+				tt.want.Mismatched = make([]Mismatch, 0)
+			}
+			diff := Diff("cc_test", tt.expected, tt.actual)
+			require.Equal(t, tt.want, diff)
+			require.Equal(t, len(tt.want.Missing)+len(tt.want.Extra)+len(tt.want.Mismatched) == 0, diff.IsConsistent())
+		})
+	}
+}
+
+func TestReconcileRestoresIndex(t *testing.T) {
+	const table = "cc_ReconcileTest"
+	ctx := context.Background()
+	db := memory.New()
+	docs := []map[string]interface{}{{"id": 1, "name": "a"}, {"id": 1, "name": "b"}}
+	require.NoError(t, db.Table(table).Insert(ctx, docs))
+	actual := dal.Index{Name: "idx_id", Keys: map[string]int32{"id": 1}}
+	require.NoError(t, db.Table(table).CreateIndex(ctx, actual))
+	extra := dal.Index{Name: "idx_name", Keys: map[string]int32{"name": 1}}
+	require.NoError(t, db.Table(table).CreateIndex(ctx, extra))
+
+	Tables[table] = []dal.Index{{Name: "idx_id", Keys: map[string]int32{"id": 1}, Unique: true}}
+	defer delete(Tables, table)
+
+	// the mismatched index is restored as the unique index can't be created over the duplicated ids
+	opt := ReconcileOption{Tables: []string{table}, Create: true, Drop: true}
+	_, err := Reconcile(ctx, db, opt)
+	require.Error(t, err)
+	indexes, err := db.Table(table).Indexes(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []dal.Index{actual}, indexes)
+
+	require.NoError(t, db.Table(table).Delete(ctx, map[string]interface{}{"name": "b"}))
+	diffs, err := Reconcile(ctx, db, opt)
+	require.NoError(t, err)
+	require.Len(t, diffs, 1)
+	require.Len(t, diffs[0].Mismatched, 1)
+	indexes, err = db.Table(table).Indexes(ctx)
+	require.NoError(t, err)
+	require.Equal(t, Tables[table], indexes)
+}
@@ -0,0 +1,202 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package index
+
+import (
+	"configcenter/src/common"
+	"configcenter/src/storage/dal"
+)
+
+// Tables is the declared indexes of the tables, it's the result of the index changes of all the upgraders,
+// a new index should be declared here as well as created by an upgrader.
+// the index without a name is matched by the keys only, the default name is given by mongodb when it's created.
+// the key "$text:$**" is the text index of all the string fields, which is used by the built-in full text search.
+// the KeyOrder of a compound index is declared so that it's created and compared in the order.
+var Tables = map[string][]dal.Index{
+	common.BKTableNameBaseApp: {
+		{Keys: map[string]int32{common.BKAppIDField: 1}, Background: true},
+		{Keys: map[string]int32{common.BKAppNameField: 1}, Background: true},
+		{Keys: map[string]int32{common.BKDefaultField: 1}, Background: true},
+		{Name: "idx_fullText", Keys: map[string]int32{"$text:$**": 1}, Background: true},
+	},
+	common.BKTableNameBaseHost: {
+		{Keys: map[string]int32{common.BKHostIDField: 1}, Background: true},
+		{Keys: map[string]int32{common.BKHostNameField: 1}, Background: true},
+		{Keys: map[string]int32{common.BKHostInnerIPField: 1}, Background: true},
+		{Keys: map[string]int32{common.BKHostOuterIPField: 1}, Background: true},
+		{Name: "innerIP_platID", Keys: map[string]int32{common.BKHostInnerIPField: 1, common.BKCloudIDField: 1}, KeyOrder: []string{common.BKHostInnerIPField, common.BKCloudIDField}},
+		{Name: "idx_fullText", Keys: map[string]int32{"$text:$**": 1}, Background: true},
+	},
+	common.BKTableNameBaseModule: {
+		{Keys: map[string]int32{common.BKModuleIDField: 1}, Background: true},
+		{Keys: map[string]int32{common.BKModuleNameField: 1}, Background: true},
+		{Keys: map[string]int32{common.BKDefaultField: 1}, Background: true},
+		{Keys: map[string]int32{common.BKAppIDField: 1}, Background: true},
+		{Keys: map[string]int32{common.BKOwnerIDField: 1}, Background: true},
+		{Keys: map[string]int32{common.BKSetIDField: 1}, Background: true},
+		{Keys: map[string]int32{common.BKInstParentStr: 1}, Background: true},
+	},
+	common.BKTableNameModuleHostConfig: {
+		{Keys: map[string]int32{common.BKAppIDField: 1}, Background: true},
+		{Keys: map[string]int32{common.BKHostIDField: 1}, Background: true},
+		{Keys: map[string]int32{common.BKModuleIDField: 1}, Background: true},
+		{Keys: map[string]int32{common.BKSetIDField: 1}, Background: true},
+	},
+	common.BKTableNameObjAsst: {
+		{Keys: map[string]int32{common.BKObjIDField: 1}, Background: true},
+		{Keys: map[string]int32{common.BKAsstObjIDField: 1}, Background: true},
+		{Keys: map[string]int32{common.BKOwnerIDField: 1}, Background: true},
+	},
+	common.BKTableNameObjAttDes: {
+		{Keys: map[string]int32{common.BKObjIDField: 1}, Background: true},
+		{Keys: map[string]int32{common.BKOwnerIDField: 1}, Background: true},
+		{Keys: map[string]int32{common.BKFieldID: 1}, Background: true},
+	},
+	common.BKTableNameObjClassifiction: {
+		{Keys: map[string]int32{common.BKClassificationIDField: 1}, Background: true},
+		{Keys: map[string]int32{common.BKClassificationNameField: 1}, Background: true},
+	},
+	common.BKTableNameObjDes: {
+		{Keys: map[string]int32{common.BKObjIDField: 1}, Background: true},
+		{Keys: map[string]int32{common.BKClassificationIDField: 1}, Background: true},
+		{Keys: map[string]int32{common.BKObjNameField: 1}, Background: true},
+		{Keys: map[string]int32{common.BKOwnerIDField: 1}, Background: true},
+		{Name: "idx_fullText", Keys: map[string]int32{"$text:$**": 1}, Background: true},
+	},
+	common.BKTableNameObjUnique: {
+		{Name: common.BKObjIDField, Keys: map[string]int32{common.BKObjIDField: 1}},
+	},
+	common.BKTableNameBaseInst: {
+		{Keys: map[string]int32{common.BKObjIDField: 1}, Background: true},
+		{Keys: map[string]int32{common.BKOwnerIDField: 1}, Background: true},
+		{Keys: map[string]int32{common.BKInstIDField: 1}, Background: true},
+		{Name: common.BKInstNameField, Keys: map[string]int32{common.BKInstNameField: 1}},
+		{Name: "idx_fullText", Keys: map[string]int32{"$text:$**": 1}, Background: true},
+	},
+	common.BKTableNameOperationLog: {
+		{Name: "op_target_1_inst_id_1_op_time_-1", Keys: map[string]int32{"op_target": 1, "inst_id": 1, "op_time": -1}, KeyOrder: []string{"op_target", "inst_id", "op_time"}, Background: true},
+		{Name: "bk_supplier_account_1_op_time_-1", Keys: map[string]int32{common.BKOwnerIDField: 1, "op_time": -1}, KeyOrder: []string{common.BKOwnerIDField, "op_time"}, Background: true},
+		{Name: "bk_biz_id_1_bk_supplier_account_1_op_time_-1", Keys: map[string]int32{common.BKAppIDField: 1, common.BKOwnerIDField: 1, "op_time": -1}, KeyOrder: []string{common.BKAppIDField, common.BKOwnerIDField, "op_time"}, Background: true},
+		{Name: "ext_key_1_bk_supplier_account_1_op_time_-1", Keys: map[string]int32{"ext_key": 1, common.BKOwnerIDField: 1, "op_time": -1}, KeyOrder: []string{"ext_key", common.BKOwnerIDField, "op_time"}, Background: true},
+	},
+	common.BKTableNameBasePlat: {
+		{Keys: map[string]int32{common.BKOwnerIDField: 1}, Background: true},
+	},
+	common.BKTableNameProcModule: {
+		{Keys: map[string]int32{common.BKAppIDField: 1}, Background: true},
+		{Keys: map[string]int32{common.BKProcessIDField: 1}, Background: true},
+	},
+	common.BKTableNameBaseProcess: {
+		{Keys: map[string]int32{common.BKProcessIDField: 1}, Background: true},
+		{Keys: map[string]int32{common.BKAppIDField: 1}, Background: true},
+		{Keys: map[string]int32{common.BKOwnerIDField: 1}, Background: true},
+	},
+	common.BKTableNamePropertyGroup: {
+		{Keys: map[string]int32{common.BKObjIDField: 1}, Background: true},
+		{Keys: map[string]int32{common.BKOwnerIDField: 1}, Background: true},
+		{Keys: map[string]int32{common.BKPropertyGroupIDField: 1}, Background: true},
+	},
+	common.BKTableNameBaseSet: {
+		{Keys: map[string]int32{common.BKSetIDField: 1}, Background: true},
+		{Keys: map[string]int32{common.BKInstParentStr: 1}, Background: true},
+		{Keys: map[string]int32{common.BKAppIDField: 1}, Background: true},
+		{Keys: map[string]int32{common.BKOwnerIDField: 1}, Background: true},
+		{Keys: map[string]int32{common.BKSetNameField: 1}, Background: true},
+	},
+	common.BKTableNameSubscription: {
+		{Keys: map[string]int32{common.BKSubscriptionIDField: 1}, Background: true},
+	},
+	common.BKTableNameTopoGraphics: {},
+	common.BKTableNameInstAsst: {
+		{Keys: map[string]int32{common.BKObjIDField: 1, common.BKInstIDField: 1}, KeyOrder: []string{common.BKObjIDField, common.BKInstIDField}, Background: true},
+		{Name: "idx_id", Keys: map[string]int32{common.BKFieldID: -1}, Background: true, Unique: true},
+		{Name: "idx_objID_asstObjID_asstID", Keys: map[string]int32{common.BKObjIDField: -1, common.BKAsstObjIDField: -1, common.AssociationKindIDField: -1}, KeyOrder: []string{common.BKObjIDField, common.BKAsstObjIDField, common.AssociationKindIDField}},
+		{Name: "idx_asstID_id", Keys: map[string]int32{common.AssociationObjAsstIDField: -1, common.BKFieldID: -1}, KeyOrder: []string{common.AssociationObjAsstIDField, common.BKFieldID}, Background: true},
+	},
+	common.BKTableNameNetcollectDevice: {
+		{Keys: map[string]int32{"device_id": 1}, Background: true},
+		{Keys: map[string]int32{"device_name": 1}, Background: true},
+		{Keys: map[string]int32{common.BKOwnerIDField: 1}, Background: true},
+	},
+	common.BKTableNameNetcollectProperty: {
+		{Keys: map[string]int32{"netcollect_property_id": 1}, Background: true},
+		{Keys: map[string]int32{common.BKOwnerIDField: 1}, Background: true},
+	},
+	common.BKTableNameProcOperateTask: {
+		{Name: "idx_taskID_gseTaskID", Keys: map[string]int32{common.BKTaskIDField: 1, common.BKGseOpTaskIDField: 1}, KeyOrder: []string{common.BKTaskIDField, common.BKGseOpTaskIDField}, Background: true},
+	},
+	common.BKTableNameProcInstanceModel: {
+		{Name: "idx_bkBizID_bkSetID_bkModuleID_bkHostInstanceID", Keys: map[string]int32{common.BKAppIDField: 1, common.BKSetIDField: 1, common.BKModuleIDField: 1, "bk_host_instance_id": 1}, KeyOrder: []string{common.BKAppIDField, common.BKSetIDField, common.BKModuleIDField, "bk_host_instance_id"}, Background: true},
+		{Name: "idx_bkBizID_bkHostID", Keys: map[string]int32{common.BKAppIDField: 1, common.BKHostIDField: 1}, KeyOrder: []string{common.BKAppIDField, common.BKHostIDField}, Background: true},
+		{Name: "idx_bkBizID_bkProcessID", Keys: map[string]int32{common.BKAppIDField: 1, common.BKProcessIDField: 1}, KeyOrder: []string{common.BKAppIDField, common.BKProcessIDField}, Background: true},
+	},
+	common.BKTableNameProcInstaceDetail: {
+		{Name: "idx_bkBizID_bkModuleID_bkProcessID", Keys: map[string]int32{common.BKAppIDField: 1, common.BKModuleIDField: 1, common.BKProcessIDField: 1}, KeyOrder: []string{common.BKAppIDField, common.BKModuleIDField, common.BKProcessIDField}, Background: true},
+		{Name: "idx_bkBizID_status", Keys: map[string]int32{common.BKAppIDField: 1, common.BKStatusField: 1}, KeyOrder: []string{common.BKAppIDField, common.BKStatusField}, Background: true},
+		{Name: "idx_bkBizID_bkHostID", Keys: map[string]int32{common.BKAppIDField: 1, common.BKHostIDField: 1}, KeyOrder: []string{common.BKAppIDField, common.BKHostIDField}, Background: true},
+	},
+	common.BKTableNameCloudTask: {
+		{Keys: map[string]int32{"bk_task_id": 1}, Background: true},
+		{Keys: map[string]int32{"bk_task_name": 1}, Background: true},
+	},
+	common.BKTableNameCloudResourceConfirm: {
+		{Keys: map[string]int32{"bk_resource_id": 1}, Background: true},
+		{Keys: map[string]int32{common.BKObjIDField: 1}, Background: true},
+	},
+	common.BKTableNameCloudSyncHistory: {
+		{Keys: map[string]int32{"bk_task_id": 1}, Background: true},
+		{Keys: map[string]int32{"bk_history_id": 1}, Background: true},
+	},
+	common.BKTableNameResourceConfirmHistory: {
+		{Keys: map[string]int32{"bk_resource_id": 1}, Background: true},
+		{Keys: map[string]int32{"confirm_history_id": 1}, Background: true},
+	},
+	common.BKTableNameSetTemplate: {
+		{Name: "idx_id", Keys: map[string]int32{common.BKFieldID: 1}, Unique: true, Background: true},
+	},
+	common.BKTableNameAPITask: {
+		{Name: "idx_taskID", Keys: map[string]int32{"task_id": 1}, Unique: true, Background: true},
+		{Name: "idx_name_status_createTime", Keys: map[string]int32{"name": 1, "status": 1, "create_time": 1}, KeyOrder: []string{"name", "status", "create_time"}, Background: true},
+		{Name: "idx_status_lastTime", Keys: map[string]int32{"status": 1, "last_time": 1}, KeyOrder: []string{"status", "last_time"}, Background: true},
+		{Name: "idx_name_flag_createTime", Keys: map[string]int32{"name": 1, "flag": 1, "create_time": 1}, KeyOrder: []string{"name", "flag", "create_time"}, Background: true},
+	},
+	common.BKTableNameSetTemplateSyncStatus: {
+		{Name: "idx_taskID", Keys: map[string]int32{"task_id": 1}, Background: true},
+		{Name: "idx_setID", Keys: map[string]int32{common.BKSetIDField: 1}, Unique: true, Background: true},
+		{Name: "idx_createLastTime", Keys: map[string]int32{"last_time": 1, "create_time": 1}, KeyOrder: []string{"last_time", "create_time"}, Background: true},
+		{Name: "idx_status", Keys: map[string]int32{"status": 1}, Background: true},
+	},
+	common.BKTableNameSetTemplateSyncHistory: {
+		{Name: "idx_taskID", Keys: map[string]int32{"task_id": 1}, Unique: true, Background: true},
+		{Name: "idx_setID", Keys: map[string]int32{common.BKSetIDField: 1}, Background: true},
+		{Name: "idx_createLastTime", Keys: map[string]int32{"last_time": 1, "create_time": 1}, KeyOrder: []string{"last_time", "create_time"}, Background: true},
+		{Name: "idx_status", Keys: map[string]int32{"status": 1}, Background: true},
+	},
+	common.BKTableNameChartConfig: {
+		{Name: "config_id", Keys: map[string]int32{"config_id": 1}, Unique: true, Background: true},
+		{Name: common.BKObjIDField, Keys: map[string]int32{common.BKObjIDField: 1}, Background: true},
+	},
+	common.BKTableNameChartPosition: {
+		{Name: common.BKAppIDField, Keys: map[string]int32{common.BKAppIDField: 1}, Background: true},
+	},
+	common.BKTableNameEventDeadLetter: {
+		{Name: "idx_id", Keys: map[string]int32{common.BKFieldID: 1}, Unique: true, Background: true},
+		{Name: "idx_subscriptionID_supplierAccount", Keys: map[string]int32{common.BKSubscriptionIDField: 1, common.BKOwnerIDField: 1}, KeyOrder: []string{common.BKSubscriptionIDField, common.BKOwnerIDField}, Background: true},
+	},
+	common.BKTableNameEventWatch: {
+		{Name: "idx_eventID", Keys: map[string]int32{"event_id": 1}, Unique: true, Background: true},
+		{Name: "idx_sequence", Keys: map[string]int32{"sequence": 1}, Unique: true, Background: true},
+		{Name: "idx_supplierAccount_objType_sequence", Keys: map[string]int32{common.BKOwnerIDField: 1, "obj_type": 1, "sequence": 1}, KeyOrder: []string{common.BKOwnerIDField, "obj_type", "sequence"}, Background: true},
+		{Name: "idx_createTime_ttl", Keys: map[string]int32{common.CreateTimeField: 1}, Background: true, ExpireAfterSeconds: 7 * 24 * 60 * 60},
+	},
+}
@@ -0,0 +1,54 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"net/http"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/metadata"
+	"configcenter/src/scene_server/admin_server/index"
+
+	"github.com/emicklei/go-restful"
+)
+
+// ReconcileIndexes diffs the declared indexes against the database, and creates the missing indexes
+// or drops the extra indexes as the request says, only the difference is returned if nothing is set.
+func (s *Service) ReconcileIndexes(req *restful.Request, resp *restful.Response) {
+	_, rid, defErr := s.getCommObject(req.Request.Header)
+
+	opt := index.ReconcileOption{}
+	if req.Request.ContentLength != 0 {
+		if err := req.ReadEntity(&opt); err != nil {
+			blog.Errorf("reconcile indexes failed, decode body failed, err: %v, rid: %s", err, rid)
+			resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Error(common.CCErrCommJSONUnmarshalFailed)})
+			return
+		}
+	}
+
+	for _, table := range opt.Tables {
+		if _, ok := index.Tables[table]; !ok {
+			resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Errorf(common.CCErrCommParamsIsInvalid, table)})
+			return
+		}
+	}
+
+	diffs, err := index.Reconcile(s.ctx, s.db, opt)
+	if err != nil {
+		blog.Errorf("reconcile indexes failed, option: %+v, err: %v, rid: %s", opt, err, rid)
+		resp.WriteError(http.StatusInternalServerError, &metadata.RespError{Msg: defErr.Errorf(common.CCErrCommMigrateFailed, err.Error())})
+		return
+	}
+	resp.WriteEntity(metadata.NewSuccessResp(diffs))
+}
@@ -0,0 +1,60 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package y3_6_202003021030
+
+import (
+	"context"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/scene_server/admin_server/upgrader"
+	"configcenter/src/storage/dal"
+)
+
+func createEventDeadLetterTable(ctx context.Context, db dal.RDB, conf *upgrader.Config) error {
+	existTable, err := db.HasTable(common.BKTableNameEventDeadLetter)
+	if err != nil {
+		blog.Errorf("has table %s error. err:%s", common.BKTableNameEventDeadLetter, err.Error())
+		return err
+	}
+
+	if !existTable {
+		if err := db.CreateTable(common.BKTableNameEventDeadLetter); err != nil {
+			blog.Errorf("create table %s error. err:%s", common.BKTableNameEventDeadLetter, err.Error())
+			return err
+		}
+	}
+
+	indexArr := []dal.Index{
+		dal.Index{
+			Keys:       map[string]int32{common.BKFieldID: 1},
+			Name:       "idx_id",
+			Unique:     true,
+			Background: true,
+		},
+		dal.Index{
+			Keys:       map[string]int32{common.BKSubscriptionIDField: 1, common.BKOwnerIDField: 1},
+			Name:       "idx_subscriptionID_supplierAccount",
+			Background: true,
+		},
+	}
+
+	for _, index := range indexArr {
+		if err := db.Table(common.BKTableNameEventDeadLetter).CreateIndex(ctx, index); err != nil && !db.IsDuplicatedError(err) {
+			blog.ErrorJSON("create table %s index %s error. err:%s", common.BKTableNameEventDeadLetter, index, err.Error())
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,42 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package y3_6_202003101000
+
+import (
+	"context"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/scene_server/admin_server/upgrader"
+	"configcenter/src/storage/dal"
+)
+
+// createFullTextIndexes creates the text indexes of all the string fields for the built-in full text search,
+// a table has one text index at most.
+func createFullTextIndexes(ctx context.Context, db dal.RDB, conf *upgrader.Config) error {
+	index := dal.Index{
+		Keys:       map[string]int32{"$text:$**": 1},
+		Name:       "idx_fullText",
+		Background: true,
+	}
+
+	tables := []string{common.BKTableNameBaseApp, common.BKTableNameBaseHost, common.BKTableNameBaseInst, common.BKTableNameObjDes}
+	for _, table := range tables {
+		if err := db.Table(table).CreateIndex(ctx, index); err != nil && !db.IsDuplicatedError(err) {
+			blog.ErrorJSON("create table %s index %s error. err:%s", table, index, err.Error())
+			return err
+		}
+	}
+
+	return nil
+}
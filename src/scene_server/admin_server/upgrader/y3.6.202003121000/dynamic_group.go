@@ -0,0 +1,39 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package y3_6_202003121000
+
+import (
+	"context"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/scene_server/admin_server/upgrader"
+	"configcenter/src/storage/dal"
+)
+
+// setHostDynamicGroupObject sets the object of the existing dynamic groups to host,
+// all of them are host groups before the dynamic groups of the other models are supported.
+func setHostDynamicGroupObject(ctx context.Context, db dal.RDB, conf *upgrader.Config) error {
+	filter := map[string]interface{}{
+		common.BKObjIDField: map[string]interface{}{common.BKDBExists: false},
+	}
+	doc := map[string]interface{}{
+		common.BKObjIDField: common.BKInnerObjIDHost,
+	}
+	if err := db.Table(common.BKTableNameUserAPI).Update(ctx, filter, doc); err != nil {
+		blog.Errorf("set the object of the dynamic groups in table %s failed, err: %v", common.BKTableNameUserAPI, err)
+		return err
+	}
+
+	return nil
+}
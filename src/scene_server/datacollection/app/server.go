@@ -40,8 +40,6 @@ import (
 	"configcenter/src/storage/dal/redis"
 	"configcenter/src/thirdpartyclient/esbserver"
 	"configcenter/src/thirdpartyclient/esbserver/esbutil"
-
-	re "gopkg.in/redis.v5"
 )
 
 func Run(ctx context.Context, cancel context.CancelFunc, op *options.ServerOption) error {
@@ -78,7 +76,7 @@ func Run(ctx context.Context, cancel context.CancelFunc, op *options.ServerOptio
 		if process.Config.MongoDB.Enable == "true" {
 			mgoCli, err = local.NewMgo(process.Config.MongoDB.BuildURI(), time.Minute)
 		} else {
-			mgoCli, err = remote.NewWithDiscover(process.Core)
+			mgoCli, err = remote.NewWithDiscover(process.Core, process.Config.MongoDB.RPCTLS)
 		}
 		if err != nil {
 			return fmt.Errorf("new mongo client failed, err: %s", err.Error())
@@ -104,7 +102,7 @@ func Run(ctx context.Context, cancel context.CancelFunc, op *options.ServerOptio
 		blog.Infof("[data-collection][RUN]connected to cc redis %+v", process.Config.CCRedis)
 		process.Service.SetCache(redisCli)
 
-		var snapcli, disCli, netCli *re.Client
+		var snapcli, disCli, netCli redis.Client
 		if process.Config.SnapRedis.Enable != "false" {
 			blog.Infof("[data-collection][RUN]connecting to snap-redis %+v", process.Config.SnapRedis.Config)
 			snapcli, err = redis.NewFromConfig(process.Config.SnapRedis.Config)
@@ -181,7 +179,11 @@ func (h *DCServer) onHostConfigUpdate(previous, current cc.ProcessConfig) {
 		blog.V(3).Infof("config updated: \n%s", out)
 
 		dbPrefix := "mongodb"
-		mongoConf := mongo.ParseConfigFromKV(dbPrefix, current.ConfigMap)
+		mongoConf, err := mongo.ParseConfigFromKV(dbPrefix, current.ConfigMap)
+		if err != nil {
+			blog.Errorf("parse mongodb config failed, err: %v", err)
+			return
+		}
 		h.Config.MongoDB = mongoConf
 
 		ccRedisPrefix := "redis"
@@ -208,7 +210,6 @@ func (h *DCServer) onHostConfigUpdate(previous, current cc.ProcessConfig) {
 		h.Config.Esb.AppCode = current.ConfigMap[esbPrefix+".appCode"]
 		h.Config.Esb.AppSecret = current.ConfigMap[esbPrefix+".appSecret"]
 
-		var err error
 		authPrefix := "auth"
 		h.Config.AuthConfig, err = authcenter.ParseConfigFromKV(authPrefix, current.ConfigMap)
 		if err != nil {
@@ -27,9 +27,9 @@ import (
 	"configcenter/src/scene_server/datacollection/datacollection/middleware"
 	"configcenter/src/scene_server/datacollection/datacollection/netcollect"
 	"configcenter/src/storage/dal"
+	"configcenter/src/storage/dal/redis"
 
 	"github.com/prometheus/client_golang/prometheus"
-	"gopkg.in/redis.v5"
 )
 
 type DataCollection struct {
@@ -44,7 +44,7 @@ func NewDataCollection(ctx context.Context, backbone *backbone.Engine, db dal.RD
 	return &DataCollection{ctx: ctx, Engine: backbone, db: db, registry: registry}
 }
 
-func (d *DataCollection) Run(redisCli, snapCli, disCli, netCli *redis.Client) error {
+func (d *DataCollection) Run(redisCli, snapCli, disCli, netCli redis.Client) error {
 	blog.Infof("data-collection start...")
 
 	var err error
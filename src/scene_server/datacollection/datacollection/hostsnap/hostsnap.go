@@ -30,9 +30,9 @@ import (
 	"configcenter/src/common/metadata"
 	dcUtil "configcenter/src/scene_server/datacollection/datacollection/middleware"
 	"configcenter/src/storage/dal"
+	"configcenter/src/storage/dal/redis"
 
 	"github.com/tidwall/gjson"
-	"gopkg.in/redis.v5"
 )
 
 var (
@@ -40,7 +40,7 @@ var (
 )
 
 type HostSnap struct {
-	redisCli    *redis.Client
+	redisCli    redis.Client
 	authManager extensions.AuthManager
 	httpHeader  http.Header
 	*backbone.Engine
@@ -56,7 +56,7 @@ type Cache struct {
 	flag  bool
 }
 
-func NewHostSnap(ctx context.Context, redisCli *redis.Client, db dal.RDB, engine *backbone.Engine, authManager extensions.AuthManager) *HostSnap {
+func NewHostSnap(ctx context.Context, redisCli redis.Client, db dal.RDB, engine *backbone.Engine, authManager extensions.AuthManager) *HostSnap {
 	header := http.Header{}
 	header.Add(common.BKHTTPOwnerID, common.BKDefaultOwnerID)
 	header.Add(common.BKHTTPHeaderUser, common.CCSystemCollectorUserName)
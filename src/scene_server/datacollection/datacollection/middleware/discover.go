@@ -20,22 +20,21 @@ import (
 	"configcenter/src/auth/extensions"
 	bkc "configcenter/src/common"
 	"configcenter/src/common/backbone"
-
-	"gopkg.in/redis.v5"
+	"configcenter/src/storage/dal/redis"
 )
 
 type Discover struct {
 	ctx        context.Context
 	httpHeader http.Header
 
-	redisCli *redis.Client
+	redisCli redis.Client
 	*backbone.Engine
 	authManager extensions.AuthManager
 }
 
 var msgHandlerCnt = int64(0)
 
-func NewDiscover(ctx context.Context, redisCli *redis.Client, backbone *backbone.Engine, authManager extensions.AuthManager) *Discover {
+func NewDiscover(ctx context.Context, redisCli redis.Client, backbone *backbone.Engine, authManager extensions.AuthManager) *Discover {
 	header := http.Header{}
 	header.Add(bkc.BKHTTPOwnerID, bkc.BKDefaultOwnerID)
 	header.Add(bkc.BKHTTPHeaderUser, bkc.CCSystemCollectorUserName)
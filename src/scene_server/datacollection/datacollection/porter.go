@@ -24,6 +24,7 @@ import (
 	"configcenter/src/common/backbone"
 	"configcenter/src/common/blog"
 	"configcenter/src/common/util"
+	ccredis "configcenter/src/storage/dal/redis"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/rs/xid"
@@ -37,7 +38,7 @@ type chanCollector struct {
 // 14kB * 10000 = 140M
 const cacheSize = 10000
 
-func BuildChanPorter(name string, analyzer Analyzer, redisCli, snapCli *redis.Client, channels []string, mockmesg string, registry prometheus.Registerer, engine *backbone.Engine) *chanPorter {
+func BuildChanPorter(name string, analyzer Analyzer, redisCli, snapCli ccredis.Client, channels []string, mockmesg string, registry prometheus.Registerer, engine *backbone.Engine) *chanPorter {
 	porter := &chanPorter{
 		analyzer:        analyzer,
 		name:            name,
@@ -138,10 +139,10 @@ type chanPorter struct {
 	isMaster *util.AtomicBool
 
 	// cc自己的redis，用于抢master锁，缓存slavequeue
-	redisCli *redis.Client
+	redisCli ccredis.Client
 
 	// 数据来源的redis，master 从这个redis读channel
-	snapCli *redis.Client
+	snapCli ccredis.Client
 
 	// redis channel 名称
 	channels []string
@@ -488,7 +489,7 @@ func writeOrClearChan(msgC chan string, name, msg string) {
 	}
 }
 
-func renewalMaster(redisCli *redis.Client, name string, procID string) error {
+func renewalMaster(redisCli ccredis.Client, name string, procID string) error {
 	lockKey := masterLockKey(name)
 	masterPID, err := redisCli.Get(lockKey).Result()
 	if err != nil {
@@ -505,7 +506,7 @@ func renewalMaster(redisCli *redis.Client, name string, procID string) error {
 }
 
 // loginMaster 抢master锁，当已经是master时给锁续期
-func loginMaster(redisCli *redis.Client, name string, procID string) error {
+func loginMaster(redisCli ccredis.Client, name string, procID string) error {
 	lockKey := masterLockKey(name)
 	var err error
 	var ok bool
@@ -546,7 +547,7 @@ func loginMaster(redisCli *redis.Client, name string, procID string) error {
 }
 
 // logoutMaster 主动退出master
-func logoutMaster(redisCli *redis.Client, name string, procID string) error {
+func logoutMaster(redisCli ccredis.Client, name string, procID string) error {
 	lockKey := masterLockKey(name)
 	masterPID, err := redisCli.Get(lockKey).Result()
 	if err == redis.Nil {
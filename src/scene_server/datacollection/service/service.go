@@ -22,18 +22,18 @@ import (
 	"configcenter/src/common/types"
 	"configcenter/src/scene_server/datacollection/logics"
 	"configcenter/src/storage/dal"
+	"configcenter/src/storage/dal/redis"
 
 	"github.com/emicklei/go-restful"
-	"gopkg.in/redis.v5"
 )
 
 type Service struct {
 	*backbone.Engine
 	db      dal.RDB
-	cache   *redis.Client
-	snapcli *redis.Client
-	disCli  *redis.Client
-	netCli  *redis.Client
+	cache   redis.Client
+	snapcli redis.Client
+	disCli  redis.Client
+	netCli  redis.Client
 	*logics.Logics
 }
 
@@ -41,19 +41,19 @@ func (s *Service) SetDB(db dal.RDB) {
 	s.db = db
 }
 
-func (s *Service) SetCache(db *redis.Client) {
+func (s *Service) SetCache(db redis.Client) {
 	s.cache = db
 }
 
-func (s *Service) SetSnapcli(db *redis.Client) {
+func (s *Service) SetSnapcli(db redis.Client) {
 	s.snapcli = db
 }
 
-func (s *Service) SetDisCli(db *redis.Client) {
+func (s *Service) SetDisCli(db redis.Client) {
 	s.disCli = db
 }
 
-func (s *Service) SetNetCli(db *redis.Client) {
+func (s *Service) SetNetCli(db redis.Client) {
 	s.netCli = db
 }
 
@@ -51,4 +51,18 @@ type Config struct {
 	Redis   redis.Config
 	RPC     rpc.ClientConfig
 	Auth    authcenter.AuthConfig
+	// SecretKey is used to encrypt the subscription secrets, configured by event.secretKey
+	SecretKey string
+	// PausedBufferLimit is the max number of events buffered for a paused or suspended subscription, the oldest
+	// ones are dropped beyond it, 0 means dropping all of them. configured by event.pausedBufferLimit
+	PausedBufferLimit int64
+	// SuspendAfterFailures is the number of consecutive failed deliveries after which a subscription is
+	// suspended, 0 means never. configured by event.suspendAfterFailures
+	SuspendAfterFailures int64
 }
+
+// default values of the event configurations
+const (
+	DefaultPausedBufferLimit    = 10000
+	DefaultSuspendAfterFailures = 100
+)
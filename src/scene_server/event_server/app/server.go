@@ -14,9 +14,11 @@ package app
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"os"
+	"strconv"
 	"sync"
 	"time"
 
@@ -78,11 +80,16 @@ func Run(ctx context.Context, cancel context.CancelFunc, op *options.ServerOptio
 		if process.Config.MongoDB.Enable == "true" {
 			db, err = local.NewMgo(process.Config.MongoDB.BuildURI(), time.Minute)
 		} else {
-			rpcCli, err = rpc.NewClientPool("tcp", engine.ServiceManageInterface.TMServer().GetServers, "/txn/v3/rpc")
+			var tlsConf *tls.Config
+			tlsConf, err = rpc.NewClientTLSConfig(process.Config.MongoDB.RPCTLS)
+			if err != nil {
+				return fmt.Errorf("load rpc tls config failed, err: %s", err.Error())
+			}
+			rpcCli, err = rpc.NewClientPool("tcp", engine.ServiceManageInterface.TMServer().GetServers, "/txn/v3/rpc", tlsConf)
 			if err != nil {
 				return fmt.Errorf("connect rpc server failed, err: %s", err.Error())
 			}
-			db, err = remote.NewWithDiscover(process.Core)
+			db, err = remote.NewWithDiscover(process.Core, process.Config.MongoDB.RPCTLS)
 		}
 		if err != nil {
 			return fmt.Errorf("connect mongo server failed, err: %s", err.Error())
@@ -94,6 +101,7 @@ func Run(ctx context.Context, cancel context.CancelFunc, op *options.ServerOptio
 			return fmt.Errorf("connect redis server failed, err: %s", err.Error())
 		}
 		process.Service.SetCache(cache)
+		process.Service.SetSecretKey(process.Config.SecretKey)
 
 		subCli, err := redis.NewFromConfig(process.Config.Redis)
 		if err != nil {
@@ -112,7 +120,16 @@ func Run(ctx context.Context, cancel context.CancelFunc, op *options.ServerOptio
 		}()
 
 		go func() {
-			errCh <- distribution.Start(ctx, cache, db, rpcCli)
+			distConf := distribution.Config{
+				SecretKey:            process.Config.SecretKey,
+				Registry:             engine.Metric().Registry(),
+				PausedBufferLimit:    process.Config.PausedBufferLimit,
+				SuspendAfterFailures: process.Config.SuspendAfterFailures,
+				Discovery:            engine.Discovery().EventServer(),
+				Address:              svrInfo.Address(),
+				Audit:                engine.CoreAPI.CoreService().Audit(),
+			}
+			errCh <- distribution.Start(ctx, cache, db, rpcCli, distConf)
 		}()
 
 		break
@@ -144,25 +161,46 @@ func (h *EventServer) onHostConfigUpdate(previous, current cc.ProcessConfig) {
 	configLock.Lock()
 	defer configLock.Unlock()
 	if len(current.ConfigMap) > 0 {
-		if h.Config == nil {
-			h.Config = new(options.Config)
-		}
 		// ignore err, cause ConfigMap is map[string]string
 		out, _ := json.MarshalIndent(current.ConfigMap, "", "  ")
 		blog.Infof("config updated: \n%s", out)
-		mongoConf := mongo.ParseConfigFromKV("mongodb", current.ConfigMap)
-		h.Config.MongoDB = mongoConf
 
-		redisConf := redis.ParseConfigFromKV("redis", current.ConfigMap)
-		h.Config.Redis = redisConf
+		// the config is parsed into a new one and set only when it's parsed successfully,
+		// Run starts as soon as the config is set
+		config := new(options.Config)
+		config.MongoDB, err = mongo.ParseConfigFromKV("mongodb", current.ConfigMap)
+		if err != nil {
+			blog.Errorf("parse mongodb config failed, err: %v", err)
+			return
+		}
 
-		h.Config.RPC.Address = current.ConfigMap["rpc.address"]
+		config.Redis = redis.ParseConfigFromKV("redis", current.ConfigMap)
 
-		h.Config.Auth, err = authcenter.ParseConfigFromKV("auth", current.ConfigMap)
+		config.RPC.Address = current.ConfigMap["rpc.address"]
+		config.SecretKey = current.ConfigMap["event.secretKey"]
+		config.PausedBufferLimit = parseInt64Config(current.ConfigMap, "event.pausedBufferLimit", options.DefaultPausedBufferLimit)
+		config.SuspendAfterFailures = parseInt64Config(current.ConfigMap, "event.suspendAfterFailures", options.DefaultSuspendAfterFailures)
+
+		config.Auth, err = authcenter.ParseConfigFromKV("auth", current.ConfigMap)
 		if err != nil {
 			blog.Errorf("parse auth center config failed: %v", err)
 		}
+		h.Config = config
+	}
+}
+
+// parseInt64Config returns the non-negative integer config of the key, or the default value if it's not set or invalid
+func parseInt64Config(configMap map[string]string, key string, defaultValue int64) int64 {
+	raw, exists := configMap[key]
+	if !exists || raw == "" {
+		return defaultValue
+	}
+	value, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || value < 0 {
+		blog.Errorf("invalid config %s: %s, use default value %d", key, raw, defaultValue)
+		return defaultValue
 	}
+	return value
 }
 
 func newServerInfo(op *options.ServerOption) (*types.ServerInfo, error) {
@@ -0,0 +1,134 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package distribution
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"configcenter/src/common/blog"
+	"configcenter/src/common/metadata"
+	"configcenter/src/scene_server/event_server/types"
+
+	"gopkg.in/redis.v5"
+)
+
+// batchPollInterval is the interval to check the length of the dist queue in batch mode
+var batchPollInterval = 100 * time.Millisecond
+
+// popDistBatch pops at most MaxSize dists of the subscriber, it waits at most MaxWaitMilliseconds for more
+// dists once the queue is not empty. the dists are popped in a transaction, so that the batches popped by
+// different event servers never interleave.
+func (dh *DistHandler) popDistBatch(sub *metadata.Subscription) []*metadata.DistInstCtx {
+	queueKey := types.EventCacheDistQueuePrefix + fmt.Sprint(sub.SubscriptionID)
+	maxSize := sub.BatchPolicy.MaxSize
+
+	// wait for the first dist as long as popDistInst does
+	deadline := time.Now().Add(time.Second * 10)
+	for {
+		length, err := dh.cache.LLen(queueKey).Result()
+		if err != nil {
+			blog.Errorf("get length of dist queue %s failed, err: %v", queueKey, err)
+			return nil
+		}
+		if length > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			return nil
+		}
+		time.Sleep(batchPollInterval)
+	}
+
+	// wait for more dists until the batch is full or the max wait time is reached
+	deadline = time.Now().Add(time.Duration(sub.BatchPolicy.MaxWaitMilliseconds) * time.Millisecond)
+	for time.Now().Before(deadline) {
+		length, err := dh.cache.LLen(queueKey).Result()
+		if err != nil {
+			blog.Errorf("get length of dist queue %s failed, err: %v", queueKey, err)
+			return nil
+		}
+		if length >= maxSize {
+			break
+		}
+		time.Sleep(batchPollInterval)
+	}
+
+	var rangeCmd *redis.StringSliceCmd
+	if _, err := dh.cache.TxPipelined(func(pipe *redis.Pipeline) error {
+		rangeCmd = pipe.LRange(queueKey, 0, maxSize-1)
+		pipe.LTrim(queueKey, maxSize, -1)
+		return nil
+	}); err != nil {
+		blog.Errorf("pop dist batch from %s failed, err: %v", queueKey, err)
+		return nil
+	}
+
+	dists := make([]*metadata.DistInstCtx, 0)
+	for _, raw := range rangeCmd.Val() {
+		dist := metadata.DistInst{}
+		if err := json.Unmarshal([]byte(raw), &dist); err != nil {
+			blog.Errorf("event distribute fail, unmarshal error: %v, data=[%s]", err, raw)
+			continue
+		}
+		dists = append(dists, &metadata.DistInstCtx{DistInst: dist, Raw: raw})
+	}
+	return dists
+}
+
+// handleDistBatch send the dists to the subscriber in one callback after the dist before the batch is done,
+// the payload is a json array of the dists in order.
+func (dh *DistHandler) handleDistBatch(sub *metadata.Subscription, dists []*metadata.DistInstCtx,
+	chNew chan metadata.Subscription, done chan struct{}) (err error) {
+	first, last := dists[0], dists[len(dists)-1]
+	blog.Infof("handling dist batch %d-%d of subscriber %d", first.DstbID, last.DstbID, sub.SubscriptionID)
+	subscriberID := fmt.Sprint(sub.SubscriptionID)
+
+	// the running key is keyed on the dist before the batch as handleDist does, so that the dist after the batch
+	// is still handled when the subscriber leaves the batch mode
+	runningKey := types.EventCacheDistRunningPrefix + subscriberID + "_" + fmt.Sprint(first.DstbID-1)
+	if err = saveRunning(dh.cache, runningKey, timeout+maxDeliverDuration(sub)); err != nil {
+		if ErrProcessExists == err {
+			blog.Infof("process exist, continue")
+			return nil
+		}
+		return err
+	}
+
+	if err = dh.waitPreviousDist(sub, subscriberID, first.DstbID); err != nil {
+		return err
+	}
+
+	defer func() {
+		for _, dist := range dists {
+			if err = dh.saveDistDone(dist); err != nil {
+				return
+			}
+		}
+		blog.Infof("done event dist batch: %d-%d", first.DstbID, last.DstbID)
+	}()
+
+	raws := make([]string, len(dists))
+	for index, dist := range dists {
+		raws[index] = buildPayload(sub, dist)
+	}
+	payload := "[" + strings.Join(raws, ",") + "]"
+	if err = dh.sendCallbackWithRetry(sub, payload, chNew, done, dists...); err != nil {
+		blog.Errorf("send callback error: %v", err)
+		return
+	}
+
+	return
+}
@@ -0,0 +1,203 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package distribution
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"configcenter/src/common/metadata"
+	"configcenter/src/scene_server/event_server/types"
+	"configcenter/src/storage/dal/mongo/memory"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testSubscriptionID = 1
+
+func newTestDistHandler(t *testing.T) *DistHandler {
+	_, cache := newFakeCache(t)
+	return &DistHandler{cache: cache, db: memory.New(), ctx: context.Background()}
+}
+
+// newTestDist returns the dist of the test subscription with the distribution id
+func newTestDist(t *testing.T, dstbID int64) *metadata.DistInstCtx {
+	dist := metadata.DistInst{
+		EventInst:      metadata.EventInst{ID: dstbID, EventType: metadata.EventTypeInstData, ObjType: "host"},
+		DstbID:         dstbID,
+		SubscriptionID: testSubscriptionID,
+	}
+	raw, err := json.Marshal(dist)
+	require.NoError(t, err)
+	return &metadata.DistInstCtx{DistInst: dist, Raw: string(raw)}
+}
+
+// pushTestDists pushes the dists to the queue of the test subscription
+func pushTestDists(t *testing.T, dh *DistHandler, dists ...*metadata.DistInstCtx) {
+	for _, dist := range dists {
+		require.NoError(t, dh.cache.RPush(types.EventCacheDistQueuePrefix+strconv.Itoa(testSubscriptionID), dist.Raw).Err())
+	}
+}
+
+// callbackServer is a subscriber which sends the received bodies to the channel
+func callbackServer(t *testing.T, handle func(body string)) string {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		handle(string(body))
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+func distIDs(dists []*metadata.DistInstCtx) []int64 {
+	ids := make([]int64, len(dists))
+	for index, dist := range dists {
+		ids[index] = dist.DstbID
+	}
+	return ids
+}
+
+func TestPopDistBatch(t *testing.T) {
+	defer func(interval time.Duration) { batchPollInterval = interval }(batchPollInterval)
+	batchPollInterval = 10 * time.Millisecond
+
+	dh := newTestDistHandler(t)
+	sub := &metadata.Subscription{
+		SubscriptionID: testSubscriptionID,
+		BatchPolicy:    &metadata.BatchPolicy{MaxSize: 3, MaxWaitMilliseconds: 50},
+	}
+	pushTestDists(t, dh, newTestDist(t, 1), newTestDist(t, 2))
+	require.NoError(t, dh.cache.RPush(types.EventCacheDistQueuePrefix+strconv.Itoa(testSubscriptionID), "invalid").Err())
+	pushTestDists(t, dh, newTestDist(t, 3), newTestDist(t, 4))
+
+	testCases := []struct {
+		name string
+		want []int64
+	}{
+		{
+			// the dist can't be unmarshalled is dropped
+			name: "full batch",
+			want: []int64{1, 2},
+		},
+		{
+			name: "wait timeout",
+			want: []int64{3, 4},
+		},
+	}
+	for _, tc := range testCases {
+		dists := dh.popDistBatch(sub)
+		require.Equal(t, tc.want, distIDs(dists), tc.name)
+		for _, dist := range dists {
+			require.Equal(t, newTestDist(t, dist.DstbID).Raw, dist.Raw, tc.name)
+		}
+	}
+	length, err := dh.cache.LLen(types.EventCacheDistQueuePrefix + strconv.Itoa(testSubscriptionID)).Result()
+	require.NoError(t, err)
+	require.Zero(t, length)
+}
+
+func TestHandleDistBatch(t *testing.T) {
+	received := make(chan string, 1)
+	dh := newTestDistHandler(t)
+	sub := &metadata.Subscription{
+		SubscriptionID: testSubscriptionID,
+		CallbackURL:    callbackServer(t, func(body string) { received <- body }),
+		TimeOutSeconds: 10,
+		BatchPolicy:    &metadata.BatchPolicy{MaxSize: 3},
+	}
+	dists := []*metadata.DistInstCtx{newTestDist(t, 1), newTestDist(t, 2), newTestDist(t, 3)}
+	require.NoError(t, dh.handleDistBatch(sub, dists, make(chan metadata.Subscription), make(chan struct{})))
+
+	payload := make([]metadata.DistInst, 0)
+	require.NoError(t, json.Unmarshal([]byte(<-received), &payload))
+	require.Len(t, payload, 3)
+	for index, dist := range payload {
+		require.Equal(t, dists[index].DistInst, dist)
+	}
+
+	for _, dist := range dists {
+		done, err := checkFromDone(dh.cache, types.EventCacheDistDonePrefix+strconv.Itoa(testSubscriptionID),
+			strconv.FormatInt(dist.DstbID, 10))
+		require.NoError(t, err)
+		require.True(t, done, "dist %d", dist.DstbID)
+	}
+}
+
+// TestHandleDistAfterBatch checks the dist after a batch is still sent in order when the subscriber leaves the
+// batch mode while the batch is being sent
+func TestHandleDistAfterBatch(t *testing.T) {
+	defer func(period time.Duration) { waitPeriod = period }(waitPeriod)
+	waitPeriod = 10 * time.Millisecond
+
+	received := make(chan string, 2)
+	release := make(chan struct{})
+	url := callbackServer(t, func(body string) {
+		received <- body
+		if body[0] == '[' {
+			<-release
+		}
+	})
+	// the blocked callback must be released for the server to be closed if the test fails
+	var releaseOnce sync.Once
+	unblock := func() { releaseOnce.Do(func() { close(release) }) }
+	t.Cleanup(unblock)
+	dh := newTestDistHandler(t)
+	batchSub := &metadata.Subscription{
+		SubscriptionID: testSubscriptionID,
+		CallbackURL:    url,
+		TimeOutSeconds: 10,
+		BatchPolicy:    &metadata.BatchPolicy{MaxSize: 3},
+	}
+	singleSub := *batchSub
+	singleSub.BatchPolicy = nil
+
+	batchErr := make(chan error, 1)
+	go func() {
+		dists := []*metadata.DistInstCtx{newTestDist(t, 1), newTestDist(t, 2), newTestDist(t, 3)}
+		batchErr <- dh.handleDistBatch(batchSub, dists, make(chan metadata.Subscription), make(chan struct{}))
+	}()
+	require.Equal(t, '[', rune((<-received)[0]))
+
+	distErr := make(chan error, 1)
+	dist := newTestDist(t, 4)
+	go func() {
+		distErr <- dh.handleDist(&singleSub, dist, make(chan metadata.Subscription), make(chan struct{}))
+	}()
+
+	// the dist waits for the batch to be done
+	select {
+	case body := <-received:
+		t.Fatalf("dist is sent before the batch is done: %s", body)
+	case err := <-distErr:
+		t.Fatalf("dist is handled before the batch is done, err: %v", err)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	unblock()
+	require.NoError(t, <-batchErr)
+	require.NoError(t, <-distErr)
+	select {
+	case body := <-received:
+		require.Equal(t, dist.Raw, body)
+	default:
+		t.Fatal("dist after the batch is not sent")
+	}
+}
@@ -0,0 +1,340 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package distribution
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	ccredis "configcenter/src/storage/dal/redis"
+
+	"github.com/alicebob/miniredis/server"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/redis.v5"
+)
+
+// fakeCmd is a command of the fake cache, it's called with the data lock held
+type fakeCmd func(c *server.Peer, args []string)
+
+// queuedCmd is a command queued in a transaction
+type queuedCmd struct {
+	cmd  fakeCmd
+	args []string
+}
+
+// fakeCache is a redis server that serves the commands used by the distribution in memory,
+// the keys never expire.
+type fakeCache struct {
+	*server.Server
+	lock    sync.Mutex
+	strings map[string]string
+	hashes  map[string]map[string]string
+	lists   map[string][]string
+	sets    map[string]map[string]bool
+	// published is the messages published to the channels
+	published map[string][]string
+}
+
+// newFakeCache starts a fake cache and returns the client connected to it
+func newFakeCache(t *testing.T) (*fakeCache, ccredis.Client) {
+	srv, err := server.NewServer("127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(srv.Close)
+
+	f := &fakeCache{
+		Server:    srv,
+		strings:   make(map[string]string),
+		hashes:    make(map[string]map[string]string),
+		lists:     make(map[string][]string),
+		sets:      make(map[string]map[string]bool),
+		published: make(map[string][]string),
+	}
+	cmds := map[string]fakeCmd{
+		"PING":     func(c *server.Peer, args []string) { c.WriteInline("PONG") },
+		"SET":      f.set,
+		"SETNX":    func(c *server.Peer, args []string) { f.set(c, append(args, "nx")) },
+		"GET":      f.get,
+		"DEL":      f.del,
+		"EXISTS":   f.exists,
+		"INCR":     f.incr,
+		"HSET":     f.hset,
+		"HGET":     f.hget,
+		"HEXISTS":  f.hexists,
+		"HDEL":     f.hdel,
+		"HINCRBY":  f.hincrby,
+		"HGETALL":  f.hgetall,
+		"RPUSH":    f.rpush,
+		"LPUSH":    f.lpush,
+		"LLEN":     f.llen,
+		"LRANGE":   f.lrange,
+		"LTRIM":    f.ltrim,
+		"SADD":     f.sadd,
+		"SMEMBERS": f.smembers,
+		"EXPIRE":   func(c *server.Peer, args []string) { c.WriteInt(1) },
+		"PUBLISH":  f.publish,
+	}
+	for name, cmd := range cmds {
+		require.NoError(t, srv.Register(name, f.wrap(cmd)))
+	}
+	require.NoError(t, srv.Register("MULTI", func(c *server.Peer, cmd string, args []string) {
+		c.Ctx = make([]queuedCmd, 0)
+		c.WriteOK()
+	}))
+	require.NoError(t, srv.Register("EXEC", func(c *server.Peer, cmd string, args []string) {
+		queued, ok := c.Ctx.([]queuedCmd)
+		if !ok {
+			c.WriteError("ERR EXEC without MULTI")
+			return
+		}
+		c.Ctx = nil
+		f.lock.Lock()
+		defer f.lock.Unlock()
+		c.WriteLen(len(queued))
+		for _, q := range queued {
+			q.cmd(c, q.args)
+		}
+	}))
+
+	client := redis.NewClient(&redis.Options{Addr: srv.Addr().String()})
+	t.Cleanup(func() { client.Close() })
+	return f, client
+}
+
+// wrap queues the command in a transaction, or runs it with the data lock held
+func (f *fakeCache) wrap(cmd fakeCmd) server.Cmd {
+	return func(c *server.Peer, name string, args []string) {
+		if queued, ok := c.Ctx.([]queuedCmd); ok {
+			c.Ctx = append(queued, queuedCmd{cmd: cmd, args: args})
+			c.WriteInline("QUEUED")
+			return
+		}
+		f.lock.Lock()
+		defer f.lock.Unlock()
+		cmd(c, args)
+	}
+}
+
+func (f *fakeCache) set(c *server.Peer, args []string) {
+	for _, opt := range args[2:] {
+		if strings.ToLower(opt) == "nx" {
+			if _, ok := f.strings[args[0]]; ok {
+				c.WriteNull()
+				return
+			}
+		}
+	}
+	f.strings[args[0]] = args[1]
+	c.WriteOK()
+}
+
+func (f *fakeCache) get(c *server.Peer, args []string) {
+	if value, ok := f.strings[args[0]]; ok {
+		c.WriteBulk(value)
+		return
+	}
+	c.WriteNull()
+}
+
+func (f *fakeCache) has(key string) bool {
+	_, isString := f.strings[key]
+	_, isHash := f.hashes[key]
+	_, isList := f.lists[key]
+	_, isSet := f.sets[key]
+	return isString || isHash || isList || isSet
+}
+
+func (f *fakeCache) del(c *server.Peer, args []string) {
+	deleted := 0
+	for _, key := range args {
+		if f.has(key) {
+			deleted++
+		}
+		delete(f.strings, key)
+		delete(f.hashes, key)
+		delete(f.lists, key)
+		delete(f.sets, key)
+	}
+	c.WriteInt(deleted)
+}
+
+func (f *fakeCache) exists(c *server.Peer, args []string) {
+	count := 0
+	for _, key := range args {
+		if f.has(key) {
+			count++
+		}
+	}
+	c.WriteInt(count)
+}
+
+func (f *fakeCache) incr(c *server.Peer, args []string) {
+	value, _ := strconv.Atoi(f.strings[args[0]])
+	value++
+	f.strings[args[0]] = strconv.Itoa(value)
+	c.WriteInt(value)
+}
+
+func (f *fakeCache) hash(key string) map[string]string {
+	hash, ok := f.hashes[key]
+	if !ok {
+		hash = make(map[string]string)
+		f.hashes[key] = hash
+	}
+	return hash
+}
+
+func (f *fakeCache) hset(c *server.Peer, args []string) {
+	hash := f.hash(args[0])
+	_, exists := hash[args[1]]
+	hash[args[1]] = args[2]
+	if exists {
+		c.WriteInt(0)
+		return
+	}
+	c.WriteInt(1)
+}
+
+func (f *fakeCache) hget(c *server.Peer, args []string) {
+	if value, ok := f.hashes[args[0]][args[1]]; ok {
+		c.WriteBulk(value)
+		return
+	}
+	c.WriteNull()
+}
+
+func (f *fakeCache) hexists(c *server.Peer, args []string) {
+	if _, ok := f.hashes[args[0]][args[1]]; ok {
+		c.WriteInt(1)
+		return
+	}
+	c.WriteInt(0)
+}
+
+func (f *fakeCache) hdel(c *server.Peer, args []string) {
+	deleted := 0
+	for _, field := range args[1:] {
+		if _, ok := f.hashes[args[0]][field]; ok {
+			delete(f.hashes[args[0]], field)
+			deleted++
+		}
+	}
+	c.WriteInt(deleted)
+}
+
+func (f *fakeCache) hincrby(c *server.Peer, args []string) {
+	hash := f.hash(args[0])
+	value, _ := strconv.Atoi(hash[args[1]])
+	incr, _ := strconv.Atoi(args[2])
+	value += incr
+	hash[args[1]] = strconv.Itoa(value)
+	c.WriteInt(value)
+}
+
+func (f *fakeCache) hgetall(c *server.Peer, args []string) {
+	hash := f.hashes[args[0]]
+	c.WriteLen(len(hash) * 2)
+	for field, value := range hash {
+		c.WriteBulk(field)
+		c.WriteBulk(value)
+	}
+}
+
+func (f *fakeCache) rpush(c *server.Peer, args []string) {
+	f.lists[args[0]] = append(f.lists[args[0]], args[1:]...)
+	c.WriteInt(len(f.lists[args[0]]))
+}
+
+func (f *fakeCache) lpush(c *server.Peer, args []string) {
+	for _, value := range args[1:] {
+		f.lists[args[0]] = append([]string{value}, f.lists[args[0]]...)
+	}
+	c.WriteInt(len(f.lists[args[0]]))
+}
+
+func (f *fakeCache) llen(c *server.Peer, args []string) {
+	c.WriteInt(len(f.lists[args[0]]))
+}
+
+// listRange converts the start and stop of the list commands to the slice bounds
+func listRange(length int, startArg, stopArg string) (int, int) {
+	start, _ := strconv.Atoi(startArg)
+	stop, _ := strconv.Atoi(stopArg)
+	if start < 0 {
+		start += length
+	}
+	if stop < 0 {
+		stop += length
+	}
+	if start < 0 {
+		start = 0
+	}
+	if stop >= length {
+		stop = length - 1
+	}
+	if start > stop {
+		return 0, 0
+	}
+	return start, stop + 1
+}
+
+func (f *fakeCache) lrange(c *server.Peer, args []string) {
+	list := f.lists[args[0]]
+	start, end := listRange(len(list), args[1], args[2])
+	c.WriteLen(end - start)
+	for _, value := range list[start:end] {
+		c.WriteBulk(value)
+	}
+}
+
+func (f *fakeCache) ltrim(c *server.Peer, args []string) {
+	list := f.lists[args[0]]
+	start, end := listRange(len(list), args[1], args[2])
+	f.lists[args[0]] = append([]string{}, list[start:end]...)
+	c.WriteOK()
+}
+
+func (f *fakeCache) sadd(c *server.Peer, args []string) {
+	set, ok := f.sets[args[0]]
+	if !ok {
+		set = make(map[string]bool)
+		f.sets[args[0]] = set
+	}
+	added := 0
+	for _, member := range args[1:] {
+		if !set[member] {
+			set[member] = true
+			added++
+		}
+	}
+	c.WriteInt(added)
+}
+
+func (f *fakeCache) smembers(c *server.Peer, args []string) {
+	members := make([]string, 0)
+	for member := range f.sets[args[0]] {
+		members = append(members, member)
+	}
+	sort.Strings(members)
+	c.WriteLen(len(members))
+	for _, member := range members {
+		c.WriteBulk(member)
+	}
+}
+
+func (f *fakeCache) publish(c *server.Peer, args []string) {
+	f.published[args[0]] = append(f.published[args[0]], args[1])
+	c.WriteInt(0)
+}
@@ -21,22 +21,27 @@ import (
 	"strconv"
 	"time"
 
-	"gopkg.in/redis.v5"
-
 	"configcenter/src/common/blog"
 	"configcenter/src/common/http/httpclient"
 	"configcenter/src/common/metadata"
 	"configcenter/src/scene_server/event_server/types"
+	"configcenter/src/storage/dal/redis"
 )
 
-func (dh *DistHandler) SendCallback(receiver *metadata.Subscription, event string) (err error) {
+// SendCallback send the event to the subscriber, returns the status code and body of the response if any
+func (dh *DistHandler) SendCallback(receiver *metadata.Subscription, deliveryID string, event string) (statusCode int, respData []byte, err error) {
 	increaseTotal(dh.cache, receiver.SubscriptionID)
 
 	body := bytes.NewBufferString(event)
 	req, err := http.NewRequest("POST", receiver.CallbackURL, body)
 	if err != nil {
 		increaseFailure(dh.cache, receiver.SubscriptionID)
-		return fmt.Errorf("event distribute fail, build request error: %v, data=[%s]", err, event)
+		return statusCode, respData, fmt.Errorf("event distribute fail, build request error: %v, data=[%s]", err, event)
+	}
+	req.Header.Set(metadata.EventDeliveryIDHeader, deliveryID)
+	if err = dh.signCallback(req.Header, receiver, event); err != nil {
+		increaseFailure(dh.cache, receiver.SubscriptionID)
+		return statusCode, respData, fmt.Errorf("event distribute fail, sign request error: %v, data=[%s]", err, event)
 	}
 	var duration time.Duration
 	if receiver.TimeOutSeconds == 0 {
@@ -47,29 +52,30 @@ func (dh *DistHandler) SendCallback(receiver *metadata.Subscription, event strin
 	resp, err := httpCli.DoWithTimeout(duration, req)
 	if err != nil {
 		increaseFailure(dh.cache, receiver.SubscriptionID)
-		return fmt.Errorf("event distribute fail, send request error: %v, data=[%s]", err, event)
+		return statusCode, respData, fmt.Errorf("event distribute fail, send request error: %v, data=[%s]", err, event)
 	}
 	defer resp.Body.Close()
-	respData, err := ioutil.ReadAll(resp.Body)
+	statusCode = resp.StatusCode
+	respData, err = ioutil.ReadAll(resp.Body)
 	if err != nil {
 		increaseFailure(dh.cache, receiver.SubscriptionID)
-		return fmt.Errorf("event distribute fail, read response error: %v, data=[%s]", err, event)
+		return statusCode, respData, fmt.Errorf("event distribute fail, read response error: %v, data=[%s]", err, event)
 	}
 	if receiver.ConfirmMode == metadata.ConfirmModeHTTPStatus {
 		if strconv.Itoa(resp.StatusCode) != receiver.ConfirmPattern {
 			increaseFailure(dh.cache, receiver.SubscriptionID)
-			return fmt.Errorf("event distribute fail, received response %s, data=[%s]", respData, event)
+			return statusCode, respData, fmt.Errorf("event distribute fail, received response %s, data=[%s]", respData, event)
 		}
 	} else if receiver.ConfirmMode == metadata.ConfirmModeRegular {
 		pattern, err := regexp.Compile(receiver.ConfirmPattern)
 		if err != nil {
-			return fmt.Errorf("event distribute fail, build regexp error: %v", err)
+			return statusCode, respData, fmt.Errorf("event distribute fail, build regexp error: %v", err)
 		}
 		if !pattern.Match(respData) {
 			increaseFailure(dh.cache, receiver.SubscriptionID)
-			return fmt.Errorf("event distribute fail, received response %s, data=[%s]", respData, event)
+			return statusCode, respData, fmt.Errorf("event distribute fail, received response %s, data=[%s]", respData, event)
 		}
-		return nil
+		return statusCode, respData, nil
 	}
 
 	return
@@ -77,15 +83,15 @@ func (dh *DistHandler) SendCallback(receiver *metadata.Subscription, event strin
 
 var httpCli = httpclient.NewHttpClient()
 
-func increaseTotal(cache *redis.Client, subscriptionID int64) error {
+func increaseTotal(cache redis.Client, subscriptionID int64) error {
 	return increase(cache, subscriptionID, "total")
 }
 
-func increaseFailure(cache *redis.Client, subscriptionID int64) error {
+func increaseFailure(cache redis.Client, subscriptionID int64) error {
 	return increase(cache, subscriptionID, "failue")
 }
 
-func increase(cache *redis.Client, subscriptionID int64, key string) error {
+func increase(cache redis.Client, subscriptionID int64, key string) error {
 	err := cache.HIncrBy(types.EventCacheDistCallBackCountPrefix+strconv.FormatInt(subscriptionID, 10), key, 1).Err()
 	if err != nil {
 		blog.V(3).Infof("increaseFailure %s", err.Error())
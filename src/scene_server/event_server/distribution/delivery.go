@@ -0,0 +1,108 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package distribution
+
+import (
+	"encoding/json"
+	"strconv"
+	"unicode/utf8"
+
+	"configcenter/src/common/blog"
+	"configcenter/src/common/metadata"
+	"configcenter/src/scene_server/event_server/types"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/redis.v5"
+)
+
+const (
+	labelSubscriptionID = "subscription_id"
+	labelStatusCode     = "status_code"
+)
+
+// deliveryMetrics are the prometheus metrics of the callbacks, labeled by the subscription
+type deliveryMetrics struct {
+	deliveryTotal    *prometheus.CounterVec
+	failureTotal     *prometheus.CounterVec
+	deliveryDuration *prometheus.HistogramVec
+}
+
+func newDeliveryMetrics(reg prometheus.Registerer) *deliveryMetrics {
+	if reg == nil {
+		return nil
+	}
+
+	m := &deliveryMetrics{
+		deliveryTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "cmdb_event_delivery_total",
+			Help: "event callback requests total, status_code is 0 if no response is received.",
+		}, []string{labelSubscriptionID, labelStatusCode}),
+		failureTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "cmdb_event_delivery_failure_total",
+			Help: "failed event callback requests total.",
+		}, []string{labelSubscriptionID}),
+		deliveryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "cmdb_event_delivery_duration_millisecond",
+			Help:    "event callback request duration millisecond.",
+			Buckets: prometheus.ExponentialBuckets(10, 2, 12),
+		}, []string{labelSubscriptionID}),
+	}
+	reg.MustRegister(m.deliveryTotal, m.failureTotal, m.deliveryDuration)
+	return m
+}
+
+// recordDelivery update the metrics and save the delivery log of a callback request
+func (dh *DistHandler) recordDelivery(log *metadata.EventDeliveryLog, sendErr error) {
+	subID := strconv.FormatInt(log.SubscriptionID, 10)
+	if dh.metrics != nil {
+		dh.metrics.deliveryTotal.With(prometheus.Labels{labelSubscriptionID: subID,
+			labelStatusCode: strconv.Itoa(log.StatusCode)}).Inc()
+		dh.metrics.deliveryDuration.With(prometheus.Labels{labelSubscriptionID: subID}).
+			Observe(float64(log.DurationMilliseconds))
+		if sendErr != nil {
+			dh.metrics.failureTotal.With(prometheus.Labels{labelSubscriptionID: subID}).Inc()
+		}
+	}
+
+	log.Request = truncateBody(log.Request)
+	log.Response = truncateBody(log.Response)
+	if sendErr != nil {
+		log.Error = truncateBody(sendErr.Error())
+	}
+	raw, err := json.Marshal(log)
+	if err != nil {
+		blog.Errorf("marshal delivery log of subscriber %d failed, err: %v", log.SubscriptionID, err)
+		return
+	}
+
+	key := types.EventCacheDeliveryLogPrefix + subID
+	if _, err := dh.cache.Pipelined(func(pipe *redis.Pipeline) error {
+		pipe.LPush(key, raw)
+		pipe.LTrim(key, 0, metadata.EventDeliveryLogCapacity-1)
+		return nil
+	}); err != nil {
+		blog.Errorf("save delivery log of subscriber %d failed, err: %v", log.SubscriptionID, err)
+	}
+}
+
+// truncateBody cuts the body to EventDeliveryLogBodyLimit bytes at most, without splitting a multi-byte character
+func truncateBody(body string) string {
+	if len(body) <= metadata.EventDeliveryLogBodyLimit {
+		return body
+	}
+	end := metadata.EventDeliveryLogBodyLimit
+	for end > 0 && !utf8.RuneStart(body[end]) {
+		end--
+	}
+	return body[:end] + "...(truncated)"
+}
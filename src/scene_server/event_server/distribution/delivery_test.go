@@ -0,0 +1,45 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package distribution
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"configcenter/src/common/metadata"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTruncateBody(t *testing.T) {
+	limit := metadata.EventDeliveryLogBodyLimit
+	testCases := []struct {
+		body   string
+		expect string
+	}{
+		{"", ""},
+		{"ok", "ok"},
+		{strings.Repeat("a", limit), strings.Repeat("a", limit)},
+		{strings.Repeat("a", limit+1), strings.Repeat("a", limit) + "...(truncated)"},
+		// the multi-byte character across the limit is dropped as a whole
+		{strings.Repeat("a", limit-1) + "主机", strings.Repeat("a", limit-1) + "...(truncated)"},
+		{strings.Repeat("a", limit-3) + "主机", strings.Repeat("a", limit-3) + "主...(truncated)"},
+		{strings.Repeat("主", limit), strings.Repeat("主", limit/3) + "...(truncated)"},
+	}
+	for index, c := range testCases {
+		truncated := truncateBody(c.body)
+		require.Equal(t, c.expect, truncated, "case %d", index)
+		require.True(t, utf8.ValidString(truncated), "case %d", index)
+	}
+}
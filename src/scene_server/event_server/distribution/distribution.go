@@ -41,17 +41,13 @@ func (dh *DistHandler) StartDistribute() (err error) {
 	reconciler := newReconciler(dh.ctx, dh.cache, dh.db)
 	reconciler.loadAll()
 	reconciler.reconcile()
-	subscribers := reconciler.persistedSubscribers
 
 	chErr := make(chan error, 1)
 	routines := map[int64]chan struct{}{}
 	renewMaps := map[int64]chan metadata.Subscription{}
-	for _, str := range subscribers {
-		subscriber := metadata.Subscription{}
-		if err := json.Unmarshal([]byte(str), &subscriber); err != nil {
-			return err
-		}
-
+	// subscribers holds all the subscribers, only those owned by the current event server are distributed here
+	subscribers := map[int64]metadata.Subscription{}
+	startDist := func(subscriber metadata.Subscription) {
 		done := make(chan struct{})
 		renewCh := make(chan metadata.Subscription)
 		go func() {
@@ -60,10 +56,30 @@ func (dh *DistHandler) StartDistribute() (err error) {
 				chErr <- err
 			}
 		}()
-		renewMaps[subscriber.SubscriptionID] = renewCh
 		routines[subscriber.SubscriptionID] = done
+		renewMaps[subscriber.SubscriptionID] = renewCh
+	}
+	stopDist := func(subscriptionID int64) {
+		if routines[subscriptionID] != nil {
+			close(routines[subscriptionID])
+			delete(routines, subscriptionID)
+			delete(renewMaps, subscriptionID)
+		}
 	}
 
+	for _, str := range reconciler.persistedSubscribers {
+		subscriber := metadata.Subscription{}
+		if err := json.Unmarshal([]byte(str), &subscriber); err != nil {
+			return err
+		}
+		subscribers[subscriber.SubscriptionID] = subscriber
+		dh.filters.refresh(subscriber)
+		if dh.partitioner.isOwner(subscriber.SubscriptionID) {
+			startDist(subscriber)
+		}
+	}
+	dh.filters.setLoaded()
+
 	go func() {
 		for range time.Tick(time.Second * 60) {
 			reconciler.loadAll()
@@ -78,47 +94,58 @@ func (dh *DistHandler) StartDistribute() (err error) {
 		blog.Infof("discovering subscriber change")
 
 		defer blog.Warn("discovering subscriber change process stopped")
+		rebalanceTicker := time.NewTicker(partitionRefreshInterval)
+		defer rebalanceTicker.Stop()
 		for {
-			msg := <-MsgChan
-			msgAction := extractChangeAction(msg)
-			msgBody := extractChangeBody(msg)
-
-			subscriber := metadata.Subscription{}
-			blog.Infof("msg: action:%s, body:%s", msgAction, msgBody)
-			if err := json.Unmarshal([]byte(msgBody), &subscriber); err != nil {
-				chErr <- err
-				return
-			}
-			switch msgAction {
-			case "create":
-				blog.Infof("starting subscribers process %d", subscriber.SubscriptionID)
-				if renewCh, ok := renewMaps[subscriber.SubscriptionID]; ok {
-					renewCh <- subscriber
+			select {
+			case <-rebalanceTicker.C:
+				if !dh.partitioner.refresh() {
 					continue
 				}
-				done := make(chan struct{})
-				renewCh := make(chan metadata.Subscription)
-				go func() {
-					err := dh.distToSubscribe(subscriber, renewCh, done)
-					if err != nil {
-						chErr <- err
+				// the dists already taken by the previous owner are protected by the running and done keys,
+				// so the new owner continues after them in order
+				for subscriptionID, subscriber := range subscribers {
+					_, running := routines[subscriptionID]
+					owned := dh.partitioner.isOwner(subscriptionID)
+					if owned && !running {
+						blog.Infof("subscriber %d is assigned to this event server, start distributing", subscriptionID)
+						startDist(subscriber)
+					} else if !owned && running {
+						blog.Infof("subscriber %d is assigned to another event server, stop distributing", subscriptionID)
+						stopDist(subscriptionID)
 					}
-				}()
-				routines[subscriber.SubscriptionID] = done
-				renewMaps[subscriber.SubscriptionID] = renewCh
-			case "update":
-				blog.Infof("renew subscribers process %d", subscriber.SubscriptionID)
-				if renewCh, exist := renewMaps[subscriber.SubscriptionID]; exist == true {
-					renewCh <- subscriber
-				} else {
-					MsgChan <- "create" + msgBody
 				}
-			case "delete":
-				blog.Infof("subscriber has been deleted, now stopping subscribe process, subscriberID: %d", subscriber.SubscriptionID)
-				if routines[subscriber.SubscriptionID] != nil {
-					close(routines[subscriber.SubscriptionID])
-					delete(routines, subscriber.SubscriptionID)
-					delete(renewMaps, subscriber.SubscriptionID)
+			case msg := <-MsgChan:
+				msgAction := extractChangeAction(msg)
+				msgBody := extractChangeBody(msg)
+
+				subscriber := metadata.Subscription{}
+				if err := json.Unmarshal([]byte(msgBody), &subscriber); err != nil {
+					chErr <- err
+					return
+				}
+				// the body is not logged, it has the secrets of the subscriber
+				blog.Infof("msg: action:%s, subscription: %d", msgAction, subscriber.SubscriptionID)
+				switch msgAction {
+				case "create", "update":
+					subscribers[subscriber.SubscriptionID] = subscriber
+					dh.filters.refresh(subscriber)
+					if !dh.partitioner.isOwner(subscriber.SubscriptionID) {
+						stopDist(subscriber.SubscriptionID)
+						continue
+					}
+					if renewCh, exist := renewMaps[subscriber.SubscriptionID]; exist {
+						blog.Infof("renew subscribers process %d", subscriber.SubscriptionID)
+						renewCh <- subscriber
+						continue
+					}
+					blog.Infof("starting subscribers process %d", subscriber.SubscriptionID)
+					startDist(subscriber)
+				case "delete":
+					blog.Infof("subscriber has been deleted, now stopping subscribe process, subscriberID: %d", subscriber.SubscriptionID)
+					delete(subscribers, subscriber.SubscriptionID)
+					dh.filters.remove(subscriber.SubscriptionID)
+					stopDist(subscriber.SubscriptionID)
 				}
 			}
 		}
@@ -145,12 +172,7 @@ func (dh *DistHandler) distToSubscribe(param metadata.Subscription, chNew chan m
 	for {
 		select {
 		case nsub := <-chNew:
-			if nsub.GetCacheKey() != sub.GetCacheKey() {
-				sub = nsub
-				blog.Infof("refreshed subscriber %v", sub.GetCacheKey())
-			} else {
-				blog.Infof("refresh ignore, subscriber cache key not change\nold:%s\nnew:%s ", sub.GetCacheKey(), nsub.GetCacheKey())
-			}
+			refreshSubscriber(&sub, nsub)
 		case <-ticker.C:
 			filter := map[string]interface{}{
 				common.BKSubscriptionIDField: sub.SubscriptionID,
@@ -167,23 +189,49 @@ func (dh *DistHandler) distToSubscribe(param metadata.Subscription, chNew chan m
 		case <-done:
 			return
 		default:
+			if !sub.IsActive() {
+				dh.trimInactiveQueue(&sub)
+				time.Sleep(inactiveCheckInterval)
+				continue
+			}
+			if sub.IsBatchMode() {
+				dists := dh.popDistBatch(&sub)
+				if len(dists) == 0 {
+					continue
+				}
+				if err = dh.handleDistBatch(&sub, dists, chNew, done); err != nil {
+					blog.Errorf("error handle dist batch of subscriber %d: %v", sub.SubscriptionID, err)
+				}
+				continue
+			}
 			dist := dh.popDistInst(sub.SubscriptionID)
 			if dist == nil {
 				continue
 			}
-			if err = dh.handleDist(&sub, dist); err != nil {
+			if err = dh.handleDist(&sub, dist, chNew, done); err != nil {
 				blog.Errorf("error handle dist: %v, %v", err, dist)
 			}
 		}
 	}
 }
 
-func (dh *DistHandler) handleDist(sub *metadata.Subscription, dist *metadata.DistInstCtx) (err error) {
+// refreshSubscriber replaces the subscriber with the renewed one if it is changed
+func refreshSubscriber(sub *metadata.Subscription, nsub metadata.Subscription) {
+	if nsub.GetCacheKey() != sub.GetCacheKey() || nsub.GetSecretDigest() != sub.GetSecretDigest() {
+		*sub = nsub
+		blog.Infof("refreshed subscriber %v", sub.GetCacheKey())
+	} else {
+		blog.Infof("refresh ignore, subscriber cache key not change\nold:%s\nnew:%s ", sub.GetCacheKey(), nsub.GetCacheKey())
+	}
+}
+
+func (dh *DistHandler) handleDist(sub *metadata.Subscription, dist *metadata.DistInstCtx,
+	chNew chan metadata.Subscription, done chan struct{}) (err error) {
 	blog.Infof("handling dist %s", dist.Raw)
 	distID := fmt.Sprint(dist.DstbID - 1)
 	subscriberID := fmt.Sprint(dist.SubscriptionID)
 	runningKey := types.EventCacheDistRunningPrefix + subscriberID + "_" + distID
-	if err = saveRunning(dh.cache, runningKey, timeout+sub.GetTimeout()); err != nil {
+	if err = saveRunning(dh.cache, runningKey, timeout+maxDeliverDuration(sub)); err != nil {
 		if ErrProcessExists == err {
 			blog.Infof("process exist, continue")
 			return nil
@@ -191,7 +239,28 @@ func (dh *DistHandler) handleDist(sub *metadata.Subscription, dist *metadata.Dis
 		return err
 	}
 
-	previousID := fmt.Sprint(dist.DstbID - 1)
+	if err = dh.waitPreviousDist(sub, subscriberID, dist.DstbID); err != nil {
+		return err
+	}
+
+	defer func() {
+		if err = dh.saveDistDone(dist); err != nil {
+			return
+		}
+		blog.Infof("done event dist : %v", dist.DstbID)
+	}()
+
+	if err = dh.sendCallbackWithRetry(sub, buildPayload(sub, dist), chNew, done, dist); err != nil {
+		blog.Errorf("send callback error: %v", err)
+		return
+	}
+
+	return
+}
+
+// waitPreviousDist wait until the dist before dstbID is done, so that the dists are sent in order
+func (dh *DistHandler) waitPreviousDist(sub *metadata.Subscription, subscriberID string, dstbID int64) error {
+	previousID := fmt.Sprint(dstbID - 1)
 	previousRunningKey := types.EventCacheDistRunningPrefix + subscriberID + "_" + previousID
 	done, err := checkFromDone(dh.cache, types.EventCacheDistDonePrefix+subscriberID, previousID)
 	if err != nil {
@@ -222,20 +291,7 @@ func (dh *DistHandler) handleDist(sub *metadata.Subscription, dist *metadata.Dis
 			}
 		}
 	}
-
-	defer func() {
-		if err = dh.saveDistDone(dist); err != nil {
-			return
-		}
-		blog.Infof("done event dist : %v", dist.DstbID)
-	}()
-
-	if err = dh.SendCallback(sub, dist.Raw); err != nil {
-		blog.Errorf("send callback error: %v", err)
-		return
-	}
-
-	return
+	return nil
 }
 
 func (dh *DistHandler) popDistInst(subID int64) *metadata.DistInstCtx {
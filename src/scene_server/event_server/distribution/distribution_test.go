@@ -0,0 +1,73 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package distribution
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"configcenter/src/common/metadata"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRefreshSubscriber(t *testing.T) {
+	base := metadata.Subscription{SubscriptionID: 1, CallbackURL: "http://127.0.0.1/callback", Secret: "encrypted"}
+
+	testCases := []struct {
+		name    string
+		change  func(sub *metadata.Subscription)
+		refresh bool
+	}{
+		{
+			name:    "not changed",
+			change:  func(sub *metadata.Subscription) {},
+			refresh: false,
+		},
+		{
+			name:    "callback url",
+			change:  func(sub *metadata.Subscription) { sub.CallbackURL = "http://127.0.0.2/callback" },
+			refresh: true,
+		},
+		{
+			name:    "secret",
+			change:  func(sub *metadata.Subscription) { sub.Secret = "rotated" },
+			refresh: true,
+		},
+		{
+			name: "previous secret",
+			change: func(sub *metadata.Subscription) {
+				sub.PreviousSecret = "encrypted"
+				sub.PreviousSecretExpireTime = metadata.Time{Time: time.Now()}
+			},
+			refresh: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sub := base
+			nsub := base
+			tc.change(&nsub)
+			refreshSubscriber(&sub, nsub)
+			if tc.refresh {
+				require.Equal(t, nsub, sub)
+			} else {
+				require.Equal(t, base, sub)
+			}
+			// the cache key is logged, it never has the secrets
+			require.False(t, strings.Contains(nsub.GetCacheKey(), "encrypted"), nsub.GetCacheKey())
+			require.False(t, strings.Contains(nsub.GetCacheKey(), "rotated"), nsub.GetCacheKey())
+		})
+	}
+}
@@ -0,0 +1,114 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package distribution
+
+import (
+	"strconv"
+	"sync"
+
+	"configcenter/src/common/blog"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/querybuilder"
+	"configcenter/src/scene_server/event_server/identifier"
+)
+
+// subscriberFilters holds the parsed filters of all the subscribers, so that the filters are parsed once when the
+// subscribers are refreshed rather than for every event. a subscriber without a valid filter is not in it.
+type subscriberFilters struct {
+	sync.RWMutex
+	rules map[int64]querybuilder.Rule
+	// loaded is closed after the filters of the persisted subscribers are loaded, the events are not handled before
+	// it, or they would be sent to the subscribers that have filters
+	loaded     chan struct{}
+	loadedOnce sync.Once
+}
+
+func newSubscriberFilters() *subscriberFilters {
+	return &subscriberFilters{rules: map[int64]querybuilder.Rule{}, loaded: make(chan struct{})}
+}
+
+// refresh parses and saves the filter of the subscriber
+func (f *subscriberFilters) refresh(sub metadata.Subscription) {
+	rule, errKey, err := sub.ParseFilter()
+	if err != nil {
+		blog.Errorf("parse filter of subscriber %d failed, key: %s, err: %v, filter: %v", sub.SubscriptionID, errKey, err, sub.Filter)
+	}
+
+	f.Lock()
+	defer f.Unlock()
+	if err != nil || rule == nil {
+		delete(f.rules, sub.SubscriptionID)
+		return
+	}
+	f.rules[sub.SubscriptionID] = rule
+}
+
+// remove removes the filter of the deleted subscriber
+func (f *subscriberFilters) remove(subscriptionID int64) {
+	f.Lock()
+	defer f.Unlock()
+	delete(f.rules, subscriptionID)
+}
+
+// setLoaded marks the filters of the persisted subscribers loaded
+func (f *subscriberFilters) setLoaded() {
+	f.loadedOnce.Do(func() { close(f.loaded) })
+}
+
+func (f *subscriberFilters) get(subscriptionID int64) querybuilder.Rule {
+	f.RLock()
+	defer f.RUnlock()
+	return f.rules[subscriptionID]
+}
+
+// matchSubscriberFilter check whether the event should be sent to the subscriber. the event
+// is sent when the subscriber has no filter, or the filter could not be checked, so that no
+// event is lost because of a broken filter.
+func (eh *EventHandler) matchSubscriberFilter(subscriber string, event *metadata.EventInst) bool {
+	subscriptionID, err := strconv.ParseInt(subscriber, 10, 64)
+	if err != nil {
+		return true
+	}
+	rule := eh.filters.get(subscriptionID)
+	if rule == nil {
+		return true
+	}
+
+	matched, err := matchEventFilter(rule, event)
+	if err != nil {
+		blog.Errorf("match filter of subscriber %s failed, err: %v", subscriber, err)
+		return true
+	}
+	return matched
+}
+
+// matchEventFilter check the rule against every data of the event, returns true if any of them matches
+func matchEventFilter(rule querybuilder.Rule, event *metadata.EventInst) (bool, error) {
+	for _, data := range event.Data {
+		curData, _ := data.CurData.(map[string]interface{})
+		preData, _ := data.PreData.(map[string]interface{})
+		doc := map[string]interface{}{
+			metadata.EventFilterCurDataField:       curData,
+			metadata.EventFilterPreDataField:       preData,
+			metadata.EventFilterChangedFieldsField: identifier.ChangedFields(curData, preData),
+		}
+		matched, err := rule.Match(doc)
+		if err != nil {
+			return false, err
+		}
+		if matched {
+			return true, nil
+		}
+	}
+	return false, nil
+}
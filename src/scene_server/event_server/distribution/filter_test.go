@@ -0,0 +1,66 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package distribution
+
+import (
+	"testing"
+
+	"configcenter/src/common/metadata"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchSubscriberFilter(t *testing.T) {
+	eh := newTestEventHandler(t)
+	eh.filters.refresh(metadata.Subscription{
+		SubscriptionID: 1,
+		Filter:         map[string]interface{}{"operator": "equal", "field": "cur_data.bk_host_name", "value": "web"},
+	})
+	// the invalid filter is skipped, all the events are sent
+	eh.filters.refresh(metadata.Subscription{
+		SubscriptionID: 2,
+		Filter:         map[string]interface{}{"operator": "unknown", "field": "cur_data.bk_host_name", "value": "web"},
+	})
+	eh.filters.refresh(metadata.Subscription{SubscriptionID: 3})
+
+	event := func(hostName string) *metadata.EventInst {
+		return &metadata.EventInst{Data: []metadata.EventData{
+			{CurData: map[string]interface{}{"bk_host_name": hostName}},
+		}}
+	}
+	testCases := []struct {
+		subscriber string
+		hostName   string
+		expect     bool
+	}{
+		{"1", "web", true},
+		{"1", "db", false},
+		{"2", "db", true},
+		{"3", "db", true},
+		{"4", "db", true},
+	}
+	for _, c := range testCases {
+		require.Equal(t, c.expect, eh.matchSubscriberFilter(c.subscriber, event(c.hostName)), "%+v", c)
+	}
+
+	// the filter is refreshed with the subscriber
+	eh.filters.refresh(metadata.Subscription{
+		SubscriptionID: 1,
+		Filter:         map[string]interface{}{"operator": "equal", "field": "cur_data.bk_host_name", "value": "db"},
+	})
+	require.False(t, eh.matchSubscriberFilter("1", event("web")))
+	require.True(t, eh.matchSubscriberFilter("1", event("db")))
+
+	eh.filters.remove(1)
+	require.True(t, eh.matchSubscriberFilter("1", event("web")))
+}
@@ -23,8 +23,7 @@ import (
 	"configcenter/src/common/blog"
 	"configcenter/src/common/metadata"
 	"configcenter/src/scene_server/event_server/types"
-
-	"gopkg.in/redis.v5"
+	"configcenter/src/storage/dal/redis"
 )
 
 var (
@@ -50,6 +49,7 @@ func (eh *EventHandler) Run() (err error) {
 		}
 	}()
 
+	<-eh.filters.loaded
 	blog.Info("event inst handle process started")
 	for {
 		event := eh.popEvent()
@@ -114,6 +114,7 @@ func (eh *EventHandler) handleEvent(event *metadata.EventInstCtx) (err error) {
 	}()
 
 	originDists := eh.GetDistInst(&event.EventInst)
+	eh.saveWatchRecord(event, originDists)
 
 	for _, originDist := range originDists {
 		subscribers := eh.findEventTypeSubscribers(originDist.GetType(), event.OwnerID)
@@ -124,6 +125,10 @@ func (eh *EventHandler) handleEvent(event *metadata.EventInstCtx) (err error) {
 
 		for _, subscriber := range subscribers {
 			var dstbID, subscribeID int64
+			if !eh.matchSubscriberFilter(subscriber, &originDist.EventInst) {
+				blog.V(4).Infof("event %d doesn't match the filter of subscriber %s, skip", event.ID, subscriber)
+				continue
+			}
 			distInst := originDist
 			dstbID, err = eh.nextDistID(subscriber)
 			if err != nil {
@@ -202,7 +207,7 @@ func (eh *EventHandler) SaveEventDone(event *metadata.EventInstCtx) (err error)
 	return
 }
 
-func waitPreviousDone(cache *redis.Client, key string, id string, timeout time.Duration) (err error) {
+func waitPreviousDone(cache redis.Client, key string, id string, timeout time.Duration) (err error) {
 	var done bool
 	timer := time.NewTimer(timeout)
 	for !done {
@@ -221,18 +226,18 @@ func waitPreviousDone(cache *redis.Client, key string, id string, timeout time.D
 	return
 }
 
-func checkFromDone(cache *redis.Client, key string, id string) (bool, error) {
+func checkFromDone(cache redis.Client, key string, id string) (bool, error) {
 	if id == "0" {
 		return true, nil
 	}
 	return cache.HExists(key, fmt.Sprint(id)).Result()
 }
 
-func checkFromRunning(cache *redis.Client, key string) (bool, error) {
+func checkFromRunning(cache redis.Client, key string) (bool, error) {
 	return cache.Exists(key).Result()
 }
 
-func saveRunning(cache *redis.Client, key string, timeout time.Duration) (err error) {
+func saveRunning(cache redis.Client, key string, timeout time.Duration) (err error) {
 	set, err := cache.SetNX(key, time.Now().UTC().Format(time.RFC3339), timeout).Result()
 	if !set {
 		return ErrProcessExists
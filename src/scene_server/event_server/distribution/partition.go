@@ -0,0 +1,128 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package distribution
+
+import (
+	"hash/crc32"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"configcenter/src/apimachinery/discovery"
+	"configcenter/src/common/blog"
+)
+
+// partitionVirtualNodes is the number of virtual nodes of every event server on the hash ring,
+// so that the subscribers are spread evenly
+const partitionVirtualNodes = 100
+
+// partitionRefreshInterval is the interval to check whether the event servers changed
+const partitionRefreshInterval = 10 * time.Second
+
+// partitioner assigns the subscribers to the event servers registered in zookeeper with consistent
+// hashing, so that every subscriber is distributed by only one event server, and only a few of the
+// subscribers move when an event server joins or leaves.
+type partitioner struct {
+	// self is the address of the current event server
+	self      string
+	discovery discovery.Interface
+
+	lock    sync.RWMutex
+	servers []string
+	ring    []uint32
+	nodes   map[uint32]string
+}
+
+func newPartitioner(self string, disc discovery.Interface) *partitioner {
+	p := &partitioner{self: self, discovery: disc}
+	p.refresh()
+	return p
+}
+
+// refresh rebuilds the hash ring with the event servers currently registered, returns true if they changed.
+// the previous ring is kept if the event servers can't be discovered, the other event servers may still own
+// their subscribers, so that they are not distributed twice.
+func (p *partitioner) refresh() bool {
+	servers := make([]string, 0)
+	if p.discovery != nil {
+		var err error
+		servers, err = p.discovery.GetServers()
+		if err != nil {
+			blog.Errorf("get event servers failed, keep the current event servers %v, err: %v", p.getServers(), err)
+			return false
+		}
+	}
+
+	// the current event server may not be discovered yet while it's registering
+	servers = append(servers, p.self)
+	sort.Strings(servers)
+	uniqServers := make([]string, 0)
+	for index, server := range servers {
+		if server == "" || (index > 0 && server == servers[index-1]) {
+			continue
+		}
+		uniqServers = append(uniqServers, server)
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if strings.Join(uniqServers, ",") == strings.Join(p.servers, ",") {
+		return false
+	}
+
+	ring := make([]uint32, 0, len(uniqServers)*partitionVirtualNodes)
+	nodes := make(map[uint32]string, len(uniqServers)*partitionVirtualNodes)
+	for _, server := range uniqServers {
+		for i := 0; i < partitionVirtualNodes; i++ {
+			hash := crc32.ChecksumIEEE([]byte(server + "#" + strconv.Itoa(i)))
+			if _, exists := nodes[hash]; exists {
+				continue
+			}
+			ring = append(ring, hash)
+			nodes[hash] = server
+		}
+	}
+	sort.Slice(ring, func(i, j int) bool { return ring[i] < ring[j] })
+
+	blog.Infof("event servers changed from %v to %v, rebalance the subscribers", p.servers, uniqServers)
+	p.servers = uniqServers
+	p.ring = ring
+	p.nodes = nodes
+	return true
+}
+
+// getServers returns the event servers on the hash ring
+func (p *partitioner) getServers() []string {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+	return p.servers
+}
+
+// isOwner returns true if the subscriber is assigned to the current event server, no subscriber is owned
+// before the event servers are discovered.
+func (p *partitioner) isOwner(subscriptionID int64) bool {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+	if len(p.ring) == 0 {
+		return false
+	}
+
+	hash := crc32.ChecksumIEEE([]byte(strconv.FormatInt(subscriptionID, 10)))
+	index := sort.Search(len(p.ring), func(i int) bool { return p.ring[i] >= hash })
+	if index == len(p.ring) {
+		index = 0
+	}
+	return p.nodes[p.ring[index]] == p.self
+}
@@ -0,0 +1,117 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package distribution
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDiscovery returns the servers or the error set by the test
+type fakeDiscovery struct {
+	servers []string
+	err     error
+}
+
+func (d *fakeDiscovery) GetServers() ([]string, error) {
+	return d.servers, d.err
+}
+
+// ownedSubscribers returns the subscribers in [1, count] owned by each partitioner
+func ownedSubscribers(count int64, partitioners ...*partitioner) [][]int64 {
+	owned := make([][]int64, len(partitioners))
+	for id := int64(1); id <= count; id++ {
+		for index, p := range partitioners {
+			if p.isOwner(id) {
+				owned[index] = append(owned[index], id)
+			}
+		}
+	}
+	return owned
+}
+
+func TestPartitionerIsOwner(t *testing.T) {
+	servers := []string{"127.0.0.1:60009", "127.0.0.2:60009", "127.0.0.3:60009"}
+	partitioners := make([]*partitioner, len(servers))
+	for index, server := range servers {
+		partitioners[index] = newPartitioner(server, &fakeDiscovery{servers: servers})
+	}
+
+	// every subscriber is owned by exactly one event server, and all of them get some
+	owned := ownedSubscribers(300, partitioners...)
+	total := 0
+	for index := range partitioners {
+		require.NotEmpty(t, owned[index], servers[index])
+		total += len(owned[index])
+	}
+	require.Equal(t, 300, total)
+
+	// a server not discovered yet still owns its part
+	single := newPartitioner(servers[0], &fakeDiscovery{})
+	require.Len(t, ownedSubscribers(300, single)[0], 300)
+}
+
+func TestPartitionerRefresh(t *testing.T) {
+	servers := []string{"127.0.0.1:60009", "127.0.0.2:60009"}
+	disc := &fakeDiscovery{servers: servers}
+	p := newPartitioner(servers[0], disc)
+	before := ownedSubscribers(200, p)[0]
+
+	testCases := []struct {
+		name    string
+		servers []string
+		err     error
+		changed bool
+	}{
+		{
+			name:    "not changed",
+			servers: []string{servers[1], servers[0], servers[1]},
+			changed: false,
+		},
+		{
+			// the ring is kept, so the subscribers of the other event server are not taken over
+			name:    "discovery failed",
+			err:     errors.New("zk unavailable"),
+			changed: false,
+		},
+		{
+			name:    "server joined",
+			servers: append([]string{"127.0.0.3:60009"}, servers...),
+			changed: true,
+		},
+	}
+	for _, tc := range testCases {
+		disc.servers, disc.err = tc.servers, tc.err
+		require.Equal(t, tc.changed, p.refresh(), tc.name)
+		if !tc.changed {
+			require.Equal(t, servers, p.getServers(), tc.name)
+			require.Equal(t, before, ownedSubscribers(200, p)[0], tc.name)
+		}
+	}
+
+	// the subscribers moved to the new event server are taken from the others only
+	after := ownedSubscribers(200, p)[0]
+	require.True(t, len(after) < len(before))
+	require.Subset(t, before, after)
+}
+
+func TestPartitionerDiscoveryFailedOnStart(t *testing.T) {
+	p := newPartitioner("127.0.0.1:60009", &fakeDiscovery{err: errors.New("zk unavailable")})
+	require.Empty(t, ownedSubscribers(100, p)[0])
+
+	p.discovery = &fakeDiscovery{servers: []string{"127.0.0.1:60009"}}
+	require.True(t, p.refresh())
+	require.Len(t, ownedSubscribers(100, p)[0], 100)
+}
@@ -0,0 +1,97 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package distribution
+
+import (
+	"encoding/json"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/metadata"
+	"configcenter/src/scene_server/event_server/identifier"
+)
+
+// buildPayload returns the payload of the dist shaped by the payload option of the subscriber,
+// the raw dist is returned as is for the v1 payloads, and also when it's failed to be reshaped.
+func buildPayload(sub *metadata.Subscription, dist *metadata.DistInstCtx) string {
+	if !sub.IsCustomPayload() {
+		return dist.Raw
+	}
+
+	payload := metadata.DistInstPayload{
+		DistInst:       dist.DistInst,
+		PayloadVersion: metadata.EventPayloadVersionV2,
+		Data:           make([]metadata.EventPayloadData, 0, len(dist.Data)),
+	}
+	option := sub.PayloadOption
+	for _, data := range dist.Data {
+		curData, _ := data.CurData.(map[string]interface{})
+		preData, _ := data.PreData.(map[string]interface{})
+		if option.Mode == metadata.EventPayloadModeDiff {
+			payload.Data = append(payload.Data, diffEventData(dist.ObjType, curData, preData, option.Fields))
+			continue
+		}
+		payload.Data = append(payload.Data, metadata.EventPayloadData{
+			CurData: projectFields(curData, option.Fields),
+			PreData: projectFields(preData, option.Fields),
+		})
+	}
+
+	out, err := json.Marshal(payload)
+	if err != nil {
+		blog.Errorf("build payload of dist %d for subscriber %d failed, send the raw dist instead, err: %v",
+			dist.DstbID, sub.SubscriptionID, err)
+		return dist.Raw
+	}
+	return string(out)
+}
+
+// diffEventData returns the changed fields of the data with their old and new values, only the fields
+// in the projection list are returned if it's set.
+func diffEventData(objType string, curData, preData map[string]interface{}, fields []string) metadata.EventPayloadData {
+	data := metadata.EventPayloadData{Changed: make(map[string]metadata.EventPayloadChange)}
+
+	instIDField := common.GetInstIDField(objType)
+	if instID, exist := curData[instIDField]; exist {
+		data.InstID = instID
+	} else if instID, exist := preData[instIDField]; exist {
+		data.InstID = instID
+	}
+
+	projection := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		projection[field] = true
+	}
+	for _, field := range identifier.ChangedFields(curData, preData) {
+		if len(projection) > 0 && !projection[field] {
+			continue
+		}
+		data.Changed[field] = metadata.EventPayloadChange{Old: preData[field], New: curData[field]}
+	}
+	return data
+}
+
+// projectFields returns the fields of the data in the projection list, all the fields are returned if it's empty
+func projectFields(data map[string]interface{}, fields []string) map[string]interface{} {
+	if data == nil || len(fields) == 0 {
+		return data
+	}
+
+	projected := make(map[string]interface{}, len(fields))
+	for _, field := range fields {
+		if value, exist := data[field]; exist {
+			projected[field] = value
+		}
+	}
+	return projected
+}
@@ -0,0 +1,136 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package distribution
+
+import (
+	"encoding/json"
+	"testing"
+
+	"configcenter/src/common"
+	"configcenter/src/common/metadata"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffEventData(t *testing.T) {
+	curData := map[string]interface{}{common.BKHostIDField: 1, "bk_host_name": "web", "bk_cpu": 8}
+	preData := map[string]interface{}{common.BKHostIDField: 1, "bk_host_name": "db", "bk_mem": 16}
+	testCases := []struct {
+		objType string
+		curData map[string]interface{}
+		preData map[string]interface{}
+		fields  []string
+		expect  metadata.EventPayloadData
+	}{
+		{
+			objType: common.BKInnerObjIDHost,
+			curData: curData,
+			preData: preData,
+			expect: metadata.EventPayloadData{InstID: 1, Changed: map[string]metadata.EventPayloadChange{
+				"bk_host_name": {Old: "db", New: "web"},
+				"bk_cpu":       {Old: nil, New: 8},
+				"bk_mem":       {Old: 16, New: nil},
+			}},
+		},
+		// only the changed fields in the projection list are returned
+		{
+			objType: common.BKInnerObjIDHost,
+			curData: curData,
+			preData: preData,
+			fields:  []string{"bk_host_name", common.BKHostIDField},
+			expect: metadata.EventPayloadData{InstID: 1, Changed: map[string]metadata.EventPayloadChange{
+				"bk_host_name": {Old: "db", New: "web"},
+			}},
+		},
+		// the instance id is taken from the pre data of the deleted instance
+		{
+			objType: common.BKInnerObjIDHost,
+			preData: preData,
+			fields:  []string{"bk_host_name"},
+			expect: metadata.EventPayloadData{InstID: 1, Changed: map[string]metadata.EventPayloadChange{
+				"bk_host_name": {Old: "db", New: nil},
+			}},
+		},
+		{
+			objType: common.BKInnerObjIDHost,
+			curData: map[string]interface{}{"bk_host_name": "web"},
+			preData: map[string]interface{}{"bk_host_name": "web"},
+			expect:  metadata.EventPayloadData{Changed: map[string]metadata.EventPayloadChange{}},
+		},
+	}
+	for index, c := range testCases {
+		require.Equal(t, c.expect, diffEventData(c.objType, c.curData, c.preData, c.fields), "case %d", index)
+	}
+}
+
+func TestBuildPayload(t *testing.T) {
+	dist := metadata.DistInst{
+		EventInst: metadata.EventInst{
+			ID:        1,
+			EventType: metadata.EventTypeInstData,
+			ObjType:   common.BKInnerObjIDHost,
+			Action:    metadata.EventActionUpdate,
+			Data: []metadata.EventData{{
+				CurData: map[string]interface{}{common.BKHostIDField: 1, "bk_host_name": "web", "bk_cpu": 8},
+				PreData: map[string]interface{}{common.BKHostIDField: 1, "bk_host_name": "db", "bk_cpu": 8},
+			}},
+		},
+		DstbID:         1,
+		SubscriptionID: testSubscriptionID,
+	}
+	raw, err := json.Marshal(dist)
+	require.NoError(t, err)
+	distCtx := &metadata.DistInstCtx{DistInst: dist, Raw: string(raw)}
+
+	testCases := []struct {
+		option *metadata.PayloadOption
+		expect []metadata.EventPayloadData
+	}{
+		{option: nil},
+		{option: &metadata.PayloadOption{Version: metadata.EventPayloadVersionV1}},
+		{
+			option: &metadata.PayloadOption{Version: metadata.EventPayloadVersionV2},
+			expect: []metadata.EventPayloadData{{
+				CurData: map[string]interface{}{common.BKHostIDField: float64(1), "bk_host_name": "web", "bk_cpu": float64(8)},
+				PreData: map[string]interface{}{common.BKHostIDField: float64(1), "bk_host_name": "db", "bk_cpu": float64(8)},
+			}},
+		},
+		{
+			option: &metadata.PayloadOption{Version: metadata.EventPayloadVersionV2, Fields: []string{"bk_host_name"}},
+			expect: []metadata.EventPayloadData{{
+				CurData: map[string]interface{}{"bk_host_name": "web"},
+				PreData: map[string]interface{}{"bk_host_name": "db"},
+			}},
+		},
+		{
+			option: &metadata.PayloadOption{Version: metadata.EventPayloadVersionV2, Mode: metadata.EventPayloadModeDiff},
+			expect: []metadata.EventPayloadData{{
+				InstID:  float64(1),
+				Changed: map[string]metadata.EventPayloadChange{"bk_host_name": {Old: "db", New: "web"}},
+			}},
+		},
+	}
+	for index, c := range testCases {
+		payload := buildPayload(&metadata.Subscription{PayloadOption: c.option}, distCtx)
+		if c.expect == nil {
+			require.Equal(t, distCtx.Raw, payload, "case %d", index)
+			continue
+		}
+
+		decoded := metadata.DistInstPayload{}
+		require.NoError(t, json.Unmarshal([]byte(payload), &decoded), "case %d", index)
+		require.Equal(t, metadata.EventPayloadVersionV2, decoded.PayloadVersion, "case %d", index)
+		require.Equal(t, dist.DstbID, decoded.DstbID, "case %d", index)
+		require.Equal(t, c.expect, decoded.Data, "case %d", index)
+	}
+}
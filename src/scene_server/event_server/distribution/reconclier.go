@@ -14,6 +14,7 @@ package distribution
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"reflect"
@@ -26,6 +27,7 @@ import (
 	"configcenter/src/common/util"
 	"configcenter/src/scene_server/event_server/types"
 	"configcenter/src/storage/dal"
+	ccredis "configcenter/src/storage/dal/redis"
 
 	"github.com/tidwall/gjson"
 	"gopkg.in/redis.v5"
@@ -33,16 +35,18 @@ import (
 
 type reconciler struct {
 	db                   dal.RDB
-	cache                *redis.Client
+	cache                ccredis.Client
 	cached               map[string][]string
 	persisted            map[string][]string
 	cachedSubscribers    []string
 	persistedSubscribers []string
+	cachedFilters        map[string]string
+	persistedFilters     map[string]string
 	processID            string
 	ctx                  context.Context
 }
 
-func newReconciler(ctx context.Context, cache *redis.Client, db dal.RDB) *reconciler {
+func newReconciler(ctx context.Context, cache ccredis.Client, db dal.RDB) *reconciler {
 	return &reconciler{
 		ctx:                  ctx,
 		db:                   db,
@@ -50,6 +54,8 @@ func newReconciler(ctx context.Context, cache *redis.Client, db dal.RDB) *reconc
 		cached:               map[string][]string{},
 		persisted:            map[string][]string{},
 		persistedSubscribers: []string{},
+		cachedFilters:        map[string]string{},
+		persistedFilters:     map[string]string{},
 	}
 }
 
@@ -65,16 +71,22 @@ func (r *reconciler) loadAll() {
 
 func (r *reconciler) loadAllCached() {
 	r.cached = map[string][]string{}
-	for _, formKey := range r.cache.Keys(types.EventCacheSubscribeFormKey + "*").Val() {
+	formKeys, err := ccredis.Keys(r.cache, types.EventCacheSubscribeFormKey+"*")
+	if err != nil {
+		blog.Errorf("get subscribe form keys failed, err: %v", err)
+	}
+	for _, formKey := range formKeys {
 		if formKey != "" && formKey != nilStr && formKey != "redis" {
 			r.cached[strings.TrimPrefix(formKey, types.EventCacheSubscribeFormKey)] = r.cache.SMembers(formKey).Val()
 		}
 	}
+	r.cachedFilters = r.cache.HGetAll(types.EventCacheSubscribeFilterKey).Val()
 }
 
 func (r *reconciler) loadAllPersisted() {
 	r.persisted = map[string][]string{}
 	r.persistedSubscribers = []string{}
+	r.persistedFilters = map[string]string{}
 	subscriptions := make([]metadata.Subscription, 0)
 	if err := r.db.Table(common.BKTableNameSubscription).Find(nil).All(r.ctx, &subscriptions); err != nil {
 		blog.Errorf("reconcile err: %v", err)
@@ -82,11 +94,25 @@ func (r *reconciler) loadAllPersisted() {
 	blog.Infof("loaded %v subscriptions from persistent", len(subscriptions))
 	for _, sub := range subscriptions {
 		eventNames := strings.Split(sub.SubscriptionForm, ",")
-		r.persistedSubscribers = append(r.persistedSubscribers, sub.GetCacheKey())
+		// the secrets are not in the cache key, so the whole subscription is kept for the distribution
+		raw, err := json.Marshal(sub)
+		if err != nil {
+			blog.Errorf("reconcile err: marshal subscription %d failed: %v", sub.SubscriptionID, err)
+			continue
+		}
+		r.persistedSubscribers = append(r.persistedSubscribers, string(raw))
 		for _, eventName := range eventNames {
 			eventName = sub.OwnerID + ":" + eventName
 			r.persisted[eventName] = append(r.persisted[eventName], fmt.Sprint(sub.SubscriptionID))
 		}
+		if len(sub.Filter) > 0 {
+			filter, err := json.Marshal(sub.Filter)
+			if err != nil {
+				blog.Errorf("reconcile err: marshal filter of subscription %d failed: %v", sub.SubscriptionID, err)
+				continue
+			}
+			r.persistedFilters[fmt.Sprint(sub.SubscriptionID)] = string(filter)
+		}
 	}
 }
 
@@ -113,9 +139,22 @@ func (r *reconciler) reconcile() {
 		r.cache.Del(types.EventCacheSubscribeFormKey + k)
 	}
 
+	for subID, filter := range r.persistedFilters {
+		if r.cachedFilters[subID] != filter {
+			if err := r.cache.HSet(types.EventCacheSubscribeFilterKey, subID, filter).Err(); err != nil {
+				blog.Errorf("reconcile err: %v", err)
+			}
+		}
+		delete(r.cachedFilters, subID)
+	}
+	for subID := range r.cachedFilters {
+		if err := r.cache.HDel(types.EventCacheSubscribeFilterKey, subID).Err(); err != nil {
+			blog.Errorf("reconcile err: %v", err)
+		}
+	}
 }
 
-func SubscribeChannel(redisCli *redis.Client) (err error) {
+func SubscribeChannel(redisCli ccredis.Client) (err error) {
 	subChan, err := redisCli.PSubscribe(types.EventCacheProcessChannel)
 	if err != nil {
 		return err
@@ -150,15 +189,15 @@ func SubscribeChannel(redisCli *redis.Client) (err error) {
 	}
 }
 
-func cleanExpiredEvents(redisCli *redis.Client) {
+func cleanExpiredEvents(redisCli ccredis.Client) {
 	var err error
 	timeout := time.Hour * 1
 	tick := util.NewTicker(timeout)
 	tick.Tick()
 	for range tick.C {
 		blog.Infof("starting clean expired events")
-		var keys = make([]string, 0)
-		if err = redisCli.Keys(types.EventCacheDistDonePrefix + "*").ScanSlice(&keys); err != nil {
+		var keys []string
+		if keys, err = ccredis.Keys(redisCli, types.EventCacheDistDonePrefix+"*"); err != nil {
 			blog.Errorf("fetch expired event keys failed: %v", err)
 		}
 		keys = append(keys, types.EventCacheEventDoneKey)
@@ -0,0 +1,177 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package distribution
+
+import (
+	"math/rand"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/metadata"
+
+	"github.com/rs/xid"
+)
+
+// sendCallbackWithRetry send the payload of the dists to the subscriber, and retry according to the subscriber's
+// retry policy if failed. the dists are saved as dead letters when all the attempts failed, or when the distribute
+// routine is stopped while waiting for the next attempt. the subscriber renewed during the wait is applied at once,
+// so that the routine never blocks the renew of the subscriber.
+func (dh *DistHandler) sendCallbackWithRetry(sub *metadata.Subscription, payload string, chNew chan metadata.Subscription,
+	done chan struct{}, dists ...*metadata.DistInstCtx) (err error) {
+	maxAttempts := sub.GetMaxAttempts()
+	// all the attempts share the same delivery id, so that the subscriber could drop the duplicated ones
+	deliveryID := xid.New().String()
+	distIDs := make([]int64, len(dists))
+	for index, dist := range dists {
+		distIDs[index] = dist.DstbID
+	}
+	attempt := int64(1)
+	for ; attempt <= maxAttempts; attempt++ {
+		start := time.Now()
+		var statusCode int
+		var respData []byte
+		statusCode, respData, err = dh.SendCallback(sub, deliveryID, payload)
+		dh.recordDelivery(&metadata.EventDeliveryLog{
+			SubscriptionID:       sub.SubscriptionID,
+			DeliveryID:           deliveryID,
+			Attempt:              attempt,
+			DistributionIDs:      distIDs,
+			CallbackURL:          sub.CallbackURL,
+			Request:              payload,
+			StatusCode:           statusCode,
+			Response:             string(respData),
+			Success:              err == nil,
+			DurationMilliseconds: time.Since(start).Nanoseconds() / int64(time.Millisecond),
+			CreateTime:           metadata.Now(),
+		}, err)
+		if err == nil {
+			dh.resetDeliveryFailures(sub)
+			return nil
+		}
+		if attempt == maxAttempts {
+			break
+		}
+		backoff := retryBackoff(sub.RetryPolicy, attempt)
+		blog.Warnf("send callback to subscriber %d failed at attempt %d/%d, retry after %v, err: %v",
+			sub.SubscriptionID, attempt, maxAttempts, backoff, err)
+		if !waitRetry(sub, backoff, chNew, done) {
+			blog.Warnf("distribute routine of subscriber %d is stopped, stop retrying at attempt %d/%d",
+				sub.SubscriptionID, attempt, maxAttempts)
+			break
+		}
+	}
+
+	// every dist is saved separately, so that they could be redelivered one by one
+	for _, dist := range dists {
+		if saveErr := dh.saveDeadLetter(sub, dist, attempt, err); saveErr != nil {
+			blog.Errorf("save dead letter of subscriber %d failed, err: %v, dist: %s", sub.SubscriptionID, saveErr, dist.Raw)
+		}
+	}
+	dh.increaseDeliveryFailures(sub)
+	return err
+}
+
+// waitRetry waits for the backoff before the next attempt, the renewed subscriber is applied during the wait.
+// it returns false if the distribute routine is stopped.
+func waitRetry(sub *metadata.Subscription, backoff time.Duration, chNew chan metadata.Subscription, done chan struct{}) bool {
+	timer := time.NewTimer(backoff)
+	defer timer.Stop()
+	for {
+		select {
+		case <-timer.C:
+			return true
+		case nsub := <-chNew:
+			refreshSubscriber(sub, nsub)
+		case <-done:
+			return false
+		}
+	}
+}
+
+// retryBackoff returns the interval before the next attempt, attempt is the number of attempts already made
+func retryBackoff(policy *metadata.RetryPolicy, attempt int64) time.Duration {
+	if policy == nil || policy.BackoffSeconds <= 0 {
+		return 0
+	}
+
+	backoff := time.Duration(policy.BackoffSeconds) * time.Second
+	maxBackoff := time.Duration(policy.MaxBackoffSeconds) * time.Second
+	for i := int64(1); i < attempt; i++ {
+		backoff *= 2
+		if maxBackoff > 0 && backoff >= maxBackoff {
+			break
+		}
+	}
+	if maxBackoff > 0 && backoff > maxBackoff {
+		backoff = maxBackoff
+	}
+
+	if policy.Jitter > 0 {
+		backoff -= time.Duration(rand.Float64() * policy.Jitter * float64(backoff))
+	}
+	return backoff
+}
+
+// maxDeliverDuration returns the longest time a dist may take to be sent, all the retries included
+func maxDeliverDuration(sub *metadata.Subscription) time.Duration {
+	perAttempt := sub.GetTimeout()
+	if sub.TimeOutSeconds == 0 {
+		perAttempt = timeout
+	}
+
+	maxAttempts := sub.GetMaxAttempts()
+	duration := perAttempt * time.Duration(maxAttempts)
+	if sub.RetryPolicy == nil {
+		return duration
+	}
+
+	// jitter only shortens the interval, so the longest interval is the one without jitter
+	policy := *sub.RetryPolicy
+	policy.Jitter = 0
+	for attempt := int64(1); attempt < maxAttempts; attempt++ {
+		duration += retryBackoff(&policy, attempt)
+	}
+	return duration
+}
+
+func (dh *DistHandler) saveDeadLetter(sub *metadata.Subscription, dist *metadata.DistInstCtx, attempts int64, sendErr error) error {
+	id, err := dh.db.NextSequence(dh.ctx, common.BKTableNameEventDeadLetter)
+	if err != nil {
+		return err
+	}
+
+	deadLetter := metadata.EventDeadLetter{
+		ID:             int64(id),
+		SubscriptionID: sub.SubscriptionID,
+		DstbID:         dist.DstbID,
+		EventID:        dist.ID,
+		EventType:      dist.EventType,
+		ObjType:        dist.ObjType,
+		Action:         dist.Action,
+		Payload:        dist.Raw,
+		Attempts:       attempts,
+		OwnerID:        sub.OwnerID,
+		CreateTime:     metadata.Now(),
+	}
+	if sendErr != nil {
+		deadLetter.LastError = sendErr.Error()
+	}
+
+	if err := dh.db.Table(common.BKTableNameEventDeadLetter).Insert(dh.ctx, deadLetter); err != nil {
+		return err
+	}
+	blog.Warnf("dist %d of subscriber %d failed after %d attempts, saved as dead letter %d",
+		dist.DstbID, sub.SubscriptionID, attempts, deadLetter.ID)
+	return nil
+}
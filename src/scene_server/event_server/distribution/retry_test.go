@@ -0,0 +1,124 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package distribution
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/metadata"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryBackoff(t *testing.T) {
+	testCases := []struct {
+		policy  *metadata.RetryPolicy
+		attempt int64
+		expect  time.Duration
+	}{
+		{nil, 1, 0},
+		{&metadata.RetryPolicy{MaxAttempts: 3}, 1, 0},
+		{&metadata.RetryPolicy{BackoffSeconds: 1}, 1, time.Second},
+		{&metadata.RetryPolicy{BackoffSeconds: 1}, 2, 2 * time.Second},
+		{&metadata.RetryPolicy{BackoffSeconds: 1}, 4, 8 * time.Second},
+		{&metadata.RetryPolicy{BackoffSeconds: 1, MaxBackoffSeconds: 3}, 2, 2 * time.Second},
+		{&metadata.RetryPolicy{BackoffSeconds: 1, MaxBackoffSeconds: 3}, 3, 3 * time.Second},
+		{&metadata.RetryPolicy{BackoffSeconds: 1, MaxBackoffSeconds: 3}, 100, 3 * time.Second},
+		{&metadata.RetryPolicy{BackoffSeconds: 5, MaxBackoffSeconds: 3}, 1, 3 * time.Second},
+	}
+	for _, c := range testCases {
+		require.Equal(t, c.expect, retryBackoff(c.policy, c.attempt), "%+v, attempt: %d", c.policy, c.attempt)
+	}
+
+	// the jitter shortens the backoff by at most the ratio
+	policy := &metadata.RetryPolicy{BackoffSeconds: 10, Jitter: 0.5}
+	for i := 0; i < 100; i++ {
+		backoff := retryBackoff(policy, 1)
+		require.True(t, backoff > 5*time.Second && backoff <= 10*time.Second, "backoff: %v", backoff)
+	}
+}
+
+func TestWaitRetry(t *testing.T) {
+	sub := &metadata.Subscription{SubscriptionID: testSubscriptionID, CallbackURL: "http://127.0.0.1/old"}
+	chNew := make(chan metadata.Subscription)
+	done := make(chan struct{})
+	require.True(t, waitRetry(sub, 10*time.Millisecond, chNew, done))
+
+	// the renewed subscriber is applied while waiting
+	go func() {
+		chNew <- metadata.Subscription{SubscriptionID: testSubscriptionID, CallbackURL: "http://127.0.0.1/new"}
+	}()
+	require.True(t, waitRetry(sub, 100*time.Millisecond, chNew, done))
+	require.Equal(t, "http://127.0.0.1/new", sub.CallbackURL)
+
+	close(done)
+	start := time.Now()
+	require.False(t, waitRetry(sub, time.Minute, chNew, done))
+	require.True(t, time.Since(start) < time.Second)
+}
+
+func TestSendCallbackWithRetry(t *testing.T) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	t.Cleanup(srv.Close)
+
+	dh := newTestDistHandler(t)
+	sub := &metadata.Subscription{
+		SubscriptionID: testSubscriptionID,
+		OwnerID:        "0",
+		CallbackURL:    srv.URL,
+		ConfirmMode:    metadata.ConfirmModeHTTPStatus,
+		ConfirmPattern: "200",
+		TimeOutSeconds: 10,
+		RetryPolicy:    &metadata.RetryPolicy{MaxAttempts: 3},
+	}
+	dists := []*metadata.DistInstCtx{newTestDist(t, 1), newTestDist(t, 2)}
+	err := dh.sendCallbackWithRetry(sub, "payload", make(chan metadata.Subscription), make(chan struct{}), dists...)
+	require.Error(t, err)
+	require.EqualValues(t, 3, atomic.LoadInt32(&requests))
+
+	// every dist is saved as a dead letter after all the attempts failed
+	deadLetters := make([]metadata.EventDeadLetter, 0)
+	require.NoError(t, dh.db.Table(common.BKTableNameEventDeadLetter).Find(nil).Sort("id").All(dh.ctx, &deadLetters))
+	require.Len(t, deadLetters, 2)
+	for index, deadLetter := range deadLetters {
+		require.Equal(t, int64(testSubscriptionID), deadLetter.SubscriptionID)
+		require.Equal(t, dists[index].DstbID, deadLetter.DstbID)
+		require.Equal(t, dists[index].Raw, deadLetter.Payload)
+		require.EqualValues(t, 3, deadLetter.Attempts)
+		require.Equal(t, "0", deadLetter.OwnerID)
+		require.NotEmpty(t, deadLetter.LastError)
+	}
+	require.NotEqual(t, deadLetters[0].ID, deadLetters[1].ID)
+
+	// the retries stop once the distribute routine is stopped
+	atomic.StoreInt32(&requests, 0)
+	sub.RetryPolicy = &metadata.RetryPolicy{MaxAttempts: 3, BackoffSeconds: 60}
+	done := make(chan struct{})
+	close(done)
+	require.Error(t, dh.sendCallbackWithRetry(sub, "payload", make(chan metadata.Subscription), done, newTestDist(t, 3)))
+	require.EqualValues(t, 1, atomic.LoadInt32(&requests))
+
+	deadLetter := metadata.EventDeadLetter{}
+	cond := map[string]interface{}{"distribution_id": 3}
+	require.NoError(t, dh.db.Table(common.BKTableNameEventDeadLetter).Find(cond).One(dh.ctx, &deadLetter))
+	require.EqualValues(t, 1, deadLetter.Attempts)
+}
@@ -0,0 +1,62 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package distribution
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+)
+
+// signCallback set the timestamp header, and the signature header if the receiver has a secret.
+func (dh *DistHandler) signCallback(header http.Header, receiver *metadata.Subscription, payload string) error {
+	now := time.Now()
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	header.Set(metadata.EventTimestampHeader, timestamp)
+	if len(receiver.Secret) == 0 {
+		return nil
+	}
+
+	secrets := []string{receiver.Secret}
+	if len(receiver.PreviousSecret) != 0 && now.Before(receiver.PreviousSecretExpireTime.Time) {
+		secrets = append(secrets, receiver.PreviousSecret)
+	}
+
+	signatures := make([]string, 0, len(secrets))
+	for _, encrypted := range secrets {
+		secret, err := util.DecryptAES(dh.secretKey, encrypted)
+		if err != nil {
+			return fmt.Errorf("decrypt secret failed, err: %v", err)
+		}
+		signatures = append(signatures, "sha256="+signPayload(secret, timestamp, payload))
+	}
+	header.Set(metadata.EventSignatureHeader, strings.Join(signatures, ","))
+	return nil
+}
+
+// signPayload returns hex(hmac-sha256(secret, timestamp + "." + payload))
+func signPayload(secret, timestamp, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -19,29 +19,67 @@ import (
 	"sync"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"gopkg.in/redis.v5"
 
+	"configcenter/src/apimachinery/coreservice/auditlog"
+	"configcenter/src/apimachinery/discovery"
 	"configcenter/src/common"
 	"configcenter/src/common/blog"
 	"configcenter/src/common/util"
 	"configcenter/src/scene_server/event_server/identifier"
+	"configcenter/src/scene_server/event_server/types"
 	"configcenter/src/storage/dal"
+	ccredis "configcenter/src/storage/dal/redis"
 	"configcenter/src/storage/rpc"
 )
 
-func Start(ctx context.Context, cache *redis.Client, db dal.RDB, rc rpc.Client) error {
+// Config is the configuration of event distribution
+type Config struct {
+	// SecretKey is used to decrypt the subscription secrets
+	SecretKey string
+	// Registry is used to register the delivery metrics
+	Registry prometheus.Registerer
+	// PausedBufferLimit is the max number of events buffered for an inactive subscription
+	PausedBufferLimit int64
+	// SuspendAfterFailures is the number of consecutive failed deliveries after which a subscription is suspended
+	SuspendAfterFailures int64
+	// Discovery discovers the event servers, the subscribers are partitioned among them
+	Discovery discovery.Interface
+	// Address is the address of the current event server registered in zookeeper
+	Address string
+	// Audit saves the audit logs of the subscriptions suspended by the event server
+	Audit auditlog.AuditClientInterface
+}
+
+func Start(ctx context.Context, cache ccredis.Client, db dal.RDB, rc rpc.Client, conf Config) error {
 	chErr := make(chan error, 1)
 	err := migrateIDToMongo(ctx, cache, db)
 	if err != nil {
 		return fmt.Errorf("migrateIDToMongo failed: %v", err)
 	}
+	if err := migrateEventQueues(cache); err != nil {
+		return fmt.Errorf("migrateEventQueues failed: %v", err)
+	}
 
-	eh := &EventHandler{cache: cache}
+	filters := newSubscriberFilters()
+	eh := &EventHandler{cache: cache, db: db, ctx: ctx, filters: filters}
 	go func() {
 		chErr <- eh.Run()
 	}()
 
-	dh := &DistHandler{cache: cache, db: db, ctx: ctx}
+	dh := &DistHandler{
+		cache:                cache,
+		db:                   db,
+		ctx:                  ctx,
+		secretKey:            conf.SecretKey,
+		metrics:              newDeliveryMetrics(conf.Registry),
+		pausedBufferLimit:    conf.PausedBufferLimit,
+		suspendAfterFailures: conf.SuspendAfterFailures,
+		partitioner:          newPartitioner(conf.Address, conf.Discovery),
+		filters:              filters,
+		audit:                conf.Audit,
+	}
 	go func() {
 		chErr <- dh.StartDistribute()
 	}()
@@ -52,6 +90,7 @@ func Start(ctx context.Context, cache *redis.Client, db dal.RDB, rc rpc.Client)
 	}()
 
 	go cleanExpiredEvents(cache)
+	go drainLegacyEventQueues(cache)
 
 	if rc != nil {
 		th := &TxnHandler{cache: cache, db: db, ctx: ctx, rc: rc, committed: make(chan string, 100), shouldClose: util.NewBool(false)}
@@ -68,7 +107,7 @@ func Start(ctx context.Context, cache *redis.Client, db dal.RDB, rc rpc.Client)
 	return <-chErr
 }
 
-func migrateIDToMongo(ctx context.Context, cache *redis.Client, db dal.RDB) error {
+func migrateIDToMongo(ctx context.Context, cache ccredis.Client, db dal.RDB) error {
 	sid, err := cache.Get(common.EventCacheEventIDKey).Result()
 	if redis.Nil == err {
 		return nil
@@ -98,16 +137,120 @@ func migrateIDToMongo(ctx context.Context, cache *redis.Client, db dal.RDB) erro
 	return cache.Del(common.EventCacheEventIDKey).Err()
 }
 
-type EventHandler struct{ cache *redis.Client }
+// the event queue keys before they have the hash tag
+const (
+	legacyEventCacheEventQueueKey          = common.BKCacheKeyV3Prefix + "event:inst_queue"
+	legacyEventCacheEventQueueDuplicateKey = common.BKCacheKeyV3Prefix + "event:inst_queue_duplicate"
+	legacyEventCacheEventTxnQueuePrefix    = common.BKCacheKeyV3Prefix + "event:inst_txn_queue:"
+)
+
+// migrateEventQueues moves the events in the legacy event queues to the hash tagged ones
+func migrateEventQueues(cache ccredis.Client) error {
+	queues := map[string]string{
+		legacyEventCacheEventQueueKey:          types.EventCacheEventQueueKey,
+		legacyEventCacheEventQueueDuplicateKey: types.EventCacheEventQueueDuplicateKey,
+	}
+	txnIDs, err := cache.ZRange(common.EventCacheEventTxnSet, 0, -1).Result()
+	if err != nil {
+		return err
+	}
+	for _, txnID := range txnIDs {
+		queues[legacyEventCacheEventTxnQueuePrefix+txnID] = common.EventCacheEventTxnQueuePrefix + txnID
+	}
+
+	for legacy, queue := range queues {
+		exists, err := cache.Exists(legacy).Result()
+		if err != nil {
+			return err
+		}
+		if !exists {
+			continue
+		}
+		blog.Infof("migrate events from %s to %s", legacy, queue)
+		// the queues are consumed from the tail, the legacy events are older and appended to the tail in order.
+		// the legacy queue and the new one may be in different slots, so pop and push in two commands
+		for {
+			event, err := cache.LPop(legacy).Result()
+			if redis.Nil == err {
+				break
+			}
+			if err != nil {
+				return err
+			}
+			if err := cache.RPush(queue, event).Err(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// legacyEventQueueDrainInterval is the interval to drain the legacy event queues. the coreservice and event server
+// instances of the older versions keep pushing to them during a rolling upgrade, the events would be lost otherwise.
+const legacyEventQueueDrainInterval = 5 * time.Second
+
+// drainLegacyEventQueues moves the events pushed to the legacy event queues to the hash tagged ones periodically,
+// the events of the transactions are moved when the transactions are committed.
+func drainLegacyEventQueues(cache ccredis.Client) {
+	queues := map[string]string{
+		legacyEventCacheEventQueueKey:          types.EventCacheEventQueueKey,
+		legacyEventCacheEventQueueDuplicateKey: types.EventCacheEventQueueDuplicateKey,
+	}
+	ticker := time.NewTicker(legacyEventQueueDrainInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		for legacy, queue := range queues {
+			if err := moveEventQueue(cache, legacy, queue); err != nil {
+				blog.Errorf("drain the legacy event queue %s failed: %v", legacy, err)
+			}
+		}
+	}
+}
+
+// moveEventQueue moves the events from the tail of a queue to the head of another one in order. the queues may be
+// in different slots in cluster mode, so an event is popped and pushed in two commands, and pushed back on failure.
+func moveEventQueue(cache ccredis.Client, from, to string) error {
+	for {
+		event, err := cache.RPop(from).Result()
+		if redis.Nil == err {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := cache.LPush(to, event).Err(); err != nil {
+			if pushBackErr := cache.RPush(from, event).Err(); pushBackErr != nil {
+				blog.Errorf("push event %s back to %s failed: %v", event, from, pushBackErr)
+			}
+			return err
+		}
+	}
+}
+
+type EventHandler struct {
+	cache   ccredis.Client
+	db      dal.RDB
+	ctx     context.Context
+	filters *subscriberFilters
+}
 type DistHandler struct {
-	cache *redis.Client
-	db    dal.RDB
-	ctx   context.Context
+	cache     ccredis.Client
+	db        dal.RDB
+	ctx       context.Context
+	secretKey string
+	metrics   *deliveryMetrics
+
+	pausedBufferLimit    int64
+	suspendAfterFailures int64
+
+	partitioner *partitioner
+	filters     *subscriberFilters
+	audit       auditlog.AuditClientInterface
 }
 
 type TxnHandler struct {
 	rc          rpc.Client
-	cache       *redis.Client
+	cache       ccredis.Client
 	db          dal.RDB
 	ctx         context.Context
 	committed   chan string
@@ -0,0 +1,150 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package distribution
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/auditoplog"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+	"configcenter/src/scene_server/event_server/types"
+	ccredis "configcenter/src/storage/dal/redis"
+)
+
+// inactiveCheckInterval is the interval to check the queue of an inactive subscription
+var inactiveCheckInterval = time.Second
+
+// trimInactiveQueue drops the oldest dists of an inactive subscription beyond the buffer limit, the dropped
+// dists are marked as done, so that the dists after them are not blocked once the subscription is resumed.
+func (dh *DistHandler) trimInactiveQueue(sub *metadata.Subscription) {
+	queueKey := types.EventCacheDistQueuePrefix + fmt.Sprint(sub.SubscriptionID)
+	length, err := dh.cache.LLen(queueKey).Result()
+	if err != nil {
+		blog.Errorf("get length of dist queue %s failed, err: %v", queueKey, err)
+		return
+	}
+
+	var dropped int64
+	for ; length > dh.pausedBufferLimit; length-- {
+		raw, err := dh.cache.LPop(queueKey).Result()
+		if err != nil {
+			if !ccredis.IsNilErr(err) {
+				blog.Errorf("drop dist from queue %s failed, err: %v", queueKey, err)
+			}
+			break
+		}
+		dist := metadata.DistInst{}
+		if err := json.Unmarshal([]byte(raw), &dist); err != nil {
+			blog.Errorf("drop dist, but unmarshal failed, err: %v, data=[%s]", err, raw)
+			continue
+		}
+		if err := dh.saveDistDone(&metadata.DistInstCtx{DistInst: dist, Raw: raw}); err != nil {
+			blog.Errorf("drop dist %d, but save done failed, err: %v", dist.DstbID, err)
+		}
+		dropped++
+	}
+	if dropped > 0 {
+		blog.Warnf("subscription %d is %s, dropped %d dists beyond the buffer limit %d",
+			sub.SubscriptionID, sub.Status, dropped, dh.pausedBufferLimit)
+	}
+}
+
+func (dh *DistHandler) resetDeliveryFailures(sub *metadata.Subscription) {
+	key := types.EventCacheDistCallBackCountPrefix + strconv.FormatInt(sub.SubscriptionID, 10)
+	if err := dh.cache.HDel(key, types.EventCallBackConsecutiveFailureField).Err(); err != nil {
+		blog.Errorf("reset consecutive failures of subscription %d failed, err: %v", sub.SubscriptionID, err)
+	}
+}
+
+// increaseDeliveryFailures count a failed delivery, and suspend the subscription once the consecutive
+// failures reach the threshold
+func (dh *DistHandler) increaseDeliveryFailures(sub *metadata.Subscription) {
+	key := types.EventCacheDistCallBackCountPrefix + strconv.FormatInt(sub.SubscriptionID, 10)
+	failures, err := dh.cache.HIncrBy(key, types.EventCallBackConsecutiveFailureField, 1).Result()
+	if err != nil {
+		blog.Errorf("increase consecutive failures of subscription %d failed, err: %v", sub.SubscriptionID, err)
+		return
+	}
+
+	// the threshold may be lowered or passed by the concurrent failures, so the subscription is suspended once the
+	// failures reach it, unless it's already suspended
+	if dh.suspendAfterFailures <= 0 || failures < dh.suspendAfterFailures {
+		return
+	}
+	if sub.Status == metadata.SubscriptionStatusSuspended {
+		return
+	}
+	if err := dh.suspendSubscription(sub, failures); err != nil {
+		blog.Errorf("suspend subscription %d failed, err: %v", sub.SubscriptionID, err)
+	}
+}
+
+func (dh *DistHandler) suspendSubscription(sub *metadata.Subscription, failures int64) error {
+	preData := *sub
+	preData.HideSecrets()
+
+	condition := map[string]interface{}{
+		common.BKSubscriptionIDField: sub.SubscriptionID,
+		common.BKOwnerIDField:        sub.OwnerID,
+	}
+	data := map[string]interface{}{
+		"status":             metadata.SubscriptionStatusSuspended,
+		common.LastTimeField: metadata.Now(),
+	}
+	if err := dh.db.Table(common.BKTableNameSubscription).Update(dh.ctx, condition, data); err != nil {
+		return err
+	}
+	sub.Status = metadata.SubscriptionStatusSuspended
+	blog.Warnf("subscription %d is suspended after %d consecutive failed deliveries", sub.SubscriptionID, failures)
+
+	curData := *sub
+	curData.HideSecrets()
+	dh.saveSuspendAuditLog(sub, preData, curData, failures)
+
+	// notify all the event servers to stop sending events to the subscriber
+	msg, _ := json.Marshal(sub)
+	return dh.cache.Publish(types.EventCacheProcessChannel, "update"+string(msg)).Err()
+}
+
+// saveSuspendAuditLog saves the audit log of suspending the subscription by the system operator
+func (dh *DistHandler) saveSuspendAuditLog(sub *metadata.Subscription, preData, curData metadata.Subscription, failures int64) {
+	header := make(http.Header)
+	header.Set(common.BKHTTPOwnerID, sub.OwnerID)
+	header.Set(common.BKHTTPHeaderUser, common.CCSystemOperatorUserName)
+	header.Set(common.BKHTTPCCRequestID, util.GenerateRID())
+	auditLog := metadata.SaveAuditLogParams{
+		ID:     sub.SubscriptionID,
+		Model:  "subscription",
+		OpType: auditoplog.AuditOpTypeModify,
+		OpDesc: fmt.Sprintf("suspend subscription after %d consecutive failed deliveries", failures),
+		Content: metadata.Content{
+			PreData: preData,
+			CurData: curData,
+		},
+	}
+	resp, err := dh.audit.SaveAuditLog(dh.ctx, header, auditLog)
+	if err != nil {
+		blog.Errorf("save audit log of suspending subscription %d failed, err: %v", sub.SubscriptionID, err)
+		return
+	}
+	if !resp.Result {
+		blog.Errorf("save audit log of suspending subscription %d failed, err: %s", sub.SubscriptionID, resp.ErrMsg)
+	}
+}
@@ -0,0 +1,90 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package distribution
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"configcenter/src/common"
+	"configcenter/src/common/metadata"
+	"configcenter/src/scene_server/event_server/types"
+	"configcenter/src/storage/dal/mongo/memory"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAudit saves the audit logs in memory
+type fakeAudit struct {
+	headers []http.Header
+	logs    []metadata.SaveAuditLogParams
+}
+
+func (a *fakeAudit) SaveAuditLog(ctx context.Context, h http.Header, logs ...metadata.SaveAuditLogParams) (*metadata.Response, error) {
+	a.headers = append(a.headers, h)
+	a.logs = append(a.logs, logs...)
+	return &metadata.Response{BaseResp: metadata.BaseResp{Result: true}}, nil
+}
+
+func (a *fakeAudit) SearchAuditLog(ctx context.Context, h http.Header, param metadata.QueryInput) (*metadata.AuditQueryResult, error) {
+	return nil, nil
+}
+
+func TestIncreaseDeliveryFailures(t *testing.T) {
+	f, cache := newFakeCache(t)
+	audit := new(fakeAudit)
+	dh := &DistHandler{cache: cache, db: memory.New(), ctx: context.Background(), audit: audit, suspendAfterFailures: 3}
+
+	subscription := func(id int64) *metadata.Subscription {
+		sub := &metadata.Subscription{SubscriptionID: id, OwnerID: "0", Status: metadata.SubscriptionStatusActive}
+		require.NoError(t, dh.db.Table(common.BKTableNameSubscription).Insert(dh.ctx, sub))
+		return sub
+	}
+	status := func(id int64) string {
+		sub := metadata.Subscription{}
+		cond := map[string]interface{}{common.BKSubscriptionIDField: id}
+		require.NoError(t, dh.db.Table(common.BKTableNameSubscription).Find(cond).One(dh.ctx, &sub))
+		return sub.Status
+	}
+
+	sub := subscription(1)
+	dh.increaseDeliveryFailures(sub)
+	dh.increaseDeliveryFailures(sub)
+	require.Equal(t, metadata.SubscriptionStatusActive, status(1))
+	require.Empty(t, audit.logs)
+
+	dh.increaseDeliveryFailures(sub)
+	require.Equal(t, metadata.SubscriptionStatusSuspended, sub.Status)
+	require.Equal(t, metadata.SubscriptionStatusSuspended, status(1))
+	require.Len(t, audit.logs, 1)
+	require.EqualValues(t, 1, audit.logs[0].ID)
+	require.Equal(t, "subscription", audit.logs[0].Model)
+	require.Equal(t, "0", audit.headers[0].Get(common.BKHTTPOwnerID))
+	require.Equal(t, common.CCSystemOperatorUserName, audit.headers[0].Get(common.BKHTTPHeaderUser))
+	require.Len(t, f.published[types.EventCacheProcessChannel], 1)
+
+	// the subscription already suspended is not suspended again
+	dh.increaseDeliveryFailures(sub)
+	require.Len(t, audit.logs, 1)
+	require.Len(t, f.published[types.EventCacheProcessChannel], 1)
+
+	// the subscription is suspended when the failures have passed the threshold
+	sub = subscription(2)
+	key := types.EventCacheDistCallBackCountPrefix + strconv.FormatInt(sub.SubscriptionID, 10)
+	require.NoError(t, cache.HSet(key, types.EventCallBackConsecutiveFailureField, 5).Err())
+	dh.increaseDeliveryFailures(sub)
+	require.Equal(t, metadata.SubscriptionStatusSuspended, status(2))
+	require.Len(t, audit.logs, 2)
+}
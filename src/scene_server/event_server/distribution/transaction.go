@@ -65,6 +65,11 @@ outer:
 				continue outer
 			}
 		}
+		// the coreservice instances of the older versions push the events to the legacy queue during a rolling upgrade
+		if err = moveEventQueue(th.cache, legacyEventCacheEventTxnQueuePrefix+txnID, common.EventCacheEventQueueKey); err != nil {
+			blog.Warnf("move committed event in the legacy queue to event queue failed: %v, we will try again later", err)
+			continue
+		}
 		if err = th.cache.Del(common.EventCacheEventTxnQueuePrefix + txnID).Err(); err != nil {
 			blog.Warnf("remove [%s] transaction queue failed: %v, we will try again later", txnID, err)
 			continue
@@ -167,13 +172,13 @@ func (th *TxnHandler) dropTransaction(txnIDs []string) {
 		return
 	}
 	blog.V(4).Infof("transaction %v should drop", txnIDs)
-	dropKeys := make([]string, len(txnIDs))
+	dropKeys := make([]string, 0, len(txnIDs)*2)
 	dropTxnIDs := make([]interface{}, len(txnIDs))
 	for index, txnID := range txnIDs {
 		dropTxnIDs[index] = txnID
-		dropKeys[index] = common.EventCacheEventTxnQueuePrefix + txnID
+		dropKeys = append(dropKeys, common.EventCacheEventTxnQueuePrefix+txnID, legacyEventCacheEventTxnQueuePrefix+txnID)
 	}
-	if err := th.cache.Del(dropKeys...).Err(); err != nil {
+	if err := ccredis.Del(th.cache, dropKeys...); err != nil {
 		blog.Warnf("drop transaction queue [%v] failed: %v, we will try again later", dropKeys, err)
 		return
 	}
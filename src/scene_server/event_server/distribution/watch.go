@@ -0,0 +1,177 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package distribution
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/lock"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+)
+
+const (
+	saveWatchRecordAttempts = 3
+	// watchSequenceLockExpire must be longer than saving a record takes, or the records may be saved out of order
+	watchSequenceLockExpire = 30 * time.Second
+)
+
+var (
+	// watchSequenceLockWait is the longest time to wait for the sequence lock
+	watchSequenceLockWait     = 10 * time.Second
+	watchSequenceLockInterval = 50 * time.Millisecond
+)
+
+// saveWatchRecord keep the event in db for the watchers. the events are handled by several event servers at the
+// same time, so the records are not saved in the order of the event id, each record is given a sequence when it's
+// saved instead, and the watchers page on the sequence.
+func (eh *EventHandler) saveWatchRecord(event *metadata.EventInstCtx, dists []metadata.DistInst) {
+	inst := event.EventInst
+	// the object type of the dist is the real object id of a custom object instance
+	if len(dists) > 0 {
+		inst.ObjType = dists[0].ObjType
+	}
+	raw, err := json.Marshal(inst)
+	if err != nil {
+		blog.Errorf("marshal event %d for watching failed, err: %v", event.ID, err)
+		return
+	}
+
+	record := metadata.EventWatchRecord{
+		EventID:    inst.ID,
+		EventType:  inst.EventType,
+		ObjType:    inst.ObjType,
+		Action:     inst.Action,
+		OwnerID:    inst.OwnerID,
+		BizIDs:     eh.watchBizIDs(&inst),
+		Event:      string(raw),
+		CreateTime: metadata.Now(),
+	}
+	for attempt := 1; attempt <= saveWatchRecordAttempts; attempt++ {
+		err = eh.insertWatchRecord(&record)
+		// the event may be handled again after the event server restarted
+		if err == nil || eh.db.IsDuplicatedError(err) {
+			return
+		}
+		blog.Errorf("save watch record of event %d failed at attempt %d, err: %v", event.ID, attempt, err)
+		time.Sleep(time.Second)
+	}
+}
+
+// insertWatchRecord gives the record the next sequence and saves it with the sequence lock held,
+// so a record is always visible before the records with a greater sequence are saved.
+func (eh *EventHandler) insertWatchRecord(record *metadata.EventWatchRecord) error {
+	locker := lock.NewLocker(eh.cache)
+	deadline := time.Now().Add(watchSequenceLockWait)
+	for {
+		locked, err := locker.Lock(lock.EventWatchSequenceKey, watchSequenceLockExpire)
+		if err != nil {
+			return err
+		}
+		if locked {
+			break
+		}
+		if time.Now().After(deadline) {
+			return errors.New("wait for the watch sequence lock timeout")
+		}
+		time.Sleep(watchSequenceLockInterval)
+		locker = lock.NewLocker(eh.cache)
+	}
+	defer func() {
+		if err := locker.Unlock(); err != nil {
+			blog.Errorf("unlock the watch sequence failed, err: %v", err)
+		}
+	}()
+
+	sequence, err := eh.db.NextSequence(eh.ctx, common.BKTableNameEventWatch)
+	if err != nil {
+		return err
+	}
+	record.Sequence = int64(sequence)
+	return eh.db.Table(common.BKTableNameEventWatch).Insert(eh.ctx, record)
+}
+
+// watchBizIDs returns the businesses the event belongs to, the host data has no business id,
+// so a host event belongs to the businesses the host is in.
+func (eh *EventHandler) watchBizIDs(event *metadata.EventInst) []int64 {
+	bizIDs := eventBizIDs(event)
+	if len(bizIDs) > 0 || event.ObjType != common.BKInnerObjIDHost {
+		return bizIDs
+	}
+
+	hostIDs := make([]int64, 0)
+	for _, data := range event.Data {
+		for _, item := range []interface{}{data.CurData, data.PreData} {
+			m, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			hostID, err := util.GetInt64ByInterface(m[common.BKHostIDField])
+			if err != nil {
+				continue
+			}
+			hostIDs = append(hostIDs, hostID)
+		}
+	}
+	if len(hostIDs) == 0 {
+		return bizIDs
+	}
+
+	relations := make([]metadata.ModuleHost, 0)
+	condition := map[string]interface{}{
+		common.BKHostIDField: map[string]interface{}{common.BKDBIN: hostIDs},
+	}
+	if err := eh.db.Table(common.BKTableNameModuleHostConfig).Find(condition).Fields(common.BKAppIDField).
+		All(eh.ctx, &relations); err != nil {
+		blog.Errorf("get businesses of the hosts in event %d failed, err: %v", event.ID, err)
+		return bizIDs
+	}
+	exists := make(map[int64]bool)
+	for _, relation := range relations {
+		if exists[relation.AppID] {
+			continue
+		}
+		exists[relation.AppID] = true
+		bizIDs = append(bizIDs, relation.AppID)
+	}
+	return bizIDs
+}
+
+// eventBizIDs returns the business ids in the current and previous data of the event
+func eventBizIDs(event *metadata.EventInst) []int64 {
+	bizIDs := make([]int64, 0)
+	exists := make(map[int64]bool)
+	for _, data := range event.Data {
+		for _, item := range []interface{}{data.CurData, data.PreData} {
+			m, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			value, ok := m[common.BKAppIDField]
+			if !ok {
+				continue
+			}
+			bizID, err := util.GetInt64ByInterface(value)
+			if err != nil || exists[bizID] {
+				continue
+			}
+			exists[bizID] = true
+			bizIDs = append(bizIDs, bizID)
+		}
+	}
+	return bizIDs
+}
@@ -0,0 +1,209 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package distribution
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/lock"
+	"configcenter/src/common/metadata"
+	"configcenter/src/storage/dal"
+	"configcenter/src/storage/dal/mongo/memory"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestEventHandler(t *testing.T) *EventHandler {
+	_, cache := newFakeCache(t)
+	return &EventHandler{cache: cache, db: memory.New(), ctx: context.Background(), filters: newSubscriberFilters()}
+}
+
+func findWatchRecords(t *testing.T, eh *EventHandler) []metadata.EventWatchRecord {
+	records := make([]metadata.EventWatchRecord, 0)
+	require.NoError(t, eh.db.Table(common.BKTableNameEventWatch).Find(nil).Sort("sequence").All(eh.ctx, &records))
+	return records
+}
+
+func TestSaveWatchRecord(t *testing.T) {
+	eh := newTestEventHandler(t)
+	require.NoError(t, eh.db.Table(common.BKTableNameEventWatch).CreateIndex(eh.ctx,
+		dal.Index{Name: "idx_eventID", Keys: map[string]int32{"event_id": 1}, Unique: true}))
+
+	event := &metadata.EventInstCtx{EventInst: metadata.EventInst{
+		ID:        1,
+		EventType: metadata.EventTypeInstData,
+		ObjType:   "object",
+		Action:    metadata.EventActionCreate,
+		OwnerID:   "0",
+		Data:      []metadata.EventData{{CurData: map[string]interface{}{common.BKAppIDField: 2}}},
+	}}
+	eh.saveWatchRecord(event, []metadata.DistInst{{EventInst: metadata.EventInst{ObjType: "switch"}}})
+	// the event is saved only once when it's handled again
+	eh.saveWatchRecord(event, nil)
+
+	records := findWatchRecords(t, eh)
+	require.Len(t, records, 1)
+	require.Equal(t, int64(1), records[0].Sequence)
+	require.Equal(t, int64(1), records[0].EventID)
+	require.Equal(t, "switch", records[0].ObjType)
+	require.Equal(t, []int64{2}, records[0].BizIDs)
+
+	// the sequence lock is released after saving
+	locker := lock.NewLocker(eh.cache)
+	locked, err := locker.Lock(lock.EventWatchSequenceKey, time.Second)
+	require.NoError(t, err)
+	require.True(t, locked)
+	require.NoError(t, locker.Unlock())
+}
+
+func TestSaveWatchRecordConcurrently(t *testing.T) {
+	eh := newTestEventHandler(t)
+
+	// the events are handled out of order by several routines
+	var wg sync.WaitGroup
+	for id := int64(10); id > 0; id-- {
+		wg.Add(1)
+		go func(id int64) {
+			defer wg.Done()
+			eh.saveWatchRecord(&metadata.EventInstCtx{EventInst: metadata.EventInst{ID: id, OwnerID: "0"}}, nil)
+		}(id)
+	}
+	wg.Wait()
+
+	records := findWatchRecords(t, eh)
+	require.Len(t, records, 10)
+	events := make(map[int64]bool)
+	for index, record := range records {
+		require.Equal(t, int64(index+1), record.Sequence)
+		events[record.EventID] = true
+	}
+	require.Len(t, events, 10)
+}
+
+func TestInsertWatchRecordLockTimeout(t *testing.T) {
+	eh := newTestEventHandler(t)
+	defer func(wait time.Duration) { watchSequenceLockWait = wait }(watchSequenceLockWait)
+	watchSequenceLockWait = 200 * time.Millisecond
+
+	locker := lock.NewLocker(eh.cache)
+	locked, err := locker.Lock(lock.EventWatchSequenceKey, time.Minute)
+	require.NoError(t, err)
+	require.True(t, locked)
+
+	record := &metadata.EventWatchRecord{EventID: 1}
+	require.Error(t, eh.insertWatchRecord(record))
+	require.Empty(t, findWatchRecords(t, eh))
+
+	// the record is saved once the lock is released
+	require.NoError(t, locker.Unlock())
+	require.NoError(t, eh.insertWatchRecord(record))
+	require.Equal(t, int64(1), record.Sequence)
+}
+
+func TestEventBizIDs(t *testing.T) {
+	testCases := []struct {
+		name string
+		data []metadata.EventData
+		want []int64
+	}{
+		{
+			name: "no data",
+			want: []int64{},
+		},
+		{
+			name: "current and previous",
+			data: []metadata.EventData{
+				{CurData: map[string]interface{}{common.BKAppIDField: 2}, PreData: map[string]interface{}{common.BKAppIDField: 3}},
+			},
+			want: []int64{2, 3},
+		},
+		{
+			name: "duplicated",
+			data: []metadata.EventData{
+				{CurData: map[string]interface{}{common.BKAppIDField: 2}, PreData: map[string]interface{}{common.BKAppIDField: int64(2)}},
+				{CurData: map[string]interface{}{common.BKAppIDField: float64(2)}},
+			},
+			want: []int64{2},
+		},
+		{
+			name: "without business",
+			data: []metadata.EventData{
+				{CurData: map[string]interface{}{common.BKHostIDField: 1}, PreData: nil},
+				{CurData: "invalid"},
+			},
+			want: []int64{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, eventBizIDs(&metadata.EventInst{Data: tc.data}), fmt.Sprint(tc.data))
+		})
+	}
+}
+
+func TestWatchBizIDsOfHost(t *testing.T) {
+	eh := newTestEventHandler(t)
+	for _, relation := range []metadata.ModuleHost{
+		{AppID: 2, HostID: 1, ModuleID: 10},
+		{AppID: 2, HostID: 1, ModuleID: 11},
+		{AppID: 3, HostID: 2, ModuleID: 20},
+	} {
+		require.NoError(t, eh.db.Table(common.BKTableNameModuleHostConfig).Insert(eh.ctx, relation))
+	}
+
+	testCases := []struct {
+		name  string
+		event metadata.EventInst
+		want  []int64
+	}{
+		{
+			name: "host in a business",
+			event: metadata.EventInst{ObjType: common.BKInnerObjIDHost, Data: []metadata.EventData{
+				{CurData: map[string]interface{}{common.BKHostIDField: 1}, PreData: map[string]interface{}{common.BKHostIDField: 1}},
+			}},
+			want: []int64{2},
+		},
+		{
+			name: "hosts in businesses",
+			event: metadata.EventInst{ObjType: common.BKInnerObjIDHost, Data: []metadata.EventData{
+				{CurData: map[string]interface{}{common.BKHostIDField: 1}},
+				{PreData: map[string]interface{}{common.BKHostIDField: 2}},
+			}},
+			want: []int64{2, 3},
+		},
+		{
+			name: "deleted host",
+			event: metadata.EventInst{ObjType: common.BKInnerObjIDHost, Data: []metadata.EventData{
+				{PreData: map[string]interface{}{common.BKHostIDField: 3}},
+			}},
+			want: []int64{},
+		},
+		{
+			// the business in the data is used as it is
+			name: "host relation",
+			event: metadata.EventInst{ObjType: common.BKInnerObjIDHost, Data: []metadata.EventData{
+				{CurData: map[string]interface{}{common.BKHostIDField: 1, common.BKAppIDField: 5}},
+			}},
+			want: []int64{5},
+		},
+	}
+	for _, tc := range testCases {
+		require.ElementsMatch(t, tc.want, eh.watchBizIDs(&tc.event), tc.name)
+	}
+}
@@ -15,17 +15,16 @@ package identifier
 import (
 	"context"
 
-	"gopkg.in/redis.v5"
-
 	"configcenter/src/common"
 	"configcenter/src/common/blog"
 	"configcenter/src/common/condition"
 	"configcenter/src/common/metadata"
 	"configcenter/src/common/util"
 	"configcenter/src/storage/dal"
+	"configcenter/src/storage/dal/redis"
 )
 
-func fillIdentifier(identifier *metadata.HostIdentifier, ctx context.Context, cache *redis.Client, db dal.RDB) (*metadata.HostIdentifier, error) {
+func fillIdentifier(identifier *metadata.HostIdentifier, ctx context.Context, cache redis.Client, db dal.RDB) (*metadata.HostIdentifier, error) {
 	// fill cloudName
 	cloud, err := getCache(ctx, cache, db, common.BKInnerObjIDPlat, identifier.CloudID, false)
 	if err != nil {
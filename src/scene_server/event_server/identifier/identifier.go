@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"reflect"
 	"runtime/debug"
+	"sort"
 	"strconv"
 	"time"
 
@@ -28,8 +29,7 @@ import (
 	"configcenter/src/common/util"
 	"configcenter/src/scene_server/event_server/types"
 	"configcenter/src/storage/dal"
-
-	"gopkg.in/redis.v5"
+	"configcenter/src/storage/dal/redis"
 )
 
 var delayTime = time.Second * 30
@@ -227,9 +227,9 @@ func (ih *IdentifierHandler) handleHostBatch(hostIdentify metadata.EventInst, ho
 		for _, hostID := range hosIDs[index:leftIndex] {
 			hostIDKeys = append(hostIDKeys, getInstCacheKey(common.BKInnerObjIDHost, hostID))
 		}
-		idens, err := ih.cache.MGet(hostIDKeys...).Result()
+		idens, err := redis.MGet(ih.cache, hostIDKeys...)
 		if err != nil {
-			blog.Warnf("identifier: ih.cache.MGet by %v,%v. we will try to fetch it from db instead, rid: %s", hostIDKeys, err, rid)
+			blog.Warnf("identifier: redis.MGet by %v,%v. we will try to fetch it from db instead, rid: %s", hostIDKeys, err, rid)
 			idens = make([]interface{}, len(hostIDKeys))
 			for index := range idens {
 				// simulate that redis returns all nil
@@ -372,7 +372,7 @@ func (i *Inst) set(key string, value interface{}) error {
 	return err
 }
 
-func (i *Inst) saveCache(cache *redis.Client) error {
+func (i *Inst) saveCache(cache redis.Client) error {
 	out, err := json.Marshal(i.data)
 	if err != nil {
 		return err
@@ -423,7 +423,7 @@ func NewHostIdentifier(m map[string]interface{}) (*metadata.HostIdentifier, erro
 	return &ident, nil
 }
 
-func getCache(ctx context.Context, cache *redis.Client, db dal.RDB, objType string, instID int64, fromdb bool) (*Inst, error) {
+func getCache(ctx context.Context, cache redis.Client, db dal.RDB, objType string, instID int64, fromdb bool) (*Inst, error) {
 	var err error
 	ret := cache.Get(getInstCacheKey(objType, instID)).Val()
 	inst := Inst{objType: objType, instID: instID, ident: &metadata.HostIdentifier{}, data: map[string]interface{}{}}
@@ -725,22 +725,50 @@ func (ih *IdentifierHandler) fetchHostCache() {
 	blog.Infof("identifier: fetched %d hosts", len(hosts))
 }
 
+// hasChanged returns whether any of the fields has changed, it's checked the same as ChangedFields
 func hasChanged(curData, preData map[string]interface{}, fields ...string) (isDifferent bool) {
 	for _, field := range fields {
-		if curData[field] != preData[field] {
+		if fieldChanged(curData, preData, field) {
 			return true
 		}
 	}
 	return false
 }
 
+// ChangedFields returns the sorted fields whose value differs between curData and preData,
+// including those only exist in one of them.
+func ChangedFields(curData, preData map[string]interface{}) []string {
+	fields := make([]string, 0)
+	for field := range curData {
+		if fieldChanged(curData, preData, field) {
+			fields = append(fields, field)
+		}
+	}
+	for field := range preData {
+		if _, exist := curData[field]; !exist {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+func fieldChanged(curData, preData map[string]interface{}, field string) bool {
+	curValue, curExist := curData[field]
+	preValue, preExist := preData[field]
+	if curExist != preExist {
+		return true
+	}
+	return !reflect.DeepEqual(curValue, preValue)
+}
+
 type IdentifierHandler struct {
-	cache *redis.Client
+	cache redis.Client
 	db    dal.RDB
 	ctx   context.Context
 }
 
-func NewIdentifierHandler(ctx context.Context, cache *redis.Client, db dal.RDB) *IdentifierHandler {
+func NewIdentifierHandler(ctx context.Context, cache redis.Client, db dal.RDB) *IdentifierHandler {
 	return &IdentifierHandler{ctx: ctx, cache: cache, db: db}
 }
 
@@ -0,0 +1,53 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package identifier
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestChangedFields(t *testing.T) {
+	testCases := []struct {
+		curData map[string]interface{}
+		preData map[string]interface{}
+		changed []string
+	}{
+		{map[string]interface{}{"a": 1}, map[string]interface{}{"a": 1}, []string{}},
+		{map[string]interface{}{"a": 1, "b": "x"}, map[string]interface{}{"a": 2, "b": "x"}, []string{"a"}},
+		{map[string]interface{}{"a": []interface{}{"1"}}, map[string]interface{}{"a": []interface{}{"1"}}, []string{}},
+		{map[string]interface{}{"a": []interface{}{"1"}}, map[string]interface{}{"a": []interface{}{"2"}}, []string{"a"}},
+		{map[string]interface{}{"a": nil}, map[string]interface{}{}, []string{"a"}},
+		{map[string]interface{}{}, map[string]interface{}{"b": 1}, []string{"b"}},
+		{map[string]interface{}{"c": 1, "a": 1}, nil, []string{"a", "c"}},
+	}
+	for _, c := range testCases {
+		changed := ChangedFields(c.curData, c.preData)
+		require.Equal(t, c.changed, changed, "%+v", c)
+
+		// the identifier events and the changed fields agree with each other
+		for _, field := range []string{"a", "b", "c"} {
+			require.Equal(t, contains(changed, field), hasChanged(c.curData, c.preData, field), "%+v, field: %s", c, field)
+		}
+	}
+}
+
+func contains(fields []string, field string) bool {
+	for _, f := range fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,237 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+	"configcenter/src/scene_server/event_server/types"
+
+	"github.com/emicklei/go-restful"
+)
+
+// deadLetterClaimTimeout is the longest time a dead letter is claimed by a redelivery
+const deadLetterClaimTimeout = time.Minute
+
+func (s *Service) ListDeadLetters(req *restful.Request, resp *restful.Response) {
+	header := req.Request.Header
+	rid := util.GetHTTPCCRequestID(header)
+	defErr := s.CCErr.CreateDefaultCCErrorIf(util.GetLanguage(header))
+	ownerID := util.GetOwnerID(header)
+
+	var data metadata.ParamDeadLetterSearch
+	if err := json.NewDecoder(req.Request.Body).Decode(&data); err != nil {
+		blog.Errorf("search dead letters, but decode body failed, err: %v, rid: %s", err, rid)
+		resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Error(common.CCErrCommJSONUnmarshalFailed)})
+		return
+	}
+
+	condition := util.SetModOwner(data.Condition, ownerID)
+	limit := data.Page.Limit
+	if limit <= 0 {
+		limit = common.BKNoLimit
+	}
+	sortOption := data.Page.Sort
+	if sortOption == "" {
+		sortOption = common.BKFieldID
+	}
+
+	count, err := s.db.Table(common.BKTableNameEventDeadLetter).Find(condition).Count(s.ctx)
+	if err != nil {
+		blog.Errorf("count dead letters failed, condition: %+v, err: %v, rid: %s", condition, err, rid)
+		resp.WriteError(http.StatusInternalServerError, &metadata.RespError{Msg: defErr.Error(common.CCErrEventDeadLetterSelectFailed)})
+		return
+	}
+
+	results := make([]metadata.EventDeadLetter, 0)
+	if err := s.db.Table(common.BKTableNameEventDeadLetter).Find(condition).Fields(data.Fields...).Sort(sortOption).
+		Start(uint64(data.Page.Start)).Limit(uint64(limit)).All(s.ctx, &results); err != nil {
+		blog.Errorf("search dead letters failed, condition: %+v, err: %v, rid: %s", condition, err, rid)
+		resp.WriteError(http.StatusInternalServerError, &metadata.RespError{Msg: defErr.Error(common.CCErrEventDeadLetterSelectFailed)})
+		return
+	}
+
+	resp.WriteEntity(metadata.NewSuccessResp(metadata.RspDeadLetterSearch{Count: count, Info: results}))
+}
+
+func (s *Service) GetDeadLetter(req *restful.Request, resp *restful.Response) {
+	header := req.Request.Header
+	rid := util.GetHTTPCCRequestID(header)
+	defErr := s.CCErr.CreateDefaultCCErrorIf(util.GetLanguage(header))
+	ownerID := util.GetOwnerID(header)
+
+	id, err := strconv.ParseInt(req.PathParameter("deadLetterID"), 10, 64)
+	if err != nil {
+		resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Errorf(common.CCErrCommParamsInvalid, "deadLetterID")})
+		return
+	}
+
+	deadLetter := metadata.EventDeadLetter{}
+	condition := util.NewMapBuilder(common.BKFieldID, id, common.BKOwnerIDField, ownerID).Build()
+	if err := s.db.Table(common.BKTableNameEventDeadLetter).Find(condition).One(s.ctx, &deadLetter); err != nil {
+		blog.Errorf("get dead letter %d failed, err: %v, rid: %s", id, err, rid)
+		if s.db.IsNotFoundError(err) {
+			resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Error(common.CCErrCommNotFound)})
+			return
+		}
+		resp.WriteError(http.StatusInternalServerError, &metadata.RespError{Msg: defErr.Error(common.CCErrEventDeadLetterSelectFailed)})
+		return
+	}
+
+	resp.WriteEntity(metadata.NewSuccessResp(deadLetter))
+}
+
+// RedeliverDeadLetters push the dead letters back to the distribution queue of their subscribers,
+// a dead letter is removed once it's queued. the result of each dead letter is returned, the dead letters which are
+// not found, whose subscription is deleted or failed to be queued are returned as failed.
+func (s *Service) RedeliverDeadLetters(req *restful.Request, resp *restful.Response) {
+	header := req.Request.Header
+	rid := util.GetHTTPCCRequestID(header)
+	defErr := s.CCErr.CreateDefaultCCErrorIf(util.GetLanguage(header))
+	ownerID := util.GetOwnerID(header)
+
+	data := metadata.ParamDeadLetterRedeliver{}
+	if err := json.NewDecoder(req.Request.Body).Decode(&data); err != nil {
+		blog.Errorf("redeliver dead letters, but decode body failed, err: %v, rid: %s", err, rid)
+		resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Error(common.CCErrCommJSONUnmarshalFailed)})
+		return
+	}
+	if len(data.IDs) == 0 {
+		resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Errorf(common.CCErrCommParamsNeedSet, "ids")})
+		return
+	}
+
+	deadLetters := make([]metadata.EventDeadLetter, 0)
+	condition := map[string]interface{}{
+		common.BKFieldID:      map[string]interface{}{common.BKDBIN: data.IDs},
+		common.BKOwnerIDField: ownerID,
+	}
+	if err := s.db.Table(common.BKTableNameEventDeadLetter).Find(condition).Sort(common.BKFieldID).All(s.ctx, &deadLetters); err != nil {
+		blog.Errorf("search dead letters %v failed, err: %v, rid: %s", data.IDs, err, rid)
+		resp.WriteError(http.StatusInternalServerError, &metadata.RespError{Msg: defErr.Error(common.CCErrEventDeadLetterSelectFailed)})
+		return
+	}
+
+	subscriptionIDs := make([]int64, 0)
+	for _, deadLetter := range deadLetters {
+		subscriptionIDs = append(subscriptionIDs, deadLetter.SubscriptionID)
+	}
+	subscriptions := make([]metadata.Subscription, 0)
+	subCond := map[string]interface{}{
+		common.BKSubscriptionIDField: map[string]interface{}{common.BKDBIN: subscriptionIDs},
+		common.BKOwnerIDField:        ownerID,
+	}
+	if err := s.db.Table(common.BKTableNameSubscription).Find(subCond).Fields(common.BKSubscriptionIDField).
+		All(s.ctx, &subscriptions); err != nil {
+		blog.Errorf("search subscriptions %v of dead letters failed, err: %v, rid: %s", subscriptionIDs, err, rid)
+		resp.WriteError(http.StatusInternalServerError, &metadata.RespError{Msg: defErr.Error(common.CCErrEventDeadLetterSelectFailed)})
+		return
+	}
+	subscriptionExists := make(map[int64]bool)
+	for _, subscription := range subscriptions {
+		subscriptionExists[subscription.SubscriptionID] = true
+	}
+
+	result := metadata.RspDeadLetterRedeliver{Redelivered: make([]int64, 0), Failed: make([]metadata.DeadLetterRedeliverFail, 0)}
+	found := make(map[int64]bool)
+	for index := range deadLetters {
+		deadLetter := &deadLetters[index]
+		found[deadLetter.ID] = true
+		if !subscriptionExists[deadLetter.SubscriptionID] {
+			result.Failed = append(result.Failed, metadata.DeadLetterRedeliverFail{
+				ID:    deadLetter.ID,
+				Error: fmt.Sprintf("subscription %d not exist", deadLetter.SubscriptionID),
+			})
+			continue
+		}
+		if err := s.redeliverDeadLetter(deadLetter); err != nil {
+			blog.Errorf("redeliver dead letter %d failed, err: %v, rid: %s", deadLetter.ID, err, rid)
+			result.Failed = append(result.Failed, metadata.DeadLetterRedeliverFail{ID: deadLetter.ID, Error: err.Error()})
+			continue
+		}
+		result.Redelivered = append(result.Redelivered, deadLetter.ID)
+	}
+	for _, id := range data.IDs {
+		if !found[id] {
+			found[id] = true
+			result.Failed = append(result.Failed, metadata.DeadLetterRedeliverFail{ID: id, Error: "dead letter not exist"})
+		}
+	}
+
+	resp.WriteEntity(metadata.NewSuccessResp(result))
+}
+
+// redeliverDeadLetter claims the dead letter and removes it before it's queued, so that it's never queued twice
+// by the concurrent or the repeated redeliveries. the dead letter is saved back if it failed to be queued.
+func (s *Service) redeliverDeadLetter(deadLetter *metadata.EventDeadLetter) error {
+	dist := metadata.DistInst{}
+	if err := json.Unmarshal([]byte(deadLetter.Payload), &dist); err != nil {
+		return fmt.Errorf("unmarshal payload failed, err: %v", err)
+	}
+
+	claimKey := types.EventCacheDeadLetterClaimPrefix + strconv.FormatInt(deadLetter.ID, 10)
+	claimed, err := s.cache.SetNX(claimKey, time.Now().UTC().Format(time.RFC3339), deadLetterClaimTimeout).Result()
+	if err != nil {
+		return fmt.Errorf("claim dead letter failed, err: %v", err)
+	}
+	if !claimed {
+		return errors.New("dead letter is being redelivered")
+	}
+	defer func() {
+		if err := s.cache.Del(claimKey).Err(); err != nil {
+			blog.Errorf("release the claim of dead letter %d failed, err: %v", deadLetter.ID, err)
+		}
+	}()
+
+	condition := map[string]interface{}{common.BKFieldID: deadLetter.ID}
+	if err := s.db.Table(common.BKTableNameEventDeadLetter).Delete(s.ctx, condition); err != nil {
+		return fmt.Errorf("remove dead letter failed, err: %v", err)
+	}
+
+	if err := s.queueDeadLetter(deadLetter, &dist); err != nil {
+		if saveErr := s.db.Table(common.BKTableNameEventDeadLetter).Insert(s.ctx, deadLetter); saveErr != nil {
+			blog.Errorf("save back dead letter %d failed, err: %v, dead letter: %+v", deadLetter.ID, saveErr, deadLetter)
+		}
+		return err
+	}
+	return nil
+}
+
+// queueDeadLetter push the dist of the dead letter to the distribution queue of its subscriber as a new one,
+// so that it's sent after those already in the queue
+func (s *Service) queueDeadLetter(deadLetter *metadata.EventDeadLetter, dist *metadata.DistInst) error {
+	subID := strconv.FormatInt(deadLetter.SubscriptionID, 10)
+	dstbID, err := s.cache.Incr(types.EventCacheDistIDPrefix + subID).Result()
+	if err != nil {
+		return fmt.Errorf("generate distribution id failed, err: %v", err)
+	}
+	dist.DstbID = dstbID
+	dist.SubscriptionID = deadLetter.SubscriptionID
+	distByte, err := json.Marshal(dist)
+	if err != nil {
+		return err
+	}
+	if err := s.cache.RPush(types.EventCacheDistQueuePrefix+subID, string(distByte)).Err(); err != nil {
+		return fmt.Errorf("push to distribution queue failed, err: %v", err)
+	}
+	return nil
+}
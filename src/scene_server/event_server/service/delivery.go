@@ -0,0 +1,94 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+	"configcenter/src/scene_server/event_server/types"
+
+	"github.com/emicklei/go-restful"
+)
+
+// SearchDeliveryLogs search the latest delivery logs of a subscription, newest first
+func (s *Service) SearchDeliveryLogs(req *restful.Request, resp *restful.Response) {
+	header := req.Request.Header
+	rid := util.GetHTTPCCRequestID(header)
+	defErr := s.CCErr.CreateDefaultCCErrorIf(util.GetLanguage(header))
+	ownerID := util.GetOwnerID(header)
+
+	data := metadata.ParamDeliveryLogSearch{}
+	if err := json.NewDecoder(req.Request.Body).Decode(&data); err != nil {
+		blog.Errorf("search delivery logs, but decode body failed, err: %v, rid: %s", err, rid)
+		resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Error(common.CCErrCommJSONUnmarshalFailed)})
+		return
+	}
+	if data.SubscriptionID <= 0 {
+		resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Errorf(common.CCErrCommParamsNeedSet, common.BKSubscriptionIDField)})
+		return
+	}
+
+	// the logs are kept in cache without owner, so check the subscription belongs to the owner
+	condition := util.NewMapBuilder(common.BKSubscriptionIDField, data.SubscriptionID, common.BKOwnerIDField, ownerID).Build()
+	count, err := s.db.Table(common.BKTableNameSubscription).Find(condition).Count(s.ctx)
+	if err != nil {
+		blog.Errorf("get subscription %d failed, err: %v, rid: %s", data.SubscriptionID, err, rid)
+		resp.WriteError(http.StatusInternalServerError, &metadata.RespError{Msg: defErr.Error(common.CCErrEventSubscribeSelectFailed)})
+		return
+	}
+	if count == 0 {
+		resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Error(common.CCErrCommNotFound)})
+		return
+	}
+
+	key := types.EventCacheDeliveryLogPrefix + strconv.FormatInt(data.SubscriptionID, 10)
+	rawLogs, err := s.cache.LRange(key, 0, -1).Result()
+	if err != nil {
+		blog.Errorf("get delivery logs of subscription %d failed, err: %v, rid: %s", data.SubscriptionID, err, rid)
+		resp.WriteError(http.StatusInternalServerError, &metadata.RespError{Msg: defErr.Error(common.CCErrEventDeliveryLogSelectFailed)})
+		return
+	}
+
+	matched := make([]metadata.EventDeliveryLog, 0)
+	for _, raw := range rawLogs {
+		log := metadata.EventDeliveryLog{}
+		if err := json.Unmarshal([]byte(raw), &log); err != nil {
+			blog.Errorf("unmarshal delivery log failed, err: %v, log: %s, rid: %s", err, raw, rid)
+			continue
+		}
+		if data.Match(&log) {
+			matched = append(matched, log)
+		}
+	}
+
+	result := metadata.RspDeliveryLogSearch{Count: uint64(len(matched)), Info: make([]metadata.EventDeliveryLog, 0)}
+	start := data.Page.Start
+	if start < 0 {
+		start = 0
+	}
+	if start < len(matched) {
+		end := len(matched)
+		if data.Page.Limit > 0 && start+data.Page.Limit < end {
+			end = start + data.Page.Limit
+		}
+		result.Info = matched[start:end]
+	}
+
+	resp.WriteEntity(metadata.NewSuccessResp(result))
+}
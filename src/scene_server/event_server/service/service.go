@@ -16,6 +16,7 @@ import (
 	"context"
 
 	"configcenter/src/auth"
+	"configcenter/src/auth/extensions"
 	"configcenter/src/common"
 	"configcenter/src/common/backbone"
 	"configcenter/src/common/errors"
@@ -24,17 +25,19 @@ import (
 	"configcenter/src/common/rdapi"
 	"configcenter/src/common/types"
 	"configcenter/src/storage/dal"
+	"configcenter/src/storage/dal/redis"
 
 	"github.com/emicklei/go-restful"
-	"gopkg.in/redis.v5"
 )
 
 type Service struct {
 	*backbone.Engine
-	db    dal.RDB
-	cache *redis.Client
-	auth  auth.Authorize
-	ctx   context.Context
+	db          dal.RDB
+	cache       redis.Client
+	auth        auth.Authorize
+	authManager *extensions.AuthManager
+	ctx         context.Context
+	secretKey   string
 }
 
 func NewService(ctx context.Context) *Service {
@@ -47,12 +50,17 @@ func (s *Service) SetDB(db dal.RDB) {
 	s.db = db
 }
 
-func (s *Service) SetCache(db *redis.Client) {
+func (s *Service) SetCache(db redis.Client) {
 	s.cache = db
 }
 
 func (s *Service) SetAuth(auth auth.Authorize) {
 	s.auth = auth
+	s.authManager = extensions.NewAuthManager(s.Engine.CoreAPI, auth)
+}
+
+func (s *Service) SetSecretKey(key string) {
+	s.secretKey = key
 }
 
 func (s *Service) WebService() *restful.Container {
@@ -72,6 +80,17 @@ func (s *Service) WebService() *restful.Container {
 	api.Route(api.POST("/subscribe/{ownerID}/{appID}").To(s.Subscribe))
 	api.Route(api.DELETE("/subscribe/{ownerID}/{appID}/{subscribeID}").To(s.UnSubscribe))
 	api.Route(api.PUT("/subscribe/{ownerID}/{appID}/{subscribeID}").To(s.UpdateSubscription))
+	api.Route(api.POST("/subscribe/{ownerID}/{appID}/{subscribeID}/pause").To(s.PauseSubscription))
+	api.Route(api.POST("/subscribe/{ownerID}/{appID}/{subscribeID}/resume").To(s.ResumeSubscription))
+
+	api.Route(api.POST("/deadletter/search/{ownerID}/{appID}").To(s.ListDeadLetters))
+	api.Route(api.GET("/deadletter/{ownerID}/{appID}/{deadLetterID}").To(s.GetDeadLetter))
+	api.Route(api.POST("/deadletter/redeliver/{ownerID}/{appID}").To(s.RedeliverDeadLetters))
+
+	api.Route(api.POST("/delivery/search/{ownerID}/{appID}").To(s.SearchDeliveryLogs))
+
+	api.Route(api.POST("/watch/{ownerID}/{appID}").To(s.WatchEvents))
+	api.Route(api.GET("/stream/{ownerID}/{appID}").To(s.StreamEvents).Produces(eventStreamMIME, restful.MIME_JSON))
 
 	api.Route(api.POST("/subscribe/ping").To(s.Ping))
 	api.Route(api.POST("/subscribe/telnet").To(s.Telnet))
@@ -0,0 +1,91 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+	"configcenter/src/scene_server/event_server/types"
+
+	"github.com/emicklei/go-restful"
+)
+
+// PauseSubscription stop sending events to the subscriber, the events are buffered until it's resumed
+func (s *Service) PauseSubscription(req *restful.Request, resp *restful.Response) {
+	s.changeSubscriptionStatus(req, resp, metadata.SubscriptionStatusPaused)
+}
+
+// ResumeSubscription resume a paused or suspended subscription, the buffered events are sent in order
+func (s *Service) ResumeSubscription(req *restful.Request, resp *restful.Response) {
+	s.changeSubscriptionStatus(req, resp, metadata.SubscriptionStatusActive)
+}
+
+func (s *Service) changeSubscriptionStatus(req *restful.Request, resp *restful.Response, status string) {
+	header := req.Request.Header
+	rid := util.GetHTTPCCRequestID(header)
+	defErr := s.CCErr.CreateDefaultCCErrorIf(util.GetLanguage(header))
+	ownerID := util.GetOwnerID(header)
+
+	id, err := strconv.ParseInt(req.PathParameter("subscribeID"), 10, 64)
+	if err != nil {
+		resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Errorf(common.CCErrCommParamsInvalid, "subscribeID")})
+		return
+	}
+
+	sub := metadata.Subscription{}
+	condition := util.NewMapBuilder(common.BKSubscriptionIDField, id, common.BKOwnerIDField, ownerID).Build()
+	if err := s.db.Table(common.BKTableNameSubscription).Find(condition).One(s.ctx, &sub); err != nil {
+		blog.Errorf("get subscription %d failed, err: %v, rid: %s", id, err, rid)
+		if s.db.IsNotFoundError(err) {
+			resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Error(common.CCErrCommNotFound)})
+			return
+		}
+		resp.WriteError(http.StatusInternalServerError, &metadata.RespError{Msg: defErr.Error(common.CCErrEventSubscribeSelectFailed)})
+		return
+	}
+	if sub.Status == status || (sub.IsActive() && status == metadata.SubscriptionStatusActive) {
+		resp.WriteEntity(metadata.NewSuccessResp(nil))
+		return
+	}
+
+	sub.Status = status
+	sub.LastTime = metadata.Now()
+	sub.Operator = util.GetUser(header)
+	data := map[string]interface{}{
+		"status":             sub.Status,
+		common.LastTimeField: sub.LastTime,
+		"operator":           sub.Operator,
+	}
+	if err := s.db.Table(common.BKTableNameSubscription).Update(s.ctx, condition, data); err != nil {
+		blog.Errorf("change status of subscription %d to %s failed, err: %v, rid: %s", id, status, err, rid)
+		resp.WriteError(http.StatusInternalServerError, &metadata.RespError{Msg: defErr.Error(common.CCErrEventSubscribeUpdateFailed)})
+		return
+	}
+
+	// the subscription starts counting the failures from scratch after resumed
+	if status == metadata.SubscriptionStatusActive {
+		s.cache.HDel(types.EventCacheDistCallBackCountPrefix+strconv.FormatInt(id, 10), types.EventCallBackConsecutiveFailureField)
+	}
+	msg, _ := json.Marshal(&sub)
+	if err := s.cache.Publish(types.EventCacheProcessChannel, "update"+string(msg)).Err(); err != nil {
+		blog.Errorf("publish status change of subscription %d failed, err: %v, rid: %s", id, err, rid)
+	}
+
+	resp.WriteEntity(metadata.NewSuccessResp(nil))
+}
@@ -0,0 +1,165 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"configcenter/src/auth/meta"
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+
+	"github.com/emicklei/go-restful"
+)
+
+const (
+	eventStreamMIME = "text/event-stream"
+	// lastEventIDHeader is sent by the sse client when it reconnects
+	lastEventIDHeader = "Last-Event-ID"
+)
+
+// streamHeartbeatInterval is the interval to send a comment line to keep the stream alive
+var streamHeartbeatInterval = 15 * time.Second
+
+// StreamEvents push the events to the client as server-sent events, each event is sent with its watch sequence,
+// so that the client could resume from the last event it received with the Last-Event-ID header after reconnecting.
+// the events could be filtered by the query parameters event_types, resources and bk_biz_id, the user must be
+// authorized to find the business to filter by it. a host event is matched by the businesses the host belongs to
+// when the event is saved, the host deleted from a business is matched by none.
+func (s *Service) StreamEvents(req *restful.Request, resp *restful.Response) {
+	header := req.Request.Header
+	rid := util.GetHTTPCCRequestID(header)
+	defErr := s.CCErr.CreateDefaultCCErrorIf(util.GetLanguage(header))
+	ownerID := util.GetOwnerID(header)
+
+	var cursor int64
+	lastEventID := header.Get(lastEventIDHeader)
+	if lastEventID == "" {
+		lastEventID = req.QueryParameter("last_event_id")
+	}
+	if lastEventID != "" {
+		var err error
+		cursor, err = strconv.ParseInt(lastEventID, 10, 64)
+		if err != nil || cursor < 0 {
+			resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Errorf(common.CCErrCommParamsInvalid, lastEventIDHeader)})
+			return
+		}
+	}
+
+	condition := map[string]interface{}{
+		common.BKOwnerIDField: ownerID,
+	}
+	if eventTypes := splitQueryParameter(req.QueryParameter("event_types")); len(eventTypes) > 0 {
+		condition["event_type"] = map[string]interface{}{common.BKDBIN: eventTypes}
+	}
+	if resources := splitQueryParameter(req.QueryParameter("resources")); len(resources) > 0 {
+		condition["obj_type"] = map[string]interface{}{common.BKDBIN: resources}
+	}
+	if bizID := req.QueryParameter(common.BKAppIDField); bizID != "" {
+		id, err := strconv.ParseInt(bizID, 10, 64)
+		if err != nil {
+			resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Errorf(common.CCErrCommParamsInvalid, common.BKAppIDField)})
+			return
+		}
+		if err := s.authManager.AuthorizeByBusinessID(s.ctx, header, meta.Find, id); err != nil {
+			blog.Errorf("stream events of business %d, but authorize failed, err: %v, rid: %s", id, err, rid)
+			resp.WriteError(http.StatusForbidden, &metadata.RespError{Msg: defErr.Error(common.CCErrCommAuthorizeFailed)})
+			return
+		}
+		condition[common.BKAppIDField] = id
+	}
+
+	if cursor > 0 {
+		expired, err := s.isWatchCursorExpired(cursor)
+		if err != nil {
+			blog.Errorf("check stream cursor %d failed, err: %v, rid: %s", cursor, err, rid)
+			resp.WriteError(http.StatusInternalServerError, &metadata.RespError{Msg: defErr.Error(common.CCErrEventWatchFailed)})
+			return
+		}
+		if expired {
+			blog.Errorf("stream events, but the events after cursor %d have expired, rid: %s", cursor, rid)
+			resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Error(common.CCErrEventWatchCursorExpired)})
+			return
+		}
+	}
+
+	flusher, ok := util.GetHTTPFlusher(resp.ResponseWriter)
+	if !ok {
+		blog.Errorf("stream events, but the response writer does not support flushing, rid: %s", rid)
+		resp.WriteError(http.StatusInternalServerError, &metadata.RespError{Msg: defErr.Error(common.CCErrEventWatchFailed)})
+		return
+	}
+	resp.Header().Set("Content-Type", eventStreamMIME)
+	resp.Header().Set("Cache-Control", "no-cache")
+	resp.Header().Set("Connection", "keep-alive")
+	resp.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	blog.Infof("start streaming events after %d, condition: %+v, rid: %s", cursor, condition, rid)
+	heartbeat := time.NewTicker(streamHeartbeatInterval)
+	defer heartbeat.Stop()
+	poll := time.NewTicker(watchPollInterval)
+	defer poll.Stop()
+	for {
+		select {
+		case <-req.Request.Context().Done():
+			blog.Infof("stream closed by the client, last event id: %d, rid: %s", cursor, rid)
+			return
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(resp, ": ping\n\n"); err != nil {
+				blog.Errorf("write heartbeat to stream failed, err: %v, rid: %s", err, rid)
+				return
+			}
+			flusher.Flush()
+		case <-poll.C:
+			condition["sequence"] = map[string]interface{}{common.BKDBGT: cursor}
+			records := make([]metadata.EventWatchRecord, 0)
+			if err := s.db.Table(common.BKTableNameEventWatch).Find(condition).Sort("sequence").
+				Limit(metadata.EventWatchMaxLimit).All(s.ctx, &records); err != nil {
+				blog.Errorf("find events to stream failed, condition: %+v, err: %v, rid: %s", condition, err, rid)
+				continue
+			}
+			if len(records) == 0 {
+				continue
+			}
+
+			for _, record := range records {
+				// the event is written as it is saved, which is the json of metadata.EventInst
+				if _, err := fmt.Fprintf(resp, "id: %d\nevent: %s\ndata: %s\n\n", record.Sequence, record.EventType,
+					record.Event); err != nil {
+					blog.Errorf("write event %d to stream failed, err: %v, rid: %s", record.EventID, err, rid)
+					return
+				}
+				cursor = record.Sequence
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// splitQueryParameter splits a comma separated query parameter
+func splitQueryParameter(param string) []string {
+	values := make([]string, 0)
+	for _, value := range strings.Split(param, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
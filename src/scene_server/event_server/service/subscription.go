@@ -22,6 +22,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"configcenter/src/auth/meta"
 	"configcenter/src/common"
@@ -29,6 +30,7 @@ import (
 	"configcenter/src/common/metadata"
 	"configcenter/src/common/util"
 	"configcenter/src/scene_server/event_server/types"
+	ccredis "configcenter/src/storage/dal/redis"
 
 	"github.com/emicklei/go-restful"
 )
@@ -70,9 +72,41 @@ func (s *Service) Subscribe(req *restful.Request, resp *restful.Response) {
 	if sub.ConfirmMode == metadata.ConfirmModeHTTPStatus && sub.ConfirmPattern == "" {
 		sub.ConfirmPattern = strconv.FormatInt(http.StatusOK, 10)
 	}
+	if sub.RetryPolicy != nil {
+		if field, err := sub.RetryPolicy.Validate(); err != nil {
+			blog.Errorf("invalid retry policy, err: %v, rid: %s", err, rid)
+			resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Errorf(common.CCErrCommParamsInvalid, "retry_policy."+field)})
+			return
+		}
+	}
+	if sub.BatchPolicy != nil {
+		if field, err := sub.BatchPolicy.Validate(); err != nil {
+			blog.Errorf("invalid batch policy, err: %v, rid: %s", err, rid)
+			resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Errorf(common.CCErrCommParamsInvalid, "batch_policy."+field)})
+			return
+		}
+	}
+	if sub.PayloadOption != nil {
+		if field, err := sub.PayloadOption.Validate(); err != nil {
+			blog.Errorf("invalid payload option, err: %v, rid: %s", err, rid)
+			resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Errorf(common.CCErrCommParamsInvalid, "payload_option."+field)})
+			return
+		}
+	}
+	if field, err := validateFilter(sub); err != nil {
+		blog.Errorf("invalid subscription filter, err: %v, rid: %s", err, rid)
+		resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Errorf(common.CCErrCommParamsInvalid, "filter."+field)})
+		return
+	}
 	now := metadata.Now()
 	sub.LastTime = now
 	sub.OwnerID = ownerID
+	sub.Status = metadata.SubscriptionStatusActive
+	if err := s.prepareSecret(sub, nil); err != nil {
+		blog.Errorf("create subscription, but prepare secret failed, err: %v, rid: %s", err, rid)
+		resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Error(common.CCErrEventSubscribeSecretUnavailable)})
+		return
+	}
 
 	eventTypesStr := strings.Replace(sub.SubscriptionForm, " ", "", -1)
 	eventTypes := strings.Split(eventTypesStr, ",")
@@ -132,6 +166,12 @@ func (s *Service) Subscribe(req *restful.Request, resp *restful.Response) {
 			return
 		}
 	}
+	if err := s.saveFilterCache(sub); err != nil {
+		blog.Errorf("create subscription failed, save filter to cache failed, error:%s, rid: %s", err.Error(), rid)
+		result := &metadata.RespError{Msg: defErr.Error(common.CCErrEventSubscribeInsertFailed)}
+		resp.WriteError(http.StatusInternalServerError, result)
+		return
+	}
 	msg, _ := json.Marshal(&sub)
 	s.cache.Publish(types.EventCacheProcessChannel, "create"+string(msg))
 	s.cache.Del(types.EventCacheDistCallBackCountPrefix + strconv.FormatInt(sub.SubscriptionID, 10))
@@ -215,9 +255,16 @@ func (s *Service) UnSubscribe(req *restful.Request, resp *restful.Response) {
 		}
 	}
 
-	s.cache.Del(types.EventCacheDistIDPrefix+subID,
+	ccredis.Del(s.cache, types.EventCacheDistIDPrefix+subID,
 		types.EventCacheDistQueuePrefix+subID,
-		types.EventCacheDistDonePrefix+subID)
+		types.EventCacheDistDonePrefix+subID,
+		types.EventCacheDeliveryLogPrefix+subID)
+	s.cache.HDel(types.EventCacheSubscribeFilterKey, subID)
+
+	deadLetterCond := map[string]interface{}{common.BKSubscriptionIDField: id, common.BKOwnerIDField: ownerID}
+	if err := s.db.Table(common.BKTableNameEventDeadLetter).Delete(s.ctx, deadLetterCond); err != nil {
+		blog.Errorf("delete dead letters of subscription %d failed, err: %v, rid: %s", id, err, rid)
+	}
 
 	msg, _ := json.Marshal(&sub)
 	s.cache.Publish(types.EventCacheProcessChannel, "delete"+string(msg))
@@ -282,6 +329,42 @@ func (s *Service) UpdateSubscription(req *restful.Request, resp *restful.Respons
 	if sub.ConfirmMode == metadata.ConfirmModeHTTPStatus && sub.ConfirmPattern == "" {
 		sub.ConfirmPattern = strconv.FormatInt(http.StatusOK, 10)
 	}
+	if sub.RetryPolicy != nil {
+		if field, err := sub.RetryPolicy.Validate(); err != nil {
+			blog.Errorf("invalid retry policy, err: %v, rid: %s", err, rid)
+			resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Errorf(common.CCErrCommParamsInvalid, "retry_policy."+field)})
+			return
+		}
+	}
+	if sub.BatchPolicy != nil {
+		if field, err := sub.BatchPolicy.Validate(); err != nil {
+			blog.Errorf("invalid batch policy, err: %v, rid: %s", err, rid)
+			resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Errorf(common.CCErrCommParamsInvalid, "batch_policy."+field)})
+			return
+		}
+	}
+	if sub.PayloadOption != nil {
+		if field, err := sub.PayloadOption.Validate(); err != nil {
+			blog.Errorf("invalid payload option, err: %v, rid: %s", err, rid)
+			resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Errorf(common.CCErrCommParamsInvalid, "payload_option."+field)})
+			return
+		}
+	}
+	if field, err := validateFilter(sub); err != nil {
+		blog.Errorf("invalid subscription filter, err: %v, rid: %s", err, rid)
+		resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Errorf(common.CCErrCommParamsInvalid, "filter."+field)})
+		return
+	}
+	if sub.ClearSecret && len(sub.Secret) != 0 {
+		blog.Errorf("secret and clear_secret are both set, rid: %s", rid)
+		resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Errorf(common.CCErrCommParamsInvalid, "clear_secret")})
+		return
+	}
+	if sub.SecretOverlapSeconds < 0 || sub.SecretOverlapSeconds > metadata.SecretOverlapSecondsLimit {
+		blog.Errorf("invalid secret_overlap_seconds %d, rid: %s", sub.SecretOverlapSeconds, rid)
+		resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Errorf(common.CCErrCommParamsInvalid, "secret_overlap_seconds")})
+		return
+	}
 	sub.Operator = util.GetUser(req.Request.Header)
 	if err = s.updateSubscription(header, id, ownerID, sub); err != nil {
 		result := &metadata.RespError{
@@ -341,6 +424,12 @@ func (s *Service) updateSubscription(header http.Header, id int64, ownerID strin
 	now := metadata.Now()
 	sub.LastTime = now
 	sub.OwnerID = ownerID
+	// the status could only be changed by pausing or resuming the subscription
+	sub.Status = oldSub.Status
+	if err := s.prepareSecret(sub, &oldSub); err != nil {
+		blog.Errorf("prepare subscription secret failed, err: %v, rid: %s", err, rid)
+		return err
+	}
 
 	sub.SubscriptionForm = strings.Replace(sub.SubscriptionForm, " ", "", -1)
 	events := strings.Split(sub.SubscriptionForm, ",")
@@ -375,6 +464,11 @@ func (s *Service) updateSubscription(header http.Header, id int64, ownerID strin
 		}
 	}
 
+	if err := s.saveFilterCache(sub); err != nil {
+		blog.Errorf("save subscription filter to cache failed, error:%s, rid: %s", err.Error(), rid)
+		return err
+	}
+
 	mesg, err := json.Marshal(&sub)
 	if err != nil {
 		return err
@@ -382,6 +476,68 @@ func (s *Service) updateSubscription(header http.Header, id int64, ownerID strin
 	return s.cache.Publish(types.EventCacheProcessChannel, "update"+string(mesg)).Err()
 }
 
+// validateFilter validate the filter of the subscription, returns the invalid field if any
+func validateFilter(sub *metadata.Subscription) (string, error) {
+	rule, field, err := sub.ParseFilter()
+	if err != nil {
+		return field, err
+	}
+	if rule == nil {
+		return "", nil
+	}
+	return rule.Validate()
+}
+
+// saveFilterCache save the filter of the subscription to cache, so that the event handler
+// could find out the events should be sent to the subscriber.
+func (s *Service) saveFilterCache(sub *metadata.Subscription) error {
+	subID := strconv.FormatInt(sub.SubscriptionID, 10)
+	if len(sub.Filter) == 0 {
+		return s.cache.HDel(types.EventCacheSubscribeFilterKey, subID).Err()
+	}
+	filter, err := json.Marshal(sub.Filter)
+	if err != nil {
+		return err
+	}
+	return s.cache.HSet(types.EventCacheSubscribeFilterKey, subID, string(filter)).Err()
+}
+
+// prepareSecret encrypt the secret of the subscription before it's saved. when updating, an empty
+// secret keeps the old one, and a new secret rotates the old one, which is kept valid for
+// SecretOverlapSeconds so that the subscriber could switch to the new secret smoothly.
+// ClearSecret removes the secrets, the callbacks are not signed any more.
+func (s *Service) prepareSecret(sub *metadata.Subscription, oldSub *metadata.Subscription) error {
+	sub.PreviousSecret = ""
+	sub.PreviousSecretExpireTime = metadata.Time{}
+	if sub.ClearSecret {
+		sub.Secret = ""
+		return nil
+	}
+	if len(sub.Secret) == 0 {
+		if oldSub != nil {
+			sub.Secret = oldSub.Secret
+			sub.PreviousSecret = oldSub.PreviousSecret
+			sub.PreviousSecretExpireTime = oldSub.PreviousSecretExpireTime
+		}
+		return nil
+	}
+
+	if len(s.secretKey) == 0 {
+		return fmt.Errorf("event.secretKey is not configured")
+	}
+	encrypted, err := util.EncryptAES(s.secretKey, sub.Secret)
+	if err != nil {
+		return err
+	}
+	sub.Secret = encrypted
+
+	if oldSub != nil && len(oldSub.Secret) != 0 && sub.SecretOverlapSeconds > 0 {
+		sub.PreviousSecret = oldSub.Secret
+		sub.PreviousSecretExpireTime = metadata.Time{Time: time.Now().UTC().Add(time.Duration(sub.SecretOverlapSeconds) * time.Second)}
+	}
+	return nil
+}
+
 func (s *Service) ListSubscriptions(req *restful.Request, resp *restful.Response) {
 	header := req.Request.Header
 	rid := util.GetHTTPCCRequestID(header)
@@ -446,6 +602,10 @@ func (s *Service) ListSubscriptions(req *restful.Request, resp *restful.Response
 			Total:   total,
 			Failure: failure,
 		}
+		results[index].HideSecrets()
+		if results[index].Status == "" {
+			results[index].Status = metadata.SubscriptionStatusActive
+		}
 	}
 
 	info := make(map[string]interface{})
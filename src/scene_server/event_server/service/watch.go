@@ -0,0 +1,131 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+
+	"github.com/emicklei/go-restful"
+)
+
+// watchPollInterval is the interval to check the new events while watching
+var watchPollInterval = time.Second
+
+// WatchEvents returns the events after the cursor, if there is no such event yet, the request is held
+// until new events come or the timeout is reached, then the watcher should watch again with the returned cursor.
+func (s *Service) WatchEvents(req *restful.Request, resp *restful.Response) {
+	header := req.Request.Header
+	rid := util.GetHTTPCCRequestID(header)
+	defErr := s.CCErr.CreateDefaultCCErrorIf(util.GetLanguage(header))
+	ownerID := util.GetOwnerID(header)
+
+	data := metadata.ParamEventWatch{}
+	if err := json.NewDecoder(req.Request.Body).Decode(&data); err != nil {
+		blog.Errorf("watch events, but decode body failed, err: %v, rid: %s", err, rid)
+		resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Error(common.CCErrCommJSONUnmarshalFailed)})
+		return
+	}
+	if data.Cursor < 0 {
+		resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Errorf(common.CCErrCommParamsInvalid, "cursor")})
+		return
+	}
+	if data.Limit <= 0 {
+		data.Limit = metadata.EventWatchDefaultLimit
+	}
+	if data.Limit > metadata.EventWatchMaxLimit {
+		resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Errorf(common.CCErrCommPageLimitIsExceeded)})
+		return
+	}
+	if data.TimeoutSeconds <= 0 {
+		data.TimeoutSeconds = metadata.EventWatchDefaultTimeoutSeconds
+	}
+	if data.TimeoutSeconds > metadata.EventWatchMaxTimeoutSeconds {
+		data.TimeoutSeconds = metadata.EventWatchMaxTimeoutSeconds
+	}
+
+	if data.Cursor > 0 {
+		expired, err := s.isWatchCursorExpired(data.Cursor)
+		if err != nil {
+			blog.Errorf("check watch cursor %d failed, err: %v, rid: %s", data.Cursor, err, rid)
+			resp.WriteError(http.StatusInternalServerError, &metadata.RespError{Msg: defErr.Error(common.CCErrEventWatchFailed)})
+			return
+		}
+		if expired {
+			blog.Errorf("watch events, but the events after cursor %d have expired, rid: %s", data.Cursor, rid)
+			resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Error(common.CCErrEventWatchCursorExpired)})
+			return
+		}
+	}
+
+	condition := map[string]interface{}{
+		common.BKOwnerIDField: ownerID,
+		"sequence":            map[string]interface{}{common.BKDBGT: data.Cursor},
+	}
+	if len(data.Resources) > 0 {
+		condition["obj_type"] = map[string]interface{}{common.BKDBIN: data.Resources}
+	}
+
+	deadline := time.Now().Add(time.Duration(data.TimeoutSeconds) * time.Second)
+	records := make([]metadata.EventWatchRecord, 0)
+	for {
+		if err := s.db.Table(common.BKTableNameEventWatch).Find(condition).Sort("sequence").
+			Limit(uint64(data.Limit)).All(s.ctx, &records); err != nil {
+			blog.Errorf("watch events failed, condition: %+v, err: %v, rid: %s", condition, err, rid)
+			resp.WriteError(http.StatusInternalServerError, &metadata.RespError{Msg: defErr.Error(common.CCErrEventWatchFailed)})
+			return
+		}
+		if len(records) > 0 || !time.Now().Add(watchPollInterval).Before(deadline) {
+			break
+		}
+
+		select {
+		case <-req.Request.Context().Done():
+			blog.Infof("watcher closed the request, cursor: %d, rid: %s", data.Cursor, rid)
+			return
+		case <-time.After(watchPollInterval):
+		}
+	}
+
+	result := metadata.RspEventWatch{Cursor: data.Cursor, Events: make([]metadata.EventInst, 0)}
+	for _, record := range records {
+		event := metadata.EventInst{}
+		if err := json.Unmarshal([]byte(record.Event), &event); err != nil {
+			blog.Errorf("unmarshal event %d failed, err: %v, event: %s, rid: %s", record.EventID, err, record.Event, rid)
+			resp.WriteError(http.StatusInternalServerError, &metadata.RespError{Msg: defErr.Error(common.CCErrEventWatchFailed)})
+			return
+		}
+		result.Events = append(result.Events, event)
+		result.Cursor = record.Sequence
+	}
+
+	resp.WriteEntity(metadata.NewSuccessResp(result))
+}
+
+// isWatchCursorExpired checks whether the events after the cursor may have been removed by the ttl index.
+// a cursor is always the sequence of a saved record, the sequences are not continuous because the records are
+// filtered by the watchers, so the cursor is valid as long as its own record is still kept, the records expire
+// in the order they are saved, so the records after it are kept too.
+func (s *Service) isWatchCursorExpired(cursor int64) (bool, error) {
+	count, err := s.db.Table(common.BKTableNameEventWatch).Find(map[string]interface{}{"sequence": cursor}).Count(s.ctx)
+	if err != nil {
+		return false, err
+	}
+	return count == 0, nil
+}
@@ -0,0 +1,55 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"testing"
+
+	"configcenter/src/common"
+	"configcenter/src/common/metadata"
+	"configcenter/src/storage/dal/mongo/memory"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsWatchCursorExpired(t *testing.T) {
+	s := &Service{db: memory.New(), ctx: context.Background()}
+
+	expired, err := s.isWatchCursorExpired(1)
+	require.NoError(t, err)
+	require.True(t, expired, "no record is kept")
+
+	// the sequences are not continuous for a watcher, the record 3 has expired
+	for _, sequence := range []int64{4, 6, 9} {
+		record := metadata.EventWatchRecord{Sequence: sequence, EventID: sequence * 10, OwnerID: "0"}
+		require.NoError(t, s.db.Table(common.BKTableNameEventWatch).Insert(s.ctx, record))
+	}
+
+	testCases := []struct {
+		cursor  int64
+		expired bool
+	}{
+		{cursor: 3, expired: true},
+		{cursor: 4, expired: false},
+		{cursor: 6, expired: false},
+		{cursor: 9, expired: false},
+		// a cursor never returned
+		{cursor: 10, expired: true},
+	}
+	for _, tc := range testCases {
+		expired, err := s.isWatchCursorExpired(tc.cursor)
+		require.NoError(t, err)
+		require.Equal(t, tc.expired, expired, "cursor %d", tc.cursor)
+	}
+}
@@ -21,8 +21,8 @@ const (
 
 	// EventCacheEventIDKey the event instance id key in cache
 	EventCacheEventIDKey             = common.BKCacheKeyV3Prefix + "event:inst_id"
-	EventCacheEventQueueKey          = common.BKCacheKeyV3Prefix + "event:inst_queue"
-	EventCacheEventQueueDuplicateKey = common.BKCacheKeyV3Prefix + "event:inst_queue_duplicate"
+	EventCacheEventQueueKey          = common.EventCacheEventQueueKey
+	EventCacheEventQueueDuplicateKey = common.BKCacheKeyV3Prefix + "{event}:inst_queue_duplicate"
 	EventCacheEventPendingKey        = common.BKCacheKeyV3Prefix + "event:inst_pending"
 	EventCacheEventRunningPrefix     = common.BKCacheKeyV3Prefix + "event:inst_running_"
 	EventCacheEventTimeoutKey        = common.BKCacheKeyV3Prefix + "event:inst_timeout"
@@ -36,11 +36,19 @@ const (
 	EventCacheDistDonePrefix    = common.BKCacheKeyV3Prefix + "event:dist_done_"
 
 	EventCacheDistCallBackCountPrefix = common.BKCacheKeyV3Prefix + "event:dist_callback_"
+	// EventCallBackConsecutiveFailureField is the field of the consecutive failed deliveries in the callback count hash
+	EventCallBackConsecutiveFailureField = "consecutive_failure"
+	// EventCacheDeliveryLogPrefix the list of the latest delivery logs of a subscription, newest first
+	EventCacheDeliveryLogPrefix = common.BKCacheKeyV3Prefix + "event:delivery_log_"
+	// EventCacheDeadLetterClaimPrefix the key a dead letter is claimed by while it's being redelivered
+	EventCacheDeadLetterClaimPrefix = common.BKCacheKeyV3Prefix + "event:dead_letter_claim_"
 
 	// EventCacheSubscribeFormKey the key prefix in cache
 	EventCacheSubscribeFormKey = common.BKCacheKeyV3Prefix + "event:subscribeform:"
 	EventCacheSubscribesKey    = common.BKCacheKeyV3Prefix + "event:subscribers"
 	EventCacheProcessChannel   = common.BKCacheKeyV3Prefix + "event_process_channel"
+	// EventCacheSubscribeFilterKey the hash of subscription id to its filter
+	EventCacheSubscribeFilterKey = common.BKCacheKeyV3Prefix + "event:subscribefilter"
 
 	EventCacheIdentInstPrefix = common.BKCacheKeyV3Prefix + "ident:inst_"
 )
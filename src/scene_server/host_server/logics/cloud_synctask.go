@@ -175,10 +175,8 @@ func (lgc *Logics) FrontEndSyncSwitch(ctx context.Context, opt map[string]interf
 // ListenRedisSubscribe subscribe redis channel to stop the started sync task
 func (lgc *Logics) ListenRedisSubscribe(ctx context.Context) {
 	var mutex = &sync.Mutex{}
-	newClient := *lgc.cache
-
 	for {
-		pub, err := newClient.Subscribe("stop")
+		pub, err := lgc.cache.Subscribe("stop")
 		if err != nil {
 			time.Sleep(5 * time.Second)
 			blog.Errorf("redis subscribe fail, err: %v, rid: %v", err, lgc.rid)
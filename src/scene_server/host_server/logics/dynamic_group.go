@@ -0,0 +1,116 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package logics
+
+import (
+	"context"
+	"strconv"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/mapstr"
+	meta "configcenter/src/common/metadata"
+)
+
+// SearchDynamicGroupInstances executes the dynamic group of the instances of a model other than host in the business,
+// the mainline instances of the business are searched, and so are the instances of the business and the ones of no
+// business for the other models.
+func (lgc *Logics) SearchDynamicGroupInstances(ctx context.Context, bizID int64, objID string, info *meta.DynamicGroupInfo, page meta.BasePage) (*meta.InstDataInfo, errors.CCError) {
+	isMainline := true
+	switch objID {
+	case common.BKInnerObjIDApp, common.BKInnerObjIDSet, common.BKInnerObjIDModule:
+	default:
+		var err errors.CCError
+		isMainline, err = lgc.isMainlineObject(ctx, objID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	cond := dynamicGroupBizCondition(bizID, objID, isMainline)
+
+	if len(info.AssociationFilters) > 0 {
+		option := &meta.SearchInstIDsByAsstFilterOption{BizID: bizID, Filters: info.AssociationFilters}
+		result, err := lgc.CoreAPI.CoreService().Association().SearchInstIDsByAsstFilter(ctx, lgc.header, objID, option)
+		if err != nil {
+			blog.Errorf("SearchDynamicGroupInstances search by association filters http do error, err:%s, objID:%s, input:%+v, rid:%s", err.Error(), objID, option, lgc.rid)
+			return nil, lgc.ccErr.Error(common.CCErrCommHTTPDoRequestFailed)
+		}
+		if !result.Result {
+			blog.Errorf("SearchDynamicGroupInstances search by association filters http response error, err code:%d, err msg:%s, objID:%s, input:%+v, rid:%s", result.Code, result.ErrMsg, objID, option, lgc.rid)
+			return nil, lgc.ccErr.New(result.Code, result.ErrMsg)
+		}
+		if len(result.Data) == 0 {
+			return &meta.InstDataInfo{Info: make([]mapstr.MapStr, 0)}, nil
+		}
+		cond[common.GetInstIDField(objID)] = mapstr.MapStr{common.BKDBIN: result.Data}
+	}
+
+	if page.Sort == "" {
+		page.Sort = common.GetInstIDField(objID)
+	}
+	query := &meta.QueryCondition{
+		Condition:      cond,
+		PropertyFilter: info.Condition,
+		Fields:         info.Fields,
+		Limit:          meta.SearchLimit{Offset: int64(page.Start), Limit: int64(page.Limit)},
+		SortArr:        page.ToSearchSort(),
+	}
+	result, err := lgc.CoreAPI.CoreService().Instance().ReadInstance(ctx, lgc.header, objID, query)
+	if err != nil {
+		blog.Errorf("SearchDynamicGroupInstances http do error, err:%s, objID:%s, input:%+v, rid:%s", err.Error(), objID, query, lgc.rid)
+		return nil, lgc.ccErr.Error(common.CCErrCommHTTPDoRequestFailed)
+	}
+	if !result.Result {
+		blog.Errorf("SearchDynamicGroupInstances http response error, err code:%d, err msg:%s, objID:%s, input:%+v, rid:%s", result.Code, result.ErrMsg, objID, query, lgc.rid)
+		return nil, lgc.ccErr.New(result.Code, result.ErrMsg)
+	}
+
+	return &result.Data, nil
+}
+
+// dynamicGroupBizCondition returns the condition of the instances of the object in the business, the mainline
+// instances belong to a business by bk_biz_id, while the other instances by the business label in metadata.
+func dynamicGroupBizCondition(bizID int64, objID string, isMainline bool) mapstr.MapStr {
+	cond := mapstr.MapStr{}
+	if isMainline {
+		cond[common.BKAppIDField] = bizID
+		return cond
+	}
+
+	cond[common.BKDBOR] = []mapstr.MapStr{
+		{meta.MetadataBizField: strconv.FormatInt(bizID, 10)},
+		{meta.MetadataBizField: mapstr.MapStr{common.BKDBExists: false}},
+	}
+	return cond
+}
+
+// isMainlineObject checks whether the object is a level of the business topology
+func (lgc *Logics) isMainlineObject(ctx context.Context, objID string) (bool, errors.CCError) {
+	query := &meta.QueryCondition{
+		Condition: mapstr.MapStr{
+			common.BKObjIDField:           objID,
+			common.AssociationKindIDField: common.AssociationKindMainline,
+		},
+	}
+	result, err := lgc.CoreAPI.CoreService().Association().ReadModelAssociation(ctx, lgc.header, query)
+	if err != nil {
+		blog.Errorf("isMainlineObject http do error, err:%s, objID:%s, rid:%s", err.Error(), objID, lgc.rid)
+		return false, lgc.ccErr.Error(common.CCErrCommHTTPDoRequestFailed)
+	}
+	if !result.Result {
+		blog.Errorf("isMainlineObject http response error, err code:%d, err msg:%s, objID:%s, rid:%s", result.Code, result.ErrMsg, objID, lgc.rid)
+		return false, lgc.ccErr.New(result.Code, result.ErrMsg)
+	}
+	return len(result.Data.Info) > 0, nil
+}
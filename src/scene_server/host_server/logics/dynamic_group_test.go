@@ -0,0 +1,37 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package logics
+
+import (
+	"testing"
+
+	"configcenter/src/common"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDynamicGroupBizCondition(t *testing.T) {
+	// the mainline instances are scoped by bk_biz_id, so that a custom level never matches other businesses
+	for _, objID := range []string{common.BKInnerObjIDApp, common.BKInnerObjIDSet, common.BKInnerObjIDModule, "idc"} {
+		require.Equal(t, mapstr.MapStr{common.BKAppIDField: int64(2)}, dynamicGroupBizCondition(2, objID, true), objID)
+	}
+
+	require.Equal(t, mapstr.MapStr{
+		common.BKDBOR: []mapstr.MapStr{
+			{metadata.MetadataBizField: "2"},
+			{metadata.MetadataBizField: mapstr.MapStr{common.BKDBExists: false}},
+		},
+	}, dynamicGroupBizCondition(2, "rack", false))
+}
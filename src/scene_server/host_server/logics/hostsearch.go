@@ -70,6 +70,8 @@ type searchHostModuleHostConfig struct {
 	moduleIDArr   []int64
 	setIDArr      []int64
 	asstHostIDArr []int64
+	// asstFilterHostIDArr is the ids of the hosts matching the association filters
+	asstFilterHostIDArr []int64
 }
 
 type searchHostIDArr struct {
@@ -521,6 +523,10 @@ func (sh *searchHost) searchByTopo() errors.CCError {
 	}
 	//Query host information based on associated objects, alternate code
 	//sh.searchByAssocation()
+	err = sh.searchByAssociationFilters()
+	if err != nil {
+		return err
+	}
 	err = sh.searchByPlatCondition()
 	if err != nil {
 		return err
@@ -672,6 +678,9 @@ func (sh *searchHost) searchByHostConds() errors.CCError {
 	if err != nil {
 		return err
 	}
+	if sh.noData {
+		return nil
+	}
 
 	if 0 != len(sh.conds.hostCond.Fields) {
 		sh.conds.hostCond.Fields = append(sh.conds.hostCond.Fields, common.BKHostIDField, common.BKCloudIDField)
@@ -740,6 +749,19 @@ func (sh *searchHost) appendHostTopoConds() errors.CCError {
 		moduleHostConfig.HostIDArr = sh.idArr.moduleHostConfig.asstHostIDArr
 		isAddHostID = true
 	}
+	if len(sh.hostSearchParam.AssociationFilters) > 0 {
+		if len(sh.conds.objectCondMap) > 0 {
+			moduleHostConfig.HostIDArr = util.IntArrIntersection(moduleHostConfig.HostIDArr, sh.idArr.moduleHostConfig.asstFilterHostIDArr)
+		} else {
+			moduleHostConfig.HostIDArr = sh.idArr.moduleHostConfig.asstFilterHostIDArr
+		}
+		// empty host id array means no limit on the hosts
+		if len(moduleHostConfig.HostIDArr) == 0 {
+			sh.noData = true
+			return nil
+		}
+		isAddHostID = true
+	}
 
 	var appIDArr []int64
 	if len(sh.conds.appCond.Condition) > 0 {
@@ -859,6 +881,34 @@ func (sh *searchHost) searchByAssociation() errors.CCError {
 
 }
 
+// searchByAssociationFilters search the hosts associated to the instances matching the association filters
+func (sh *searchHost) searchByAssociationFilters() errors.CCError {
+	if sh.noData || len(sh.hostSearchParam.AssociationFilters) == 0 {
+		return nil
+	}
+
+	option := &metadata.SearchInstIDsByAsstFilterOption{Filters: sh.hostSearchParam.AssociationFilters}
+	if sh.hostSearchParam.AppID > 0 {
+		option.BizID = sh.hostSearchParam.AppID
+	}
+	result, err := sh.lgc.CoreAPI.CoreService().Association().SearchInstIDsByAsstFilter(sh.ctx, sh.pheader, common.BKInnerObjIDHost, option)
+	if err != nil {
+		blog.Errorf("search host by association filters failed, err: %v, rid: %s", err, sh.ccRid)
+		return sh.ccErr.Error(common.CCErrCommHTTPDoRequestFailed)
+	}
+	if !result.Result {
+		blog.Errorf("search host by association filters failed, error code: %d, error message: %s, rid: %s", result.Code, result.ErrMsg, sh.ccRid)
+		return sh.ccErr.New(result.Code, result.ErrMsg)
+	}
+
+	if len(result.Data) == 0 {
+		sh.noData = true
+		return nil
+	}
+	sh.idArr.moduleHostConfig.asstFilterHostIDArr = result.Data
+	return nil
+}
+
 func (sh *searchHost) tryParseAppID() {
 	//search appID by cond
 	if -1 != sh.hostSearchParam.AppID && 0 != sh.hostSearchParam.AppID {
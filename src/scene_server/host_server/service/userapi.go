@@ -22,6 +22,7 @@ import (
 
 	"configcenter/src/common"
 	"configcenter/src/common/blog"
+	"configcenter/src/common/mapstr"
 	meta "configcenter/src/common/metadata"
 	parser "configcenter/src/common/paraparse"
 	"configcenter/src/common/util"
@@ -47,11 +48,14 @@ func (s *Service) AddUserCustomQuery(req *restful.Request, resp *restful.Respons
 		_ = resp.WriteError(http.StatusOK, &meta.RespError{Msg: srvData.ccErr.Errorf(common.CCErrCommParamsNeedSet, "info")})
 		return
 	}
+	// the dynamic group is a host group if the object is not set
+	if "" == ucq.ObjectID {
+		ucq.ObjectID = common.BKInnerObjIDHost
+	}
 	// check if the info string matches the required structure
-	err := json.Unmarshal([]byte(ucq.Info), &meta.HostCommonSearch{})
-	if err != nil {
-		blog.Errorf("AddUserCustomQuery info unmarshal failed, err: %v, input:%+v, rid:%s", err.Error(), ucq, srvData.rid)
-		_ = resp.WriteError(http.StatusOK, &meta.RespError{Msg: srvData.ccErr.Error(common.CCErrCommJSONUnmarshalFailed)})
+	if err := s.validateDynamicGroupInfo(srvData, ucq.ObjectID, ucq.Info); err != nil {
+		blog.Errorf("AddUserCustomQuery info is invalid, err: %v, input:%+v, rid:%s", err.Error(), ucq, srvData.rid)
+		_ = resp.WriteError(http.StatusOK, &meta.RespError{Msg: err})
 		return
 	}
 
@@ -97,21 +101,38 @@ func (s *Service) UpdateUserCustomQuery(req *restful.Request, resp *restful.Resp
 
 	params["modify_user"] = srvData.user
 	params[common.LastTimeField] = time.Now().UTC()
+	// the object of a dynamic group can not be changed
+	delete(params, common.BKObjIDField)
 
+	bizID := req.PathParameter("bk_biz_id")
 	if info, exists := params["info"]; exists {
 		info := info.(string)
 		if len(info) != 0 {
-			// check if the info string matches the required structure
-			err := json.Unmarshal([]byte(info), &meta.HostCommonSearch{})
+			detail, err := s.CoreAPI.CoreService().Host().GetUserConfigDetail(srvData.ctx, bizID, req.PathParameter("id"), srvData.header)
 			if err != nil {
-				blog.Errorf("UpdateUserCustomQuery info unmarshal failed, err: %v, input:%+v, rid:%s", err.Error(), params, srvData.rid)
-				_ = resp.WriteError(http.StatusOK, &meta.RespError{Msg: srvData.ccErr.Error(common.CCErrCommJSONUnmarshalFailed)})
+				blog.Errorf("UpdateUserCustomQuery get user custom query detail http do error,err:%s, biz:%v,input:%+v,rid:%s", err.Error(), bizID, params, srvData.rid)
+				_ = resp.WriteError(http.StatusInternalServerError, &meta.RespError{Msg: srvData.ccErr.Error(common.CCErrCommHTTPDoRequestFailed)})
+				return
+			}
+			if !detail.Result {
+				blog.Errorf("UpdateUserCustomQuery get user custom query detail http response error,err code:%d,err msg:%s, bizID:%v,input:%+v,rid:%s", detail.Code, detail.ErrMsg, bizID, params, srvData.rid)
+				_ = resp.WriteError(http.StatusInternalServerError, &meta.RespError{Msg: srvData.ccErr.New(detail.Code, detail.ErrMsg)})
+				return
+			}
+
+			objID := detail.Data.ObjectID
+			if "" == objID {
+				objID = common.BKInnerObjIDHost
+			}
+			// check if the info string matches the required structure
+			if err := s.validateDynamicGroupInfo(srvData, objID, info); err != nil {
+				blog.Errorf("UpdateUserCustomQuery info is invalid, err: %v, input:%+v, rid:%s", err.Error(), params, srvData.rid)
+				_ = resp.WriteError(http.StatusOK, &meta.RespError{Msg: err})
 				return
 			}
 		}
 	}
 
-	bizID := req.PathParameter("bk_biz_id")
 	result, err := s.CoreAPI.CoreService().Host().UpdateUserConfig(srvData.ctx, bizID, req.PathParameter("id"), srvData.header, params)
 	if err != nil {
 		blog.Errorf("UpdateUserCustomQuery http do error,err:%s, biz:%v,input:%+v,rid:%s", err.Error(), bizID, params, srvData.rid)
@@ -288,29 +309,37 @@ func (s *Service) GetUserCustomQueryResult(req *restful.Request, resp *restful.R
 		return
 	}
 
-	var input meta.HostCommonSearch
-	input.AppID = intAppID
-
-	err = json.Unmarshal([]byte(result.Data.Info), &input)
-	if nil != err {
-		blog.Errorf("UserAPIResult custom unmarshal failed,  err: %v, appid:%s, id:%s, logID:%s", err.Error(), appID, ID, srvData.rid)
-		_ = resp.WriteError(http.StatusInternalServerError, &meta.RespError{Msg: srvData.ccErr.Error(common.CCErrCommJSONUnmarshalFailed)})
-		return
-	}
-
-	input.Page.Start, err = util.GetIntByInterface(req.PathParameter("start"))
+	start, err := util.GetIntByInterface(req.PathParameter("start"))
 	if err != nil {
 		blog.Errorf("UserAPIResult start invalid, err: %v, appid:%s, id:%s, logID:%s", err.Error(), appID, ID, srvData.rid)
 		_ = resp.WriteError(http.StatusOK, &meta.RespError{Msg: srvData.ccErr.Errorf(common.CCErrCommParamsIsInvalid, "start")})
 		return
 	}
-	input.Page.Limit, err = util.GetIntByInterface(req.PathParameter("limit"))
+	limit, err := util.GetIntByInterface(req.PathParameter("limit"))
 	if err != nil {
 		blog.Errorf("UserAPIResult limit invalid, err: %v, appid:%s, id:%s, logID:%s", err.Error(), appID, ID, srvData.rid)
 		_ = resp.WriteError(http.StatusOK, &meta.RespError{Msg: srvData.ccErr.Errorf(common.CCErrCommParamsIsInvalid, "limit")})
 		return
 	}
 
+	// the dynamic group of the instances of a model other than host
+	if "" != result.Data.ObjectID && common.BKInnerObjIDHost != result.Data.ObjectID {
+		s.getDynamicGroupInstances(resp, srvData, intAppID, result.Data, meta.BasePage{Start: start, Limit: limit})
+		return
+	}
+
+	var input meta.HostCommonSearch
+	input.AppID = intAppID
+
+	err = json.Unmarshal([]byte(result.Data.Info), &input)
+	if nil != err {
+		blog.Errorf("UserAPIResult custom unmarshal failed,  err: %v, appid:%s, id:%s, logID:%s", err.Error(), appID, ID, srvData.rid)
+		_ = resp.WriteError(http.StatusInternalServerError, &meta.RespError{Msg: srvData.ccErr.Error(common.CCErrCommJSONUnmarshalFailed)})
+		return
+	}
+	input.Page.Start = start
+	input.Page.Limit = limit
+
 	retData, err := srvData.lgc.SearchHost(srvData.ctx, &input, false)
 	if nil != err || (nil == err && !result.Result) {
 		if nil == err {
@@ -331,3 +360,80 @@ func (s *Service) GetUserCustomQueryResult(req *restful.Request, resp *restful.R
 
 	return
 }
+
+func (s *Service) getDynamicGroupInstances(resp *restful.Response, srvData *srvComm, bizID int64, group meta.UserConfigMeta, page meta.BasePage) {
+	if page.IsIllegal() || page.Limit == common.BKNoLimit {
+		blog.Errorf("UserAPIResult limit invalid, limit: %d, appid:%d, id:%s, logID:%s", page.Limit, bizID, group.ID, srvData.rid)
+		_ = resp.WriteError(http.StatusOK, &meta.RespError{Msg: srvData.ccErr.Errorf(common.CCErrCommParamsIsInvalid, "limit")})
+		return
+	}
+
+	info := new(meta.DynamicGroupInfo)
+	if err := json.Unmarshal([]byte(group.Info), info); err != nil {
+		blog.Errorf("UserAPIResult custom unmarshal failed,  err: %v, appid:%d, id:%s, logID:%s", err.Error(), bizID, group.ID, srvData.rid)
+		_ = resp.WriteError(http.StatusInternalServerError, &meta.RespError{Msg: srvData.ccErr.Error(common.CCErrCommJSONUnmarshalFailed)})
+		return
+	}
+	if key, err := info.Validate(); err != nil {
+		blog.Errorf("UserAPIResult custom info invalid, key: %s, err: %v, appid:%d, id:%s, logID:%s", key, err, bizID, group.ID, srvData.rid)
+		_ = resp.WriteError(http.StatusInternalServerError, &meta.RespError{Msg: srvData.ccErr.Errorf(common.CCErrCommParamsInvalid, "info."+key)})
+		return
+	}
+
+	// the dynamic group could only be executed by those who could find the instances of the model
+	if err := s.AuthManager.AuthorizeFindInstancesByObject(srvData.ctx, srvData.header, bizID, group.ObjectID); err != nil {
+		blog.Errorf("UserAPIResult authorize find instances of %s failed, err: %v, appid:%d, id:%s, rid: %s", group.ObjectID, err, bizID, group.ID, srvData.rid)
+		_ = resp.WriteError(http.StatusForbidden, &meta.RespError{Msg: srvData.ccErr.Error(common.CCErrCommAuthorizeFailed)})
+		return
+	}
+
+	retData, err := srvData.lgc.SearchDynamicGroupInstances(srvData.ctx, bizID, group.ObjectID, info, page)
+	if err != nil {
+		blog.Errorf("UserAPIResult custom query search instances failed, err: %v, appid:%d, id:%s, rid: %s", err, bizID, group.ID, srvData.rid)
+		_ = resp.WriteError(http.StatusInternalServerError, &meta.RespError{Msg: err})
+		return
+	}
+
+	_ = resp.WriteEntity(meta.Response{
+		BaseResp: meta.SuccessBaseResp,
+		Data:     retData,
+	})
+}
+
+// validateDynamicGroupInfo checks if the info string matches the structure of the dynamic group of the object,
+// which is HostCommonSearch for host and DynamicGroupInfo for the other models.
+func (s *Service) validateDynamicGroupInfo(srvData *srvComm, objID, info string) error {
+	if objID == common.BKInnerObjIDHost {
+		if err := json.Unmarshal([]byte(info), &meta.HostCommonSearch{}); err != nil {
+			blog.Errorf("validate dynamic group info, unmarshal host search failed, err: %v, rid:%s", err, srvData.rid)
+			return srvData.ccErr.Error(common.CCErrCommJSONUnmarshalFailed)
+		}
+		return nil
+	}
+
+	cond := &meta.QueryCondition{Condition: mapstr.MapStr{common.BKObjIDField: objID}}
+	result, err := s.CoreAPI.CoreService().Model().ReadModel(srvData.ctx, srvData.header, cond)
+	if err != nil {
+		blog.Errorf("validate dynamic group info, read model %s http do error, err: %v, rid:%s", objID, err, srvData.rid)
+		return srvData.ccErr.Error(common.CCErrCommHTTPDoRequestFailed)
+	}
+	if !result.Result {
+		blog.Errorf("validate dynamic group info, read model %s http response error, err code:%d, err msg:%s, rid:%s", objID, result.Code, result.ErrMsg, srvData.rid)
+		return srvData.ccErr.New(result.Code, result.ErrMsg)
+	}
+	if len(result.Data.Info) == 0 {
+		blog.Errorf("validate dynamic group info, model %s not found, rid:%s", objID, srvData.rid)
+		return srvData.ccErr.Errorf(common.CCErrCommParamsIsInvalid, common.BKObjIDField)
+	}
+
+	groupInfo := new(meta.DynamicGroupInfo)
+	if err := json.Unmarshal([]byte(info), groupInfo); err != nil {
+		blog.Errorf("validate dynamic group info, unmarshal failed, err: %v, rid:%s", err, srvData.rid)
+		return srvData.ccErr.Error(common.CCErrCommJSONUnmarshalFailed)
+	}
+	if key, err := groupInfo.Validate(); err != nil {
+		blog.Errorf("validate dynamic group info failed, key: %s, err: %v, rid:%s", key, err, srvData.rid)
+		return srvData.ccErr.Errorf(common.CCErrCommParamsInvalid, "info."+key)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"configcenter/src/apimachinery"
+	"configcenter/src/common"
+	"configcenter/src/common/backbone"
+	"configcenter/src/common/errors"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateDynamicGroupInfo(t *testing.T) {
+	const (
+		modelFound    = `{"result": true, "bk_error_code": 0, "data": {"count": 1, "info": [{"spec": {"bk_obj_id": "rack"}}]}}`
+		modelNotFound = `{"result": true, "bk_error_code": 0, "data": {"count": 0, "info": []}}`
+		hostInfo      = `{"condition": [{"bk_obj_id": "host", "fields": [], "condition": []}], "ip": {"flag": "bk_host_innerip", "exact": 0, "data": []}}`
+		modelInfo     = `{"condition": {"condition": "AND", "rules": [{"field": "bk_inst_name", "operator": "equal", "value": "a"}]}}`
+	)
+
+	testCases := []struct {
+		name     string
+		objID    string
+		info     string
+		response string
+		valid    bool
+	}{
+		{name: "host info of host", objID: common.BKInnerObjIDHost, info: hostInfo, valid: true},
+		{name: "invalid host info", objID: common.BKInnerObjIDHost, info: `"bk_host_innerip"`},
+		{name: "model info of model", objID: "rack", info: modelInfo, response: modelFound, valid: true},
+		{name: "host info of model", objID: "rack", info: hostInfo, response: modelFound},
+		{name: "model info of unknown model", objID: "rack", info: modelInfo, response: modelNotFound},
+		{
+			name:     "invalid association filter",
+			objID:    "rack",
+			info:     `{"association_filters": [{"bk_obj_id": "host", "max_hops": 4}]}`,
+			response: modelFound,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			coreAPI := apimachinery.NewMockApiMachinery()
+			coreAPI.MockDo(testCase.response)
+			s := &Service{Engine: &backbone.Engine{CoreAPI: coreAPI}}
+			srvData := &srvComm{
+				header: make(http.Header),
+				ctx:    context.Background(),
+				ccErr:  errors.NewFromCtx(errors.EmptyErrorsSetting).CreateDefaultCCErrorIf("zh-cn"),
+			}
+
+			err := s.validateDynamicGroupInfo(srvData, testCase.objID, testCase.info)
+			if testCase.valid {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+		})
+	}
+}
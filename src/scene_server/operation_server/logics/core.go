@@ -21,16 +21,15 @@ import (
 	"configcenter/src/common/errors"
 	"configcenter/src/common/language"
 	"configcenter/src/common/util"
+	"configcenter/src/storage/dal/redis"
 	"configcenter/src/thirdpartyclient/esbserver"
-
-	"gopkg.in/redis.v5"
 )
 
 type Logics struct {
 	*backbone.Engine
 	esbServ     esbserver.EsbClientInterface
 	ErrHandle   errors.DefaultCCErrorIf
-	cache       *redis.Client
+	cache       redis.Client
 	header      http.Header
 	rid         string
 	ownerID     string
@@ -13,7 +13,9 @@
 package service
 
 import (
+	authmeta "configcenter/src/auth/meta"
 	"configcenter/src/common"
+	"configcenter/src/common/errors"
 	"configcenter/src/common/http/rest"
 	"configcenter/src/common/mapstr"
 	"configcenter/src/common/metadata"
@@ -187,3 +189,38 @@ func (o *OperationServer) UpdateChartPosition(ctx *rest.Contexts) {
 
 	ctx.RespEntity(nil)
 }
+
+// AggregateInstances groups the instances of the model by the fields and computes the metrics of each group
+func (o *OperationServer) AggregateInstances(ctx *rest.Contexts) {
+	option := new(metadata.InstAggregationOption)
+	if err := ctx.DecodeInto(option); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	// the instances of a business are aggregated by the users who could find the business
+	if option.BizID != 0 {
+		if err := o.AuthManager.AuthorizeByBusinessID(ctx.Kit.Ctx, ctx.Kit.Header, authmeta.Find, option.BizID); err != nil {
+			ctx.RespErrorCodeOnly(common.CCErrCommAuthorizeFailed, "aggregate instances fail, authorize business %d fail, err: %v, rid: %v", option.BizID, err, ctx.Kit.Rid)
+			return
+		}
+	}
+
+	// the values of the instances are returned by the groups, so the user should be able to find the instances
+	objID := ctx.Request.PathParameter(common.BKObjIDField)
+	if err := o.AuthManager.AuthorizeFindInstancesByObject(ctx.Kit.Ctx, ctx.Kit.Header, option.BizID, objID); err != nil {
+		ctx.RespErrorCodeOnly(common.CCErrCommAuthorizeFailed, "aggregate instances fail, authorize finding instances of %s fail, err: %v, rid: %v", objID, err, ctx.Kit.Rid)
+		return
+	}
+	result, err := o.CoreAPI.CoreService().Operation().AggregateInstances(ctx.Kit.Ctx, ctx.Kit.Header, objID, option)
+	if err != nil {
+		ctx.RespErrorCodeOnly(common.CCErrOperationGetChartDataFail, "aggregate instances fail, objID: %s, err: %v, rid: %v", objID, err, ctx.Kit.Rid)
+		return
+	}
+	if !result.Result {
+		ctx.RespAutoError(errors.New(result.Code, result.ErrMsg))
+		return
+	}
+
+	ctx.RespEntity(result.Data)
+}
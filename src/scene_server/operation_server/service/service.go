@@ -113,6 +113,7 @@ func (o *OperationServer) newOperationService(web *restful.WebService) {
 	utility.AddHandler(rest.Action{Verb: http.MethodGet, Path: "/search/operation/chart", Handler: o.SearchOperationChart})
 	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/search/operation/chart/data", Handler: o.SearchChartData})
 	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/update/operation/chart/position", Handler: o.UpdateChartPosition})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/search/operation/aggregation/object/{bk_obj_id}", Handler: o.AggregateInstances})
 
 	utility.AddToRestfulWebService(web)
 }
@@ -172,8 +173,11 @@ func (o *OperationServer) OnOperationConfigUpdate(previous, current cc.ProcessCo
 		return
 	}
 
-	cfg := mongo.ParseConfigFromKV("mongodb", current.ConfigMap)
-	o.Config.Mongo = cfg
+	o.Config.Mongo, err = mongo.ParseConfigFromKV("mongodb", current.ConfigMap)
+	if err != nil {
+		blog.Errorf("parse mongodb config failed, err: %v", err)
+		return
+	}
 
 	o.Config.Auth, err = authcenter.ParseConfigFromKV("auth", current.ConfigMap)
 	if err != nil {
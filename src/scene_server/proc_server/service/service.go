@@ -20,6 +20,7 @@ import (
 	"configcenter/src/common"
 	"configcenter/src/common/backbone"
 	cfnc "configcenter/src/common/backbone/configcenter"
+	"configcenter/src/common/blog"
 	"configcenter/src/common/errors"
 	"configcenter/src/common/http/rest"
 	"configcenter/src/common/language"
@@ -239,6 +240,10 @@ func (ps *ProcServer) OnProcessConfigUpdate(previous, current cfnc.ProcessConfig
 	ps.ConfigMap = current.ConfigMap
 
 	dbPrefix := "mongodb"
-	mongoCfg := mongo.ParseConfigFromKV(dbPrefix, current.ConfigMap)
+	mongoCfg, err := mongo.ParseConfigFromKV(dbPrefix, current.ConfigMap)
+	if err != nil {
+		blog.Errorf("parse mongodb config failed, err: %v", err)
+		return
+	}
 	ps.Config.Mongo = &mongoCfg
 }
@@ -42,16 +42,20 @@ func (lgc *Logics) NewFetchAssociation(syncConfig *options.ConfigItem, conds map
 	}
 }
 
-// Fetch fetch massociation
-func (fa *FetchAssociation) Fetch(ctx context.Context, dataClassify string, start, limit int64) (*metadata.InstDataInfo, errors.CCError) {
+// Fetch fetch massociation after the host lastHostID and module lastModuleID, sorted by the host and module id
+func (fa *FetchAssociation) Fetch(ctx context.Context, dataClassify string, lastHostID, lastModuleID, limit int64) (*metadata.InstDataInfo, errors.CCError) {
 	input := &metadata.SynchronizeFindInfoParameter{
 		Condition: mapstr.New(),
 	}
 	input.Limit = uint64(limit)
-	input.Start = uint64(start)
+	input.Sort = common.BKHostIDField + "," + common.BKModuleIDField
 	input.DataClassify = dataClassify
 	input.DataType = metadata.SynchronizeOperateDataTypeAssociation
 	input.Condition.Merge(fa.baseConds)
+	input.Condition.Set(common.BKDBOR, []mapstr.MapStr{
+		{common.BKHostIDField: mapstr.MapStr{common.BKDBGT: lastHostID}},
+		{common.BKHostIDField: lastHostID, common.BKModuleIDField: mapstr.MapStr{common.BKDBGT: lastModuleID}},
+	})
 	switch dataClassify {
 	case common.SynchronizeAssociationTypeModelHost:
 		input.Condition.Merge(fa.getAppCondition())
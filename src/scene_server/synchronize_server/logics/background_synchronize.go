@@ -169,15 +169,20 @@ func (s *synchronizeItem) synchronizeInstanceTask(ctx context.Context) (errorInf
 }
 
 func (s *synchronizeItem) synchronizeInstance(ctx context.Context, objID string, inst *FetchInst) ([]metadata.ExceptionResult, error) {
-	var start int64 = 0
+	var lastID int64 = 0
 	limit := int64(defaultLimit)
 	var errorInfoArr []metadata.ExceptionResult
+	idField := common.GetInstIDField(objID)
 
 	for {
-		info, err := inst.Fetch(ctx, objID, start, limit)
+		// page by the last instance id, so that the later pages don't skip scan the earlier ones
+		info, err := inst.Fetch(ctx, objID, lastID, limit)
 		if err != nil {
 			return nil, err
 		}
+		if info == nil || len(info.Info) == 0 {
+			break
+		}
 
 		input := &metadata.SynchronizeDataInfo{}
 		input.OperateDataType = metadata.SynchronizeOperateDataTypeInstance
@@ -194,10 +199,14 @@ func (s *synchronizeItem) synchronizeInstance(ctx context.Context, objID string,
 			errorInfoArr = append(errorInfoArr, pageErrInfoArr...)
 		}
 
-		start += limit
-		if start >= int64(info.Count) {
+		if int64(len(info.Info)) < limit {
 			break
 		}
+		lastID, err = info.Info[len(info.Info)-1].Int64(idField)
+		if err != nil {
+			blog.Errorf("synchronizeInstance get %s of the last instance error, objID:%s, err:%s, rid:%s", idField, objID, err.Error(), s.lgc.rid)
+			return nil, err
+		}
 	}
 	if common.BKInnerObjIDApp == objID {
 		inst.SetAppIDArr(s.appIDArr)
@@ -432,16 +441,19 @@ func (s *synchronizeItem) synchronizeAssociationTask(ctx context.Context) ([]met
 }
 
 func (s *synchronizeItem) sycnhronizeAssociation(ctx context.Context, association *FetchAssociation, dataClassify string) ([]metadata.ExceptionResult, error) {
-	var start int64 = 0
+	var lastHostID, lastModuleID int64 = 0, 0
 	limit := int64(defaultLimit)
 	var errorInfoArr []metadata.ExceptionResult
 
 	for {
-
-		info, err := association.Fetch(ctx, dataClassify, start, limit)
+		// page by the last host and module id, so that the later pages don't skip scan the earlier ones
+		info, err := association.Fetch(ctx, dataClassify, lastHostID, lastModuleID, limit)
 		if err != nil {
 			return nil, err
 		}
+		if info == nil || len(info.Info) == 0 {
+			break
+		}
 
 		input := &metadata.SynchronizeDataInfo{}
 		input.OperateDataType = metadata.SynchronizeOperateDataTypeAssociation
@@ -457,10 +469,18 @@ func (s *synchronizeItem) sycnhronizeAssociation(ctx context.Context, associatio
 		if len(pageErrInfoArr) > 0 {
 			errorInfoArr = append(errorInfoArr, pageErrInfoArr...)
 		}
-		start += limit
-		if start >= int64(info.Count) {
+		if int64(len(info.Info)) < limit {
 			break
 		}
+		last := info.Info[len(info.Info)-1]
+		if lastHostID, err = last.Int64(common.BKHostIDField); err != nil {
+			blog.Errorf("sycnhronizeAssociation get host id of the last association error, err:%s, rid:%s", err.Error(), s.lgc.rid)
+			return nil, err
+		}
+		if lastModuleID, err = last.Int64(common.BKModuleIDField); err != nil {
+			blog.Errorf("sycnhronizeAssociation get module id of the last association error, err:%s, rid:%s", err.Error(), s.lgc.rid)
+			return nil, err
+		}
 	}
 
 	return errorInfoArr, nil
@@ -71,6 +71,7 @@ func (lgc *Logics) Find(ctx context.Context, input *metadata.SynchronizeFindInfo
 func SynchronizeFindInfoParameterToQuerycondition(input *metadata.SynchronizeFindInfoParameter) *metadata.QueryCondition {
 	ret := &metadata.QueryCondition{
 		Limit:     metadata.SearchLimit{Limit: int64(input.Limit), Offset: int64(input.Start)},
+		SortArr:   metadata.NewSearchSortParse().String(input.Sort).ToSearchSortArr(),
 		Condition: input.Condition,
 	}
 	if ret.Limit.Limit <= 0 {
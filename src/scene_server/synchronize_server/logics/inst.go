@@ -63,13 +63,13 @@ func (fi *FetchInst) Pretreatment() errors.CCError {
 	return nil
 }
 
-// Fetch fetch instance data
-func (fi *FetchInst) Fetch(ctx context.Context, objID string, start, limit int64) (*metadata.InstDataInfo, errors.CCError) {
+// Fetch fetch instance data after the instance lastID, sorted by the instance id
+func (fi *FetchInst) Fetch(ctx context.Context, objID string, lastID, limit int64) (*metadata.InstDataInfo, errors.CCError) {
 	input := &metadata.SynchronizeFindInfoParameter{
 		Condition: mapstr.New(),
 	}
 	input.Limit = uint64(limit)
-	input.Start = uint64(start)
+	input.Sort = common.GetInstIDField(objID)
 	switch objID {
 	case common.BKInnerObjIDApp:
 		conds := condition.CreateCondition()
@@ -104,6 +104,7 @@ func (fi *FetchInst) Fetch(ctx context.Context, objID string, start, limit int64
 
 	}
 	input.Condition.Merge(fi.baseConds)
+	input.Condition.Set(input.Sort, mapstr.MapStr{common.BKDBGT: lastID})
 	input.DataClassify = objID
 	input.DataType = metadata.SynchronizeOperateDataTypeInstance
 
@@ -120,7 +120,12 @@ func (h *TaskServer) onHostConfigUpdate(previous, current cc.ProcessConfig) {
 	h.Config.Redis.Port = current.ConfigMap["redis.port"]
 	h.Config.Redis.MasterName = current.ConfigMap["redis.user"]
 
-	h.Config.Mongo = mongo.ParseConfigFromKV("mongodb", current.ConfigMap)
+	var err error
+	h.Config.Mongo, err = mongo.ParseConfigFromKV("mongodb", current.ConfigMap)
+	if err != nil {
+		blog.Errorf("parse mongodb config failed, err: %v", err)
+		return
+	}
 
 	taskNameArr := strings.Split(current.ConfigMap["task.name"], ",")
 
@@ -58,11 +58,15 @@ func (t *TopoServer) onTopoConfigUpdate(previous, current cc.ProcessConfig) {
 		}
 		blog.Infof("config update with max topology level: %d", t.Config.BusinessTopoLevelMax)
 	}
-	t.Config.Mongo = mongo.ParseConfigFromKV("mongodb", current.ConfigMap)
+	var err error
+	t.Config.Mongo, err = mongo.ParseConfigFromKV("mongodb", current.ConfigMap)
+	if err != nil {
+		blog.Errorf("parse mongodb config failed, err: %v", err)
+		return
+	}
 	t.Config.ConfigMap = current.ConfigMap
 	blog.Infof("the new cfg:%#v the origin cfg:%#v", t.Config, current.ConfigMap)
 
-	var err error
 	t.Config.Auth, err = authcenter.ParseConfigFromKV("auth", current.ConfigMap)
 	if err != nil {
 		blog.Warnf("parse auth center config failed: %v", err)
@@ -103,11 +107,15 @@ func Run(ctx context.Context, cancel context.CancelFunc, op *options.ServerOptio
 		return err
 	}
 
-	var txn dal.Transcation
+	// the db is used as the transaction and the storage of the built-in full text search
+	var db interface {
+		dal.RDB
+		dal.Transcation
+	}
 	if server.Config.Mongo.Enable == "true" {
-		txn, err = local.NewMgo(server.Config.Mongo.BuildURI(), time.Second*5)
+		db, err = local.NewMgo(server.Config.Mongo.BuildURI(), time.Second*5)
 	} else {
-		txn, err = remote.NewWithDiscover(engine)
+		db, err = remote.NewWithDiscover(engine, server.Config.Mongo.RPCTLS)
 	}
 	if err != nil {
 		blog.Errorf("failed to connect the txc server, error info is %v", err)
@@ -120,14 +128,15 @@ func Run(ctx context.Context, cancel context.CancelFunc, op *options.ServerOptio
 		return err
 	}
 
-	essrv := new(elasticsearch.EsSrv)
+	// search the elasticsearch if it's configured, otherwise the text indexes of mongodb
+	fullText := service.NewMongoSearcher(db)
 	if server.Config.Es.FullTextSearch == "on" {
 		esClient, err := elasticsearch.NewEsClient(server.Config.Es)
 		if err != nil {
 			blog.Errorf("failed to create elastic search client, err:%s", err.Error())
 			return fmt.Errorf("new es client failed, err: %v", err)
 		}
-		essrv.Client = esClient
+		fullText = service.NewEsSearcher(&elasticsearch.EsSrv{Client: esClient})
 	}
 
 	authManager := extensions.NewAuthManager(engine.CoreAPI, authorize)
@@ -135,10 +144,10 @@ func Run(ctx context.Context, cancel context.CancelFunc, op *options.ServerOptio
 		Language:    engine.Language,
 		Engine:      engine,
 		AuthManager: authManager,
-		Es:          essrv,
+		FullText:    fullText,
 		Core:        core.New(engine.CoreAPI, authManager),
 		Error:       engine.CCErr,
-		Txn:         txn,
+		Txn:         db,
 		Config:      server.Config,
 	}
 
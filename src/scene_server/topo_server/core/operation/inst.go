@@ -968,6 +968,11 @@ func (c *commonInst) FindInstByAssociationInst(params types.ContextParams, obj m
 func (c *commonInst) FindOriginInst(params types.ContextParams, obj model.Object, cond *metadata.QueryInput) (*metadata.InstResult, error) {
 	switch obj.Object().ObjectID {
 	case common.BKInnerObjIDHost:
+		if cond.PropertyFilter != nil {
+			// the hosts are filtered by the host_property_filter of the list hosts api
+			blog.Errorf("[operation-inst] property filter is not supported for hosts, rid: %s", params.ReqID)
+			return nil, params.Err.Errorf(common.CCErrCommParamsInvalid, "property_filter")
+		}
 		rsp, err := c.clientSet.CoreService().Host().GetHosts(context.Background(), params.Header, cond)
 		if nil != err {
 			blog.Errorf("[operation-inst] failed to request object controller, err: %s, rid: %s", err.Error(), params.ReqID)
@@ -984,7 +989,7 @@ func (c *commonInst) FindOriginInst(params types.ContextParams, obj model.Object
 
 	default:
 		queryCond, err := mapstr.NewFromInterface(cond.Condition)
-		input := &metadata.QueryCondition{Condition: queryCond}
+		input := &metadata.QueryCondition{Condition: queryCond, PropertyFilter: cond.PropertyFilter}
 		input.Limit.Offset = int64(cond.Start)
 		input.Limit.Limit = int64(cond.Limit)
 		input.Fields = strings.Split(cond.Fields, ",")
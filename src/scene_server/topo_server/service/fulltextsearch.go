@@ -11,6 +11,7 @@ import (
 	"configcenter/src/common/mapstr"
 	"configcenter/src/common/util"
 	"configcenter/src/scene_server/topo_server/core/types"
+	"configcenter/src/thirdpartyclient/elasticsearch"
 
 	"github.com/olivere/elastic"
 )
@@ -56,9 +57,14 @@ func NewQuery() *Query {
 	return query
 }
 
+// FullTextSearcher is the backend of the full text search
+type FullTextSearcher interface {
+	Search(ctx context.Context, query *Query) (*SearchResults, error)
+}
+
 func (s *Service) FullTextFind(params types.ContextParams, pathParams, queryParams ParamsGetter, data mapstr.MapStr) (interface{}, error) {
-	if s.Es.Client == nil {
-		blog.Errorf("FullTextFind failed, es client is nil, rid: %s", params.ReqID)
+	if s.FullText == nil {
+		blog.Errorf("FullTextFind failed, full text searcher is nil, rid: %s", params.ReqID)
 		return nil, params.Err.Error(common.CCErrorTopoFullTextClientNotInitialized)
 	}
 
@@ -78,13 +84,33 @@ func (s *Service) FullTextFind(params types.ContextParams, pathParams, queryPara
 		blog.Errorf("full_text_find failed, query string [%s] large than 32, rid: %s", rawString, params.ReqID)
 		return nil, params.Err.Errorf(common.CCErrCommParamsIsInvalid, "query_string")
 	}
+
+	searchResults, err := s.FullText.Search(params.Context, query)
+	if err != nil {
+		blog.Errorf("full_text_find failed, search failed, err: %+v, rid: %s", err, params.ReqID)
+		return nil, params.Err.Error(common.CCErrorTopoFullTextFindErr)
+	}
+	return searchResults, nil
+}
+
+// esSearcher searches the data synchronized to the elasticsearch
+type esSearcher struct {
+	es *elasticsearch.EsSrv
+}
+
+// NewEsSearcher returns the full text searcher of the elasticsearch
+func NewEsSearcher(es *elasticsearch.EsSrv) FullTextSearcher {
+	return &esSearcher{es: es}
+}
+
+func (e *esSearcher) Search(ctx context.Context, query *Query) (*SearchResults, error) {
+	rawString, _ := query.checkQueryString()
 	// get query and search types
 	esQuery, searchTypes := query.toEsQueryAndSearchTypes()
 
-	result, err := s.Es.Search(params.Context, esQuery, searchTypes, query.Paging.Start, query.Paging.Limit)
+	result, err := e.es.Search(ctx, esQuery, searchTypes, query.Paging.Start, query.Paging.Limit)
 	if err != nil {
-		blog.Errorf("full_text_find failed, es search failed, err: %+v, rid: %s", err, params.ReqID)
-		return nil, params.Err.Error(common.CCErrorTopoFullTextFindErr)
+		return nil, err
 	}
 
 	// result is hits and aggregations
@@ -96,7 +122,7 @@ func (s *Service) FullTextFind(params types.ContextParams, pathParams, queryPara
 		// ignore not correct cmdb table data
 		if hit.Index == common.CMDBINDEX && hit.Id != common.INDICES {
 			sr := SearchResult{}
-			sr.setHit(params.Context, hit, query.BkBizId, rawString)
+			sr.setHit(ctx, hit, query.BkBizId, rawString)
 			searchResults.Hits = append(searchResults.Hits, sr)
 		}
 	}
@@ -190,27 +216,33 @@ func (query Query) toEsQueryAndSearchTypes() (elastic.Query, []string) {
 
 	// if set bk_obj_id
 	qString := elastic.NewQueryStringQuery(query.QueryString)
+	if query.isObjectSearch() {
+		// if define bk_obj_id, we use bool query include must(bk_obj_id=xxx) and should(query string)
+		qBool.Must(elastic.NewTermQuery("bk_obj_id", query.BkObjId))
+	}
+	return qBool.Must(qString), query.searchTypes()
+}
+
+// isObjectSearch returns whether the query searches the instances and model of the bk_obj_id only
+func (query Query) isObjectSearch() bool {
+	return query.BkObjId != "" && query.BkObjId != common.TypeHost && query.BkObjId != common.TypeApplication
+}
+
+// searchTypes returns the tables to be searched
+func (query Query) searchTypes() []string {
 	if query.BkObjId == "" {
 		// get search types from filter
 		indexTypes := getEsIndexTypes(query.TypeFilter)
 		// add search cc_ApplicationBase type
-		indexTypes = append(indexTypes, common.BKTableNameBaseApp)
-		return qBool.Must(qString), indexTypes
+		return append(indexTypes, common.BKTableNameBaseApp)
 	} else if query.BkObjId == common.TypeHost {
 		// if bk_obj_id is host, we search only from type cc_HostBase
-		indexTypes := []string{common.BKTableNameBaseHost}
-		return qBool.Must(qString), indexTypes
+		return []string{common.BKTableNameBaseHost}
 	} else if query.BkObjId == common.TypeApplication {
 		// if bk_obj_id is biz, we search only from type cc_ApplicationBase
-		indexTypes := []string{common.BKTableNameBaseApp}
-		return qBool.Must(qString), indexTypes
-	} else {
-		// if define bk_obj_id, we use bool query include must(bk_obj_id=xxx) and should(query string)
-		qBool.Must(elastic.NewTermQuery("bk_obj_id", query.BkObjId))
-		qBool.Must(qString)
-		indexTypes := getEsIndexTypes(query.TypeFilter)
-		return qBool, indexTypes
+		return []string{common.BKTableNameBaseApp}
 	}
+	return getEsIndexTypes(query.TypeFilter)
 }
 
 func getEsIndexTypes(typesFilter []string) []string {
@@ -249,6 +281,23 @@ func inTypes(val string, types []string) bool {
 	return false
 }
 
+// searchResultType returns the type of the search result of the table
+func searchResultType(table string) string {
+	switch table {
+	case common.BKTableNameBaseInst:
+		return common.TypeObject
+	case common.BKTableNameBaseHost:
+		return common.TypeHost
+	case common.BKTableNameBaseProcess:
+		return common.TypeProcess
+	case common.BKTableNameBaseApp:
+		return common.TypeApplication
+	case common.BKTableNameObjDes:
+		return common.TypeModel
+	}
+	return ""
+}
+
 func (agg *Aggregation) setAgg(bucket *elastic.AggregationBucketKeyItem) {
 	if bucket.Key == common.BKTableNameBaseHost {
 		agg.Key = common.TypeHost
@@ -264,18 +313,7 @@ func (agg *Aggregation) setAgg(bucket *elastic.AggregationBucketKeyItem) {
 func (sr *SearchResult) setHit(ctx context.Context, searchHit *elastic.SearchHit, bkBizId, rawString string) {
 	rid := util.ExtractRequestIDFromContext(ctx)
 	sr.Score = *searchHit.Score
-	switch searchHit.Type {
-	case common.BKTableNameBaseInst:
-		sr.Type = common.TypeObject
-	case common.BKTableNameBaseHost:
-		sr.Type = common.TypeHost
-	case common.BKTableNameBaseProcess:
-		sr.Type = common.TypeProcess
-	case common.BKTableNameBaseApp:
-		sr.Type = common.TypeApplication
-	case common.BKTableNameObjDes:
-		sr.Type = common.TypeModel
-	}
+	sr.Type = searchResultType(searchHit.Type)
 
 	// sr.Highlight = searchHit.Highlight
 	err := json.Unmarshal(*searchHit.Source, &(sr.Source))
@@ -0,0 +1,334 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"configcenter/src/common"
+	"configcenter/src/common/util"
+	"configcenter/src/storage/dal"
+)
+
+// fullTextScoreField is the field that the text score of a matched document is set to
+const fullTextScoreField = "_full_text_score"
+
+// fullTextSubstringFields are the name and ip fields of the searched tables that the words are matched with as the
+// substrings when they can't be searched by the text indexes, matching all the fields of the documents is too slow
+var fullTextSubstringFields = map[string][]string{
+	common.BKTableNameBaseHost: {common.BKHostInnerIPField, common.BKHostOuterIPField, common.BKHostNameField},
+	common.BKTableNameBaseApp:  {common.BKAppNameField},
+	common.BKTableNameBaseInst: {common.BKInstNameField},
+	common.BKTableNameObjDes:   {common.BKObjIDField, common.BKObjNameField},
+}
+
+// fullTextMinWordLength is the min length of the words searched by the text indexes
+const fullTextMinWordLength = 3
+
+// fullTextDefaultLimit is the default page size, the same as the elasticsearch
+const fullTextDefaultLimit = 10
+
+// mongoSearcher is the built-in full text searcher which searches the text indexes of the tables in mongodb.
+// the words of the query string are searched as phrases, so a document matches only if it contains all of them.
+// the words that the text indexes can't search are matched as substrings of the name and ip fields of the tables only.
+type mongoSearcher struct {
+	db dal.RDB
+}
+
+// NewMongoSearcher returns the built-in full text searcher, the searched tables must have the text indexes
+func NewMongoSearcher(db dal.RDB) FullTextSearcher {
+	return &mongoSearcher{db: db}
+}
+
+func (m *mongoSearcher) Search(ctx context.Context, query *Query) (*SearchResults, error) {
+	rawString, _ := query.checkQueryString()
+	words := strings.Fields(strings.Replace(rawString, "\"", " ", -1))
+	textSearch := query.isTextSearch(words)
+
+	start, limit := query.Paging.Start, query.Paging.Limit
+	if start < 0 {
+		start = 0
+	}
+	if limit < 0 {
+		limit = fullTextDefaultLimit
+	}
+
+	results := make([]fullTextTableResult, 0)
+	for _, table := range query.searchTypes() {
+		pipeline := query.toMongoPipeline(table, words, textSearch)
+		counts, err := m.count(ctx, table, pipeline)
+		if err != nil {
+			return nil, err
+		}
+
+		// the hits of all the tables are sorted by the score together, so the first start+limit hits of each table is enough
+		hits, err := m.find(ctx, table, pipeline, textSearch, start+limit)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, fullTextTableResult{table: table, counts: counts, hits: hits})
+	}
+
+	searchResults := mergeFullTextResults(results, start, limit)
+	highlighter := newFullTextHighlighter(words, rawString)
+	for i := range searchResults.Hits {
+		searchResults.Hits[i].Highlight = highlighter.highlight(searchResults.Hits[i].Source)
+	}
+	return searchResults, nil
+}
+
+// fullTextTableResult is the counts grouped by the bk_obj_id and the first hits of a searched table
+type fullTextTableResult struct {
+	table  string
+	counts map[string]int64
+	hits   []SearchResult
+}
+
+// mergeFullTextResults merges the results of the searched tables into one page in the order of the score,
+// the host and biz tables are aggregated by the type and the others by the bk_obj_id, just like the elasticsearch
+func mergeFullTextResults(results []fullTextTableResult, start, limit int) *SearchResults {
+	searchResults := &SearchResults{
+		Aggregations: make([]Aggregation, 0),
+		Hits:         make([]SearchResult, 0),
+	}
+	objAggs := make(map[string]int64)
+	typeAggs := make([]Aggregation, 0)
+	hits := make([]SearchResult, 0)
+	for _, result := range results {
+		for objID, count := range result.counts {
+			searchResults.Total += count
+			switch result.table {
+			case common.BKTableNameBaseHost, common.BKTableNameBaseApp:
+				typeAggs = append(typeAggs, Aggregation{Key: searchResultType(result.table), Count: count})
+			default:
+				objAggs[objID] += count
+			}
+		}
+		hits = append(hits, result.hits...)
+	}
+
+	objAggKeys := make([]string, 0, len(objAggs))
+	for objID := range objAggs {
+		objAggKeys = append(objAggKeys, objID)
+	}
+	sort.Slice(objAggKeys, func(i, j int) bool {
+		if objAggs[objAggKeys[i]] != objAggs[objAggKeys[j]] {
+			return objAggs[objAggKeys[i]] > objAggs[objAggKeys[j]]
+		}
+		return objAggKeys[i] < objAggKeys[j]
+	})
+	for _, objID := range objAggKeys {
+		searchResults.Aggregations = append(searchResults.Aggregations, Aggregation{Key: objID, Count: objAggs[objID]})
+	}
+	sort.SliceStable(typeAggs, func(i, j int) bool {
+		return typeAggs[i].Count > typeAggs[j].Count
+	})
+	searchResults.Aggregations = append(searchResults.Aggregations, typeAggs...)
+
+	sort.SliceStable(hits, func(i, j int) bool {
+		return hits[i].Score > hits[j].Score
+	})
+	if start < len(hits) {
+		end := start + limit
+		if end > len(hits) {
+			end = len(hits)
+		}
+		searchResults.Hits = hits[start:end]
+	}
+	return searchResults
+}
+
+// count returns the count of the matched documents of the table grouped by the bk_obj_id
+func (m *mongoSearcher) count(ctx context.Context, table string, match []map[string]interface{}) (map[string]int64, error) {
+	pipeline := append(append(make([]map[string]interface{}, 0, len(match)+1), match...),
+		map[string]interface{}{"$group": map[string]interface{}{
+			"_id":   "$" + common.BKObjIDField,
+			"count": map[string]interface{}{"$sum": 1},
+		}},
+	)
+
+	groups := make([]struct {
+		ObjID interface{} `bson:"_id"`
+		Count int64       `bson:"count"`
+	}, 0)
+	if err := m.db.Table(table).AggregateAll(ctx, pipeline, &groups); err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64)
+	for _, group := range groups {
+		objID := ""
+		if group.ObjID != nil {
+			objID = util.GetStrByInterface(group.ObjID)
+		}
+		counts[objID] += group.Count
+	}
+	return counts, nil
+}
+
+// find returns the first limit matched documents of the table in the order of the text score
+func (m *mongoSearcher) find(ctx context.Context, table string, match []map[string]interface{}, scored bool, limit int) ([]SearchResult, error) {
+	if limit == 0 {
+		return nil, nil
+	}
+
+	pipeline := append(make([]map[string]interface{}, 0, len(match)+3), match...)
+	if scored {
+		pipeline = append(pipeline,
+			map[string]interface{}{"$addFields": map[string]interface{}{
+				fullTextScoreField: map[string]interface{}{"$meta": "textScore"},
+			}},
+			map[string]interface{}{"$sort": map[string]interface{}{fullTextScoreField: -1}},
+		)
+	}
+	pipeline = append(pipeline, map[string]interface{}{"$limit": limit})
+
+	docs := make([]map[string]interface{}, 0)
+	if err := m.db.Table(table).AggregateAll(ctx, pipeline, &docs); err != nil {
+		return nil, err
+	}
+
+	hits := make([]SearchResult, 0, len(docs))
+	for _, doc := range docs {
+		// all the documents have the same score if there is nothing to search, just like the match all query
+		score := 1.0
+		if scored {
+			score, _ = util.GetFloat64ByInterface(doc[fullTextScoreField])
+		}
+		delete(doc, fullTextScoreField)
+		delete(doc, "_id")
+		hits = append(hits, SearchResult{
+			Source: doc,
+			Type:   searchResultType(table),
+			Score:  score,
+		})
+	}
+	return hits, nil
+}
+
+// isTextSearch returns whether the words can be searched by the text indexes. the text indexes match the whole
+// stemmed words only and don't tokenize the chinese, so the wildcard, short, chinese or punctuated words such as
+// the ip fragments are searched as the substrings of the name and ip fields instead.
+func (query Query) isTextSearch(words []string) bool {
+	if len(words) == 0 || strings.Contains(query.QueryString, "*") {
+		return false
+	}
+	for _, word := range words {
+		if utf8.RuneCountInString(word) < fullTextMinWordLength {
+			return false
+		}
+		for _, r := range word {
+			if r > unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r)) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// toMongoCondition returns the condition of the built-in full text search, it's the same as the elasticsearch query
+func (query Query) toMongoCondition(words []string, textSearch bool) map[string]interface{} {
+	bizCond := []map[string]interface{}{
+		{common.BkBizMetaKey: map[string]interface{}{common.BKDBExists: false}},
+	}
+	if query.BkBizId != "" {
+		bizCond = append(bizCond, map[string]interface{}{common.BkBizMetaKey: query.BkBizId})
+	}
+
+	cond := map[string]interface{}{
+		common.BKDBOR:         bizCond,
+		common.BKAppNameField: map[string]interface{}{common.BKDBNE: common.DefaultAppName},
+	}
+	if query.isObjectSearch() {
+		cond[common.BKObjIDField] = query.BkObjId
+	}
+
+	if textSearch && len(words) > 0 {
+		phrases := make([]string, len(words))
+		for i, word := range words {
+			phrases[i] = "\"" + word + "\""
+		}
+		cond["$text"] = map[string]interface{}{"$search": strings.Join(phrases, " ")}
+	}
+	return cond
+}
+
+// toMongoPipeline returns the match stages of the built-in full text search of the table. if the words can't be
+// searched by the text indexes, the documents are matched if each word is a case insensitive substring of one of
+// the name or ip fields of the table.
+func (query Query) toMongoPipeline(table string, words []string, textSearch bool) []map[string]interface{} {
+	cond := query.toMongoCondition(words, textSearch)
+	if !textSearch && len(words) > 0 {
+		wordsCond := make([]map[string]interface{}, len(words))
+		for i, word := range words {
+			fieldsCond := make([]map[string]interface{}, 0)
+			for _, field := range fullTextSubstringFields[table] {
+				fieldsCond = append(fieldsCond, map[string]interface{}{
+					field: map[string]interface{}{
+						common.BKDBLIKE: regexp.QuoteMeta(word),
+						"$options":      "i",
+					},
+				})
+			}
+			wordsCond[i] = map[string]interface{}{common.BKDBOR: fieldsCond}
+		}
+		cond[common.BKDBAND] = wordsCond
+	}
+	return []map[string]interface{}{{"$match": cond}}
+}
+
+// fullTextHighlighter highlights the searched words in the string fields of a document like the elasticsearch
+type fullTextHighlighter struct {
+	pattern   *regexp.Regexp
+	rawString string
+}
+
+func newFullTextHighlighter(words []string, rawString string) *fullTextHighlighter {
+	if len(words) == 0 {
+		return &fullTextHighlighter{rawString: rawString}
+	}
+
+	quoted := make([]string, len(words))
+	for i, word := range words {
+		quoted[i] = regexp.QuoteMeta(word)
+	}
+	return &fullTextHighlighter{
+		pattern:   regexp.MustCompile("(?i)(" + strings.Join(quoted, "|") + ")"),
+		rawString: rawString,
+	}
+}
+
+func (h *fullTextHighlighter) highlight(source map[string]interface{}) map[string][]string {
+	highlight := make(map[string][]string)
+	if h.pattern == nil {
+		return highlight
+	}
+
+	for field, value := range source {
+		str, ok := value.(string)
+		if !ok || !h.pattern.MatchString(str) {
+			continue
+		}
+		// the same as the elasticsearch highlight, bk_obj_id is highlighted only if it contains the raw query string
+		if field == common.BKObjIDField && !strings.Contains(str, h.rawString) {
+			continue
+		}
+		highlight[field] = []string{h.pattern.ReplaceAllString(str, "<em>${1}</em>")}
+	}
+	return highlight
+}
@@ -0,0 +1,204 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"testing"
+
+	"configcenter/src/common"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsObjectSearch(t *testing.T) {
+	testCases := []struct {
+		objID  string
+		expect bool
+	}{
+		{"", false},
+		{common.TypeHost, false},
+		{common.TypeApplication, false},
+		{"switch", true},
+	}
+	for _, c := range testCases {
+		require.Equal(t, c.expect, Query{BkObjId: c.objID}.isObjectSearch(), c.objID)
+	}
+}
+
+func TestSearchTypes(t *testing.T) {
+	testCases := []struct {
+		query  Query
+		expect []string
+	}{
+		{
+			Query{},
+			[]string{common.BKTableNameBaseInst, common.BKTableNameBaseHost, common.BKTableNameObjDes, common.BKTableNameBaseApp},
+		},
+		// the types in the filter are excluded
+		{Query{TypeFilter: []string{common.TypeHost, common.TypeModel}}, []string{common.BKTableNameBaseInst, common.BKTableNameBaseApp}},
+		{Query{BkObjId: common.TypeHost, TypeFilter: []string{common.TypeObject}}, []string{common.BKTableNameBaseHost}},
+		{Query{BkObjId: common.TypeApplication}, []string{common.BKTableNameBaseApp}},
+		{Query{BkObjId: "switch", TypeFilter: []string{common.TypeHost, common.TypeModel}}, []string{common.BKTableNameBaseInst}},
+	}
+	for _, c := range testCases {
+		require.Equal(t, c.expect, c.query.searchTypes(), "%+v", c.query)
+	}
+}
+
+func TestIsTextSearch(t *testing.T) {
+	testCases := []struct {
+		queryString string
+		words       []string
+		expect      bool
+	}{
+		{"", nil, false},
+		{"web server", []string{"web", "server"}, true},
+		{"*e*", []string{"e"}, false},
+		{"web*", []string{"web"}, false},
+		{"db", []string{"db"}, false},
+		{"192.168", []string{"192.168"}, false},
+		{"主机", []string{"主机"}, false},
+		{"web 主机", []string{"web", "主机"}, false},
+	}
+	for _, c := range testCases {
+		require.Equal(t, c.expect, Query{QueryString: c.queryString}.isTextSearch(c.words), c.queryString)
+	}
+}
+
+func TestToMongoCondition(t *testing.T) {
+	query := Query{QueryString: "web server", BkBizId: "2", BkObjId: "switch"}
+	require.Equal(t, map[string]interface{}{
+		common.BKDBOR: []map[string]interface{}{
+			{common.BkBizMetaKey: map[string]interface{}{common.BKDBExists: false}},
+			{common.BkBizMetaKey: "2"},
+		},
+		common.BKAppNameField: map[string]interface{}{common.BKDBNE: common.DefaultAppName},
+		common.BKObjIDField:   "switch",
+		"$text":               map[string]interface{}{"$search": `"web" "server"`},
+	}, query.toMongoCondition([]string{"web", "server"}, true))
+
+	query = Query{BkObjId: common.TypeHost}
+	require.Equal(t, map[string]interface{}{
+		common.BKDBOR: []map[string]interface{}{
+			{common.BkBizMetaKey: map[string]interface{}{common.BKDBExists: false}},
+		},
+		common.BKAppNameField: map[string]interface{}{common.BKDBNE: common.DefaultAppName},
+	}, query.toMongoCondition([]string{"192.168"}, false))
+}
+
+func TestToMongoPipeline(t *testing.T) {
+	query := Query{QueryString: "web server"}
+	pipeline := query.toMongoPipeline(common.BKTableNameBaseHost, []string{"web", "server"}, true)
+	require.Len(t, pipeline, 1)
+	require.Contains(t, pipeline[0]["$match"], "$text")
+	require.NotContains(t, pipeline[0]["$match"], common.BKDBAND)
+
+	pipeline = query.toMongoPipeline(common.BKTableNameBaseHost, nil, false)
+	require.Len(t, pipeline, 1)
+	require.NotContains(t, pipeline[0]["$match"], "$text")
+	require.NotContains(t, pipeline[0]["$match"], common.BKDBAND)
+
+	// the words are matched with the name and ip fields of the table only
+	like := func(field, word string) map[string]interface{} {
+		return map[string]interface{}{field: map[string]interface{}{common.BKDBLIKE: word, "$options": "i"}}
+	}
+	query = Query{QueryString: "*192.168* 主机"}
+	pipeline = query.toMongoPipeline(common.BKTableNameBaseHost, []string{"192.168", "主机"}, false)
+	require.Len(t, pipeline, 1)
+	require.NotContains(t, pipeline[0]["$match"], "$text")
+	require.Equal(t, []map[string]interface{}{
+		{common.BKDBOR: []map[string]interface{}{
+			like(common.BKHostInnerIPField, `192\.168`),
+			like(common.BKHostOuterIPField, `192\.168`),
+			like(common.BKHostNameField, `192\.168`),
+		}},
+		{common.BKDBOR: []map[string]interface{}{
+			like(common.BKHostInnerIPField, "主机"),
+			like(common.BKHostOuterIPField, "主机"),
+			like(common.BKHostNameField, "主机"),
+		}},
+	}, pipeline[0]["$match"].(map[string]interface{})[common.BKDBAND])
+
+	pipeline = query.toMongoPipeline(common.BKTableNameBaseApp, []string{"db"}, false)
+	require.Equal(t, []map[string]interface{}{
+		{common.BKDBOR: []map[string]interface{}{like(common.BKAppNameField, "db")}},
+	}, pipeline[0]["$match"].(map[string]interface{})[common.BKDBAND])
+}
+
+func TestFullTextHighlight(t *testing.T) {
+	source := map[string]interface{}{
+		common.BKObjIDField:    "switch",
+		common.BKInstNameField: "Web-Switch",
+		"bk_ip":                "192.168.1.1",
+		"bk_count":             12,
+	}
+
+	highlighter := newFullTextHighlighter([]string{"switch", "192.168"}, "switch 192.168")
+	require.Equal(t, map[string][]string{
+		common.BKInstNameField: {"Web-<em>Switch</em>"},
+		"bk_ip":                {"<em>192.168</em>.1.1"},
+	}, highlighter.highlight(source))
+
+	highlighter = newFullTextHighlighter([]string{"switch"}, "switch")
+	require.Equal(t, map[string][]string{
+		common.BKObjIDField:    {"<em>switch</em>"},
+		common.BKInstNameField: {"Web-<em>Switch</em>"},
+	}, highlighter.highlight(source))
+
+	highlighter = newFullTextHighlighter(nil, "")
+	require.Empty(t, highlighter.highlight(source))
+}
+
+func TestMergeFullTextResults(t *testing.T) {
+	hit := func(table string, score float64) SearchResult {
+		return SearchResult{Type: searchResultType(table), Score: score}
+	}
+	results := []fullTextTableResult{
+		{
+			table:  common.BKTableNameBaseHost,
+			counts: map[string]int64{"": 2},
+			hits:   []SearchResult{hit(common.BKTableNameBaseHost, 3), hit(common.BKTableNameBaseHost, 1)},
+		},
+		{
+			table:  common.BKTableNameBaseInst,
+			counts: map[string]int64{"switch": 1, "router": 3},
+			hits:   []SearchResult{hit(common.BKTableNameBaseInst, 4), hit(common.BKTableNameBaseInst, 2)},
+		},
+		{
+			table:  common.BKTableNameBaseApp,
+			counts: map[string]int64{"": 5},
+			hits:   []SearchResult{hit(common.BKTableNameBaseApp, 2.5)},
+		},
+	}
+
+	merged := mergeFullTextResults(results, 0, 10)
+	require.EqualValues(t, 11, merged.Total)
+	require.Equal(t, []Aggregation{
+		{Key: "router", Count: 3},
+		{Key: "switch", Count: 1},
+		{Key: common.TypeApplication, Count: 5},
+		{Key: common.TypeHost, Count: 2},
+	}, merged.Aggregations)
+	scores := make([]float64, len(merged.Hits))
+	for i, h := range merged.Hits {
+		scores[i] = h.Score
+	}
+	require.Equal(t, []float64{4, 3, 2.5, 2, 1}, scores)
+
+	merged = mergeFullTextResults(results, 1, 2)
+	require.Equal(t, []SearchResult{hit(common.BKTableNameBaseHost, 3), hit(common.BKTableNameBaseApp, 2.5)}, merged.Hits)
+
+	merged = mergeFullTextResults(results, 5, 2)
+	require.EqualValues(t, 11, merged.Total)
+	require.Empty(t, merged.Hits)
+}
@@ -22,6 +22,7 @@ import (
 	"configcenter/src/common/mapstr"
 	"configcenter/src/common/metadata"
 	paraparse "configcenter/src/common/paraparse"
+	"configcenter/src/common/querybuilder"
 	"configcenter/src/common/util"
 	"configcenter/src/scene_server/topo_server/core/operation"
 	"configcenter/src/scene_server/topo_server/core/types"
@@ -369,6 +370,16 @@ func (s *Service) SearchInsts(params types.ContextParams, pathParams, queryParam
 	query.Limit = page.Limit
 	query.Sort = page.Sort
 	query.Start = page.Start
+	if query.PropertyFilter, err = parsePropertyFilter(params, data); err != nil {
+		return nil, err
+	}
+	matched, err := s.applyAssociationFilters(params, objID, data, query)
+	if err != nil {
+		return nil, err
+	}
+	if !matched {
+		return mapstr.MapStr{"count": 0, "info": make([]mapstr.MapStr, 0)}, nil
+	}
 
 	cnt, instItems, err := s.Core.InstOperation().FindInst(params, obj, query, false)
 	if nil != err {
@@ -406,6 +417,16 @@ func (s *Service) SearchInstAndAssociationDetail(params types.ContextParams, pat
 	query.Limit = page.Limit
 	query.Sort = page.Sort
 	query.Start = page.Start
+	if query.PropertyFilter, err = parsePropertyFilter(params, data); err != nil {
+		return nil, err
+	}
+	matched, err := s.applyAssociationFilters(params, objID, data, query)
+	if err != nil {
+		return nil, err
+	}
+	if !matched {
+		return mapstr.MapStr{"count": 0, "info": make([]mapstr.MapStr, 0)}, nil
+	}
 
 	cnt, instItems, err := s.Core.InstOperation().FindInst(params, obj, query, true)
 	if nil != err {
@@ -721,3 +742,68 @@ func (s *Service) SearchInstAssociationWithOtherObject(params types.ContextParam
 		"page":  input.Limit,
 	}, err
 }
+
+// parsePropertyFilter parse the optional property filter of the instance search,
+// which could be querybuilder rules or an expression like `bk_inst_name begins_with "web" AND bk_cpu >= 8`
+func parsePropertyFilter(params types.ContextParams, data mapstr.MapStr) (*querybuilder.QueryFilter, error) {
+	input := struct {
+		PropertyFilter *querybuilder.QueryFilter `json:"property_filter"`
+	}{}
+	if err := data.MarshalJSONInto(&input); err != nil {
+		blog.Errorf("[api-inst] failed to parse the property filter, err: %v, rid: %s", err, params.ReqID)
+		return nil, params.Err.Errorf(common.CCErrCommParamsInvalid, "property_filter")
+	}
+	if input.PropertyFilter == nil || input.PropertyFilter.Rule == nil {
+		return nil, nil
+	}
+	if key, err := input.PropertyFilter.Validate(); err != nil {
+		blog.Errorf("[api-inst] invalid property filter, key: %s, err: %v, rid: %s", key, err, params.ReqID)
+		return nil, params.Err.Errorf(common.CCErrCommParamsInvalid, "property_filter."+key)
+	}
+	return input.PropertyFilter, nil
+}
+
+// applyAssociationFilters limits the instances of the query to the ones associated to the instances
+// matching the optional association filters, returns false if no instance matches.
+func (s *Service) applyAssociationFilters(params types.ContextParams, objID string, data mapstr.MapStr, query *metadata.QueryInput) (bool, error) {
+	input := struct {
+		AssociationFilters []metadata.InstAsstFilter `json:"association_filters"`
+	}{}
+	if err := data.MarshalJSONInto(&input); err != nil {
+		blog.Errorf("[api-inst] failed to parse the association filters, err: %v, rid: %s", err, params.ReqID)
+		return false, params.Err.Errorf(common.CCErrCommParamsInvalid, "association_filters")
+	}
+	if len(input.AssociationFilters) == 0 {
+		return true, nil
+	}
+
+	option := &metadata.SearchInstIDsByAsstFilterOption{Filters: input.AssociationFilters}
+	if params.MetaData != nil {
+		bizID, err := metadata.BizIDFromMetadata(*params.MetaData)
+		if err != nil {
+			blog.Errorf("[api-inst] failed to parse the business id, err: %v, rid: %s", err, params.ReqID)
+			return false, params.Err.Errorf(common.CCErrCommParamsInvalid, metadata.BKMetadata)
+		}
+		option.BizID = bizID
+	}
+	rsp, err := s.Engine.CoreAPI.CoreService().Association().SearchInstIDsByAsstFilter(params.Context, params.Header, objID, option)
+	if err != nil {
+		blog.Errorf("[api-inst] failed to search the instances by association filters, err: %v, rid: %s", err, params.ReqID)
+		return false, params.Err.Error(common.CCErrCommHTTPDoRequestFailed)
+	}
+	if !rsp.Result {
+		blog.Errorf("[api-inst] failed to search the instances by association filters, err: %s, rid: %s", rsp.ErrMsg, params.ReqID)
+		return false, params.Err.New(rsp.Code, rsp.ErrMsg)
+	}
+	if len(rsp.Data) == 0 {
+		return false, nil
+	}
+
+	instIDCond := mapstr.MapStr{common.GetInstIDField(objID): mapstr.MapStr{common.BKDBIN: rsp.Data}}
+	if cond, ok := query.Condition.(map[string]interface{}); ok && len(cond) == 0 {
+		query.Condition = instIDCond
+	} else {
+		query.Condition = mapstr.MapStr{common.BKDBAND: []interface{}{query.Condition, instIDCond}}
+	}
+	return true, nil
+}
@@ -36,7 +36,6 @@ import (
 	"configcenter/src/scene_server/topo_server/core"
 	"configcenter/src/scene_server/topo_server/core/types"
 	"configcenter/src/storage/dal"
-	"configcenter/src/thirdpartyclient/elasticsearch"
 
 	"github.com/emicklei/go-restful"
 )
@@ -47,7 +46,7 @@ type Service struct {
 	Core        core.Core
 	Config      options.Config
 	AuthManager *extensions.AuthManager
-	Es          *elasticsearch.EsSrv
+	FullText    FullTextSearcher
 	Error       errors.CCErrorIf
 	Language    language.CCLanguageIf
 	actions     []action
@@ -39,7 +39,12 @@ type CoreServer struct {
 
 func (t *CoreServer) onCoreServiceConfigUpdate(previous, current cc.ProcessConfig) {
 
-	t.Config.Mongo = mongo.ParseConfigFromKV("mongodb", current.ConfigMap)
+	var err error
+	t.Config.Mongo, err = mongo.ParseConfigFromKV("mongodb", current.ConfigMap)
+	if err != nil {
+		blog.Errorf("parse mongodb config failed, err: %v", err)
+		return
+	}
 	t.Config.Redis = redis.ParseConfigFromKV("redis", current.ConfigMap)
 
 	blog.V(3).Infof("the new cfg:%#v the origin cfg:%#v", t.Config, current.ConfigMap)
@@ -0,0 +1,266 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.,
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the ",License",); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an ",AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package association
+
+import (
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+	"configcenter/src/source_controller/coreservice/core"
+)
+
+// asstNode is an instance in the association graph, the instance ids of different models may be the same
+type asstNode struct {
+	ObjectID string `bson:"obj"`
+	InstID   int64  `bson:"inst"`
+}
+
+// asstSide is the fields of one side of an instance association
+type asstSide struct {
+	objField  string
+	instField string
+}
+
+var (
+	asstSrcSide  = asstSide{objField: common.BKObjIDField, instField: common.BKInstIDField}
+	asstDestSide = asstSide{objField: common.BKAsstObjIDField, instField: common.BKAsstInstIDField}
+)
+
+// asstFilterBizHostsField is the field the module relations of the hosts are joined to when the hosts are
+// limited to a business
+const asstFilterBizHostsField = "_biz_hosts"
+
+// SearchInstIDsByAsstFilter returns the ids of the instances of objID that are associated to the instances
+// matching the filters, the filters are ANDed. The associated instances and the associations are limited to
+// option.BizID if it is set, and every hop may reach at most metadata.InstAsstFilterMaxInstances instances.
+func (m *associationInstance) SearchInstIDsByAsstFilter(ctx core.ContextParams, objID string, option metadata.SearchInstIDsByAsstFilterOption) ([]int64, error) {
+	if len(option.Filters) == 0 {
+		return nil, ctx.Error.CCErrorf(common.CCErrCommParamsInvalid, "filters")
+	}
+
+	instIDs := make([]int64, 0)
+	for index := range option.Filters {
+		filter := option.Filters[index]
+		if key, err := filter.Validate(); err != nil {
+			blog.Errorf("search instance ids by association filter failed, invalid filter %d, key: %s, err: %v, rid: %s", index, key, err, ctx.ReqID)
+			return nil, ctx.Error.CCErrorf(common.CCErrCommParamsInvalid, "filters."+key)
+		}
+
+		ids, err := m.searchInstIDsByAsstFilter(ctx, objID, option.BizID, filter)
+		if err != nil {
+			return nil, err
+		}
+		if index == 0 {
+			instIDs = ids
+		} else {
+			instIDs = util.IntArrIntersection(instIDs, ids)
+		}
+		if len(instIDs) == 0 {
+			break
+		}
+	}
+	return instIDs, nil
+}
+
+// searchInstIDsByAsstFilter walks the instance associations from the instances matching the filter hop by hop,
+// the instances of objID reached in no more than filter.MaxHops hops are returned.
+func (m *associationInstance) searchInstIDsByAsstFilter(ctx core.ContextParams, objID string, bizID int64, filter metadata.InstAsstFilter) ([]int64, error) {
+	frontier, err := m.searchAsstFilterTargets(ctx, bizID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	visited := make(map[asstNode]bool)
+	for _, node := range frontier {
+		visited[node] = true
+	}
+
+	instIDs := make([]int64, 0)
+	found := make(map[int64]bool)
+	for hop := 0; hop < filter.MaxHops && len(frontier) > 0; hop++ {
+		neighbors := make([]asstNode, 0)
+		if filter.Direction != metadata.InstAsstFilterDirectionDest {
+			// the searched instance is the source, walk from the destination to the source
+			nodes, err := m.searchAsstNeighbors(ctx, bizID, frontier, filter.AsstKindID, asstDestSide, asstSrcSide)
+			if err != nil {
+				return nil, err
+			}
+			neighbors = append(neighbors, nodes...)
+		}
+		if filter.Direction != metadata.InstAsstFilterDirectionSrc {
+			nodes, err := m.searchAsstNeighbors(ctx, bizID, frontier, filter.AsstKindID, asstSrcSide, asstDestSide)
+			if err != nil {
+				return nil, err
+			}
+			neighbors = append(neighbors, nodes...)
+		}
+
+		next := make([]asstNode, 0)
+		for _, node := range neighbors {
+			if node.ObjectID == objID && !found[node.InstID] {
+				found[node.InstID] = true
+				instIDs = append(instIDs, node.InstID)
+			}
+			if visited[node] {
+				continue
+			}
+			visited[node] = true
+			next = append(next, node)
+		}
+		if len(instIDs) > metadata.InstAsstFilterMaxInstances {
+			return nil, m.asstFilterExceedLimitError(ctx, objID)
+		}
+		frontier = next
+	}
+	return instIDs, nil
+}
+
+// searchAsstFilterTargets returns the instances of filter.ObjectID in the business that match filter.Condition
+func (m *associationInstance) searchAsstFilterTargets(ctx core.ContextParams, bizID int64, filter metadata.InstAsstFilter) ([]asstNode, error) {
+	cond := mapstr.MapStr{}
+	if filter.Condition != nil && filter.Condition.Rule != nil {
+		mgoFilter, key, err := filter.Condition.ToMgo()
+		if err != nil {
+			blog.Errorf("search association filter targets failed, invalid condition, key: %s, err: %v, rid: %s", key, err, ctx.ReqID)
+			return nil, ctx.Error.CCErrorf(common.CCErrCommParamsInvalid, "condition."+key)
+		}
+		cond = mgoFilter
+	}
+
+	tableName := common.GetInstTableName(filter.ObjectID)
+	if tableName == common.BKTableNameBaseInst {
+		cond = mapstr.MapStr{common.BKDBAND: []interface{}{cond, mapstr.MapStr{common.BKObjIDField: filter.ObjectID}}}
+	}
+	cond = util.SetQueryOwner(cond, ctx.SupplierAccount)
+
+	bizStages, err := m.asstFilterBizStages(ctx, filter.ObjectID, bizID)
+	if err != nil {
+		return nil, err
+	}
+
+	pipeline := []mapstr.MapStr{{common.BKDBMatch: cond}}
+	pipeline = append(pipeline, bizStages...)
+	pipeline = append(pipeline,
+		mapstr.MapStr{common.BKDBGroup: mapstr.MapStr{"_id": "$" + common.GetInstIDField(filter.ObjectID)}},
+		mapstr.MapStr{"$limit": metadata.InstAsstFilterMaxInstances + 1},
+	)
+	result := make([]struct {
+		ID int64 `bson:"_id"`
+	}, 0)
+	if err := m.dbProxy.Table(tableName).AggregateAll(ctx, pipeline, &result); err != nil {
+		blog.Errorf("search association filter targets of %s failed, err: %v, rid: %s", filter.ObjectID, err, ctx.ReqID)
+		return nil, ctx.Error.Error(common.CCErrCommDBSelectFailed)
+	}
+	if len(result) > metadata.InstAsstFilterMaxInstances {
+		return nil, m.asstFilterExceedLimitError(ctx, filter.ObjectID)
+	}
+
+	nodes := make([]asstNode, len(result))
+	for index, item := range result {
+		nodes[index] = asstNode{ObjectID: filter.ObjectID, InstID: item.ID}
+	}
+	return nodes, nil
+}
+
+// searchAsstNeighbors returns the instances associated to the nodes by the associations of the business,
+// the nodes are on the from side of the associations and the returned instances are on the to side.
+func (m *associationInstance) searchAsstNeighbors(ctx core.ContextParams, bizID int64, nodes []asstNode, asstKindID string, from, to asstSide) ([]asstNode, error) {
+	objInstIDs := make(map[string][]int64)
+	for _, node := range nodes {
+		objInstIDs[node.ObjectID] = append(objInstIDs[node.ObjectID], node.InstID)
+	}
+	orCond := make([]interface{}, 0, len(objInstIDs))
+	for objID, instIDs := range objInstIDs {
+		orCond = append(orCond, mapstr.MapStr{
+			from.objField:  objID,
+			from.instField: mapstr.MapStr{common.BKDBIN: instIDs},
+		})
+	}
+
+	cond := mapstr.MapStr{common.BKDBOR: orCond}
+	if len(asstKindID) > 0 {
+		cond[common.AssociationKindIDField] = asstKindID
+	}
+	if bizID != 0 {
+		cond = mapstr.MapStr{common.BKDBAND: []interface{}{cond, metadata.NewPublicOrBizConditionByBizID(bizID)}}
+	}
+	cond = util.SetQueryOwner(cond, ctx.SupplierAccount)
+
+	pipeline := []mapstr.MapStr{
+		{common.BKDBMatch: cond},
+		{common.BKDBGroup: mapstr.MapStr{"_id": mapstr.MapStr{"obj": "$" + to.objField, "inst": "$" + to.instField}}},
+		{"$limit": metadata.InstAsstFilterMaxInstances + 1},
+	}
+	result := make([]struct {
+		ID asstNode `bson:"_id"`
+	}, 0)
+	if err := m.dbProxy.Table(common.BKTableNameInstAsst).AggregateAll(ctx, pipeline, &result); err != nil {
+		blog.Errorf("search associated instances failed, err: %v, rid: %s", err, ctx.ReqID)
+		return nil, ctx.Error.Error(common.CCErrCommDBSelectFailed)
+	}
+	if len(result) > metadata.InstAsstFilterMaxInstances {
+		return nil, m.asstFilterExceedLimitError(ctx, "associated instances")
+	}
+
+	neighbors := make([]asstNode, len(result))
+	for index, item := range result {
+		neighbors[index] = item.ID
+	}
+	return neighbors, nil
+}
+
+// asstFilterBizStages returns the pipeline stages that limit the instances of objID to the business,
+// the mainline instances have the business id, the hosts are in the business by their module relations,
+// and the other instances are public or labeled with the business.
+func (m *associationInstance) asstFilterBizStages(ctx core.ContextParams, objID string, bizID int64) ([]mapstr.MapStr, error) {
+	if bizID == 0 {
+		return nil, nil
+	}
+
+	switch objID {
+	case common.BKInnerObjIDHost:
+		return []mapstr.MapStr{
+			{"$lookup": mapstr.MapStr{
+				"from":         common.BKTableNameModuleHostConfig,
+				"localField":   common.BKHostIDField,
+				"foreignField": common.BKHostIDField,
+				"as":           asstFilterBizHostsField,
+			}},
+			{common.BKDBMatch: mapstr.MapStr{asstFilterBizHostsField + "." + common.BKAppIDField: bizID}},
+		}, nil
+	case common.BKInnerObjIDApp, common.BKInnerObjIDSet, common.BKInnerObjIDModule:
+		return []mapstr.MapStr{{common.BKDBMatch: mapstr.MapStr{common.BKAppIDField: bizID}}}, nil
+	}
+
+	cond := mapstr.MapStr{
+		common.BKObjIDField:           objID,
+		common.AssociationKindIDField: common.AssociationKindMainline,
+	}
+	cond = util.SetQueryOwner(cond, ctx.SupplierAccount)
+	count, err := m.dbProxy.Table(common.BKTableNameObjAsst).Find(cond).Count(ctx)
+	if err != nil {
+		blog.Errorf("check whether %s is a mainline object failed, err: %v, rid: %s", objID, err, ctx.ReqID)
+		return nil, ctx.Error.Error(common.CCErrCommDBSelectFailed)
+	}
+	if count > 0 {
+		return []mapstr.MapStr{{common.BKDBMatch: mapstr.MapStr{common.BKAppIDField: bizID}}}, nil
+	}
+	return []mapstr.MapStr{{common.BKDBMatch: metadata.NewPublicOrBizConditionByBizID(bizID)}}, nil
+}
+
+func (m *associationInstance) asstFilterExceedLimitError(ctx core.ContextParams, name string) error {
+	blog.Errorf("association filter reaches more than %d %s, rid: %s", metadata.InstAsstFilterMaxInstances, name, ctx.ReqID)
+	return ctx.Error.CCErrorf(common.CCErrCommXXExceedLimit, name, metadata.InstAsstFilterMaxInstances)
+}
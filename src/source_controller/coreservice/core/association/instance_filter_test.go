@@ -0,0 +1,178 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.,
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the ",License",); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an ",AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package association_test
+
+import (
+	"context"
+	"testing"
+
+	"configcenter/src/common"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/querybuilder"
+	"configcenter/src/source_controller/coreservice/core/association"
+	"configcenter/src/storage/dal/mongo/memory"
+
+	"github.com/stretchr/testify/require"
+)
+
+func bizLabel(bizID string) mapstr.MapStr {
+	return mapstr.MapStr{metadata.BKLabel: mapstr.MapStr{metadata.LabelBusinessID: bizID}}
+}
+
+// newAsstFilterTestDB returns the hosts 1, 2 and 3 in the businesses 1, 2 and 1, the switches 10, 11 and 12
+// and the router 20, host 1 and host 3 connect to switch 10, host 2 connects to switch 11, host 3 connects to
+// switch 12 and switch 10 connects to router 20, switch 11 and the association of host 3 to switch 10 are
+// labeled with business 2.
+func newAsstFilterTestDB(t *testing.T) *memory.Memory {
+	db := memory.New()
+	ctx := context.Background()
+	owner := defaultCtx.SupplierAccount
+
+	hosts := []mapstr.MapStr{
+		{common.BKHostIDField: int64(1), common.BKOSTypeField: "linux", common.BKOwnerIDField: owner},
+		{common.BKHostIDField: int64(2), common.BKOSTypeField: "linux", common.BKOwnerIDField: owner},
+		{common.BKHostIDField: int64(3), common.BKOSTypeField: "windows", common.BKOwnerIDField: owner},
+	}
+	require.NoError(t, db.Table(common.BKTableNameBaseHost).Insert(ctx, hosts))
+
+	relations := []mapstr.MapStr{
+		{common.BKHostIDField: int64(1), common.BKAppIDField: int64(1), common.BKModuleIDField: int64(100)},
+		{common.BKHostIDField: int64(2), common.BKAppIDField: int64(2), common.BKModuleIDField: int64(200)},
+		{common.BKHostIDField: int64(3), common.BKAppIDField: int64(1), common.BKModuleIDField: int64(100)},
+	}
+	require.NoError(t, db.Table(common.BKTableNameModuleHostConfig).Insert(ctx, relations))
+
+	insts := []mapstr.MapStr{
+		{common.BKInstIDField: int64(10), common.BKObjIDField: "bk_switch", "bk_vendor": "x", common.BKOwnerIDField: owner},
+		{common.BKInstIDField: int64(11), common.BKObjIDField: "bk_switch", "bk_vendor": "x", common.BKOwnerIDField: owner,
+			metadata.BKMetadata: bizLabel("2")},
+		{common.BKInstIDField: int64(12), common.BKObjIDField: "bk_switch", "bk_vendor": "y", common.BKOwnerIDField: owner},
+		{common.BKInstIDField: int64(20), common.BKObjIDField: "bk_router", "bk_vendor": "x", common.BKOwnerIDField: owner},
+	}
+	require.NoError(t, db.Table(common.BKTableNameBaseInst).Insert(ctx, insts))
+
+	assts := []mapstr.MapStr{
+		{common.BKObjIDField: "host", common.BKInstIDField: int64(1), common.BKAsstObjIDField: "bk_switch",
+			common.BKAsstInstIDField: int64(10), common.AssociationKindIDField: "connect", common.BKOwnerIDField: owner},
+		{common.BKObjIDField: "host", common.BKInstIDField: int64(2), common.BKAsstObjIDField: "bk_switch",
+			common.BKAsstInstIDField: int64(11), common.AssociationKindIDField: "connect", common.BKOwnerIDField: owner},
+		{common.BKObjIDField: "host", common.BKInstIDField: int64(3), common.BKAsstObjIDField: "bk_switch",
+			common.BKAsstInstIDField: int64(10), common.AssociationKindIDField: "connect", common.BKOwnerIDField: owner,
+			metadata.BKMetadata: bizLabel("2")},
+		{common.BKObjIDField: "host", common.BKInstIDField: int64(3), common.BKAsstObjIDField: "bk_switch",
+			common.BKAsstInstIDField: int64(12), common.AssociationKindIDField: "connect", common.BKOwnerIDField: owner},
+		{common.BKObjIDField: "bk_switch", common.BKInstIDField: int64(10), common.BKAsstObjIDField: "bk_router",
+			common.BKAsstInstIDField: int64(20), common.AssociationKindIDField: "belong", common.BKOwnerIDField: owner},
+	}
+	require.NoError(t, db.Table(common.BKTableNameInstAsst).Insert(ctx, assts))
+	return db
+}
+
+func equalCondition(field string, value interface{}) *querybuilder.QueryFilter {
+	rule := querybuilder.AtomRule{Field: field, Operator: querybuilder.OperatorEqual, Value: value}
+	return &querybuilder.QueryFilter{Rule: querybuilder.CombinedRule{Condition: querybuilder.ConditionAnd, Rules: []querybuilder.Rule{rule}}}
+}
+
+func TestSearchInstIDsByAsstFilter(t *testing.T) {
+	asstMgr := association.New(newAsstFilterTestDB(t), &mockDependences{})
+
+	vendorX := equalCondition("bk_vendor", "x")
+	linux := equalCondition(common.BKOSTypeField, "linux")
+	tests := []struct {
+		name    string
+		objID   string
+		option  metadata.SearchInstIDsByAsstFilterOption
+		wantIDs []int64
+	}{
+		{
+			name:    "hosts connected to switches of vendor x",
+			objID:   common.BKInnerObjIDHost,
+			option:  metadata.SearchInstIDsByAsstFilterOption{Filters: []metadata.InstAsstFilter{{ObjectID: "bk_switch", Condition: vendorX}}},
+			wantIDs: []int64{1, 2, 3},
+		},
+		{
+			name:    "switches and associations of other businesses are skipped",
+			objID:   common.BKInnerObjIDHost,
+			option:  metadata.SearchInstIDsByAsstFilterOption{BizID: 1, Filters: []metadata.InstAsstFilter{{ObjectID: "bk_switch", Condition: vendorX}}},
+			wantIDs: []int64{1},
+		},
+		{
+			name:    "hosts of other businesses are skipped",
+			objID:   "bk_switch",
+			option:  metadata.SearchInstIDsByAsstFilterOption{BizID: 2, Filters: []metadata.InstAsstFilter{{ObjectID: common.BKInnerObjIDHost, Condition: linux}}},
+			wantIDs: []int64{11},
+		},
+		{
+			name:  "host is the source of the associations",
+			objID: common.BKInnerObjIDHost,
+			option: metadata.SearchInstIDsByAsstFilterOption{Filters: []metadata.InstAsstFilter{
+				{ObjectID: "bk_switch", Condition: vendorX, Direction: metadata.InstAsstFilterDirectionDest}}},
+			wantIDs: []int64{},
+		},
+		{
+			name:  "association kind",
+			objID: common.BKInnerObjIDHost,
+			option: metadata.SearchInstIDsByAsstFilterOption{Filters: []metadata.InstAsstFilter{
+				{ObjectID: "bk_switch", Condition: vendorX, AsstKindID: "belong"}}},
+			wantIDs: []int64{},
+		},
+		{
+			name:  "routers reached from the hosts in two hops",
+			objID: "bk_router",
+			option: metadata.SearchInstIDsByAsstFilterOption{Filters: []metadata.InstAsstFilter{
+				{ObjectID: common.BKInnerObjIDHost, Condition: linux, MaxHops: 2}}},
+			wantIDs: []int64{20},
+		},
+		{
+			name:  "routers are not reached in one hop",
+			objID: "bk_router",
+			option: metadata.SearchInstIDsByAsstFilterOption{Filters: []metadata.InstAsstFilter{
+				{ObjectID: common.BKInnerObjIDHost, Condition: linux}}},
+			wantIDs: []int64{},
+		},
+		{
+			name:  "filters are ANDed",
+			objID: common.BKInnerObjIDHost,
+			option: metadata.SearchInstIDsByAsstFilterOption{Filters: []metadata.InstAsstFilter{
+				{ObjectID: "bk_switch", Condition: vendorX},
+				{ObjectID: "bk_switch", Condition: equalCondition("bk_vendor", "y")}}},
+			wantIDs: []int64{3},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ids, err := asstMgr.SearchInstIDsByAsstFilter(defaultCtx, tt.objID, tt.option)
+			require.NoError(t, err)
+			require.ElementsMatch(t, tt.wantIDs, ids)
+		})
+	}
+}
+
+func TestSearchInstIDsByAsstFilterLimit(t *testing.T) {
+	db := memory.New()
+	insts := make([]mapstr.MapStr, 0, metadata.InstAsstFilterMaxInstances+1)
+	for id := 1; id <= metadata.InstAsstFilterMaxInstances+1; id++ {
+		insts = append(insts, mapstr.MapStr{
+			common.BKInstIDField:  int64(id),
+			common.BKObjIDField:   "bk_switch",
+			common.BKOwnerIDField: defaultCtx.SupplierAccount,
+		})
+	}
+	require.NoError(t, db.Table(common.BKTableNameBaseInst).Insert(context.Background(), insts))
+	asstMgr := association.New(db, &mockDependences{})
+
+	option := metadata.SearchInstIDsByAsstFilterOption{Filters: []metadata.InstAsstFilter{{ObjectID: "bk_switch"}}}
+	_, err := asstMgr.SearchInstIDsByAsstFilter(defaultCtx, common.BKInnerObjIDHost, option)
+	require.Error(t, err)
+}
@@ -43,7 +43,7 @@ func (s *instDependences) DeleteInstAsst(ctx core.ContextParams, objID string, i
 }
 
 // SelectObjectAttWithParams select object att with params
-func (s *instDependences) SelectObjectAttWithParams(ctx core.ContextParams, objID string) (attribute []metadata.Attribute, err error) {
+func (s *instDependences) SelectObjectAttWithParams(ctx core.ContextParams, objID string, bizID int64) (attribute []metadata.Attribute, err error) {
 	return nil, nil
 }
 
@@ -82,7 +82,7 @@ func newModel(t *testing.T) core.ModelOperation {
 
 	db, err := local.NewMgo("mongodb://cc:cc@localhost:27010,localhost:27011,localhost:27012,localhost:27013/cmdb", time.Minute)
 	require.NoError(t, err)
-	return model.New(db, &mockDependences{})
+	return model.New(db, &mockDependences{}, nil)
 }
 
 func newAssociation(t *testing.T) core.AssociationOperation {
@@ -96,11 +96,11 @@ func newInstances(t *testing.T) core.InstanceOperation {
 
 	db, err := local.NewMgo("mongodb://cc:cc@localhost:27010,localhost:27011,localhost:27012,localhost:27013/cmdb", time.Minute)
 	require.NoError(t, err)
-	return instances.New(db, &instDependences{})
+	return instances.New(db, &instDependences{}, nil)
 }
 
 var defaultCtx = func() core.ContextParams {
-	err, _ := errors.New("../../../../../resources/errors/")
+	err, _ := errors.NewFactory("../../../../../resources/errors/")
 	lan, _ := language.New("../../../../../resources/language/")
 	return core.ContextParams{
 		Context:         context.Background(),
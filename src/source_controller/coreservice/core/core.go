@@ -120,6 +120,7 @@ type InstanceAssociation interface {
 	CreateManyInstanceAssociation(ctx ContextParams, inputParam metadata.CreateManyInstanceAssociation) (*metadata.CreateManyDataResult, error)
 	SearchInstanceAssociation(ctx ContextParams, inputParam metadata.QueryCondition) (*metadata.QueryResult, error)
 	DeleteInstanceAssociation(ctx ContextParams, inputParam metadata.DeleteOption) (*metadata.DeletedCount, error)
+	SearchInstIDsByAsstFilter(ctx ContextParams, objID string, option metadata.SearchInstIDsByAsstFilterOption) ([]int64, error)
 }
 
 // DataSynchronizeOperation manager data synchronize interface
@@ -185,6 +186,7 @@ type StatisticOperation interface {
 	UpdateOperationChart(ctx ContextParams, inputParam mapstr.MapStr) (interface{}, error)
 	SearchTimerChartData(ctx ContextParams, inputParam metadata.ChartConfig) (interface{}, error)
 	TimerFreshData(params ContextParams) error
+	AggregateInstances(ctx ContextParams, objID string, option metadata.InstAggregationOption) (*metadata.InstAggregationResult, error)
 }
 
 // Core core itnerfaces methods
@@ -31,6 +31,7 @@ type associationFindData struct {
 	dbProxy      dal.RDB
 	start        uint64
 	limit        uint64
+	sort         string
 	condition    mapstr.MapStr
 }
 
@@ -42,6 +43,7 @@ func NewSynchronizeFindAdapter(input *metadata.SynchronizeFindInfoParameter, dbP
 		dbProxy:      dbProxy,
 		start:        input.Start,
 		limit:        input.Limit,
+		sort:         input.Sort,
 		condition:    input.Condition,
 	}
 }
@@ -73,7 +75,7 @@ func (a *associationFindData) findModel(ctx core.ContextParams) ([]mapstr.MapStr
 
 func (a *associationFindData) dbQueryModel(ctx core.ContextParams, tableName string) ([]mapstr.MapStr, uint64, errors.CCError) {
 	info := make([]mapstr.MapStr, 0)
-	err := a.dbProxy.Table(tableName).Find(a.condition).Start(a.start).Limit(a.limit).All(ctx, &info)
+	err := a.dbProxy.Table(tableName).Find(a.condition).Sort(a.sort).Start(a.start).Limit(a.limit).All(ctx, &info)
 	if err != nil {
 		blog.Errorf("dbQueryModel info error. error:%s,rid:%s", err.Error(), ctx.ReqID)
 		return nil, 0, ctx.Error.Error(common.CCErrCommDBSelectFailed)
@@ -96,7 +98,7 @@ func (a *associationFindData) findAssociation(ctx core.ContextParams) ([]mapstr.
 
 func (a *associationFindData) dbQueryAssociation(ctx core.ContextParams) ([]mapstr.MapStr, uint64, errors.CCError) {
 	info := make([]mapstr.MapStr, 0)
-	err := a.dbProxy.Table(common.BKTableNameModuleHostConfig).Find(a.condition).Start(a.start).Limit(a.limit).All(ctx, &info)
+	err := a.dbProxy.Table(common.BKTableNameModuleHostConfig).Find(a.condition).Sort(a.sort).Start(a.start).Limit(a.limit).All(ctx, &info)
 	if err != nil {
 		blog.Errorf("dbQueryAssociation info error. error:%s,rid:%s", err.Error(), ctx.ReqID)
 		return nil, 0, ctx.Error.Error(common.CCErrCommDBSelectFailed)
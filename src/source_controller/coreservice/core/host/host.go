@@ -13,20 +13,19 @@
 package host
 
 import (
-	"gopkg.in/redis.v5"
-
 	"configcenter/src/common/eventclient"
 	"configcenter/src/source_controller/coreservice/core"
 	"configcenter/src/source_controller/coreservice/core/host/searcher"
 	"configcenter/src/source_controller/coreservice/core/host/transfer"
 	"configcenter/src/storage/dal"
+	"configcenter/src/storage/dal/redis"
 )
 
 var _ core.HostOperation = (*hostManager)(nil)
 
 type hostManager struct {
 	DbProxy      dal.RDB
-	Cache        *redis.Client
+	Cache        redis.Client
 	EventCli     eventclient.Client
 	hostTransfer *transfer.TransferManager
 	dependent    transfer.OperationDependence
@@ -34,7 +33,7 @@ type hostManager struct {
 }
 
 // New create a new model manager instance
-func New(dbProxy dal.RDB, cache *redis.Client, dependent transfer.OperationDependence) core.HostOperation {
+func New(dbProxy dal.RDB, cache redis.Client, dependent transfer.OperationDependence) core.HostOperation {
 
 	coreMgr := &hostManager{
 		DbProxy:   dbProxy,
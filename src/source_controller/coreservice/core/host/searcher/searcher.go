@@ -20,16 +20,15 @@ import (
 	"configcenter/src/common/metadata"
 	"configcenter/src/common/util"
 	"configcenter/src/storage/dal"
-
-	"gopkg.in/redis.v5"
+	"configcenter/src/storage/dal/redis"
 )
 
 type Searcher struct {
 	DbProxy dal.RDB
-	Cache   *redis.Client
+	Cache   redis.Client
 }
 
-func New(db dal.RDB, cache *redis.Client) Searcher {
+func New(db dal.RDB, cache redis.Client) Searcher {
 	return Searcher{
 		DbProxy: db,
 		Cache:   cache,
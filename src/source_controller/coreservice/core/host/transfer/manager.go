@@ -13,8 +13,6 @@
 package transfer
 
 import (
-	"gopkg.in/redis.v5"
-
 	"configcenter/src/common"
 	"configcenter/src/common/blog"
 	"configcenter/src/common/condition"
@@ -24,12 +22,13 @@ import (
 	"configcenter/src/common/util"
 	"configcenter/src/source_controller/coreservice/core"
 	"configcenter/src/storage/dal"
+	"configcenter/src/storage/dal/redis"
 )
 
 type TransferManager struct {
 	dbProxy    dal.RDB
 	eventCli   eventclient.Client
-	cache      *redis.Client
+	cache      redis.Client
 	dependence OperationDependence
 }
 
@@ -39,7 +38,7 @@ type OperationDependence interface {
 	UpdateModelInstance(ctx core.ContextParams, objID string, param metadata.UpdateOption) (*metadata.UpdatedCount, error)
 }
 
-func New(db dal.RDB, cache *redis.Client, ec eventclient.Client, dependence OperationDependence) *TransferManager {
+func New(db dal.RDB, cache redis.Client, ec eventclient.Client, dependence OperationDependence) *TransferManager {
 	return &TransferManager{
 		dbProxy:    db,
 		cache:      cache,
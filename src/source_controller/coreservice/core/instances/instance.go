@@ -13,8 +13,6 @@
 package instances
 
 import (
-	redis "gopkg.in/redis.v5"
-
 	"configcenter/src/common"
 	"configcenter/src/common/blog"
 	"configcenter/src/common/condition"
@@ -26,6 +24,7 @@ import (
 	"configcenter/src/common/util"
 	"configcenter/src/source_controller/coreservice/core"
 	"configcenter/src/storage/dal"
+	"configcenter/src/storage/dal/redis"
 )
 
 var _ core.InstanceOperation = (*instanceManager)(nil)
@@ -34,12 +33,12 @@ type instanceManager struct {
 	dbProxy   dal.RDB
 	dependent OperationDependences
 	validator validator
-	Cache     *redis.Client
+	Cache     redis.Client
 	EventCli  eventclient.Client
 }
 
 // New create a new instance manager instance
-func New(dbProxy dal.RDB, dependent OperationDependences, cache *redis.Client) core.InstanceOperation {
+func New(dbProxy dal.RDB, dependent OperationDependences, cache redis.Client) core.InstanceOperation {
 	return &instanceManager{
 		dbProxy:   dbProxy,
 		dependent: dependent,
@@ -182,6 +181,19 @@ func (m *instanceManager) UpdateModelInstance(ctx core.ContextParams, objID stri
 }
 
 func (m *instanceManager) SearchModelInstance(ctx core.ContextParams, objID string, inputParam metadata.QueryCondition) (*metadata.QueryResult, error) {
+	if inputParam.PropertyFilter != nil {
+		propertyFilter, key, err := inputParam.PropertyFilter.ToMgo()
+		if err != nil {
+			blog.Errorf("SearchModelInstance failed, invalid property filter, key: %s, err: %v, rid: %s", key, err, ctx.ReqID)
+			return &metadata.QueryResult{}, ctx.Error.CCErrorf(common.CCErrCommParamsInvalid, "property_filter."+key)
+		}
+		if len(inputParam.Condition) == 0 {
+			inputParam.Condition = propertyFilter
+		} else {
+			inputParam.Condition = mapstr.MapStr{common.BKDBAND: []interface{}{inputParam.Condition, propertyFilter}}
+		}
+	}
+
 	condition, err := mongo.NewConditionFromMapStr(inputParam.Condition)
 	if nil != err {
 		blog.Errorf("SearchModelInstance failed, parse condition failed, inputParam: %+v, err: %+v, rid: %s", inputParam, err, ctx.ReqID)
@@ -26,14 +26,13 @@ import (
 	"configcenter/src/common/util"
 	"configcenter/src/source_controller/coreservice/core"
 	"configcenter/src/storage/dal"
-
-    redis "gopkg.in/redis.v5"
+	"configcenter/src/storage/dal/redis"
 )
 
 type modelAttribute struct {
 	model   *modelManager
 	dbProxy dal.RDB
-	cache   *redis.Client
+	cache   redis.Client
 }
 
 func (m *modelAttribute) CreateModelAttributes(ctx core.ContextParams, objID string, inputParam metadata.CreateModelAttributes) (dataResult *metadata.CreateManyDataResult, err error) {
@@ -16,8 +16,6 @@ import (
 	"fmt"
 	"time"
 
-	redis "gopkg.in/redis.v5"
-
 	"configcenter/src/common"
 	"configcenter/src/common/blog"
 	"configcenter/src/common/lock"
@@ -27,6 +25,7 @@ import (
 	"configcenter/src/common/util"
 	"configcenter/src/source_controller/coreservice/core"
 	"configcenter/src/storage/dal"
+	"configcenter/src/storage/dal/redis"
 )
 
 var _ core.ModelOperation = (*modelManager)(nil)
@@ -37,12 +36,12 @@ type modelManager struct {
 	*modelClassification
 	*modelAttrUnique
 	dbProxy   dal.RDB
-	cache     *redis.Client
+	cache     redis.Client
 	dependent OperationDependences
 }
 
 // New create a new model manager instance
-func New(dbProxy dal.RDB, dependent OperationDependences, cache *redis.Client) core.ModelOperation {
+func New(dbProxy dal.RDB, dependent OperationDependences, cache redis.Client) core.ModelOperation {
 
 	coreMgr := &modelManager{dbProxy: dbProxy, dependent: dependent, cache: cache}
 
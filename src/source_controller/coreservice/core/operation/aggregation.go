@@ -0,0 +1,350 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.,
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the ",License",); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an ",AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package operation
+
+import (
+	"strconv"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+	"configcenter/src/source_controller/coreservice/core"
+)
+
+// hostTopoFields are the topology fields of the hosts, they are joined from the host module relations
+var hostTopoFields = map[string]bool{
+	common.BKAppIDField:    true,
+	common.BKSetIDField:    true,
+	common.BKModuleIDField: true,
+}
+
+const (
+	// hostTopoAlias is the field the host module relations are joined as
+	hostTopoAlias = "host_topo"
+	// distinctValueAlias is the field the values of the distinct count metrics are unwound as
+	distinctValueAlias = "_distinct_value"
+	// aggregationSortAlias is the field the sort keys of the groups are put in
+	aggregationSortAlias = "_sort_keys"
+)
+
+// numericMetrics are the metrics that only support the numeric fields
+var numericMetrics = map[string]bool{
+	metadata.AggregationOpSum: true,
+	metadata.AggregationOpAvg: true,
+	metadata.AggregationOpMin: true,
+	metadata.AggregationOpMax: true,
+}
+
+// AggregateInstances groups the instances of the model by the fields and computes the metrics of each group
+func (m *operationManager) AggregateInstances(ctx core.ContextParams, objID string, option metadata.InstAggregationOption) (*metadata.InstAggregationResult, error) {
+	if key, err := option.Validate(); err != nil {
+		blog.Errorf("aggregate instances of %s failed, invalid option, key: %s, err: %v, rid: %v", objID, key, err, ctx.ReqID)
+		return nil, ctx.Error.CCErrorf(common.CCErrCommParamsInvalid, key)
+	}
+
+	fieldTypes, err := m.getAggregationFieldTypes(ctx, objID)
+	if err != nil {
+		return nil, err
+	}
+
+	isHost := objID == common.BKInnerObjIDHost
+	joinTopo := isHost && option.BizID != 0
+	topoFields := make([]string, 0)
+	ownFields := make([]string, 0)
+	addField := func(field string) {
+		if isHost && hostTopoFields[field] {
+			joinTopo = true
+			if !util.InStrArr(topoFields, field) {
+				topoFields = append(topoFields, field)
+			}
+			return
+		}
+		if !util.InStrArr(ownFields, field) {
+			ownFields = append(ownFields, field)
+		}
+	}
+
+	for _, field := range option.GroupBy {
+		if _, ok := fieldTypes[field]; !ok && !(isHost && hostTopoFields[field]) {
+			blog.Errorf("aggregate instances of %s failed, group by field %s not exist, rid: %v", objID, field, ctx.ReqID)
+			return nil, ctx.Error.CCErrorf(common.CCErrCommParamsInvalid, "group_by")
+		}
+		addField(field)
+	}
+	for _, metric := range option.Metrics {
+		if len(metric.Field) == 0 {
+			continue
+		}
+		// a host is counted once in each topology group, so the metrics on the topology fields are not supported,
+		// group by them instead.
+		fieldType, ok := fieldTypes[metric.Field]
+		if !ok {
+			blog.Errorf("aggregate instances of %s failed, metric field %s not exist, rid: %v", objID, metric.Field, ctx.ReqID)
+			return nil, ctx.Error.CCErrorf(common.CCErrCommParamsInvalid, "metrics.field")
+		}
+		if numericMetrics[metric.Op] && fieldType != common.FieldTypeInt && fieldType != common.FieldTypeFloat {
+			blog.Errorf("aggregate instances of %s failed, %s field %s is not numeric, rid: %v", objID, metric.Op, metric.Field, ctx.ReqID)
+			return nil, ctx.Error.CCErrorf(common.CCErrCommParamsInvalid, "metrics.field")
+		}
+		addField(metric.Field)
+	}
+
+	cond, err := m.makeAggregationCondition(ctx, objID, option)
+	if err != nil {
+		return nil, err
+	}
+
+	pipeline := []M{{common.BKDBMatch: cond}}
+	fieldRef := func(field string) string {
+		return "$" + field
+	}
+	if joinTopo {
+		pipeline = append(pipeline,
+			M{"$lookup": M{
+				"from":         common.BKTableNameModuleHostConfig,
+				"localField":   common.BKHostIDField,
+				"foreignField": common.BKHostIDField,
+				"as":           hostTopoAlias,
+			}},
+			M{"$unwind": "$" + hostTopoAlias},
+		)
+		if option.BizID != 0 {
+			pipeline = append(pipeline, M{common.BKDBMatch: M{hostTopoAlias + "." + common.BKAppIDField: option.BizID}})
+		}
+
+		// a host is in several modules, so it is grouped by the topology fields first to be counted only once
+		hostID := M{common.BKHostIDField: "$" + common.BKHostIDField}
+		for _, field := range topoFields {
+			hostID[field] = "$" + hostTopoAlias + "." + field
+		}
+		hostGroup := M{"_id": hostID}
+		for _, field := range ownFields {
+			if field != common.BKHostIDField {
+				hostGroup[field] = M{"$first": "$" + field}
+			}
+		}
+		pipeline = append(pipeline, M{common.BKDBGroup: hostGroup})
+		fieldRef = func(field string) string {
+			if field == common.BKHostIDField || hostTopoFields[field] {
+				return "$_id." + field
+			}
+			return "$" + field
+		}
+	}
+
+	var groupID interface{}
+	if len(option.GroupBy) > 0 {
+		keys := M{}
+		for _, field := range option.GroupBy {
+			keys[field] = fieldRef(field)
+		}
+		groupID = keys
+	}
+
+	countPipeline := append(pipeline[:len(pipeline):len(pipeline)], M{common.BKDBGroup: M{"_id": groupID}}, M{"$count": "count"})
+	count := struct {
+		Count int64 `bson:"count"`
+	}{}
+	if err := m.dbProxy.Table(common.GetInstTableName(objID)).AggregateOne(ctx, countPipeline, &count); err != nil {
+		if !m.dbProxy.IsNotFoundError(err) {
+			blog.Errorf("aggregate instances of %s failed, count groups failed, err: %v, rid: %v", objID, err, ctx.ReqID)
+			return nil, ctx.Error.Error(common.CCErrCommDBSelectFailed)
+		}
+	}
+	result := &metadata.InstAggregationResult{Count: count.Count, Info: make([]metadata.AggregationBucket, 0)}
+	if count.Count == 0 || int64(option.Page.Start) >= count.Count {
+		return result, nil
+	}
+
+	pipeline = append(pipeline, aggregationGroupStages(groupID, option.Metrics, fieldRef)...)
+	pipeline = append(pipeline, aggregationSortStages(option)...)
+	if option.Page.Start > 0 {
+		pipeline = append(pipeline, M{"$skip": option.Page.Start})
+	}
+	pipeline = append(pipeline, M{"$limit": option.Page.Limit})
+
+	groups := make([]struct {
+		Keys    map[string]interface{} `bson:"_id"`
+		Metrics map[string]interface{} `bson:",inline"`
+	}, 0)
+	if err := m.dbProxy.Table(common.GetInstTableName(objID)).AggregateAll(ctx, pipeline, &groups); err != nil {
+		blog.Errorf("aggregate instances of %s failed, err: %v, rid: %v", objID, err, ctx.ReqID)
+		return nil, ctx.Error.Error(common.CCErrCommDBSelectFailed)
+	}
+
+	for _, item := range groups {
+		bucket := metadata.AggregationBucket{
+			Keys:    make(map[string]interface{}),
+			Metrics: make(map[string]interface{}),
+		}
+		for _, field := range option.GroupBy {
+			bucket.Keys[field] = item.Keys[field]
+		}
+		for _, metric := range option.Metrics {
+			bucket.Metrics[metric.Name] = item.Metrics[metric.Name]
+		}
+		result.Info = append(result.Info, bucket)
+	}
+	return result, nil
+}
+
+// aggregationGroupStages returns the stages that group the instances by the group id and compute the metrics.
+// the distinct values are not collected into one group document, which could exceed the document size limit,
+// instead each instance is unwound into one document per distinct count metric, grouped by the group id and the
+// value first, then the values of each group are counted.
+func aggregationGroupStages(groupID interface{}, metrics []metadata.AggregationMetric, fieldRef func(string) string) []M {
+	distinctValues := make([]interface{}, 0)
+	for _, metric := range metrics {
+		if metric.Op == metadata.AggregationOpDistinctCount {
+			distinctValues = append(distinctValues, M{"name": metric.Name, "value": fieldRef(metric.Field)})
+		}
+	}
+
+	group := M{"_id": groupID}
+	for _, metric := range metrics {
+		switch metric.Op {
+		case metadata.AggregationOpCount:
+			group[metric.Name] = M{common.BKDBSum: 1}
+		case metadata.AggregationOpDistinctCount:
+		default:
+			group[metric.Name] = M{"$" + metric.Op: fieldRef(metric.Field)}
+		}
+	}
+	if len(distinctValues) == 0 {
+		return []M{{common.BKDBGroup: group}}
+	}
+
+	// the element with an empty name carries all the instances for the other metrics, metric names are never empty
+	distinctValues = append([]interface{}{M{"name": "", "value": nil}}, distinctValues...)
+	group["_id"] = M{"keys": groupID, "name": "$" + distinctValueAlias + ".name", "value": "$" + distinctValueAlias + ".value"}
+
+	isInstanceGroup := M{"$eq": []interface{}{"$_id.name", ""}}
+	countGroup := M{"_id": "$_id.keys"}
+	for _, metric := range metrics {
+		if metric.Op == metadata.AggregationOpDistinctCount {
+			countGroup[metric.Name] = M{common.BKDBSum: M{"$cond": []interface{}{
+				M{"$eq": []interface{}{"$_id.name", metric.Name}}, 1, 0}}}
+			continue
+		}
+		countGroup[metric.Name] = M{"$max": M{"$cond": []interface{}{isInstanceGroup, "$" + metric.Name, nil}}}
+	}
+
+	return []M{
+		{"$addFields": M{distinctValueAlias: distinctValues}},
+		{"$unwind": "$" + distinctValueAlias},
+		{common.BKDBGroup: group},
+		{common.BKDBGroup: countGroup},
+	}
+}
+
+// aggregationSortStages returns the stages that sort the groups, so that the groups are paged in a stable order.
+// the sort keys are put into one document in order, because a multi keys sort document loses its key order when
+// it is sent to the tmserver as a map, and the consecutive sort stages are not stable. the group by fields break
+// the ties in the same direction as the sort field.
+func aggregationSortStages(option metadata.InstAggregationOption) []M {
+	keys := make([]interface{}, 0)
+	addKey := func(field string) {
+		keys = append(keys, M{"k": strconv.Itoa(len(keys)), "v": M{"$ifNull": []interface{}{"$" + field, nil}}})
+	}
+
+	order := 1
+	sortField := ""
+	if len(option.Page.Sort) > 0 {
+		sortField = option.Page.Sort
+		if sortField[0] == '-' {
+			sortField, order = sortField[1:], -1
+		}
+		if util.InStrArr(option.GroupBy, sortField) {
+			addKey("_id." + sortField)
+		} else {
+			addKey(sortField)
+		}
+	}
+	for _, field := range option.GroupBy {
+		if field != sortField {
+			addKey("_id." + field)
+		}
+	}
+	if len(keys) == 0 {
+		return []M{}
+	}
+
+	return []M{
+		{"$addFields": M{aggregationSortAlias: M{"$arrayToObject": []interface{}{keys}}}},
+		{"$sort": M{aggregationSortAlias: order}},
+	}
+}
+
+// getAggregationFieldTypes returns the types of the fields that the instances of the model could be aggregated on
+func (m *operationManager) getAggregationFieldTypes(ctx core.ContextParams, objID string) (map[string]string, error) {
+	cond := util.SetQueryOwner(mapstr.MapStr{common.BKObjIDField: objID}, ctx.SupplierAccount)
+	attributes := make([]metadata.Attribute, 0)
+	err := m.dbProxy.Table(common.BKTableNameObjAttDes).Find(cond).
+		Fields(common.BKPropertyIDField, common.BKPropertyTypeField).All(ctx, &attributes)
+	if err != nil {
+		blog.Errorf("aggregate instances of %s failed, search attributes failed, err: %v, rid: %v", objID, err, ctx.ReqID)
+		return nil, ctx.Error.Error(common.CCErrCommDBSelectFailed)
+	}
+	if len(attributes) == 0 {
+		blog.Errorf("aggregate instances of %s failed, model has no attributes, rid: %v", objID, ctx.ReqID)
+		return nil, ctx.Error.CCErrorf(common.CCErrCommParamsInvalid, common.BKObjIDField)
+	}
+
+	fieldTypes := map[string]string{common.GetInstIDField(objID): common.FieldTypeInt}
+	switch objID {
+	case common.BKInnerObjIDSet:
+		fieldTypes[common.BKAppIDField] = common.FieldTypeInt
+	case common.BKInnerObjIDModule:
+		fieldTypes[common.BKAppIDField] = common.FieldTypeInt
+		fieldTypes[common.BKSetIDField] = common.FieldTypeInt
+	}
+	for _, attribute := range attributes {
+		fieldTypes[attribute.PropertyID] = attribute.PropertyType
+	}
+	return fieldTypes, nil
+}
+
+// makeAggregationCondition makes the condition of the instances to be aggregated
+func (m *operationManager) makeAggregationCondition(ctx core.ContextParams, objID string, option metadata.InstAggregationOption) (mapstr.MapStr, error) {
+	conds := make([]interface{}, 0)
+	if option.Filter != nil && option.Filter.Rule != nil {
+		filter, key, err := option.Filter.ToMgo()
+		if err != nil {
+			blog.Errorf("aggregate instances of %s failed, invalid filter, key: %s, err: %v, rid: %v", objID, key, err, ctx.ReqID)
+			return nil, ctx.Error.CCErrorf(common.CCErrCommParamsInvalid, "filter."+key)
+		}
+		conds = append(conds, filter)
+	}
+
+	if common.GetInstTableName(objID) == common.BKTableNameBaseInst {
+		conds = append(conds, mapstr.MapStr{common.BKObjIDField: objID})
+	}
+
+	// the hosts are limited to the business by the host module relations
+	if option.BizID != 0 && objID != common.BKInnerObjIDHost {
+		switch objID {
+		case common.BKInnerObjIDApp, common.BKInnerObjIDSet, common.BKInnerObjIDModule:
+			conds = append(conds, mapstr.MapStr{common.BKAppIDField: option.BizID})
+		default:
+			conds = append(conds, mapstr.MapStr{metadata.MetadataBizField: strconv.FormatInt(option.BizID, 10)})
+		}
+	}
+
+	cond := mapstr.MapStr{}
+	if len(conds) > 0 {
+		cond[common.BKDBAND] = conds
+	}
+	return util.SetQueryOwner(cond, ctx.SupplierAccount), nil
+}
@@ -0,0 +1,115 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.,
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the ",License",); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an ",AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package operation
+
+import (
+	"encoding/json"
+	"strconv"
+	"testing"
+
+	"configcenter/src/common"
+	"configcenter/src/common/metadata"
+	"configcenter/src/storage/types"
+
+	"github.com/stretchr/testify/require"
+)
+
+// encodeRemote encodes the pipeline the way the remote dal sends it to the tmserver
+func encodeRemote(t *testing.T, pipeline []M) []map[string]interface{} {
+	docs := types.Documents{}
+	require.NoError(t, docs.Encode(pipeline))
+	out, err := json.Marshal(docs)
+	require.NoError(t, err)
+	stages := make([]map[string]interface{}, 0)
+	require.NoError(t, json.Unmarshal(out, &stages))
+	return stages
+}
+
+func TestAggregationSortStagesRemoteEncoding(t *testing.T) {
+	testCases := []struct {
+		name   string
+		sort   string
+		order  float64
+		fields []string
+	}{
+		{name: "default", order: 1, fields: []string{"_id.a", "_id.b", "_id.c"}},
+		{name: "group by field", sort: "b", order: 1, fields: []string{"_id.b", "_id.a", "_id.c"}},
+		{name: "metric desc", sort: "-count", order: -1, fields: []string{"count", "_id.a", "_id.b", "_id.c"}},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			option := metadata.InstAggregationOption{GroupBy: []string{"a", "b", "c"}, Page: metadata.BasePage{Sort: testCase.sort}}
+			stages := encodeRemote(t, aggregationSortStages(option))
+			require.Len(t, stages, 2)
+
+			sort := stages[1]["$sort"].(map[string]interface{})
+			require.Equal(t, map[string]interface{}{aggregationSortAlias: testCase.order}, sort)
+
+			keys := stages[0]["$addFields"].(map[string]interface{})[aggregationSortAlias].(map[string]interface{})
+			elements := keys["$arrayToObject"].([]interface{})[0].([]interface{})
+			fields := make([]string, 0)
+			for index, element := range elements {
+				kv := element.(map[string]interface{})
+				require.Equal(t, strconv.Itoa(index), kv["k"])
+				ref := kv["v"].(map[string]interface{})["$ifNull"].([]interface{})[0]
+				fields = append(fields, ref.(string)[1:])
+			}
+			require.Equal(t, testCase.fields, fields)
+		})
+	}
+
+	require.Empty(t, aggregationSortStages(metadata.InstAggregationOption{}))
+}
+
+func TestAggregationGroupStages(t *testing.T) {
+	fieldRef := func(field string) string {
+		return "$" + field
+	}
+	groupID := M{"a": "$a"}
+
+	metrics := []metadata.AggregationMetric{{Name: "count", Op: metadata.AggregationOpCount}, {Name: "sum_x", Op: metadata.AggregationOpSum, Field: "x"}}
+	stages := encodeRemote(t, aggregationGroupStages(groupID, metrics, fieldRef))
+	require.Len(t, stages, 1)
+	group := stages[0][common.BKDBGroup].(map[string]interface{})
+	require.Equal(t, map[string]interface{}{"a": "$a"}, group["_id"])
+	require.Equal(t, map[string]interface{}{common.BKDBSum: float64(1)}, group["count"])
+	require.Equal(t, map[string]interface{}{"$sum": "$x"}, group["sum_x"])
+
+	metrics = append(metrics, metadata.AggregationMetric{Name: "distinct_y", Op: metadata.AggregationOpDistinctCount, Field: "y"})
+	stages = encodeRemote(t, aggregationGroupStages(groupID, metrics, fieldRef))
+	require.Len(t, stages, 4)
+
+	values := stages[0]["$addFields"].(map[string]interface{})[distinctValueAlias].([]interface{})
+	require.Equal(t, []interface{}{
+		map[string]interface{}{"name": "", "value": nil},
+		map[string]interface{}{"name": "distinct_y", "value": "$y"},
+	}, values)
+	require.Equal(t, "$"+distinctValueAlias, stages[1]["$unwind"])
+
+	// the distinct values are grouped first, never collected into a set
+	valueGroup := stages[2][common.BKDBGroup].(map[string]interface{})
+	require.Equal(t, map[string]interface{}{
+		"keys":  map[string]interface{}{"a": "$a"},
+		"name":  "$" + distinctValueAlias + ".name",
+		"value": "$" + distinctValueAlias + ".value",
+	}, valueGroup["_id"])
+	require.NotContains(t, valueGroup, "distinct_y")
+
+	countGroup := stages[3][common.BKDBGroup].(map[string]interface{})
+	require.Equal(t, "$_id.keys", countGroup["_id"])
+	require.Equal(t, map[string]interface{}{common.BKDBSum: map[string]interface{}{"$cond": []interface{}{
+		map[string]interface{}{"$eq": []interface{}{"$_id.name", "distinct_y"}}, float64(1), float64(0)}}}, countGroup["distinct_y"])
+	require.Equal(t, map[string]interface{}{"$max": map[string]interface{}{"$cond": []interface{}{
+		map[string]interface{}{"$eq": []interface{}{"$_id.name", ""}}, "$sum_x", nil}}}, countGroup["sum_x"])
+}
@@ -209,13 +209,6 @@ func (m *operationManager) BizHostCountChange(ctx core.ContextParams, wg *sync.W
 func (m *operationManager) SearchBizHost(ctx core.ContextParams) ([]metadata.StringIDCount, error) {
 	bizHostCount := make([]metadata.IntIDArrayCount, 0)
 
-	opt := mapstr.MapStr{"bk_data_status": M{common.BKDBNE: "disabled"}, common.BKAppIDField: M{common.BKDBNE: 1}}
-	bizInfo := make([]metadata.BizInst, 0)
-	if err := m.dbProxy.Table(common.BKTableNameBaseApp).Find(opt).All(ctx, &bizInfo); err != nil {
-		blog.Errorf("SearchBizHost, get biz info fail, err: %v, rid: %v ", err, ctx.ReqID)
-		return nil, err
-	}
-
 	cond := mapstr.MapStr{}
 	count, err := m.dbProxy.Table(common.BKTableNameModuleHostConfig).Find(cond).Count(ctx)
 	if err != nil {
@@ -230,18 +223,26 @@ func (m *operationManager) SearchBizHost(ctx core.ContextParams) ([]metadata.Str
 		}
 	}
 
+	hostCount := make(map[int64]int64, len(bizHostCount))
+	for _, host := range bizHostCount {
+		hostCount[host.ID] = int64(len(host.Count))
+	}
+
 	rData := make([]metadata.StringIDCount, 0)
-	for _, biz := range bizInfo {
-		info := metadata.StringIDCount{
-			ID:    biz.BizName,
-			Count: 0,
-		}
-		for _, host := range bizHostCount {
-			if host.ID == biz.BizID {
-				info.Count = int64(len(host.Count))
-			}
+	opt := mapstr.MapStr{"bk_data_status": M{common.BKDBNE: "disabled"}, common.BKAppIDField: M{common.BKDBNE: 1}}
+	iter := m.dbProxy.Table(common.BKTableNameBaseApp).Find(opt).Fields(common.BKAppIDField, common.BKAppNameField).Iter(ctx)
+	defer iter.Close(ctx)
+	for iter.Next(ctx) {
+		biz := metadata.BizInst{}
+		if err := iter.Decode(&biz); err != nil {
+			blog.Errorf("SearchBizHost, decode biz info fail, err: %v, rid: %v ", err, ctx.ReqID)
+			return nil, err
 		}
-		rData = append(rData, info)
+		rData = append(rData, metadata.StringIDCount{ID: biz.BizName, Count: hostCount[biz.BizID]})
+	}
+	if err := iter.Err(); err != nil {
+		blog.Errorf("SearchBizHost, get biz info fail, err: %v, rid: %v ", err, ctx.ReqID)
+		return nil, err
 	}
 
 	return rData, nil
@@ -287,13 +288,6 @@ func (m *operationManager) HostBizChartData(ctx core.ContextParams, inputParam m
 		return nil, err
 	}
 
-	opt := mapstr.MapStr{"bk_data_status": M{common.BKDBNE: "disabled"}, common.BKAppIDField: M{common.BKDBNE: 1}}
-	bizInfo := make([]metadata.BizInst, 0)
-	if err := m.dbProxy.Table(common.BKTableNameBaseApp).Find(opt).All(ctx, &bizInfo); err != nil {
-		blog.Errorf("HostBizChartData, get biz info fail, err: %v, rid: %v ", err, ctx.ReqID)
-		return nil, err
-	}
-
 	return bizHost, nil
 }
 
@@ -382,15 +376,16 @@ func (m *operationManager) StatisticOperationLog(ctx core.ContextParams) (*metad
 func (m *operationManager) clearDataOverDate(ctx core.ContextParams) {
 	cond := mapstr.MapStr{}
 	cond[common.OperationReportType] = common.HostChangeBizChart
-	bizHostChange := make([]metadata.HostChangeChartData, 0)
-	if err := m.dbProxy.Table(common.BKTableNameChartData).Find(cond).All(ctx, &bizHostChange); err != nil {
-		blog.Errorf("get host change data fail, err: %v, rid: %v", err, ctx.ReqID)
-		return
-	}
-
 	shouldClear := make([]string, 0)
 	now := time.Now()
-	for _, info := range bizHostChange {
+	iter := m.dbProxy.Table(common.BKTableNameChartData).Find(cond).Fields(common.CreateTimeField).Iter(ctx)
+	defer iter.Close(ctx)
+	for iter.Next(ctx) {
+		info := metadata.HostChangeChartData{}
+		if err := iter.Decode(&info); err != nil {
+			blog.Errorf("decode host change data fail, err: %v, rid: %v", err, ctx.ReqID)
+			return
+		}
 		dateFormat := "2006-01-02"
 		loc, _ := time.LoadLocation("Asia/Shanghai")
 		createTime, _ := time.ParseInLocation(dateFormat, info.CreateTime, loc)
@@ -400,6 +395,10 @@ func (m *operationManager) clearDataOverDate(ctx core.ContextParams) {
 			shouldClear = append(shouldClear, info.CreateTime)
 		}
 	}
+	if err := iter.Err(); err != nil {
+		blog.Errorf("get host change data fail, err: %v, rid: %v", err, ctx.ReqID)
+		return
+	}
 
 	cond[common.CreateTimeField] = mapstr.MapStr{common.BKDBIN: shouldClear}
 	if err := m.dbProxy.Table(common.BKTableNameChartData).Delete(ctx, cond); err != nil {
@@ -132,13 +132,15 @@ func (m *operationManager) SearchTimerChartData(ctx core.ContextParams, inputPar
 
 	switch inputParam.ReportType {
 	case common.HostChangeBizChart:
-		chartData := make([]metadata.HostChangeChartData, 0)
-		if err := m.dbProxy.Table(common.BKTableNameChartData).Find(condition).All(ctx, &chartData); err != nil {
-			blog.Errorf("search chart data fail, chart name: %v err: %v, rid: %v", inputParam.Name, err, ctx.ReqID)
-			return nil, err
-		}
 		result := make(map[string][]metadata.StringIDCount, 0)
-		for _, data := range chartData {
+		iter := m.dbProxy.Table(common.BKTableNameChartData).Find(condition).Iter(ctx)
+		defer iter.Close(ctx)
+		for iter.Next(ctx) {
+			data := metadata.HostChangeChartData{}
+			if err := iter.Decode(&data); err != nil {
+				blog.Errorf("decode chart data fail, chart name: %v err: %v, rid: %v", inputParam.Name, err, ctx.ReqID)
+				return nil, err
+			}
 			for _, info := range data.Data {
 				if _, ok := result[info.ID]; !ok {
 					result[info.ID] = make([]metadata.StringIDCount, 0)
@@ -149,6 +151,10 @@ func (m *operationManager) SearchTimerChartData(ctx core.ContextParams, inputPar
 				})
 			}
 		}
+		if err := iter.Err(); err != nil {
+			blog.Errorf("search chart data fail, chart name: %v err: %v, rid: %v", inputParam.Name, err, ctx.ReqID)
+			return nil, err
+		}
 		return result, nil
 	case common.ModelInstChart:
 		chartData := metadata.ModelInstChartData{}
@@ -20,8 +20,7 @@ import (
 	"configcenter/src/common/metadata"
 	"configcenter/src/source_controller/coreservice/core"
 	"configcenter/src/storage/dal"
-
-	"gopkg.in/redis.v5"
+	"configcenter/src/storage/dal/redis"
 )
 
 type processOperation struct {
@@ -37,7 +36,7 @@ type OperationDependence interface {
 }
 
 // New create a new model manager instance
-func New(dbProxy dal.RDB, dependence OperationDependence, cache *redis.Client) core.ProcessOperation {
+func New(dbProxy dal.RDB, dependence OperationDependence, cache redis.Client) core.ProcessOperation {
 	processOps := &processOperation{
 		dbProxy:    dbProxy,
 		dependence: dependence,
@@ -13,6 +13,7 @@
 package service
 
 import (
+	"configcenter/src/common"
 	"configcenter/src/common/mapstr"
 	"configcenter/src/common/metadata"
 	"configcenter/src/source_controller/coreservice/core"
@@ -188,6 +189,15 @@ func (s *coreService) SearchInstanceAssociation(params core.ContextParams, pathP
 	return s.core.AssociationOperation().SearchInstanceAssociation(params, inputData)
 }
 
+func (s *coreService) SearchInstIDsByAsstFilter(params core.ContextParams, pathParams, queryParams ParamsGetter, data mapstr.MapStr) (interface{}, error) {
+
+	inputData := metadata.SearchInstIDsByAsstFilterOption{}
+	if err := data.MarshalJSONInto(&inputData); nil != err {
+		return nil, err
+	}
+	return s.core.AssociationOperation().SearchInstIDsByAsstFilter(params, pathParams(common.BKObjIDField), inputData)
+}
+
 func (s *coreService) DeleteInstanceAssociation(params core.ContextParams, pathParams, queryParams ParamsGetter, data mapstr.MapStr) (interface{}, error) {
 
 	inputData := metadata.DeleteOption{}
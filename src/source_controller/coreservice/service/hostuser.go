@@ -63,11 +63,17 @@ func (s *coreService) AddUserConfig(params core.ContextParams, pathParams, query
 		return nil, params.Error.CCErrorf(common.CCErrCommDuplicateItem, "")
 	}
 
+	// the dynamic group is a host group if the object is not set
+	if len(addQuery.ObjectID) == 0 {
+		addQuery.ObjectID = common.BKInnerObjIDHost
+	}
+
 	id := xid.New().String()
 	userQuery := meta.UserConfigMeta{
 		AppID:      addQuery.AppID,
 		Info:       addQuery.Info,
 		Name:       addQuery.Name,
+		ObjectID:   addQuery.ObjectID,
 		ID:         id,
 		CreateTime: time.Now().UTC(),
 		CreateUser: addQuery.CreateUser,
@@ -132,6 +138,8 @@ func (s *coreService) UpdateUserConfig(params core.ContextParams, pathParams, qu
 		}
 	}
 
+	// the object of a dynamic group can not be changed, the info of the group depends on it
+	dat.ObjectID = ""
 	dat.UpdateTime = time.Now().UTC()
 	dat.ModifyUser = util.GetUser(params.Header)
 	dat.AppID = appID
@@ -50,6 +50,16 @@ func (s *coreService) SearchChartDataCommon(params core.ContextParams, pathParam
 	return result, nil
 }
 
+func (s *coreService) AggregateInstances(params core.ContextParams, pathParams, queryParams ParamsGetter, data mapstr.MapStr) (interface{}, error) {
+	option := metadata.InstAggregationOption{}
+	if err := data.MarshalJSONInto(&option); err != nil {
+		blog.Errorf("aggregate instances fail, marshal aggregation option fail, err: %v, rid: %v", err, params.ReqID)
+		return nil, err
+	}
+
+	return s.core.StatisticOperation().AggregateInstances(params, pathParams(common.BKObjIDField), option)
+}
+
 func (s *coreService) DeleteOperationChart(params core.ContextParams, pathParams, queryParams ParamsGetter, data mapstr.MapStr) (interface{}, error) {
 	id := pathParams("id")
 	int64ID, err := strconv.ParseInt(id, 10, 64)
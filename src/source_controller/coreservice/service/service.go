@@ -51,7 +51,6 @@ import (
 	dalredis "configcenter/src/storage/dal/redis"
 
 	"github.com/emicklei/go-restful"
-	"gopkg.in/redis.v5"
 )
 
 // CoreServiceInterface the topo service methods used to init
@@ -74,7 +73,7 @@ type coreService struct {
 	cfg      options.Config
 	core     core.Core
 	db       dal.RDB
-	cache    *redis.Client
+	cache    dalredis.Client
 }
 
 func (s *coreService) SetConfig(cfg options.Config, engin *backbone.Engine, err errors.CCErrorIf, language language.CCLanguageIf) error {
@@ -95,7 +94,7 @@ func (s *coreService) SetConfig(cfg options.Config, engin *backbone.Engine, err
 	if s.cfg.Mongo.Enable == "true" {
 		db, dbErr = local.NewMgo(s.cfg.Mongo.BuildURI(), time.Minute)
 	} else {
-		db, dbErr = remote.NewWithDiscover(s.engin)
+		db, dbErr = remote.NewWithDiscover(s.engin, s.cfg.Mongo.RPCTLS)
 	}
 	if dbErr != nil {
 		blog.Errorf("failed to connect the txc server, error info is %s", dbErr.Error())
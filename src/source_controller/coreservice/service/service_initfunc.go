@@ -103,6 +103,7 @@ func (s *coreService) initInstanceAssociation() {
 	s.addAction(http.MethodPost, "/createmany/instanceassociation", s.CreateManyInstanceAssociation, nil)
 	s.addAction(http.MethodPost, "/read/instanceassociation", s.SearchInstanceAssociation, nil)
 	s.addAction(http.MethodDelete, "/delete/instanceassociation", s.DeleteInstanceAssociation, nil)
+	s.addAction(http.MethodPost, "/read/instanceassociation/filter/object/{bk_obj_id}", s.SearchInstIDsByAsstFilter, nil)
 }
 
 func (s *coreService) initMainline() {
@@ -183,6 +184,7 @@ func (s *coreService) initOperation() {
 	s.addAction(http.MethodPost, "/update/operation/chart/position", s.UpdateChartPosition, nil)
 	s.addAction(http.MethodPost, "/search/operation/chart/data", s.SearchTimerChartData, nil)
 	s.addAction(http.MethodPost, "/start/operation/chart/timer", s.TimerFreshData, nil)
+	s.addAction(http.MethodPost, "/read/operation/aggregation/object/{bk_obj_id}", s.AggregateInstances, nil)
 }
 
 func (s *coreService) label() {
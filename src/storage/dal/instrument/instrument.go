@@ -0,0 +1,160 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package instrument 为 dal.Table 提供监控: 按集合和操作统计耗时, 记录慢查询, 采样记录查询计划
+package instrument
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand"
+	"strconv"
+	"sync"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/util"
+	"configcenter/src/storage/dal"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// 操作类型
+const (
+	OperationFind      = "find"
+	OperationIterate   = "iterate"
+	OperationCount     = "count"
+	OperationAggregate = "aggregate"
+	OperationInsert    = "insert"
+	OperationUpdate    = "update"
+	OperationDelete    = "delete"
+	OperationBulkWrite = "bulk_write"
+)
+
+// explainTimeout 采样查询计划的超时时间
+const explainTimeout = 10 * time.Second
+
+// Config 监控配置
+type Config struct {
+	// SlowQueryThreshold 慢查询阈值, 耗时不小于该值的操作记录日志, 为0时不记录
+	SlowQueryThreshold time.Duration
+	// ExplainSampleRate 查询计划的采样率, 取值 0~1, 为0时不采样
+	ExplainSampleRate float64
+}
+
+// ParseConfigFromKV returns a new config, e.g. mongodb.slow_query_threshold_ms, mongodb.explain_sample_rate
+func ParseConfigFromKV(prefix string, configmap map[string]string) Config {
+	conf := Config{}
+	if threshold, err := strconv.ParseInt(configmap[prefix+".slow_query_threshold_ms"], 10, 64); err == nil && threshold > 0 {
+		conf.SlowQueryThreshold = time.Duration(threshold) * time.Millisecond
+	}
+	if rate, err := strconv.ParseFloat(configmap[prefix+".explain_sample_rate"], 64); err == nil && rate > 0 {
+		conf.ExplainSampleRate = rate
+	}
+	return conf
+}
+
+var (
+	configLock sync.RWMutex
+	config     Config
+
+	operationDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "cmdb_dal_operation_duration_millisecond",
+		Help:    "dal operation duration millisecond by collection and operation.",
+		Buckets: []float64{1, 5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000},
+	}, []string{"collection", "operation"})
+
+	txnRetryTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "cmdb_dal_transaction_retry_total",
+		Help: "dal transaction retry times on transient transaction errors by the failed stage.",
+	}, []string{"stage"})
+)
+
+// SetConfig 设置监控配置, 进程配置更新时调用
+func SetConfig(conf Config) {
+	configLock.Lock()
+	config = conf
+	configLock.Unlock()
+}
+
+func getConfig() Config {
+	configLock.RLock()
+	defer configLock.RUnlock()
+	return config
+}
+
+// Register 注册监控指标
+func Register(reg prometheus.Registerer) error {
+	for _, collector := range []prometheus.Collector{operationDuration, txnRetryTotal} {
+		if err := reg.Register(collector); err != nil {
+			if _, ok := err.(prometheus.AlreadyRegisteredError); !ok {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ObserveTxnRetry 统计事务因临时错误(如写冲突)的重试次数, stage 为失败的阶段
+func ObserveTxnRetry(stage string) {
+	txnRetryTotal.WithLabelValues(stage).Inc()
+}
+
+// observe 统计操作耗时, 记录慢查询, 并对查询操作采样记录查询计划
+func observe(ctx context.Context, table dal.Table, collection, operation string, filter interface{}, start time.Time) {
+	observeCost(ctx, table, collection, operation, filter, time.Since(start))
+}
+
+// observeCost 同 observe, 耗时由调用方统计
+func observeCost(ctx context.Context, table dal.Table, collection, operation string, filter interface{}, cost time.Duration) {
+	operationDuration.WithLabelValues(collection, operation).Observe(util.ToMillisecond(cost))
+
+	conf := getConfig()
+	if conf.SlowQueryThreshold > 0 && cost >= conf.SlowQueryThreshold {
+		blog.Warnf("slow query: %s %s cost %dms, filter: %s, rid: %s", operation, collection,
+			cost/time.Millisecond, toJSON(filter), util.ExtractRequestIDFromContext(ctx))
+	}
+
+	if conf.ExplainSampleRate <= 0 || rand.Float64() >= conf.ExplainSampleRate {
+		return
+	}
+	if operation != OperationFind && operation != OperationIterate && operation != OperationCount {
+		return
+	}
+	explainer, ok := table.(dal.Explainer)
+	if !ok {
+		return
+	}
+	rid := util.ExtractRequestIDFromContext(ctx)
+	go func() {
+		// explain is not allowed in a transaction, so the context of the operation is not used
+		explainCtx, cancel := context.WithTimeout(context.Background(), explainTimeout)
+		defer cancel()
+		explainCtx = context.WithValue(explainCtx, common.ContextRequestIDField, rid)
+		planner, err := explainer.Explain(explainCtx, filter)
+		if err != nil {
+			blog.Errorf("explain %s %s failed, filter: %s, err: %v, rid: %s", operation, collection, toJSON(filter), err, rid)
+			return
+		}
+		blog.Infof("explain %s %s cost %dms, filter: %s, query planner: %s, rid: %s", operation, collection,
+			cost/time.Millisecond, toJSON(filter), planner, rid)
+	}()
+}
+
+func toJSON(value interface{}) string {
+	js, err := json.Marshal(value)
+	if err != nil {
+		return err.Error()
+	}
+	return string(js)
+}
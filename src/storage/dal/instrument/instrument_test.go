@@ -0,0 +1,178 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package instrument
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"configcenter/src/storage/dal"
+)
+
+type fakeTable struct {
+	dal.Table
+	explained chan dal.Filter
+}
+
+func (t *fakeTable) Find(filter dal.Filter) dal.Find {
+	return &fakeFind{}
+}
+
+func (t *fakeTable) Delete(ctx context.Context, filter dal.Filter) error {
+	return nil
+}
+
+func (t *fakeTable) Explain(ctx context.Context, filter dal.Filter) (string, error) {
+	t.explained <- filter
+	return "{}", nil
+}
+
+type fakeFind struct {
+	dal.Find
+	limit uint64
+}
+
+func (f *fakeFind) Limit(limit uint64) dal.Find {
+	f.limit = limit
+	return f
+}
+
+func (f *fakeFind) All(ctx context.Context, result interface{}) error {
+	return nil
+}
+
+func (f *fakeFind) Count(ctx context.Context) (uint64, error) {
+	return f.limit, nil
+}
+
+func (f *fakeFind) Iter(ctx context.Context) dal.Iterator {
+	return &fakeIterator{remain: f.limit}
+}
+
+type fakeIterator struct {
+	dal.Iterator
+	remain uint64
+}
+
+func (i *fakeIterator) Next(ctx context.Context) bool {
+	if i.remain == 0 {
+		return false
+	}
+	i.remain--
+	return true
+}
+
+func (i *fakeIterator) Close(ctx context.Context) error {
+	return nil
+}
+
+func sampleCount(t *testing.T, reg *prometheus.Registry, collection, operation string) uint64 {
+	families, err := reg.Gather()
+	require.NoError(t, err)
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["collection"] == collection && labels["operation"] == operation {
+				return metric.GetHistogram().GetSampleCount()
+			}
+		}
+	}
+	return 0
+}
+
+func TestParseConfigFromKV(t *testing.T) {
+	conf := ParseConfigFromKV("mongodb", map[string]string{
+		"mongodb.slow_query_threshold_ms": "200",
+		"mongodb.explain_sample_rate":     "0.01",
+	})
+	require.Equal(t, 200*time.Millisecond, conf.SlowQueryThreshold)
+	require.Equal(t, 0.01, conf.ExplainSampleRate)
+
+	require.Equal(t, Config{}, ParseConfigFromKV("mongodb", map[string]string{"mongodb.slow_query_threshold_ms": "x"}))
+}
+
+func TestTable(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	require.NoError(t, Register(reg))
+	SetConfig(Config{ExplainSampleRate: 1})
+	defer SetConfig(Config{})
+
+	table := NewTable(&fakeTable{explained: make(chan dal.Filter, 3)}, "cc_HostBase")
+	ctx := context.Background()
+	filter := map[string]interface{}{"bk_host_id": 1}
+
+	require.NoError(t, table.Find(filter).Limit(10).All(ctx, nil))
+	count, err := table.Find(filter).Limit(10).Count(ctx)
+	require.NoError(t, err)
+	require.Equal(t, uint64(10), count)
+	require.NoError(t, table.Delete(ctx, filter))
+
+	// the iteration is observed once when the iterator is closed
+	iter := table.Find(filter).Limit(3).Iter(ctx)
+	iterated := 0
+	for iter.Next(ctx) {
+		iterated++
+	}
+	require.Equal(t, 3, iterated)
+	require.Equal(t, uint64(0), sampleCount(t, reg, "cc_HostBase", OperationIterate))
+	require.NoError(t, iter.Close(ctx))
+	require.NoError(t, iter.Close(ctx))
+
+	require.Equal(t, uint64(1), sampleCount(t, reg, "cc_HostBase", OperationFind))
+	require.Equal(t, uint64(1), sampleCount(t, reg, "cc_HostBase", OperationIterate))
+	require.Equal(t, uint64(1), sampleCount(t, reg, "cc_HostBase", OperationCount))
+	require.Equal(t, uint64(1), sampleCount(t, reg, "cc_HostBase", OperationDelete))
+
+	// the find, count and iterate operations are explained as sampled
+	for index := 0; index < 3; index++ {
+		select {
+		case explained := <-table.(*Table).Table.(*fakeTable).explained:
+			require.Equal(t, filter, explained)
+		case <-time.After(time.Second):
+			t.Fatal("the find operation is not explained")
+		}
+	}
+}
+
+func TestExplainTimeout(t *testing.T) {
+	SetConfig(Config{ExplainSampleRate: 1})
+	defer SetConfig(Config{})
+
+	deadlines := make(chan bool, 1)
+	table := &deadlineTable{deadlines: deadlines}
+	observe(context.Background(), table, "cc_HostBase", OperationFind, nil, time.Now())
+	select {
+	case hasDeadline := <-deadlines:
+		require.True(t, hasDeadline)
+	case <-time.After(time.Second):
+		t.Fatal("the find operation is not explained")
+	}
+}
+
+type deadlineTable struct {
+	dal.Table
+	deadlines chan bool
+}
+
+func (t *deadlineTable) Explain(ctx context.Context, filter dal.Filter) (string, error) {
+	_, ok := ctx.Deadline()
+	t.deadlines <- ok
+	return "{}", nil
+}
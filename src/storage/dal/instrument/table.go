@@ -0,0 +1,180 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package instrument
+
+import (
+	"context"
+	"time"
+
+	"configcenter/src/storage/dal"
+)
+
+// Table 监控 dal.Table 的操作, 索引和字段等其他操作直接调用被监控的 Table
+type Table struct {
+	dal.Table
+	collection string
+}
+
+var _ dal.Table = (*Table)(nil)
+
+// NewTable returns the table that monitors the operations of the table
+func NewTable(table dal.Table, collection string) dal.Table {
+	return &Table{Table: table, collection: collection}
+}
+
+func (t *Table) observe(ctx context.Context, operation string, filter interface{}, start time.Time) {
+	observe(ctx, t.Table, t.collection, operation, filter, start)
+}
+
+// Find 查询多个并反序列化到 Result
+func (t *Table) Find(filter dal.Filter) dal.Find {
+	return &Find{Find: t.Table.Find(filter), table: t, filter: filter}
+}
+
+// AggregateOne 聚合查询
+func (t *Table) AggregateOne(ctx context.Context, pipeline interface{}, result interface{}) error {
+	defer t.observe(ctx, OperationAggregate, pipeline, time.Now())
+	return t.Table.AggregateOne(ctx, pipeline, result)
+}
+
+// AggregateAll 聚合查询
+func (t *Table) AggregateAll(ctx context.Context, pipeline interface{}, result interface{}) error {
+	defer t.observe(ctx, OperationAggregate, pipeline, time.Now())
+	return t.Table.AggregateAll(ctx, pipeline, result)
+}
+
+// Insert 插入数据, docs 可以为 单个数据 或者 多个数据
+func (t *Table) Insert(ctx context.Context, docs interface{}) error {
+	defer t.observe(ctx, OperationInsert, nil, time.Now())
+	return t.Table.Insert(ctx, docs)
+}
+
+// Update 更新数据
+func (t *Table) Update(ctx context.Context, filter dal.Filter, doc interface{}) error {
+	defer t.observe(ctx, OperationUpdate, filter, time.Now())
+	return t.Table.Update(ctx, filter, doc)
+}
+
+// Upsert 更新或插入数据
+func (t *Table) Upsert(ctx context.Context, filter dal.Filter, doc interface{}) error {
+	defer t.observe(ctx, OperationUpdate, filter, time.Now())
+	return t.Table.Upsert(ctx, filter, doc)
+}
+
+// UpdateMultiModel 根据操作符更新数据
+func (t *Table) UpdateMultiModel(ctx context.Context, filter dal.Filter, updateModel ...dal.ModeUpdate) error {
+	defer t.observe(ctx, OperationUpdate, filter, time.Now())
+	return t.Table.UpdateMultiModel(ctx, filter, updateModel...)
+}
+
+// Delete 删除数据
+func (t *Table) Delete(ctx context.Context, filter dal.Filter) error {
+	defer t.observe(ctx, OperationDelete, filter, time.Now())
+	return t.Table.Delete(ctx, filter)
+}
+
+// BulkWrite 批量执行插入, 更新, upsert 和删除
+func (t *Table) BulkWrite(ctx context.Context, models []dal.BulkWriteModel, opt dal.BulkWriteOption) ([]dal.BulkWriteItemResult, error) {
+	defer t.observe(ctx, OperationBulkWrite, nil, time.Now())
+	return t.Table.BulkWrite(ctx, models, opt)
+}
+
+// Find 监控查询操作
+type Find struct {
+	dal.Find
+	table  *Table
+	filter dal.Filter
+}
+
+// Fields 设置查询字段
+func (f *Find) Fields(fields ...string) dal.Find {
+	f.Find = f.Find.Fields(fields...)
+	return f
+}
+
+// Sort 设置查询排序
+func (f *Find) Sort(sort string) dal.Find {
+	f.Find = f.Find.Sort(sort)
+	return f
+}
+
+// Start 设置限制查询上标
+func (f *Find) Start(start uint64) dal.Find {
+	f.Find = f.Find.Start(start)
+	return f
+}
+
+// Limit 设置查询数量
+func (f *Find) Limit(limit uint64) dal.Find {
+	f.Find = f.Find.Limit(limit)
+	return f
+}
+
+// BatchSize 设置迭代器每批获取的数量
+func (f *Find) BatchSize(size uint64) dal.Find {
+	f.Find = f.Find.BatchSize(size)
+	return f
+}
+
+// All 查询多个
+func (f *Find) All(ctx context.Context, result interface{}) error {
+	defer f.table.observe(ctx, OperationFind, f.filter, time.Now())
+	return f.Find.All(ctx, result)
+}
+
+// One 查询单个
+func (f *Find) One(ctx context.Context, result interface{}) error {
+	defer f.table.observe(ctx, OperationFind, f.filter, time.Now())
+	return f.Find.One(ctx, result)
+}
+
+// Iter 遍历查询结果, 关闭迭代器时统计遍历的耗时
+func (f *Find) Iter(ctx context.Context) dal.Iterator {
+	start := time.Now()
+	iter := f.Find.Iter(ctx)
+	return &Iterator{Iterator: iter, find: f, cost: time.Since(start)}
+}
+
+// Count 统计数量
+func (f *Find) Count(ctx context.Context) (uint64, error) {
+	defer f.table.observe(ctx, OperationCount, f.filter, time.Now())
+	return f.Find.Count(ctx)
+}
+
+// Iterator 监控遍历操作, 只统计获取文档的耗时, 不包括调用方处理文档的耗时
+type Iterator struct {
+	dal.Iterator
+	find   *Find
+	cost   time.Duration
+	closed bool
+}
+
+// Next 移动到下一个文档
+func (i *Iterator) Next(ctx context.Context) bool {
+	start := time.Now()
+	next := i.Iterator.Next(ctx)
+	i.cost += time.Since(start)
+	return next
+}
+
+// Close 关闭迭代器, 多次关闭只统计一次
+func (i *Iterator) Close(ctx context.Context) error {
+	start := time.Now()
+	err := i.Iterator.Close(ctx)
+	if !i.closed {
+		i.closed = true
+		table := i.find.table
+		observeCost(ctx, table.Table, table.collection, OperationIterate, i.find.filter, i.cost+time.Since(start))
+	}
+	return err
+}
@@ -96,6 +96,8 @@ type Table interface {
 	UpdateMultiModel(ctx context.Context, filter Filter, updateModel ...ModeUpdate) error
 	// Delete 删除数据
 	Delete(ctx context.Context, filter Filter) error
+	// BulkWrite 批量执行插入, 更新, upsert 和删除, 返回每一项的结果, 有失败项时同时返回错误
+	BulkWrite(ctx context.Context, models []BulkWriteModel, opt BulkWriteOption) ([]BulkWriteItemResult, error)
 
 	// CreateIndex 创建索引
 	CreateIndex(ctx context.Context, index Index) error
@@ -114,6 +116,12 @@ type Table interface {
 	DropColumns(ctx context.Context, filter Filter, fields []string) error
 }
 
+// Explainer 解释查询计划, 由支持的 Table 实现
+type Explainer interface {
+	// Explain 返回 filter 查询的执行计划(queryPlanner), json 格式
+	Explain(ctx context.Context, filter Filter) (string, error)
+}
+
 // JoinOption defind join transaction options
 type JoinOption struct {
 	TxnID     string // 事务ID,uuid
@@ -126,6 +134,8 @@ type JoinOption struct {
 type TxnWrapperOption struct {
 	Header http.Header
 	CCErr  ccErr.DefaultCCErrorIf
+	// DisableRetry 关闭事务遇到临时错误(如写冲突)时的自动重试
+	DisableRetry bool
 }
 
 // Find find operation interface
@@ -144,11 +154,60 @@ type Find interface {
 	One(ctx context.Context, result interface{}) error
 	// Count 统计数量(非事务)
 	Count(ctx context.Context) (uint64, error)
+	// BatchSize 设置迭代器每批获取的数量, 默认为 DefaultBatchSize
+	BatchSize(size uint64) Find
+	// Iter 逐条遍历查询结果, 结果按批获取, 使用完后必须关闭.
+	// 经 tmserver 遍历时, 未设置 Sort 则按 _id 分批, 设置 Sort 则按 Start 偏移分批, 每批都要 skip 之前的全部文档,
+	// 遍历大表的总开销随数量平方增长, 因此遍历大表时不要设置 Sort, 或者按排序字段自行分页.
+	Iter(ctx context.Context) Iterator
+}
+
+// DefaultBatchSize is the default number of documents an iterator fetches in a batch
+const DefaultBatchSize = 500
+
+// Iterator iterate the documents of a find operation one by one
+type Iterator interface {
+	// Next 移动到下一个文档, 没有更多文档或出错时返回false
+	Next(ctx context.Context) bool
+	// Decode 反序列化当前文档到 result
+	Decode(result interface{}) error
+	// Err 返回遍历过程中的错误
+	Err() error
+	// Close 关闭迭代器并释放资源
+	Close(ctx context.Context) error
 }
 
 // Index define the DB index struct
 type Index mongodb.Index
 
+// bulk write operations
+const (
+	// BulkWriteInsert 插入 Doc
+	BulkWriteInsert = mongodb.BulkWriteInsert
+	// BulkWriteUpdateOne 更新 Filter 匹配的第一个文档, Doc 为要更新的字段
+	BulkWriteUpdateOne = mongodb.BulkWriteUpdateOne
+	// BulkWriteUpsert 更新 Filter 匹配的第一个文档, 不存在时插入
+	BulkWriteUpsert = mongodb.BulkWriteUpsert
+	// BulkWriteDelete 删除 Filter 匹配的所有文档
+	BulkWriteDelete = mongodb.BulkWriteDelete
+)
+
+// BulkWriteModel 批量写中的一项操作
+type BulkWriteModel struct {
+	Op     string
+	Filter Filter
+	Doc    interface{}
+}
+
+// BulkWriteOption 批量写选项
+type BulkWriteOption struct {
+	// Ordered 按顺序执行, 遇到失败项时停止执行后续操作; 否则执行所有操作并返回失败项
+	Ordered bool
+}
+
+// BulkWriteItemResult 批量写中每一项操作的结果
+type BulkWriteItemResult mongodb.BulkWriteItemResult
+
 // ModeUpdate  根据不同的操作符去更新数据
 type ModeUpdate struct {
 	Op  string
@@ -18,10 +18,12 @@ import (
 	"strings"
 	"time"
 
+	apiutil "configcenter/src/apimachinery/util"
 	"configcenter/src/common/backbone"
 	"configcenter/src/storage/dal"
 	"configcenter/src/storage/dal/mongo/local"
 	"configcenter/src/storage/dal/mongo/remote"
+	"configcenter/src/storage/rpc"
 )
 
 // Config config
@@ -36,6 +38,8 @@ type Config struct {
 	MaxOpenConns string
 	MaxIdleConns string
 	Enable       string
+	// RPCTLS is the tls config of the rpc connection to tmserver, it's used when Enable is not true
+	RPCTLS apiutil.TLSClientConfig
 }
 
 // BuildURI return mongo uri according to  https://docs.mongodb.com/manual/reference/connection-string/
@@ -77,7 +81,11 @@ func (c Config) GetMaxIdleConns() int {
 }
 
 // ParseConfigFromKV returns a new config
-func ParseConfigFromKV(prefix string, configmap map[string]string) Config {
+func ParseConfigFromKV(prefix string, configmap map[string]string) (Config, error) {
+	rpcTLS, err := apiutil.NewTLSClientConfigFromConfig(rpc.TLSConfigPrefix, configmap)
+	if err != nil {
+		return Config{}, fmt.Errorf("parse rpc tls config failed, err: %v", err)
+	}
 	return Config{
 		Address:      configmap[prefix+".host"],
 		Port:         configmap[prefix+".port"],
@@ -88,14 +96,15 @@ func ParseConfigFromKV(prefix string, configmap map[string]string) Config {
 		MaxIdleConns: configmap[prefix+".maxIDleConns"],
 		Mechanism:    configmap[prefix+".mechanism"],
 		Enable:       configmap[prefix+".enable"],
-	}
+		RPCTLS:       rpcTLS,
+	}, nil
 }
 
 func (c Config) GetMongoClient(engine *backbone.Engine) (db dal.RDB, err error) {
 	if c.Enable == "true" {
 		db, err = local.NewMgo(c.BuildURI(), time.Minute)
 	} else {
-		db, err = remote.NewWithDiscover(engine)
+		db, err = remote.NewWithDiscover(engine, c.RPCTLS)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("connect mongo server failed %s", err.Error())
@@ -107,7 +116,7 @@ func (c Config) GetTransactionClient(engine *backbone.Engine) (client dal.Transc
 	if c.Enable == "true" {
 		client, err = local.NewMgo(c.BuildURI(), time.Minute)
 	} else {
-		client, err = remote.NewWithDiscover(engine)
+		client, err = remote.NewWithDiscover(engine, c.RPCTLS)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("connect mongo server failed %s", err.Error())
@@ -0,0 +1,79 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package local
+
+import (
+	"context"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/storage/dal"
+
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// Iter 逐条遍历查询结果, 结果由 mongodb 游标按批获取
+func (f *Find) Iter(ctx context.Context) dal.Iterator {
+	batchSize := f.batchSize
+	if batchSize == 0 {
+		batchSize = dal.DefaultBatchSize
+	}
+
+	sess := f.dbc.Copy()
+	query := sess.DB(f.dbname).C(f.collName).Find(f.filter)
+	query = query.Select(f.projection)
+	query = query.Skip(int(f.start))
+	query = query.Limit(int(f.limit))
+	query = query.Sort(f.sort...)
+	query = query.Batch(int(batchSize))
+	return &Iterator{sess: sess, iter: query.Iter(), startTime: time.Now()}
+}
+
+// Iterator implement dal.Iterator interface with mongodb cursor
+type Iterator struct {
+	sess      *mgo.Session
+	iter      *mgo.Iter
+	current   bson.Raw
+	count     uint64
+	startTime time.Time
+}
+
+// Next 移动到下一个文档
+func (i *Iterator) Next(ctx context.Context) bool {
+	if !i.iter.Next(&i.current) {
+		return false
+	}
+	i.count++
+	return true
+}
+
+// Decode 反序列化当前文档
+func (i *Iterator) Decode(result interface{}) error {
+	return i.current.Unmarshal(result)
+}
+
+// Err 遍历过程中的错误
+func (i *Iterator) Err() error {
+	return i.iter.Err()
+}
+
+// Close 关闭游标
+func (i *Iterator) Close(ctx context.Context) error {
+	err := i.iter.Close()
+	i.sess.Close()
+	rid := ctx.Value(common.ContextRequestIDField)
+	blog.V(4).InfoDepthf(1, "mongo iterate %d documents cost %dms, rid: %v", i.count, time.Since(i.startTime)/time.Millisecond, rid)
+	return err
+}
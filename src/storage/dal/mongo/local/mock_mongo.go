@@ -219,6 +219,52 @@ func (f *MockFind) Count(ctx context.Context) (uint64, error) {
 	return f.Mock.retval.Count, err
 }
 
+// BatchSize 迭代器每批获取的数量, mock 中一次获取全部
+func (f *MockFind) BatchSize(size uint64) dal.Find {
+	return f
+}
+
+// Iter 逐条遍历查询结果, 结果同 All
+func (f *MockFind) Iter(ctx context.Context) dal.Iterator {
+	iter := &MockIterator{index: -1}
+	iter.err = f.All(ctx, &iter.docs)
+	return iter
+}
+
+// MockIterator implement dal.Iterator interface
+type MockIterator struct {
+	docs  []bson.Raw
+	index int
+	err   error
+}
+
+// Next 移动到下一个文档
+func (i *MockIterator) Next(ctx context.Context) bool {
+	if i.err != nil || i.index+1 >= len(i.docs) {
+		return false
+	}
+	i.index++
+	return true
+}
+
+// Decode 反序列化当前文档
+func (i *MockIterator) Decode(result interface{}) error {
+	if i.index < 0 || i.index >= len(i.docs) {
+		return errors.New("no current document")
+	}
+	return i.docs[i.index].Unmarshal(result)
+}
+
+// Err 遍历过程中的错误
+func (i *MockIterator) Err() error {
+	return i.err
+}
+
+// Close 关闭迭代器
+func (i *MockIterator) Close(ctx context.Context) error {
+	return nil
+}
+
 // Insert 插入数据, docs 可以为 单个数据 或者 多个数据
 func (c *MockCollection) Insert(ctx context.Context, docs interface{}) error {
 	bsonout, err := bson.Marshal(docs)
@@ -284,6 +330,28 @@ func (c *MockCollection) Delete(ctx context.Context, filter dal.Filter) error {
 	return nil
 }
 
+// BulkWrite 批量执行插入, 更新, upsert 和删除
+func (c *MockCollection) BulkWrite(ctx context.Context, models []dal.BulkWriteModel, opt dal.BulkWriteOption) ([]dal.BulkWriteItemResult, error) {
+	out, err := json.Marshal(models)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]dal.BulkWriteItemResult, len(models))
+	for index := range items {
+		items[index] = dal.BulkWriteItemResult{Index: index, Executed: true}
+	}
+	key := "BULKWRITE:" + c.collName + ":" + string(out)
+	if retval, ok := c.Mock.cache[key]; ok {
+		return items, retval.Err
+	}
+
+	c.Mock.cache[key] = c.Mock.retval
+	c.Mock.retval = nil
+
+	return items, nil
+}
+
 // NextSequence 获取新序列号(非事务)
 func (c *Mock) NextSequence(ctx context.Context, sequenceName string) (uint64, error) {
 
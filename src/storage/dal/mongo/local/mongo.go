@@ -14,6 +14,7 @@ package local
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"strings"
 	"time"
@@ -22,6 +23,8 @@ import (
 	"configcenter/src/common/blog"
 	"configcenter/src/common/util"
 	"configcenter/src/storage/dal"
+	"configcenter/src/storage/dal/instrument"
+	"configcenter/src/storage/mongodb"
 	"configcenter/src/storage/types"
 
 	"gopkg.in/mgo.v2"
@@ -98,7 +101,7 @@ func (c *Mongo) Table(collName string) dal.Table {
 	col := Collection{}
 	col.collName = collName
 	col.Mongo = c
-	return &col
+	return instrument.NewTable(&col, collName)
 }
 
 // Collection implement client.Collection interface
@@ -120,6 +123,7 @@ type Find struct {
 	start      uint64
 	limit      uint64
 	sort       []string
+	batchSize  uint64
 }
 
 // Fields 查询字段
@@ -154,6 +158,12 @@ func (f *Find) Limit(limit uint64) dal.Find {
 	return f
 }
 
+// BatchSize 迭代器每批获取的数量
+func (f *Find) BatchSize(size uint64) dal.Find {
+	f.batchSize = size
+	return f
+}
+
 // All 查询多个
 func (f *Find) All(ctx context.Context, result interface{}) error {
 	sess := f.dbc.Copy()
@@ -198,6 +208,25 @@ func (f *Find) Count(ctx context.Context) (uint64, error) {
 	return uint64(count), err
 }
 
+// Explain 返回查询计划
+func (c *Collection) Explain(ctx context.Context, filter dal.Filter) (string, error) {
+	sess := c.dbc.Copy()
+	defer sess.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		sess.SetSocketTimeout(time.Until(deadline))
+	}
+
+	result := bson.M{}
+	if err := sess.DB(c.dbname).C(c.collName).Find(filter).Explain(&result); err != nil {
+		return "", err
+	}
+	planner, err := json.Marshal(result["queryPlanner"])
+	if err != nil {
+		return "", err
+	}
+	return string(planner), nil
+}
+
 // Insert 插入数据, docs 可以为 单个数据 或者 多个数据
 func (c *Collection) Insert(ctx context.Context, docs interface{}) error {
 	rid := ctx.Value(common.ContextRequestIDField)
@@ -266,6 +295,57 @@ func (c *Collection) Delete(ctx context.Context, filter dal.Filter) error {
 	return err
 }
 
+// BulkWrite 批量执行插入, 更新, upsert 和删除
+func (c *Collection) BulkWrite(ctx context.Context, models []dal.BulkWriteModel, opt dal.BulkWriteOption) ([]dal.BulkWriteItemResult, error) {
+	rid := ctx.Value(common.ContextRequestIDField)
+	start := time.Now()
+	sess := c.dbc.Clone()
+	defer sess.Close()
+
+	bulk := sess.DB(c.dbname).C(c.collName).Bulk()
+	if !opt.Ordered {
+		bulk.Unordered()
+	}
+	for _, model := range models {
+		switch model.Op {
+		case dal.BulkWriteInsert:
+			bulk.Insert(model.Doc)
+		case dal.BulkWriteUpdateOne:
+			bulk.Update(model.Filter, bson.M{"$set": model.Doc})
+		case dal.BulkWriteUpsert:
+			bulk.Upsert(model.Filter, bson.M{"$set": model.Doc})
+		case dal.BulkWriteDelete:
+			bulk.RemoveAll(model.Filter)
+		default:
+			return nil, errors.New("unknown bulk write operation " + model.Op)
+		}
+	}
+
+	var failures map[int]string
+	_, err := bulk.Run()
+	blog.V(4).InfoDepthf(1, "mongo bulk write %d models cost %dms, rid: %v", len(models), time.Since(start)/time.Millisecond, rid)
+	if err != nil {
+		bulkErr, ok := err.(*mgo.BulkError)
+		if !ok {
+			return nil, err
+		}
+		failures = make(map[int]string)
+		for _, errCase := range bulkErr.Cases() {
+			if errCase.Index < 0 {
+				return nil, err
+			}
+			failures[errCase.Index] = errCase.Err.Error()
+		}
+	}
+
+	results := mongodb.NewBulkWriteItemResults(len(models), opt.Ordered, failures)
+	items := make([]dal.BulkWriteItemResult, len(results))
+	for index, result := range results {
+		items[index] = dal.BulkWriteItemResult(result)
+	}
+	return items, err
+}
+
 // NextSequence 获取新序列号(非事务)
 func (c *Mongo) NextSequence(ctx context.Context, sequenceName string) (uint64, error) {
 	rid := ctx.Value(common.ContextRequestIDField)
@@ -357,15 +437,19 @@ func (c *Mongo) DB(collName string) dal.RDB {
 // CreateIndex 创建索引
 func (c *Collection) CreateIndex(ctx context.Context, index dal.Index) error {
 	keys := []string{}
-	for key := range index.Keys {
+	for _, key := range mongodb.Index(index).OrderedKeys() {
+		if index.Keys[key] < 0 && !strings.HasPrefix(key, mongodb.TextIndexKeyPrefix) {
+			key = "-" + key
+		}
 		keys = append(keys, key)
 	}
 
 	i := mgo.Index{
-		Key:        keys,
-		Name:       index.Name,
-		Unique:     index.Unique,
-		Background: index.Background,
+		Key:         keys,
+		Name:        index.Name,
+		Unique:      index.Unique,
+		Background:  index.Background,
+		ExpireAfter: time.Duration(index.ExpireAfterSeconds) * time.Second,
 	}
 	sess := c.dbc.Clone()
 	defer sess.Close()
@@ -391,6 +475,7 @@ func (c *Collection) Indexes(ctx context.Context) ([]dal.Index, error) {
 	indexs := []dal.Index{}
 	for _, dbindex := range dbindexs {
 		keys := map[string]int32{}
+		keyOrder := make([]string, 0, len(dbindex.Key))
 		for _, key := range dbindex.Key {
 			if strings.HasPrefix(key, "-") {
 				key = strings.TrimLeft(key, "-")
@@ -398,13 +483,16 @@ func (c *Collection) Indexes(ctx context.Context) ([]dal.Index, error) {
 			} else {
 				keys[key] = 1
 			}
+			keyOrder = append(keyOrder, key)
 		}
 
 		index := dal.Index{}
 		index.Name = dbindex.Name
 		index.Unique = dbindex.Unique
 		index.Background = dbindex.Background
+		index.ExpireAfterSeconds = int32(dbindex.ExpireAfter / time.Second)
 		index.Keys = keys
+		index.KeyOrder = keyOrder
 		indexs = append(indexs, index)
 	}
 	return indexs, nil
@@ -0,0 +1,477 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package memory
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+// aggregate runs the pipeline on the documents of the collection, the supported stages are
+// $match, $group, $sort, $skip, $limit, $count, $project, $unwind and $lookup.
+func (c *Collection) aggregate(pipeline interface{}) ([]bson.M, error) {
+	// the stages are decoded as raw documents to keep the field order of $sort
+	out, err := bson.Marshal(bson.M{"pipeline": pipeline})
+	if err != nil {
+		return nil, err
+	}
+	stages := struct {
+		Pipeline []bson.RawD `bson:"pipeline"`
+	}{}
+	if err := bson.Unmarshal(out, &stages); err != nil {
+		return nil, err
+	}
+
+	c.lock.RLock()
+	docs := make([]bson.M, 0)
+	if t := c.table(c.collName, false); t != nil {
+		docs = copyDocuments(t.docs)
+	}
+	c.lock.RUnlock()
+
+	for _, stage := range stages.Pipeline {
+		if len(stage) != 1 {
+			return nil, fmt.Errorf("a pipeline stage specification object must contain exactly one field")
+		}
+		if stage[0].Name == "$lookup" {
+			if docs, err = c.lookupStage(docs, stage[0].Value); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if docs, err = runStage(docs, stage[0].Name, stage[0].Value); err != nil {
+			return nil, err
+		}
+	}
+	return docs, nil
+}
+
+// lookupStage joins the documents of the from collection whose foreignField equals the localField,
+// only the equality match form {"from", "localField", "foreignField", "as"} is supported.
+func (c *Collection) lookupStage(docs []bson.M, raw bson.Raw) ([]bson.M, error) {
+	spec := struct {
+		From         string `bson:"from"`
+		LocalField   string `bson:"localField"`
+		ForeignField string `bson:"foreignField"`
+		As           string `bson:"as"`
+	}{}
+	if err := raw.Unmarshal(&spec); err != nil {
+		return nil, err
+	}
+	if spec.From == "" || spec.LocalField == "" || spec.ForeignField == "" || spec.As == "" {
+		return nil, fmt.Errorf("$lookup requires from, localField, foreignField and as")
+	}
+
+	c.lock.RLock()
+	foreignDocs := make([]bson.M, 0)
+	if t := c.table(spec.From, false); t != nil {
+		foreignDocs = copyDocuments(t.docs)
+	}
+	c.lock.RUnlock()
+
+	results := make([]bson.M, 0, len(docs))
+	for _, doc := range docs {
+		localValues := lookupJoinValues(doc, spec.LocalField)
+		joined := make([]interface{}, 0)
+		for _, foreign := range foreignDocs {
+			if joinValuesEqual(localValues, lookupJoinValues(foreign, spec.ForeignField)) {
+				joined = append(joined, copyDocument(foreign))
+			}
+		}
+		result := copyDocument(doc)
+		if err := setPath(result, spec.As, joined); err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// lookupJoinValues returns the values of the field to join on, the elements of an array are joined
+// separately, and a missing field is joined as null.
+func lookupJoinValues(doc bson.M, path string) []interface{} {
+	values := make([]interface{}, 0)
+	for _, value := range lookup(doc, strings.Split(path, ".")) {
+		if array, ok := value.([]interface{}); ok {
+			values = append(values, array...)
+			continue
+		}
+		values = append(values, value)
+	}
+	if len(values) == 0 {
+		values = append(values, nil)
+	}
+	return values
+}
+
+func joinValuesEqual(a, b []interface{}) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if compareValues(x, y) == 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func runStage(docs []bson.M, name string, raw bson.Raw) ([]bson.M, error) {
+	if name == "$sort" {
+		spec := bson.D{}
+		if err := raw.Unmarshal(&spec); err != nil {
+			return nil, err
+		}
+		keys := make([]sortKey, 0, len(spec))
+		for _, item := range spec {
+			keys = append(keys, sortKey{field: item.Name, desc: toInt64(item.Value) < 0})
+		}
+		sortDocuments(docs, keys)
+		return docs, nil
+	}
+
+	var arg interface{}
+	if err := raw.Unmarshal(&arg); err != nil {
+		return nil, err
+	}
+
+	switch name {
+	case "$match":
+		filter, ok := arg.(bson.M)
+		if !ok {
+			return nil, fmt.Errorf("the match filter must be an expression in an object")
+		}
+		matched := make([]bson.M, 0, len(docs))
+		for _, doc := range docs {
+			ok, err := matchDocument(doc, filter)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				matched = append(matched, doc)
+			}
+		}
+		return matched, nil
+	case "$group":
+		spec, ok := arg.(bson.M)
+		if !ok {
+			return nil, fmt.Errorf("a group's fields must be specified in an object")
+		}
+		return groupDocuments(docs, spec)
+	case "$skip":
+		skip := toInt64(arg)
+		if skip >= int64(len(docs)) {
+			return make([]bson.M, 0), nil
+		}
+		return docs[skip:], nil
+	case "$limit":
+		limit := toInt64(arg)
+		if limit <= 0 {
+			return nil, fmt.Errorf("the limit must be positive")
+		}
+		if limit < int64(len(docs)) {
+			return docs[:limit], nil
+		}
+		return docs, nil
+	case "$count":
+		field, ok := arg.(string)
+		if !ok || field == "" {
+			return nil, fmt.Errorf("the count field must be a non-empty string")
+		}
+		if len(docs) == 0 {
+			return docs, nil
+		}
+		return []bson.M{{field: len(docs)}}, nil
+	case "$project":
+		spec, ok := arg.(bson.M)
+		if !ok {
+			return nil, fmt.Errorf("$project specification must be an object")
+		}
+		return projectStage(docs, spec)
+	case "$unwind":
+		return unwindDocuments(docs, arg)
+	}
+	return nil, fmt.Errorf("unsupported pipeline stage %s", name)
+}
+
+// groupDocuments groups the documents by the _id expression and computes the accumulators,
+// the supported accumulators are $sum, $avg, $min, $max, $first, $last, $push and $addToSet.
+func groupDocuments(docs []bson.M, spec bson.M) ([]bson.M, error) {
+	idExpr, ok := spec["_id"]
+	if !ok {
+		return nil, fmt.Errorf("a group specification must include an _id")
+	}
+
+	type group struct {
+		id   interface{}
+		docs []bson.M
+	}
+	groups := make([]*group, 0)
+	for _, doc := range docs {
+		id, err := evalExpression(doc, idExpr)
+		if err != nil {
+			return nil, err
+		}
+		var target *group
+		for _, g := range groups {
+			if compareValues(g.id, id) == 0 {
+				target = g
+				break
+			}
+		}
+		if target == nil {
+			target = &group{id: id}
+			groups = append(groups, target)
+		}
+		target.docs = append(target.docs, doc)
+	}
+
+	results := make([]bson.M, 0, len(groups))
+	for _, g := range groups {
+		result := bson.M{"_id": g.id}
+		for field, accumulator := range spec {
+			if field == "_id" {
+				continue
+			}
+			acc, ok := accumulator.(bson.M)
+			if !ok || len(acc) != 1 {
+				return nil, fmt.Errorf("the group field '%s' must be an accumulator object", field)
+			}
+			for op, expr := range acc {
+				value, err := accumulate(g.docs, op, expr)
+				if err != nil {
+					return nil, err
+				}
+				result[field] = value
+			}
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func accumulate(docs []bson.M, op string, expr interface{}) (interface{}, error) {
+	values := make([]interface{}, 0, len(docs))
+	for _, doc := range docs {
+		value, err := evalExpression(doc, expr)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+
+	switch op {
+	case "$sum", "$avg":
+		var sum interface{} = 0
+		count := 0
+		for _, value := range values {
+			if !isNumber(value) {
+				continue
+			}
+			sum, _ = addNumbers(sum, value)
+			count++
+		}
+		if op == "$sum" {
+			return sum, nil
+		}
+		if count == 0 {
+			return nil, nil
+		}
+		return toFloat64(sum) / float64(count), nil
+	case "$min", "$max":
+		var result interface{}
+		for _, value := range values {
+			if value == nil {
+				continue
+			}
+			if result == nil || (op == "$min" && compareValues(value, result) < 0) ||
+				(op == "$max" && compareValues(value, result) > 0) {
+				result = value
+			}
+		}
+		return result, nil
+	case "$first":
+		if len(values) == 0 {
+			return nil, nil
+		}
+		return values[0], nil
+	case "$last":
+		if len(values) == 0 {
+			return nil, nil
+		}
+		return values[len(values)-1], nil
+	case "$push", "$addToSet":
+		array := make([]interface{}, 0, len(values))
+		for _, value := range values {
+			if value == nil || (op == "$addToSet" && containsValue(array, value)) {
+				continue
+			}
+			array = append(array, value)
+		}
+		return array, nil
+	}
+	return nil, fmt.Errorf("unsupported group accumulator %s", op)
+}
+
+// evalExpression evaluates the expression on the document, "$field" is the value of the field,
+// "$$ROOT" is the document itself, the object and array are evaluated by their elements,
+// other values are literals.
+func evalExpression(doc bson.M, expr interface{}) (interface{}, error) {
+	switch v := expr.(type) {
+	case string:
+		if v == "$$ROOT" {
+			return doc, nil
+		}
+		if !strings.HasPrefix(v, "$") {
+			return v, nil
+		}
+		values := lookup(doc, strings.Split(strings.TrimPrefix(v, "$"), "."))
+		switch len(values) {
+		case 0:
+			return nil, nil
+		case 1:
+			return values[0], nil
+		}
+		return values, nil
+	case bson.M:
+		result := bson.M{}
+		for field, item := range v {
+			if strings.HasPrefix(field, "$") {
+				return nil, fmt.Errorf("unsupported expression operator %s", field)
+			}
+			value, err := evalExpression(doc, item)
+			if err != nil {
+				return nil, err
+			}
+			result[field] = value
+		}
+		return result, nil
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for index, item := range v {
+			value, err := evalExpression(doc, item)
+			if err != nil {
+				return nil, err
+			}
+			result[index] = value
+		}
+		return result, nil
+	}
+	return expr, nil
+}
+
+// projectStage includes the fields with true values and the computed fields, or excludes the fields with
+// false values if no field is included, _id is included unless it's excluded explicitly.
+func projectStage(docs []bson.M, spec bson.M) ([]bson.M, error) {
+	include := false
+	for field, value := range spec {
+		if field != "_id" && isProjectInclude(value) {
+			include = true
+		}
+	}
+
+	results := make([]bson.M, 0, len(docs))
+	for _, doc := range docs {
+		if !include {
+			result := copyDocument(doc)
+			for field := range spec {
+				unsetPath(result, field)
+			}
+			results = append(results, result)
+			continue
+		}
+
+		result := bson.M{}
+		if idSpec, ok := spec["_id"]; !ok || isTrue(idSpec) {
+			if id, ok := doc["_id"]; ok {
+				result["_id"] = id
+			}
+		}
+		for field, value := range spec {
+			if !isProjectInclude(value) {
+				continue
+			}
+			if isNumber(value) || value == true {
+				if current, ok := getPath(doc, field); ok {
+					if err := setPath(result, field, current); err != nil {
+						return nil, err
+					}
+				}
+				continue
+			}
+			computed, err := evalExpression(doc, value)
+			if err != nil {
+				return nil, err
+			}
+			if err := setPath(result, field, computed); err != nil {
+				return nil, err
+			}
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// isProjectInclude returns whether the $project field is included or computed
+func isProjectInclude(value interface{}) bool {
+	if value == false || (isNumber(value) && toFloat64(value) == 0) {
+		return false
+	}
+	return true
+}
+
+// unwindDocuments outputs a document for each element of the array field, the argument is the field path
+// like "$field" or an object like {"path": "$field", "preserveNullAndEmptyArrays": true}
+func unwindDocuments(docs []bson.M, arg interface{}) ([]bson.M, error) {
+	path, preserve := "", false
+	switch v := arg.(type) {
+	case string:
+		path = v
+	case bson.M:
+		path, _ = v["path"].(string)
+		preserve = isTrue(v["preserveNullAndEmptyArrays"])
+	}
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("the path option to $unwind stage should be prefixed with a '$'")
+	}
+	path = strings.TrimPrefix(path, "$")
+
+	results := make([]bson.M, 0, len(docs))
+	for _, doc := range docs {
+		value, exists := getPath(doc, path)
+		array, isArray := value.([]interface{})
+		if !exists || value == nil || (isArray && len(array) == 0) {
+			if preserve {
+				result := copyDocument(doc)
+				if isArray {
+					unsetPath(result, path)
+				}
+				results = append(results, result)
+			}
+			continue
+		}
+		if !isArray {
+			results = append(results, doc)
+			continue
+		}
+		for _, item := range array {
+			result := copyDocument(doc)
+			if err := setPath(result, path, item); err != nil {
+				return nil, err
+			}
+			results = append(results, result)
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,262 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package memory
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+
+	"configcenter/src/storage/dal"
+	"configcenter/src/storage/types"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+// Find 查询多个并反序列化到 Result
+func (c *Collection) Find(filter dal.Filter) dal.Find {
+	return &Find{Collection: c, filter: filter, projection: types.Document{"_id": false}}
+}
+
+// Find define a find operation
+type Find struct {
+	*Collection
+	projection types.Document
+	filter     dal.Filter
+	start      uint64
+	limit      uint64
+	sort       []string
+}
+
+// Fields 查询字段
+func (f *Find) Fields(fields ...string) dal.Find {
+	for _, field := range fields {
+		if len(field) <= 0 {
+			continue
+		}
+		f.projection[field] = true
+	}
+	return f
+}
+
+// Sort 查询排序
+func (f *Find) Sort(sort string) dal.Find {
+	if sort != "" {
+		f.sort = strings.Split(sort, ",")
+	}
+	return f
+}
+
+// Start 查询上标
+func (f *Find) Start(start uint64) dal.Find {
+	f.start = start
+	return f
+}
+
+// Limit 查询限制
+func (f *Find) Limit(limit uint64) dal.Find {
+	f.limit = limit
+	return f
+}
+
+// BatchSize 迭代器每批获取的数量, 内存中一次获取全部
+func (f *Find) BatchSize(size uint64) dal.Find {
+	return f
+}
+
+// All 查询多个
+func (f *Find) All(ctx context.Context, result interface{}) error {
+	docs, err := f.find(f.start, f.limit)
+	if err != nil {
+		return err
+	}
+	return decodeAll(docs, result)
+}
+
+// One 查询一个
+func (f *Find) One(ctx context.Context, result interface{}) error {
+	docs, err := f.find(f.start, 1)
+	if err != nil {
+		return err
+	}
+	if len(docs) == 0 {
+		return dal.ErrDocumentNotFound
+	}
+	return decodeOne(docs[0], result)
+}
+
+// Count 统计数量(非事务), 同 mongodb 不受 Start 和 Limit 影响
+func (f *Find) Count(ctx context.Context) (uint64, error) {
+	docs, err := f.find(0, 0)
+	if err != nil {
+		return 0, err
+	}
+	return uint64(len(docs)), nil
+}
+
+// Iter 逐条遍历查询结果, 结果在创建时一次获取
+func (f *Find) Iter(ctx context.Context) dal.Iterator {
+	docs, err := f.find(f.start, f.limit)
+	return &Iterator{docs: docs, index: -1, err: err}
+}
+
+// find returns the matched documents after sorting, skipping, limiting and projecting
+func (f *Find) find(start, limit uint64) ([]bson.M, error) {
+	cond, err := toDocument(f.filter)
+	if err != nil {
+		return nil, err
+	}
+
+	f.lock.RLock()
+	docs := make([]bson.M, 0)
+	if t := f.table(f.collName, false); t != nil {
+		for _, doc := range t.docs {
+			matched, err := matchDocument(doc, cond)
+			if err != nil {
+				f.lock.RUnlock()
+				return nil, err
+			}
+			if matched {
+				docs = append(docs, doc)
+			}
+		}
+	}
+	f.lock.RUnlock()
+
+	sortDocuments(docs, parseSort(f.sort))
+	if start >= uint64(len(docs)) {
+		return make([]bson.M, 0), nil
+	}
+	docs = docs[start:]
+	if limit > 0 && limit < uint64(len(docs)) {
+		docs = docs[:limit]
+	}
+
+	projected := make([]bson.M, len(docs))
+	for index, doc := range docs {
+		projected[index] = projectDocument(doc, f.projection)
+	}
+	return projected, nil
+}
+
+// projectDocument returns the copy of the document with the projection fields,
+// or all the fields except the excluded ones if no field is included.
+func projectDocument(doc bson.M, projection types.Document) bson.M {
+	included := make([]string, 0)
+	for field, value := range projection {
+		if isTrue(value) {
+			included = append(included, field)
+		}
+	}
+
+	if len(included) == 0 {
+		projected := copyDocument(doc)
+		for field := range projection {
+			unsetPath(projected, field)
+		}
+		return projected
+	}
+
+	projected := bson.M{}
+	for _, field := range included {
+		if value, ok := getPath(doc, field); ok {
+			setPath(projected, field, copyValue(value))
+		}
+	}
+	return projected
+}
+
+// sortKey is a sort field, in descending order if desc is true
+type sortKey struct {
+	field string
+	desc  bool
+}
+
+// parseSort parses the sort fields like "-bk_host_id", the field is in descending order with "-" prefix
+func parseSort(fields []string) []sortKey {
+	keys := make([]sortKey, 0, len(fields))
+	for _, field := range fields {
+		field = strings.TrimSpace(field)
+		key := sortKey{field: strings.TrimLeft(field, "+-")}
+		if strings.HasPrefix(field, "-") {
+			key.desc = true
+		}
+		if key.field != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// sortDocuments sorts the documents by the keys in mongodb comparison order, the missing field is treated as null
+func sortDocuments(docs []bson.M, keys []sortKey) {
+	if len(keys) == 0 {
+		return
+	}
+	sort.SliceStable(docs, func(i, j int) bool {
+		for _, key := range keys {
+			result := compareValues(sortValue(docs[i], key.field), sortValue(docs[j], key.field))
+			if result == 0 {
+				continue
+			}
+			if key.desc {
+				return result > 0
+			}
+			return result < 0
+		}
+		return false
+	})
+}
+
+func sortValue(doc bson.M, field string) interface{} {
+	values := lookup(doc, strings.Split(field, "."))
+	if len(values) == 0 {
+		return nil
+	}
+	return values[0]
+}
+
+// Iterator implement dal.Iterator interface
+type Iterator struct {
+	docs  []bson.M
+	index int
+	err   error
+}
+
+// Next 移动到下一个文档
+func (i *Iterator) Next(ctx context.Context) bool {
+	if i.err != nil || i.index+1 >= len(i.docs) {
+		return false
+	}
+	i.index++
+	return true
+}
+
+// Decode 反序列化当前文档
+func (i *Iterator) Decode(result interface{}) error {
+	if i.index < 0 || i.index >= len(i.docs) {
+		return errors.New("no current document")
+	}
+	return decodeOne(i.docs[i.index], result)
+}
+
+// Err 遍历过程中的错误
+func (i *Iterator) Err() error {
+	return i.err
+}
+
+// Close 关闭迭代器
+func (i *Iterator) Close(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,501 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package memory
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+// matchDocument returns whether the document matches the filter
+func matchDocument(doc bson.M, filter bson.M) (bool, error) {
+	for key, cond := range filter {
+		var matched bool
+		var err error
+		switch key {
+		case "$and", "$or", "$nor":
+			matched, err = matchLogical(doc, key, cond)
+		default:
+			if strings.HasPrefix(key, "$") {
+				return false, fmt.Errorf("unsupported query operator %s", key)
+			}
+			matched, err = matchCondition(lookup(doc, strings.Split(key, ".")), cond)
+		}
+		if err != nil || !matched {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// matchLogical evaluates the $and, $or and $nor operators
+func matchLogical(doc bson.M, op string, cond interface{}) (bool, error) {
+	conds, ok := cond.([]interface{})
+	if !ok || len(conds) == 0 {
+		return false, fmt.Errorf("%s must be a nonempty array", op)
+	}
+	for _, item := range conds {
+		filter, ok := item.(bson.M)
+		if !ok {
+			return false, fmt.Errorf("%s entries need to be documents", op)
+		}
+		matched, err := matchDocument(doc, filter)
+		if err != nil {
+			return false, err
+		}
+		switch {
+		case op == "$and" && !matched:
+			return false, nil
+		case op == "$or" && matched:
+			return true, nil
+		case op == "$nor" && matched:
+			return false, nil
+		}
+	}
+	return op != "$or", nil
+}
+
+// matchCondition returns whether the values of a field match the condition,
+// which is an operator document like {"$in": [...]} or a value to be equal to.
+func matchCondition(values []interface{}, cond interface{}) (bool, error) {
+	ops, ok := cond.(bson.M)
+	if !ok || !isOperatorDocument(ops) {
+		return matchEqual(values, cond), nil
+	}
+	for op, arg := range ops {
+		matched, err := matchOperator(values, op, arg, ops)
+		if err != nil || !matched {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func isOperatorDocument(doc bson.M) bool {
+	for key := range doc {
+		if strings.HasPrefix(key, "$") {
+			return true
+		}
+	}
+	return false
+}
+
+func matchOperator(values []interface{}, op string, arg interface{}, ops bson.M) (bool, error) {
+	switch op {
+	case "$eq":
+		return matchEqual(values, arg), nil
+	case "$ne":
+		return !matchEqual(values, arg), nil
+	case "$in", "$nin":
+		items, ok := arg.([]interface{})
+		if !ok {
+			return false, fmt.Errorf("%s needs an array", op)
+		}
+		matched := false
+		for _, item := range items {
+			if matchEqual(values, item) {
+				matched = true
+				break
+			}
+		}
+		return matched == (op == "$in"), nil
+	case "$gt", "$gte", "$lt", "$lte":
+		for _, value := range expandArrays(values) {
+			if typeOrder(value) != typeOrder(arg) {
+				continue
+			}
+			result := compareValues(value, arg)
+			if (op == "$gt" && result > 0) || (op == "$gte" && result >= 0) ||
+				(op == "$lt" && result < 0) || (op == "$lte" && result <= 0) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case "$exists":
+		return (len(values) > 0) == isTrue(arg), nil
+	case "$regex":
+		re, err := compileRegex(arg, ops["$options"])
+		if err != nil {
+			return false, err
+		}
+		for _, value := range expandArrays(values) {
+			if str, ok := value.(string); ok && re.MatchString(str) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case "$options":
+		if _, ok := ops["$regex"]; !ok {
+			return false, fmt.Errorf("$options needs a $regex")
+		}
+		return true, nil
+	case "$not":
+		matched, err := matchCondition(values, arg)
+		return !matched, err
+	case "$all":
+		items, ok := arg.([]interface{})
+		if !ok {
+			return false, fmt.Errorf("$all needs an array")
+		}
+		for _, item := range items {
+			if !matchEqual(values, item) {
+				return false, nil
+			}
+		}
+		return len(items) > 0, nil
+	case "$size":
+		for _, value := range values {
+			if array, ok := value.([]interface{}); ok && int64(len(array)) == toInt64(arg) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case "$elemMatch":
+		cond, ok := arg.(bson.M)
+		if !ok {
+			return false, fmt.Errorf("$elemMatch needs an Object")
+		}
+		for _, value := range values {
+			array, ok := value.([]interface{})
+			if !ok {
+				continue
+			}
+			for _, item := range array {
+				matched, err := matchElement(item, cond)
+				if err != nil {
+					return false, err
+				}
+				if matched {
+					return true, nil
+				}
+			}
+		}
+		return false, nil
+	}
+	return false, fmt.Errorf("unsupported query operator %s", op)
+}
+
+// matchElement returns whether the array element matches the condition of $elemMatch or $pull,
+// which is an operator document for the element itself, or a filter of the element document.
+func matchElement(item interface{}, cond bson.M) (bool, error) {
+	if isOperatorDocument(cond) {
+		return matchCondition([]interface{}{item}, cond)
+	}
+	doc, ok := item.(bson.M)
+	if !ok {
+		return false, nil
+	}
+	return matchDocument(doc, cond)
+}
+
+// matchEqual returns whether any of the values or their array elements equals to the condition,
+// a null condition matches the missing field too, and a regex condition matches the strings.
+func matchEqual(values []interface{}, cond interface{}) bool {
+	if cond == nil && len(values) == 0 {
+		return true
+	}
+	for _, value := range expandArrays(values) {
+		if re, ok := cond.(bson.RegEx); ok {
+			str, isStr := value.(string)
+			if regex, err := compileRegex(re, nil); err == nil && isStr && regex.MatchString(str) {
+				return true
+			}
+			continue
+		}
+		if compareValues(value, cond) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// expandArrays returns the values and the elements of the array values, as a query on an array field
+// matches the array itself or any of its elements.
+func expandArrays(values []interface{}) []interface{} {
+	expanded := make([]interface{}, 0, len(values))
+	for _, value := range values {
+		expanded = append(expanded, value)
+		if array, ok := value.([]interface{}); ok {
+			expanded = append(expanded, array...)
+		}
+	}
+	return expanded
+}
+
+// lookup returns the values of the field path, a path through an array of documents
+// returns the values of all the documents, no value is returned if the field does not exist.
+func lookup(value interface{}, parts []string) []interface{} {
+	if len(parts) == 0 {
+		return []interface{}{value}
+	}
+	switch v := value.(type) {
+	case bson.M:
+		child, ok := v[parts[0]]
+		if !ok {
+			return nil
+		}
+		return lookup(child, parts[1:])
+	case []interface{}:
+		if index, err := strconv.Atoi(parts[0]); err == nil {
+			if index < 0 || index >= len(v) {
+				return nil
+			}
+			return lookup(v[index], parts[1:])
+		}
+		values := make([]interface{}, 0)
+		for _, item := range v {
+			if doc, ok := item.(bson.M); ok {
+				values = append(values, lookup(doc, parts)...)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+// compileRegex compiles the $regex pattern with the $options, only the i, m and s options are supported
+func compileRegex(pattern interface{}, options interface{}) (*regexp.Regexp, error) {
+	var expr, flags string
+	switch p := pattern.(type) {
+	case string:
+		expr = p
+	case bson.RegEx:
+		expr, flags = p.Pattern, p.Options
+	default:
+		return nil, fmt.Errorf("$regex has to be a string")
+	}
+	if opt, ok := options.(string); ok {
+		flags += opt
+	}
+
+	goFlags := ""
+	for _, flag := range "ims" {
+		if strings.ContainsRune(flags, flag) {
+			goFlags += string(flag)
+		}
+	}
+	if goFlags != "" {
+		expr = "(?" + goFlags + ")" + expr
+	}
+	return regexp.Compile(expr)
+}
+
+// typeOrder returns the order of the value type in mongodb comparison
+func typeOrder(value interface{}) int {
+	switch value.(type) {
+	case nil:
+		return 1
+	case int, int32, int64, float64:
+		return 2
+	case string, bson.Symbol:
+		return 3
+	case bson.M:
+		return 4
+	case []interface{}:
+		return 5
+	case []byte, bson.Binary:
+		return 6
+	case bson.ObjectId:
+		return 7
+	case bool:
+		return 8
+	case time.Time:
+		return 9
+	case bson.MongoTimestamp:
+		return 10
+	case bson.RegEx:
+		return 11
+	}
+	return 12
+}
+
+// compareValues compares the values in mongodb comparison order, the values of different types
+// are ordered by type, the numbers are compared by value regardless of the type.
+func compareValues(a, b interface{}) int {
+	ta, tb := typeOrder(a), typeOrder(b)
+	if ta != tb {
+		return compareInt(int64(ta), int64(tb))
+	}
+
+	switch x := a.(type) {
+	case nil:
+		return 0
+	case int, int32, int64, float64:
+		return compareNumbers(a, b)
+	case string:
+		return strings.Compare(x, toString(b))
+	case bson.Symbol:
+		return strings.Compare(string(x), toString(b))
+	case bson.M:
+		y := b.(bson.M)
+		xKeys, yKeys := sortedKeys(x), sortedKeys(y)
+		for index := 0; index < len(xKeys) && index < len(yKeys); index++ {
+			if result := strings.Compare(xKeys[index], yKeys[index]); result != 0 {
+				return result
+			}
+			if result := compareValues(x[xKeys[index]], y[yKeys[index]]); result != 0 {
+				return result
+			}
+		}
+		return compareInt(int64(len(xKeys)), int64(len(yKeys)))
+	case []interface{}:
+		y := b.([]interface{})
+		for index := 0; index < len(x) && index < len(y); index++ {
+			if result := compareValues(x[index], y[index]); result != 0 {
+				return result
+			}
+		}
+		return compareInt(int64(len(x)), int64(len(y)))
+	case []byte:
+		if y, ok := b.([]byte); ok {
+			return bytes.Compare(x, y)
+		}
+	case bson.ObjectId:
+		return strings.Compare(string(x), string(b.(bson.ObjectId)))
+	case bool:
+		y := b.(bool)
+		if x == y {
+			return 0
+		}
+		if !x {
+			return -1
+		}
+		return 1
+	case time.Time:
+		y := b.(time.Time)
+		if x.Before(y) {
+			return -1
+		}
+		if x.After(y) {
+			return 1
+		}
+		return 0
+	case bson.MongoTimestamp:
+		return compareInt(int64(x), int64(b.(bson.MongoTimestamp)))
+	case bson.RegEx:
+		y := b.(bson.RegEx)
+		if result := strings.Compare(x.Pattern, y.Pattern); result != 0 {
+			return result
+		}
+		return strings.Compare(x.Options, y.Options)
+	}
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+func compareInt(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// compareNumbers compares the numbers, the integers are compared exactly
+func compareNumbers(a, b interface{}) int {
+	if isInteger(a) && isInteger(b) {
+		return compareInt(toInt64(a), toInt64(b))
+	}
+	x, y := toFloat64(a), toFloat64(b)
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	}
+	return 0
+}
+
+func sortedKeys(doc bson.M) []string {
+	keys := make([]string, 0, len(doc))
+	for key := range doc {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func toString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case bson.Symbol:
+		return string(v)
+	}
+	return fmt.Sprint(value)
+}
+
+func isNumber(value interface{}) bool {
+	switch value.(type) {
+	case int, int32, int64, float64:
+		return true
+	}
+	return false
+}
+
+func isInteger(value interface{}) bool {
+	switch value.(type) {
+	case int, int32, int64:
+		return true
+	}
+	return false
+}
+
+func toInt64(value interface{}) int64 {
+	switch v := value.(type) {
+	case int:
+		return int64(v)
+	case int32:
+		return int64(v)
+	case int64:
+		return v
+	case float64:
+		return int64(v)
+	}
+	return 0
+}
+
+func toFloat64(value interface{}) float64 {
+	switch v := value.(type) {
+	case int:
+		return float64(v)
+	case int32:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case float64:
+		return v
+	}
+	return 0
+}
+
+// isTrue returns whether the value is true in the sense of mongodb, such as {"$exists": 1} and {"field": true} of projection
+func isTrue(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case int, int32, int64, float64:
+		return toFloat64(v) != 0
+	}
+	return true
+}
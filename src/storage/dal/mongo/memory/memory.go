@@ -0,0 +1,636 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/util"
+	"configcenter/src/storage/dal"
+	"configcenter/src/storage/mongodb"
+	"configcenter/src/storage/types"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+// Memory implement dal.DB interface with the documents stored in memory.
+// the filters, updates and aggregations are evaluated as mongodb does for the operators cmdb uses,
+// so the logic based on dal.DB could be unit tested without a mongodb.
+type Memory struct {
+	*store
+	// txn is the transaction started by Start, nil if the DB is not a transaction
+	txn *transaction
+}
+
+// store is the data shared by the cloned DBs
+type store struct {
+	lock   sync.RWMutex
+	tables map[string]*table
+	// txns are the transactions in progress, there is no isolation, every write is recorded by all of them
+	txns map[*transaction]struct{}
+}
+
+// transaction keeps the tables written in it as they were before the first write, so that they are restored on abort
+type transaction struct {
+	// snapshots are the written tables by name, nil if the table not existed
+	snapshots map[string]*table
+}
+
+// table is the documents and indexes of a collection, the documents are never modified in place,
+// an operation works on copies and replaces the documents when it succeeds.
+type table struct {
+	docs    []bson.M
+	indexes []dal.Index
+}
+
+var _ dal.DB = new(Memory)
+
+// New returns new empty in-memory DB
+func New() *Memory {
+	return &Memory{
+		store: &store{tables: make(map[string]*table), txns: make(map[*transaction]struct{})},
+	}
+}
+
+// table returns the table of the collection, creates it if not exists and create is true
+func (s *store) table(collName string, create bool) *table {
+	t, ok := s.tables[collName]
+	if !ok && create {
+		t = &table{docs: make([]bson.M, 0)}
+		s.tables[collName] = t
+	}
+	return t
+}
+
+// touch records the table in the transactions in progress before it's written, the lock must be held
+func (s *store) touch(collName string) {
+	for txn := range s.txns {
+		if _, ok := txn.snapshots[collName]; ok {
+			continue
+		}
+		// the documents and indexes are never modified in place, so the snapshot shares them
+		var snapshot *table
+		if t, ok := s.tables[collName]; ok {
+			snapshot = &table{docs: t.docs, indexes: t.indexes}
+		}
+		txn.snapshots[collName] = snapshot
+	}
+}
+
+// replace replaces the documents of the table after checking the unique indexes
+func (t *table) replace(docs []bson.M) error {
+	if err := checkUnique(docs, t.indexes); err != nil {
+		return err
+	}
+	t.docs = docs
+	return nil
+}
+
+// checkUnique checks whether the documents are duplicated on _id or the unique indexes,
+// a missing field is treated as null as mongodb does.
+func checkUnique(docs []bson.M, indexes []dal.Index) error {
+	uniques := [][]string{{"_id"}}
+	for _, index := range indexes {
+		if !index.Unique {
+			continue
+		}
+		keys := make([]string, 0, len(index.Keys))
+		for key := range index.Keys {
+			keys = append(keys, key)
+		}
+		uniques = append(uniques, keys)
+	}
+
+	for _, keys := range uniques {
+		seen := make([][]interface{}, 0, len(docs))
+		for _, doc := range docs {
+			values := make([]interface{}, len(keys))
+			for index, key := range keys {
+				values[index], _ = getPath(doc, key)
+			}
+			for _, exist := range seen {
+				if compareValues(exist, values) == 0 {
+					return dal.ErrDuplicated
+				}
+			}
+			seen = append(seen, values)
+		}
+	}
+	return nil
+}
+
+// Close replica client
+func (c *Memory) Close() error {
+	return nil
+}
+
+// Ping replica client
+func (c *Memory) Ping() error {
+	return nil
+}
+
+// Clone return the new client, the clones share the same data
+func (c *Memory) Clone() dal.DB {
+	nc := Memory{
+		store: c.store,
+	}
+	return &nc
+}
+
+// IsDuplicatedError check duplicated error
+func (c *Memory) IsDuplicatedError(err error) bool {
+	return err == dal.ErrDuplicated
+}
+
+// IsNotFoundError check the not found error
+func (c *Memory) IsNotFoundError(err error) bool {
+	return err == dal.ErrDocumentNotFound
+}
+
+// Table collection operation
+func (c *Memory) Table(collName string) dal.Table {
+	col := Collection{}
+	col.collName = collName
+	col.Memory = c
+	return &col
+}
+
+// NextSequence 获取新序列号(非事务)
+func (c *Memory) NextSequence(ctx context.Context, sequenceName string) (uint64, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	t := c.table(common.BKTableNameIDgenerator, true)
+	docs := copyDocuments(t.docs)
+	for _, doc := range docs {
+		if doc["_id"] != sequenceName {
+			continue
+		}
+		sequence, err := addNumbers(doc["SequenceID"], int64(1))
+		if err != nil {
+			return 0, err
+		}
+		doc["SequenceID"] = sequence
+		doc["last_time"] = time.Now()
+		if err := t.replace(docs); err != nil {
+			return 0, err
+		}
+		return uint64(toInt64(sequence)), nil
+	}
+
+	now := time.Now()
+	docs = append(docs, bson.M{"_id": sequenceName, "SequenceID": int64(1), "create_time": now, "last_time": now})
+	return 1, t.replace(docs)
+}
+
+// Start 开启新事务, 事务中写过的集合在 Abort 时恢复, 事务之间没有隔离
+func (c *Memory) Start(ctx context.Context) (dal.Transcation, error) {
+	txn := &transaction{snapshots: make(map[string]*table)}
+	c.lock.Lock()
+	c.txns[txn] = struct{}{}
+	c.lock.Unlock()
+	return &Memory{store: c.store, txn: txn}, nil
+}
+
+// Commit 提交事务
+func (c *Memory) Commit(ctx context.Context) error {
+	c.finish(false)
+	return nil
+}
+
+// Abort 取消事务
+func (c *Memory) Abort(ctx context.Context) error {
+	c.finish(true)
+	return nil
+}
+
+// finish ends the transaction, and restores the tables written in it if rollback is true
+func (c *Memory) finish(rollback bool) {
+	if c.txn == nil {
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	delete(c.txns, c.txn)
+	if rollback {
+		for collName, snapshot := range c.txn.snapshots {
+			if snapshot == nil {
+				delete(c.tables, collName)
+				continue
+			}
+			c.tables[collName] = snapshot
+		}
+	}
+	c.txn = nil
+}
+
+// TxnInfo 当前事务信息，用于事务发起者往下传递
+func (c *Memory) TxnInfo() *types.Transaction {
+	return &types.Transaction{}
+}
+
+// AutoRun 在事务中执行 f, f 返回错误时回滚
+func (c *Memory) AutoRun(ctx context.Context, opt dal.TxnWrapperOption, f func(header http.Header) error) error {
+	txn, err := c.Start(ctx)
+	if err != nil {
+		return err
+	}
+	if err := f(opt.Header); err != nil {
+		txn.Abort(ctx)
+		return err
+	}
+	return txn.Commit(ctx)
+}
+
+// HasTable 判断是否存在集合
+func (c *Memory) HasTable(collName string) (bool, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.table(collName, false) != nil, nil
+}
+
+// DropTable 移除集合
+func (c *Memory) DropTable(collName string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.table(collName, false) == nil {
+		return errors.New("ns not found")
+	}
+	c.touch(collName)
+	delete(c.tables, collName)
+	return nil
+}
+
+// CreateTable 创建集合
+func (c *Memory) CreateTable(collName string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.table(collName, false) != nil {
+		return fmt.Errorf("collection %s already exists", collName)
+	}
+	c.touch(collName)
+	c.table(collName, true)
+	return nil
+}
+
+// Collection implement client.Collection interface
+type Collection struct {
+	collName string // 集合名
+	*Memory
+}
+
+// Insert 插入数据, docs 可以为 单个数据 或者 多个数据
+func (c *Collection) Insert(ctx context.Context, docs interface{}) error {
+	newDocs := make([]bson.M, 0)
+	for _, doc := range util.ConverToInterfaceSlice(docs) {
+		item, err := toDocument(doc)
+		if err != nil {
+			return err
+		}
+		if _, ok := item["_id"]; !ok {
+			item["_id"] = bson.NewObjectId()
+		}
+		newDocs = append(newDocs, item)
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.touch(c.collName)
+	t := c.table(c.collName, true)
+	return t.replace(append(t.docs[:len(t.docs):len(t.docs)], newDocs...))
+}
+
+// Update 更新数据
+func (c *Collection) Update(ctx context.Context, filter dal.Filter, doc interface{}) error {
+	return c.update(filter, bson.M{"$set": doc}, true, false)
+}
+
+// Upsert 更新数据, 不存在时插入
+func (c *Collection) Upsert(ctx context.Context, filter dal.Filter, doc interface{}) error {
+	return c.update(filter, bson.M{"$set": doc}, false, true)
+}
+
+// UpdateMultiModel 根据不同的操作符去更新数据
+func (c *Collection) UpdateMultiModel(ctx context.Context, filter dal.Filter, updateModel ...dal.ModeUpdate) error {
+	data := bson.M{}
+	for _, item := range updateModel {
+		if _, ok := data["$"+item.Op]; ok {
+			return errors.New(item.Op + " appear multiple times")
+		}
+		data["$"+item.Op] = item.Doc
+	}
+	return c.update(filter, data, true, false)
+}
+
+// update applies the update operators to the first or all documents matching the filter,
+// inserts the document built from the filter and the update if nothing matches and upsert is true.
+func (c *Collection) update(filter dal.Filter, update interface{}, multi, upsert bool) error {
+	cond, err := toDocument(filter)
+	if err != nil {
+		return err
+	}
+	modifier, err := toDocument(update)
+	if err != nil {
+		return err
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.touch(c.collName)
+	t := c.table(c.collName, upsert)
+	if t == nil {
+		return nil
+	}
+
+	docs := make([]bson.M, len(t.docs))
+	copy(docs, t.docs)
+	matched := false
+	for index, doc := range docs {
+		ok, err := matchDocument(doc, cond)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		updated := copyDocument(doc)
+		if err := applyUpdate(updated, modifier, false); err != nil {
+			return err
+		}
+		docs[index] = updated
+		matched = true
+		if !multi {
+			break
+		}
+	}
+
+	if !matched && upsert {
+		doc := upsertDocument(cond)
+		if err := applyUpdate(doc, modifier, true); err != nil {
+			return err
+		}
+		if _, ok := doc["_id"]; !ok {
+			doc["_id"] = bson.NewObjectId()
+		}
+		docs = append(docs, doc)
+	}
+	return t.replace(docs)
+}
+
+// Delete 删除数据
+func (c *Collection) Delete(ctx context.Context, filter dal.Filter) error {
+	cond, err := toDocument(filter)
+	if err != nil {
+		return err
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.touch(c.collName)
+	t := c.table(c.collName, false)
+	if t == nil {
+		return nil
+	}
+
+	docs := make([]bson.M, 0, len(t.docs))
+	for _, doc := range t.docs {
+		matched, err := matchDocument(doc, cond)
+		if err != nil {
+			return err
+		}
+		if !matched {
+			docs = append(docs, doc)
+		}
+	}
+	t.docs = docs
+	return nil
+}
+
+// BulkWrite 批量执行插入, 更新, upsert 和删除
+func (c *Collection) BulkWrite(ctx context.Context, models []dal.BulkWriteModel, opt dal.BulkWriteOption) ([]dal.BulkWriteItemResult, error) {
+	var firstErr error
+	failures := make(map[int]string)
+	for index, model := range models {
+		var err error
+		switch model.Op {
+		case dal.BulkWriteInsert:
+			err = c.Insert(ctx, model.Doc)
+		case dal.BulkWriteUpdateOne:
+			err = c.update(model.Filter, bson.M{"$set": model.Doc}, false, false)
+		case dal.BulkWriteUpsert:
+			err = c.update(model.Filter, bson.M{"$set": model.Doc}, false, true)
+		case dal.BulkWriteDelete:
+			err = c.Delete(ctx, model.Filter)
+		default:
+			return nil, errors.New("unknown bulk write operation " + model.Op)
+		}
+		if err == nil {
+			continue
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+		failures[index] = err.Error()
+		if opt.Ordered {
+			break
+		}
+	}
+
+	results := mongodb.NewBulkWriteItemResults(len(models), opt.Ordered, failures)
+	items := make([]dal.BulkWriteItemResult, len(results))
+	for index, result := range results {
+		items[index] = dal.BulkWriteItemResult(result)
+	}
+	return items, firstErr
+}
+
+// CreateIndex 创建索引, 已存在同名索引时不做处理
+func (c *Collection) CreateIndex(ctx context.Context, index dal.Index) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.touch(c.collName)
+	t := c.table(c.collName, true)
+	for _, exist := range t.indexes {
+		if exist.Name == index.Name {
+			return nil
+		}
+	}
+
+	indexes := append(t.indexes[:len(t.indexes):len(t.indexes)], index)
+	if err := checkUnique(t.docs, indexes); err != nil {
+		return err
+	}
+	t.indexes = indexes
+	return nil
+}
+
+// DropIndex remove index by name
+func (c *Collection) DropIndex(ctx context.Context, indexName string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.touch(c.collName)
+	t := c.table(c.collName, false)
+	if t != nil {
+		for index, exist := range t.indexes {
+			if exist.Name == indexName {
+				t.indexes = append(t.indexes[:index:index], t.indexes[index+1:]...)
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("index not found with name [%s]", indexName)
+}
+
+// Indexes get all indexes for the collection
+func (c *Collection) Indexes(ctx context.Context) ([]dal.Index, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	indexes := make([]dal.Index, 0)
+	if t := c.table(c.collName, false); t != nil {
+		indexes = append(indexes, t.indexes...)
+	}
+	return indexes, nil
+}
+
+// AddColumn add a new column for the collection
+func (c *Collection) AddColumn(ctx context.Context, column string, value interface{}) error {
+	selector := types.Document{column: types.Document{"$exists": false}}
+	datac := types.Document{"$set": types.Document{column: value}}
+	return c.update(selector, datac, true, false)
+}
+
+// RenameColumn rename a column for the collection
+func (c *Collection) RenameColumn(ctx context.Context, oldName, newColumn string) error {
+	datac := types.Document{"$rename": types.Document{oldName: newColumn}}
+	return c.update(types.Document{}, datac, true, false)
+}
+
+// DropColumn remove a column by the name
+func (c *Collection) DropColumn(ctx context.Context, field string) error {
+	datac := types.Document{"$unset": types.Document{field: ""}}
+	return c.update(types.Document{}, datac, true, false)
+}
+
+// DropColumns remove the columns of the documents matching the filter
+func (c *Collection) DropColumns(ctx context.Context, filter dal.Filter, fields []string) error {
+	unsetFields := make(map[string]interface{})
+	for _, field := range fields {
+		unsetFields[field] = ""
+	}
+	datac := types.Document{"$unset": unsetFields}
+	return c.update(filter, datac, true, false)
+}
+
+// AggregateAll aggregate all operation
+func (c *Collection) AggregateAll(ctx context.Context, pipeline interface{}, result interface{}) error {
+	docs, err := c.aggregate(pipeline)
+	if err != nil {
+		return err
+	}
+	return decodeAll(docs, result)
+}
+
+// AggregateOne aggregate one operation
+func (c *Collection) AggregateOne(ctx context.Context, pipeline interface{}, result interface{}) error {
+	docs, err := c.aggregate(pipeline)
+	if err != nil {
+		return err
+	}
+	if len(docs) == 0 {
+		return dal.ErrDocumentNotFound
+	}
+	return decodeOne(docs[0], result)
+}
+
+// toDocument converts the filter, document or update to bson.M with the bson types, as mongodb receives it
+func toDocument(value interface{}) (bson.M, error) {
+	doc := bson.M{}
+	if value == nil {
+		return doc, nil
+	}
+	out, err := bson.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	if err := bson.Unmarshal(out, &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// decodeOne decodes the document into result
+func decodeOne(doc bson.M, result interface{}) error {
+	out, err := bson.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	return bson.Unmarshal(out, result)
+}
+
+// decodeAll decodes the documents into result, which should be a pointer to slice
+func decodeAll(docs []bson.M, result interface{}) error {
+	if docs == nil {
+		docs = make([]bson.M, 0)
+	}
+	out, err := bson.Marshal(bson.M{"docs": docs})
+	if err != nil {
+		return err
+	}
+	raw := struct {
+		Docs bson.Raw `bson:"docs"`
+	}{}
+	if err := bson.Unmarshal(out, &raw); err != nil {
+		return err
+	}
+	return raw.Docs.Unmarshal(result)
+}
+
+// copyDocument returns a deep copy of the document
+func copyDocument(doc bson.M) bson.M {
+	return copyValue(doc).(bson.M)
+}
+
+// copyDocuments returns deep copies of the documents
+func copyDocuments(docs []bson.M) []bson.M {
+	copied := make([]bson.M, len(docs))
+	for index, doc := range docs {
+		copied[index] = copyDocument(doc)
+	}
+	return copied
+}
+
+func copyValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case bson.M:
+		doc := make(bson.M, len(v))
+		for key, item := range v {
+			doc[key] = copyValue(item)
+		}
+		return doc
+	case []interface{}:
+		array := make([]interface{}, len(v))
+		for index, item := range v {
+			array[index] = copyValue(item)
+		}
+		return array
+	default:
+		return value
+	}
+}
@@ -0,0 +1,358 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package memory
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"configcenter/src/common/mapstr"
+	"configcenter/src/storage/dal"
+
+	"github.com/stretchr/testify/require"
+)
+
+type host struct {
+	HostID   int64    `bson:"bk_host_id"`
+	HostName string   `bson:"bk_host_name"`
+	BizID    int64    `bson:"bk_biz_id"`
+	Tags     []string `bson:"tags"`
+}
+
+func newHostTable(t *testing.T) dal.Table {
+	db := New()
+	table := db.Table("cc_HostBase")
+	err := table.Insert(context.Background(), []host{
+		{HostID: 1, HostName: "web-1", BizID: 2, Tags: []string{"web"}},
+		{HostID: 2, HostName: "web-2", BizID: 2, Tags: []string{"web", "gray"}},
+		{HostID: 3, HostName: "db-1", BizID: 3},
+	})
+	require.NoError(t, err)
+	return table
+}
+
+func findHostIDs(t *testing.T, table dal.Table, filter dal.Filter) []int64 {
+	hosts := make([]host, 0)
+	require.NoError(t, table.Find(filter).Sort("bk_host_id").All(context.Background(), &hosts))
+	ids := make([]int64, 0)
+	for _, h := range hosts {
+		ids = append(ids, h.HostID)
+	}
+	return ids
+}
+
+func TestFilter(t *testing.T) {
+	table := newHostTable(t)
+
+	cases := []struct {
+		filter dal.Filter
+		ids    []int64
+	}{
+		{nil, []int64{1, 2, 3}},
+		{mapstr.MapStr{"bk_biz_id": 2}, []int64{1, 2}},
+		{map[string]interface{}{"bk_host_id": map[string]interface{}{"$in": []int{1, 3}}}, []int64{1, 3}},
+		{map[string]interface{}{"bk_host_id": map[string]interface{}{"$nin": []int64{1, 3}}}, []int64{2}},
+		{map[string]interface{}{"bk_host_id": map[string]interface{}{"$gt": 1, "$lte": 3.0}}, []int64{2, 3}},
+		{map[string]interface{}{"bk_host_id": map[string]interface{}{"$ne": 2}}, []int64{1, 3}},
+		{map[string]interface{}{"bk_host_name": map[string]interface{}{"$regex": "^WEB", "$options": "i"}}, []int64{1, 2}},
+		{map[string]interface{}{"tags": "gray"}, []int64{2}},
+		{map[string]interface{}{"tags": map[string]interface{}{"$size": 0}}, []int64{3}},
+		{map[string]interface{}{"bk_cloud_id": nil}, []int64{1, 2, 3}},
+		{map[string]interface{}{"bk_cloud_id": map[string]interface{}{"$exists": true}}, []int64{}},
+		{map[string]interface{}{"$or": []map[string]interface{}{{"bk_host_id": 1}, {"bk_biz_id": 3}}}, []int64{1, 3}},
+		{map[string]interface{}{"$and": []map[string]interface{}{{"bk_biz_id": 2}, {"tags": map[string]interface{}{"$size": 2}}}}, []int64{2}},
+		{map[string]interface{}{"bk_host_name": map[string]interface{}{"$not": map[string]interface{}{"$regex": "web"}}}, []int64{3}},
+	}
+	for _, c := range cases {
+		require.Equal(t, c.ids, findHostIDs(t, table, c.filter), "filter: %v", c.filter)
+	}
+
+	_, err := table.Find(map[string]interface{}{"$where": "true"}).Count(context.Background())
+	require.Error(t, err)
+}
+
+func TestFind(t *testing.T) {
+	table := newHostTable(t)
+	ctx := context.Background()
+
+	hosts := make([]map[string]interface{}, 0)
+	err := table.Find(nil).Fields("bk_host_id").Sort("-bk_host_id").Start(1).Limit(1).All(ctx, &hosts)
+	require.NoError(t, err)
+	require.Equal(t, []map[string]interface{}{{"bk_host_id": int64(2)}}, hosts)
+
+	count, err := table.Find(map[string]interface{}{"bk_biz_id": 2}).Start(1).Limit(1).Count(ctx)
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), count)
+
+	h := host{}
+	require.NoError(t, table.Find(nil).Sort("bk_biz_id,-bk_host_id").One(ctx, &h))
+	require.Equal(t, int64(2), h.HostID)
+
+	err = table.Find(map[string]interface{}{"bk_host_id": 4}).One(ctx, &h)
+	require.Equal(t, dal.ErrDocumentNotFound, err)
+
+	iter := table.Find(nil).Sort("bk_host_id").Iter(ctx)
+	ids := make([]int64, 0)
+	for iter.Next(ctx) {
+		require.NoError(t, iter.Decode(&h))
+		ids = append(ids, h.HostID)
+	}
+	require.NoError(t, iter.Err())
+	require.NoError(t, iter.Close(ctx))
+	require.Equal(t, []int64{1, 2, 3}, ids)
+}
+
+func TestUpdate(t *testing.T) {
+	table := newHostTable(t)
+	ctx := context.Background()
+
+	require.NoError(t, table.Update(ctx, map[string]interface{}{"bk_biz_id": 2}, map[string]interface{}{"bk_biz_id": 4}))
+	require.Equal(t, []int64{1, 2}, findHostIDs(t, table, map[string]interface{}{"bk_biz_id": 4}))
+
+	err := table.UpdateMultiModel(ctx, map[string]interface{}{"bk_host_id": 1},
+		dal.ModeUpdate{Op: dal.UpdateOpAddToSet, Doc: map[string]interface{}{"tags": map[string]interface{}{"$each": []string{"web", "new"}}}})
+	require.NoError(t, err)
+	err = table.UpdateMultiModel(ctx, map[string]interface{}{"bk_host_id": 2},
+		dal.ModeUpdate{Op: dal.UpdateOpPull, Doc: map[string]interface{}{"tags": "gray"}})
+	require.NoError(t, err)
+	require.Equal(t, []int64{1}, findHostIDs(t, table, map[string]interface{}{"tags": map[string]interface{}{"$all": []string{"web", "new"}}}))
+	require.Equal(t, []int64{}, findHostIDs(t, table, map[string]interface{}{"tags": "gray"}))
+
+	require.NoError(t, table.Upsert(ctx, map[string]interface{}{"bk_host_id": 4}, map[string]interface{}{"bk_host_name": "db-2"}))
+	require.NoError(t, table.Upsert(ctx, map[string]interface{}{"bk_host_id": 4}, map[string]interface{}{"bk_biz_id": 3}))
+	h := host{}
+	require.NoError(t, table.Find(map[string]interface{}{"bk_host_id": 4}).One(ctx, &h))
+	require.Equal(t, host{HostID: 4, HostName: "db-2", BizID: 3}, h)
+
+	require.NoError(t, table.DropColumns(ctx, map[string]interface{}{"bk_biz_id": 3}, []string{"bk_host_name"}))
+	require.Equal(t, []int64{3, 4}, findHostIDs(t, table, map[string]interface{}{"bk_host_name": map[string]interface{}{"$exists": false}}))
+
+	require.NoError(t, table.Delete(ctx, map[string]interface{}{"bk_biz_id": 4}))
+	require.Equal(t, []int64{3, 4}, findHostIDs(t, table, nil))
+}
+
+func TestUniqueIndex(t *testing.T) {
+	db := New()
+	table := newHostTable(t)
+	ctx := context.Background()
+
+	err := table.CreateIndex(ctx, dal.Index{Name: "bk_biz_id", Keys: map[string]int32{"bk_biz_id": 1}, Unique: true})
+	require.True(t, db.IsDuplicatedError(err))
+
+	require.NoError(t, table.CreateIndex(ctx, dal.Index{Name: "bk_host_id", Keys: map[string]int32{"bk_host_id": 1}, Unique: true}))
+	err = table.Insert(ctx, host{HostID: 1})
+	require.True(t, db.IsDuplicatedError(err))
+	err = table.Update(ctx, map[string]interface{}{"bk_host_id": 2}, map[string]interface{}{"bk_host_id": 3})
+	require.True(t, db.IsDuplicatedError(err))
+	require.Equal(t, []int64{1, 2, 3}, findHostIDs(t, table, nil))
+
+	indexes, err := table.Indexes(ctx)
+	require.NoError(t, err)
+	require.Len(t, indexes, 1)
+	require.NoError(t, table.DropIndex(ctx, "bk_host_id"))
+	require.Error(t, table.DropIndex(ctx, "bk_host_id"))
+}
+
+func TestNextSequence(t *testing.T) {
+	db := New()
+	ctx := context.Background()
+
+	for expect := uint64(1); expect <= 3; expect++ {
+		id, err := db.NextSequence(ctx, "cc_HostBase")
+		require.NoError(t, err)
+		require.Equal(t, expect, id)
+	}
+	id, err := db.Clone().NextSequence(ctx, "cc_ApplicationBase")
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), id)
+}
+
+func TestAggregate(t *testing.T) {
+	table := newHostTable(t)
+	ctx := context.Background()
+
+	result := make([]struct {
+		BizID int64   `bson:"_id"`
+		Count int64   `bson:"count"`
+		Hosts []int64 `bson:"hosts"`
+	}, 0)
+	pipeline := []map[string]interface{}{
+		{"$match": map[string]interface{}{"bk_host_id": map[string]interface{}{"$gte": 1}}},
+		{"$group": map[string]interface{}{
+			"_id":   "$bk_biz_id",
+			"count": map[string]interface{}{"$sum": 1},
+			"hosts": map[string]interface{}{"$addToSet": "$bk_host_id"},
+		}},
+		{"$sort": map[string]interface{}{"count": -1}},
+	}
+	require.NoError(t, table.AggregateAll(ctx, pipeline, &result))
+	require.Len(t, result, 2)
+	require.Equal(t, int64(2), result[0].BizID)
+	require.Equal(t, int64(2), result[0].Count)
+	require.Equal(t, []int64{1, 2}, result[0].Hosts)
+	require.Equal(t, int64(3), result[1].BizID)
+	require.Equal(t, int64(1), result[1].Count)
+
+	tags := make([]map[string]interface{}, 0)
+	pipeline = []map[string]interface{}{
+		{"$unwind": "$tags"},
+		{"$group": map[string]interface{}{"_id": "$tags", "count": map[string]interface{}{"$sum": 1}}},
+		{"$sort": map[string]interface{}{"_id": 1}},
+		{"$skip": 1},
+		{"$limit": 1},
+	}
+	require.NoError(t, table.AggregateAll(ctx, pipeline, &tags))
+	require.Equal(t, []map[string]interface{}{{"_id": "web", "count": int64(2)}}, tags)
+
+	count := struct {
+		Count uint64 `bson:"unique_count"`
+	}{}
+	pipeline = []map[string]interface{}{
+		{"$match": map[string]interface{}{"bk_biz_id": 2}},
+		{"$count": "unique_count"},
+	}
+	require.NoError(t, table.AggregateOne(ctx, pipeline, &count))
+	require.Equal(t, uint64(2), count.Count)
+
+	pipeline[0] = map[string]interface{}{"$match": map[string]interface{}{"bk_biz_id": 5}}
+	require.Equal(t, dal.ErrDocumentNotFound, table.AggregateOne(ctx, pipeline, &count))
+}
+
+func TestLookup(t *testing.T) {
+	table := newHostTable(t)
+	ctx := context.Background()
+	relations := []map[string]interface{}{
+		{"bk_host_id": 1, "bk_module_id": 10},
+		{"bk_host_id": 1, "bk_module_id": 11},
+		{"bk_host_id": 2, "bk_module_id": 10},
+	}
+	require.NoError(t, table.(*Collection).Table("cc_ModuleHostConfig").Insert(ctx, relations))
+
+	result := make([]struct {
+		HostID  int64   `bson:"_id"`
+		Modules []int64 `bson:"modules"`
+	}, 0)
+	pipeline := []map[string]interface{}{
+		{"$lookup": map[string]interface{}{
+			"from":         "cc_ModuleHostConfig",
+			"localField":   "bk_host_id",
+			"foreignField": "bk_host_id",
+			"as":           "relations",
+		}},
+		{"$unwind": map[string]interface{}{"path": "$relations", "preserveNullAndEmptyArrays": true}},
+		{"$group": map[string]interface{}{
+			"_id":     "$bk_host_id",
+			"modules": map[string]interface{}{"$push": "$relations.bk_module_id"},
+		}},
+		{"$sort": map[string]interface{}{"_id": 1}},
+	}
+	require.NoError(t, table.AggregateAll(ctx, pipeline, &result))
+	require.Len(t, result, 3)
+	require.Equal(t, []int64{10, 11}, result[0].Modules)
+	require.Equal(t, []int64{10}, result[1].Modules)
+	require.Empty(t, result[2].Modules)
+}
+
+func TestTransaction(t *testing.T) {
+	ctx := context.Background()
+	db := New()
+	table := db.Table("cc_HostBase")
+	require.NoError(t, table.Insert(ctx, []host{{HostID: 1, HostName: "web-1", BizID: 2}, {HostID: 2, HostName: "web-2", BizID: 2}}))
+
+	// the writes in an aborted transaction are rolled back, including the created tables
+	txn, err := db.Start(ctx)
+	require.NoError(t, err)
+	txnTable := txn.(dal.DB).Table("cc_HostBase")
+	require.NoError(t, txnTable.Insert(ctx, host{HostID: 3, HostName: "db-1", BizID: 3}))
+	require.NoError(t, txnTable.Update(ctx, map[string]interface{}{"bk_host_id": 1}, map[string]interface{}{"bk_biz_id": 3}))
+	require.NoError(t, txnTable.Delete(ctx, map[string]interface{}{"bk_host_id": 2}))
+	require.NoError(t, txn.(dal.DB).Table("cc_ApplicationBase").Insert(ctx, map[string]interface{}{"bk_biz_id": 3}))
+	require.Equal(t, []int64{1, 3}, findHostIDs(t, table, map[string]interface{}{"bk_biz_id": 3}))
+	require.NoError(t, txn.Abort(ctx))
+	require.Equal(t, []int64{1, 2}, findHostIDs(t, table, map[string]interface{}{"bk_biz_id": 2}))
+	exists, err := db.HasTable("cc_ApplicationBase")
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	// the writes in a committed transaction are kept, and a later abort changes nothing
+	txn, err = db.Start(ctx)
+	require.NoError(t, err)
+	require.NoError(t, txn.(dal.DB).Table("cc_HostBase").Insert(ctx, host{HostID: 3, HostName: "db-1", BizID: 3}))
+	require.NoError(t, txn.Commit(ctx))
+	require.NoError(t, txn.Abort(ctx))
+	require.Equal(t, []int64{1, 2, 3}, findHostIDs(t, table, nil))
+
+	// AutoRun rolls back the writes made by f through the db when f failed
+	fErr := errors.New("f failed")
+	err = db.AutoRun(ctx, dal.TxnWrapperOption{}, func(header http.Header) error {
+		if err := table.Delete(ctx, map[string]interface{}{"bk_host_id": 3}); err != nil {
+			return err
+		}
+		return fErr
+	})
+	require.Equal(t, fErr, err)
+	require.Equal(t, []int64{1, 2, 3}, findHostIDs(t, table, nil))
+
+	err = db.AutoRun(ctx, dal.TxnWrapperOption{}, func(header http.Header) error {
+		return table.Delete(ctx, map[string]interface{}{"bk_host_id": 3})
+	})
+	require.NoError(t, err)
+	require.Equal(t, []int64{1, 2}, findHostIDs(t, table, nil))
+}
+
+func TestBulkWrite(t *testing.T) {
+	ctx := context.Background()
+	db := New()
+	models := []dal.BulkWriteModel{
+		{Op: dal.BulkWriteUpdateOne, Filter: map[string]interface{}{"bk_host_id": 1}, Doc: map[string]interface{}{"bk_biz_id": 3}},
+		{Op: dal.BulkWriteInsert, Doc: host{HostID: 2, HostName: "web-2"}},
+		{Op: dal.BulkWriteUpsert, Filter: map[string]interface{}{"bk_host_id": 4}, Doc: map[string]interface{}{"bk_host_name": "db-2"}},
+		{Op: dal.BulkWriteDelete, Filter: map[string]interface{}{"bk_host_id": 3}},
+	}
+
+	// the operations after the failed one are not executed in ordered mode
+	table := newHostTable(t)
+	require.NoError(t, table.CreateIndex(ctx, dal.Index{Name: "bk_host_id", Keys: map[string]int32{"bk_host_id": 1}, Unique: true}))
+	results, err := table.BulkWrite(ctx, models, dal.BulkWriteOption{Ordered: true})
+	require.True(t, db.IsDuplicatedError(err))
+	require.Len(t, results, 4)
+	require.Equal(t, dal.BulkWriteItemResult{Index: 0, Executed: true}, results[0])
+	require.Equal(t, 1, results[1].Index)
+	require.True(t, results[1].Executed)
+	require.NotEmpty(t, results[1].Error)
+	require.Equal(t, dal.BulkWriteItemResult{Index: 2, Executed: false}, results[2])
+	require.Equal(t, dal.BulkWriteItemResult{Index: 3, Executed: false}, results[3])
+	require.Equal(t, []int64{1, 3}, findHostIDs(t, table, map[string]interface{}{"bk_biz_id": 3}))
+	require.Equal(t, []int64{1, 2, 3}, findHostIDs(t, table, nil))
+
+	// all the operations are executed in unordered mode
+	table = newHostTable(t)
+	require.NoError(t, table.CreateIndex(ctx, dal.Index{Name: "bk_host_id", Keys: map[string]int32{"bk_host_id": 1}, Unique: true}))
+	results, err = table.BulkWrite(ctx, models, dal.BulkWriteOption{Ordered: false})
+	require.True(t, db.IsDuplicatedError(err))
+	require.Len(t, results, 4)
+	require.NotEmpty(t, results[1].Error)
+	for index, result := range results {
+		require.True(t, result.Executed)
+		if index != 1 {
+			require.Empty(t, result.Error)
+		}
+	}
+	require.Equal(t, []int64{1, 2, 4}, findHostIDs(t, table, nil))
+
+	results, err = table.BulkWrite(ctx, []dal.BulkWriteModel{{Op: "replace"}}, dal.BulkWriteOption{})
+	require.Error(t, err)
+	require.Nil(t, results)
+}
@@ -0,0 +1,280 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package memory
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+// applyUpdate applies the update operators to the document, inserting is true when the document is upserted
+func applyUpdate(doc bson.M, modifier bson.M, inserting bool) error {
+	if len(modifier) == 0 || !isOperatorDocument(modifier) {
+		return fmt.Errorf("the update document should contain update operators")
+	}
+
+	for op, arg := range modifier {
+		fields, ok := arg.(bson.M)
+		if !ok {
+			return fmt.Errorf("modifier %s allowed for objects only", op)
+		}
+		for path, value := range fields {
+			var err error
+			switch op {
+			case "$set":
+				err = setPath(doc, path, value)
+			case "$setOnInsert":
+				if inserting {
+					err = setPath(doc, path, value)
+				}
+			case "$unset":
+				unsetPath(doc, path)
+			case "$inc":
+				err = incPath(doc, path, value)
+			case "$push":
+				err = pushPath(doc, path, value, false)
+			case "$addToSet":
+				err = pushPath(doc, path, value, true)
+			case "$pull":
+				err = pullPath(doc, path, value)
+			case "$rename":
+				err = renamePath(doc, path, value)
+			default:
+				return fmt.Errorf("unsupported update operator %s", op)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// upsertDocument returns the document to be upserted with the equality conditions of the filter
+func upsertDocument(filter bson.M) bson.M {
+	doc := bson.M{}
+	for key, cond := range filter {
+		if key == "$and" {
+			conds, _ := cond.([]interface{})
+			for _, item := range conds {
+				if sub, ok := item.(bson.M); ok {
+					for field, value := range upsertDocument(sub) {
+						doc[field] = value
+					}
+				}
+			}
+			continue
+		}
+		if strings.HasPrefix(key, "$") {
+			continue
+		}
+		if ops, ok := cond.(bson.M); ok && isOperatorDocument(ops) {
+			value, ok := ops["$eq"]
+			if !ok {
+				continue
+			}
+			cond = value
+		}
+		setPath(doc, key, copyValue(cond))
+	}
+	return doc
+}
+
+// getPath returns the value of the field path, the array elements are accessed by the index
+func getPath(doc bson.M, path string) (interface{}, bool) {
+	var current interface{} = doc
+	for _, part := range strings.Split(path, ".") {
+		switch v := current.(type) {
+		case bson.M:
+			child, ok := v[part]
+			if !ok {
+				return nil, false
+			}
+			current = child
+		case []interface{}:
+			index, err := strconv.Atoi(part)
+			if err != nil || index < 0 || index >= len(v) {
+				return nil, false
+			}
+			current = v[index]
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// setPath sets the value of the field path, the missing embedded documents are created
+func setPath(doc bson.M, path string, value interface{}) error {
+	parts := strings.Split(path, ".")
+	var current interface{} = doc
+	for index, part := range parts {
+		last := index == len(parts)-1
+		switch v := current.(type) {
+		case bson.M:
+			if last {
+				v[part] = copyValue(value)
+				return nil
+			}
+			child, ok := v[part]
+			if !ok || child == nil {
+				child = bson.M{}
+				v[part] = child
+			}
+			current = child
+		case []interface{}:
+			position, err := strconv.Atoi(part)
+			if err != nil || position < 0 || position >= len(v) {
+				return fmt.Errorf("cannot create field '%s' in element %v", part, v)
+			}
+			if last {
+				v[position] = copyValue(value)
+				return nil
+			}
+			current = v[position]
+		default:
+			return fmt.Errorf("cannot create field '%s' in element %v", part, v)
+		}
+	}
+	return nil
+}
+
+// unsetPath removes the field path, the array element is set to null as mongodb does
+func unsetPath(doc bson.M, path string) {
+	parts := strings.Split(path, ".")
+	parent, ok := getPath(doc, strings.Join(parts[:len(parts)-1], "."))
+	if len(parts) == 1 {
+		parent, ok = doc, true
+	}
+	if !ok {
+		return
+	}
+
+	last := parts[len(parts)-1]
+	switch v := parent.(type) {
+	case bson.M:
+		delete(v, last)
+	case []interface{}:
+		if index, err := strconv.Atoi(last); err == nil && index >= 0 && index < len(v) {
+			v[index] = nil
+		}
+	}
+}
+
+func incPath(doc bson.M, path string, value interface{}) error {
+	if !isNumber(value) {
+		return fmt.Errorf("cannot increment with non-numeric argument: {%s: %v}", path, value)
+	}
+	current, ok := getPath(doc, path)
+	if !ok {
+		return setPath(doc, path, value)
+	}
+	sum, err := addNumbers(current, value)
+	if err != nil {
+		return fmt.Errorf("cannot apply $inc to field %s: %v", path, err)
+	}
+	return setPath(doc, path, sum)
+}
+
+// addNumbers returns the sum of the numbers, as an int64 if both are integers, otherwise a float64
+func addNumbers(a, b interface{}) (interface{}, error) {
+	if !isNumber(a) || !isNumber(b) {
+		return nil, fmt.Errorf("can not add non-numeric values %v and %v", a, b)
+	}
+	if isInteger(a) && isInteger(b) {
+		return toInt64(a) + toInt64(b), nil
+	}
+	return toFloat64(a) + toFloat64(b), nil
+}
+
+// pushPath appends the value or the values in {"$each": [...]} to the array field,
+// the values that already exist in the array are skipped if unique is true, as $addToSet does.
+func pushPath(doc bson.M, path string, value interface{}, unique bool) error {
+	items := []interface{}{value}
+	if each, ok := value.(bson.M); ok {
+		if list, ok := each["$each"]; ok {
+			if items, ok = list.([]interface{}); !ok {
+				return fmt.Errorf("the argument to $each in %s must be an array", path)
+			}
+		}
+	}
+
+	array := make([]interface{}, 0)
+	if current, ok := getPath(doc, path); ok {
+		if array, ok = current.([]interface{}); !ok {
+			return fmt.Errorf("the field '%s' must be an array", path)
+		}
+	}
+
+	for _, item := range items {
+		if unique && containsValue(array, item) {
+			continue
+		}
+		array = append(array, item)
+	}
+	return setPath(doc, path, array)
+}
+
+// pullPath removes the array elements equal to the value or matching the condition
+func pullPath(doc bson.M, path string, cond interface{}) error {
+	current, ok := getPath(doc, path)
+	if !ok {
+		return nil
+	}
+	array, ok := current.([]interface{})
+	if !ok {
+		return fmt.Errorf("cannot apply $pull to a non-array value")
+	}
+
+	remain := make([]interface{}, 0, len(array))
+	for _, item := range array {
+		var matched bool
+		if condDoc, ok := cond.(bson.M); ok {
+			var err error
+			if matched, err = matchElement(item, condDoc); err != nil {
+				return err
+			}
+		} else {
+			matched = compareValues(item, cond) == 0
+		}
+		if !matched {
+			remain = append(remain, item)
+		}
+	}
+	return setPath(doc, path, remain)
+}
+
+func renamePath(doc bson.M, path string, target interface{}) error {
+	newPath, ok := target.(string)
+	if !ok {
+		return fmt.Errorf("the 'to' field for $rename must be a string: %s: %v", path, target)
+	}
+	value, ok := getPath(doc, path)
+	if !ok {
+		return nil
+	}
+	unsetPath(doc, path)
+	return setPath(doc, newPath, value)
+}
+
+func containsValue(array []interface{}, value interface{}) bool {
+	for _, item := range array {
+		if compareValues(item, value) == 0 {
+			return true
+		}
+	}
+	return false
+}
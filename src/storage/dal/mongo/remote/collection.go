@@ -46,6 +46,28 @@ func (c *Collection) Find(filter dal.Filter) dal.Find {
 	return &find
 }
 
+// Explain 返回查询计划
+func (c *Collection) Explain(ctx context.Context, filter dal.Filter) (string, error) {
+	// build msg
+	msg := types.OPExplainOperation{}
+	msg.OPCode = types.OPExplainCode
+	msg.Collection = c.collection
+	if err := msg.Selector.Encode(filter); err != nil {
+		return "", err
+	}
+
+	// explain is not allowed in a transaction, so the txn of the context is not set
+	reply := types.OPReply{}
+	err := c.rpc.Option(nil).Call(types.CommandRDBOperation, &msg, &reply)
+	if err != nil {
+		return "", err
+	}
+	if !reply.Success {
+		return "", errors.New(reply.Message)
+	}
+	return reply.Message, nil
+}
+
 // Update 更新数据
 func (c *Collection) Upsert(ctx context.Context, filter dal.Filter, doc interface{}) error {
 	panic("unimplemented Upsert operation")
@@ -163,6 +185,54 @@ func (c *Collection) Delete(ctx context.Context, filter dal.Filter) error {
 	return nil
 }
 
+// BulkWrite 批量执行插入, 更新, upsert 和删除
+func (c *Collection) BulkWrite(ctx context.Context, models []dal.BulkWriteModel, opt dal.BulkWriteOption) ([]dal.BulkWriteItemResult, error) {
+	// build msg
+	msg := types.OPBulkWriteOperation{}
+	msg.OPCode = types.OPBulkWriteCode
+	msg.Collection = c.collection
+	msg.Ordered = opt.Ordered
+	msg.Models = make([]types.OPBulkWriteModel, len(models))
+	for index, model := range models {
+		msg.Models[index].Op = model.Op
+		if err := msg.Models[index].DOC.Encode(model.Doc); err != nil {
+			return nil, err
+		}
+		if err := msg.Models[index].Selector.Encode(model.Filter); err != nil {
+			return nil, err
+		}
+	}
+
+	// set txn
+	joinOpt, ok := ctx.Value(common.CCContextKeyJoinOption).(dal.JoinOption)
+	if ok {
+		msg.RequestID = joinOpt.RequestID
+		msg.TxnID = joinOpt.TxnID
+	}
+	if c.TxnID != "" {
+		msg.TxnID = c.TxnID
+	}
+
+	// call
+	reply := types.OPReply{}
+	err := c.rpc.Option(&joinOpt).Call(types.CommandRDBOperation, &msg, &reply)
+	if err != nil {
+		return nil, err
+	}
+	// the results are returned even if some of the operations failed
+	if !reply.Success && len(reply.Docs) == 0 {
+		return nil, errors.New(reply.Message)
+	}
+	items := make([]dal.BulkWriteItemResult, 0, len(reply.Docs))
+	if err := reply.Docs.Decode(&items); err != nil {
+		return nil, err
+	}
+	if !reply.Success {
+		return items, errors.New(reply.Message)
+	}
+	return items, nil
+}
+
 // Insert 插入数据, docs 可以为 单个数据 或者 多个数据
 func (c *Collection) Insert(ctx context.Context, docs interface{}) error {
 
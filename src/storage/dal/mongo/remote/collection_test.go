@@ -0,0 +1,106 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2019 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package remote
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	apiutil "configcenter/src/apimachinery/util"
+	"configcenter/src/storage/dal"
+	"configcenter/src/storage/dal/mongo/memory"
+	"configcenter/src/storage/rpc"
+	"configcenter/src/storage/types"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newBulkWriteServer returns a tmserver which executes the bulk writes on the memory db
+func newBulkWriteServer(t *testing.T, db dal.DB) *httptest.Server {
+	srv := rpc.NewServer()
+	srv.Handle(types.CommandRDBOperation, func(req rpc.Request) (interface{}, error) {
+		msg := types.OPBulkWriteOperation{}
+		if err := req.Decode(&msg); err != nil {
+			return nil, err
+		}
+		require.Equal(t, types.OPBulkWriteCode, msg.OPCode)
+
+		models := make([]dal.BulkWriteModel, len(msg.Models))
+		for index, model := range msg.Models {
+			models[index] = dal.BulkWriteModel{Op: model.Op, Filter: model.Selector, Doc: model.DOC}
+		}
+		results, err := db.Table(msg.Collection).BulkWrite(context.Background(), models, dal.BulkWriteOption{Ordered: msg.Ordered})
+
+		reply := &types.OPReply{}
+		reply.Docs = make(types.Documents, len(results))
+		for index, result := range results {
+			reply.Docs[index] = types.Document{}
+			if err := reply.Docs[index].Encode(result); err != nil {
+				return nil, err
+			}
+		}
+		reply.Success = err == nil
+		if err != nil {
+			reply.Message = err.Error()
+		}
+		return reply, nil
+	})
+
+	mux := http.NewServeMux()
+	mux.Handle("/txn/v3/rpc", srv)
+	return httptest.NewServer(mux)
+}
+
+func TestBulkWrite(t *testing.T) {
+	ctx := context.Background()
+	memDB := memory.New()
+	require.NoError(t, memDB.Table("cc_HostBase").CreateIndex(ctx, dal.Index{
+		Name:   "bk_host_id",
+		Keys:   map[string]int32{"bk_host_id": 1},
+		Unique: true,
+	}))
+	require.NoError(t, memDB.Table("cc_HostBase").Insert(ctx, map[string]interface{}{"bk_host_id": 1, "bk_biz_id": 2}))
+
+	ts := newBulkWriteServer(t, memDB)
+	defer ts.Close()
+	db, err := NewWithDiscover(newTestEngine(func() ([]string, error) { return []string{ts.URL}, nil }), apiutil.TLSClientConfig{})
+	require.NoError(t, err)
+	defer db.Close()
+
+	models := []dal.BulkWriteModel{
+		{Op: dal.BulkWriteUpdateOne, Filter: map[string]interface{}{"bk_host_id": 1}, Doc: map[string]interface{}{"bk_biz_id": 3}},
+		{Op: dal.BulkWriteInsert, Doc: map[string]interface{}{"bk_host_id": 1}},
+		{Op: dal.BulkWriteUpsert, Filter: map[string]interface{}{"bk_host_id": 2}, Doc: map[string]interface{}{"bk_biz_id": 3}},
+	}
+
+	// the results of the operations are returned with the error
+	results, err := db.Table("cc_HostBase").BulkWrite(ctx, models, dal.BulkWriteOption{Ordered: true})
+	require.Error(t, err)
+	require.Len(t, results, 3)
+	require.Equal(t, dal.BulkWriteItemResult{Index: 0, Executed: true}, results[0])
+	require.True(t, results[1].Executed)
+	require.Equal(t, err.Error(), results[1].Error)
+	require.Equal(t, dal.BulkWriteItemResult{Index: 2, Executed: false}, results[2])
+	count, err := memDB.Table("cc_HostBase").Find(map[string]interface{}{"bk_biz_id": 3}).Count(ctx)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, count)
+
+	results, err = db.Table("cc_HostBase").BulkWrite(ctx, models[2:], dal.BulkWriteOption{Ordered: true})
+	require.NoError(t, err)
+	require.Equal(t, []dal.BulkWriteItemResult{{Index: 0, Executed: true}}, results)
+	count, err = memDB.Table("cc_HostBase").Find(map[string]interface{}{"bk_biz_id": 3}).Count(ctx)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, count)
+}
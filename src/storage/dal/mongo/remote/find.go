@@ -27,7 +27,8 @@ import (
 // Find define a find operation
 type Find struct {
 	*Collection
-	msg *types.OPFindOperation
+	msg       *types.OPFindOperation
+	batchSize uint64
 }
 
 // Fields 查询字段
@@ -60,6 +61,12 @@ func (f *Find) Limit(limit uint64) dal.Find {
 	return f
 }
 
+// BatchSize 迭代器每批获取的数量
+func (f *Find) BatchSize(size uint64) dal.Find {
+	f.batchSize = size
+	return f
+}
+
 // All 查询多个
 func (f *Find) All(ctx context.Context, result interface{}) error {
 	start := time.Now()
@@ -0,0 +1,178 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.,
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the ",License",); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an ",AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package remote
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/storage/dal"
+	"configcenter/src/storage/types"
+)
+
+// Iter 逐条遍历查询结果, 结果按批从 tmserver 获取.
+// 未设置排序时按 _id 分批遍历, 不会产生 skip 扫描; 设置排序时按 Start 偏移分批获取.
+func (f *Find) Iter(ctx context.Context) dal.Iterator {
+	batchSize := f.batchSize
+	if batchSize == 0 {
+		batchSize = dal.DefaultBatchSize
+	}
+
+	msg := *f.msg
+	msg.OPCode = types.OPFindCode
+	msg.ObjectIDCursor = msg.Sort == ""
+	return &Iterator{
+		find:      f,
+		msg:       &msg,
+		batchSize: batchSize,
+		limited:   msg.Limit > 0,
+		remain:    msg.Limit,
+		index:     -1,
+		startTime: time.Now(),
+	}
+}
+
+// Iterator implement dal.Iterator interface with batched find operations
+type Iterator struct {
+	find      *Find
+	msg       *types.OPFindOperation
+	batchSize uint64
+	// remain is the number of documents left to fetch if the find operation has a limit
+	limited   bool
+	remain    uint64
+	docs      types.Documents
+	index     int
+	exhausted bool
+	count     uint64
+	err       error
+	startTime time.Time
+}
+
+// Next 移动到下一个文档, 当前批次遍历完成后获取下一批
+func (i *Iterator) Next(ctx context.Context) bool {
+	if i.err != nil {
+		return false
+	}
+	if i.index+1 < len(i.docs) {
+		i.index++
+		i.count++
+		return true
+	}
+	if i.exhausted {
+		return false
+	}
+
+	if i.err = i.fetch(ctx); i.err != nil || len(i.docs) == 0 {
+		return false
+	}
+	i.index = 0
+	i.count++
+	return true
+}
+
+func (i *Iterator) fetch(ctx context.Context) error {
+	limit := i.batchSize
+	if i.limited && i.remain < limit {
+		limit = i.remain
+	}
+	i.msg.Limit = limit
+
+	// set txn
+	opt, ok := ctx.Value(common.CCContextKeyJoinOption).(dal.JoinOption)
+	if ok {
+		i.msg.RequestID = opt.RequestID
+		i.msg.TxnID = opt.TxnID
+	}
+	if i.find.TxnID != "" {
+		i.msg.TxnID = i.find.TxnID
+	}
+
+	// call
+	reply := types.OPReply{}
+	err := i.find.rpc.Option(&opt).Call(types.CommandRDBOperation, i.msg, &reply)
+	if err != nil {
+		return err
+	}
+	if !reply.Success {
+		return errors.New(reply.Message)
+	}
+
+	i.docs = reply.Docs
+	if uint64(len(i.docs)) < limit {
+		i.exhausted = true
+	}
+	if i.limited {
+		i.remain -= uint64(len(i.docs))
+		if i.remain == 0 {
+			i.exhausted = true
+		}
+	}
+
+	// the start only skips the documents before the first batch when iterating by _id
+	if i.msg.ObjectIDCursor {
+		i.msg.Start = 0
+		if len(i.docs) > 0 {
+			if err := i.setAfterID(i.docs[len(i.docs)-1]["_id"]); err != nil {
+				return err
+			}
+		}
+		for _, doc := range i.docs {
+			delete(doc, "_id")
+		}
+	} else {
+		i.msg.Start += uint64(len(i.docs))
+	}
+	return nil
+}
+
+// setAfterID 设置下一批的起始 _id, ObjectID 由 tmserver 以 {ObjectIDCursorKey: hex} 的形式返回
+func (i *Iterator) setAfterID(id interface{}) error {
+	switch v := id.(type) {
+	case string:
+		i.msg.AfterObjectID, i.msg.AfterIDIsObjectID = v, false
+		return nil
+	case map[string]interface{}:
+		return i.setAfterID(types.Document(v))
+	case types.Document:
+		if hex, ok := v[types.ObjectIDCursorKey].(string); ok {
+			i.msg.AfterObjectID, i.msg.AfterIDIsObjectID = hex, true
+			return nil
+		}
+	}
+	return errors.New("iterate by _id is only supported for ObjectID and string _id")
+}
+
+// Decode 反序列化当前文档
+func (i *Iterator) Decode(result interface{}) error {
+	if i.index < 0 || i.index >= len(i.docs) {
+		return errors.New("no current document")
+	}
+	return i.docs[i.index].Decode(result)
+}
+
+// Err 遍历过程中的错误
+func (i *Iterator) Err() error {
+	return i.err
+}
+
+// Close 关闭迭代器
+func (i *Iterator) Close(ctx context.Context) error {
+	i.docs = nil
+	i.exhausted = true
+	rid := ctx.Value(common.ContextRequestIDField)
+	blog.V(5).InfoDepthf(1, "Find iterate %d documents cost %dms, rid: %v", i.count, time.Since(i.startTime)/time.Millisecond, rid)
+	return nil
+}
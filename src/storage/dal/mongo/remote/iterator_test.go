@@ -0,0 +1,158 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2019 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package remote
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	apiutil "configcenter/src/apimachinery/util"
+	"configcenter/src/storage/rpc"
+	"configcenter/src/storage/types"
+
+	"github.com/stretchr/testify/require"
+)
+
+type iterTestDoc struct {
+	id       string
+	objectID bool
+	value    int64
+}
+
+// newFindServer returns a tmserver which pages the documents like the tmserver find command,
+// the find operations it received are recorded in ops
+func newFindServer(t *testing.T, docs []iterTestDoc, ops *[]types.OPFindOperation) *httptest.Server {
+	sort.Slice(docs, func(i, j int) bool { return docs[i].id < docs[j].id })
+
+	lock := sync.Mutex{}
+	srv := rpc.NewServer()
+	srv.Handle(types.CommandRDBOperation, func(req rpc.Request) (interface{}, error) {
+		msg := types.OPFindOperation{}
+		if err := req.Decode(&msg); err != nil {
+			return nil, err
+		}
+		require.Equal(t, types.OPFindCode, msg.OPCode)
+		lock.Lock()
+		*ops = append(*ops, msg)
+		lock.Unlock()
+
+		matched := docs
+		if msg.ObjectIDCursor && msg.AfterObjectID != "" {
+			matched = nil
+			for _, doc := range docs {
+				if doc.id > msg.AfterObjectID && doc.objectID == msg.AfterIDIsObjectID {
+					matched = append(matched, doc)
+				}
+			}
+		}
+		if msg.Start >= uint64(len(matched)) {
+			matched = nil
+		} else {
+			matched = matched[msg.Start:]
+		}
+		if msg.Limit > 0 && uint64(len(matched)) > msg.Limit {
+			matched = matched[:msg.Limit]
+		}
+
+		reply := &types.OPReply{Success: true, Docs: types.Documents{}}
+		for _, doc := range matched {
+			var id interface{} = doc.id
+			if doc.objectID {
+				id = types.Document{types.ObjectIDCursorKey: doc.id}
+			}
+			if !msg.ObjectIDCursor {
+				reply.Docs = append(reply.Docs, types.Document{"value": doc.value})
+				continue
+			}
+			reply.Docs = append(reply.Docs, types.Document{"_id": id, "value": doc.value})
+		}
+		return reply, nil
+	})
+
+	mux := http.NewServeMux()
+	mux.Handle("/txn/v3/rpc", srv)
+	return httptest.NewServer(mux)
+}
+
+func TestIter(t *testing.T) {
+	objectIDs := make([]iterTestDoc, 5)
+	stringIDs := make([]iterTestDoc, 5)
+	for index := range objectIDs {
+		// the string ids look like ObjectIDs but must be compared as strings
+		hex := strings.Repeat("0", 23) + string(rune('1'+index))
+		objectIDs[index] = iterTestDoc{id: hex, objectID: true, value: int64(index + 1)}
+		stringIDs[index] = iterTestDoc{id: hex, value: int64(index + 1)}
+	}
+
+	tests := []struct {
+		name       string
+		docs       []iterTestDoc
+		sort       string
+		start      uint64
+		limit      uint64
+		wantValues []int64
+		wantOps    int
+	}{
+		{name: "ObjectID _id", docs: objectIDs, wantValues: []int64{1, 2, 3, 4, 5}, wantOps: 3},
+		{name: "string _id", docs: stringIDs, wantValues: []int64{1, 2, 3, 4, 5}, wantOps: 3},
+		{name: "limit", docs: objectIDs, limit: 3, wantValues: []int64{1, 2, 3}, wantOps: 2},
+		{name: "start", docs: objectIDs, start: 1, wantValues: []int64{2, 3, 4, 5}, wantOps: 3},
+		{name: "sort", docs: objectIDs, sort: "value", start: 1, limit: 3, wantValues: []int64{2, 3, 4}, wantOps: 2},
+		{name: "empty", docs: nil, wantValues: []int64{}, wantOps: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ops := make([]types.OPFindOperation, 0)
+			ts := newFindServer(t, tt.docs, &ops)
+			defer ts.Close()
+			db, err := NewWithDiscover(newTestEngine(func() ([]string, error) { return []string{ts.URL}, nil }), apiutil.TLSClientConfig{})
+			require.NoError(t, err)
+			defer db.Close()
+
+			ctx := context.Background()
+			find := db.Table("cc_test").Find(nil).BatchSize(2).Start(tt.start).Limit(tt.limit)
+			if tt.sort != "" {
+				find = find.Sort(tt.sort)
+			}
+			iter := find.Iter(ctx)
+			values := make([]int64, 0)
+			for iter.Next(ctx) {
+				doc := map[string]interface{}{}
+				require.NoError(t, iter.Decode(&doc))
+				require.NotContains(t, doc, "_id")
+				values = append(values, doc["value"].(int64))
+			}
+			require.NoError(t, iter.Err())
+			require.NoError(t, iter.Close(ctx))
+			require.Equal(t, tt.wantValues, values)
+			require.Len(t, ops, tt.wantOps)
+
+			for index, op := range ops {
+				require.Equal(t, tt.sort == "", op.ObjectIDCursor)
+				if !op.ObjectIDCursor || index == 0 {
+					continue
+				}
+				// the next batch starts after the last _id of the previous batch
+				last := tt.docs[int(tt.start)+index*2-1]
+				require.Zero(t, op.Start)
+				require.Equal(t, last.id, op.AfterObjectID)
+				require.Equal(t, last.objectID, op.AfterIDIsObjectID)
+			}
+		})
+	}
+}
@@ -20,10 +20,12 @@ import (
 	"strings"
 	"time"
 
+	apiutil "configcenter/src/apimachinery/util"
 	"configcenter/src/common"
 	"configcenter/src/common/backbone"
 	"configcenter/src/common/blog"
 	"configcenter/src/storage/dal"
+	"configcenter/src/storage/dal/instrument"
 	"configcenter/src/storage/rpc"
 	"configcenter/src/storage/types"
 
@@ -47,12 +49,17 @@ type Mongo struct {
 	reg prometheus.Registerer
 }
 
-// NewWithDiscover returns new DB
-func NewWithDiscover(engine *backbone.Engine) (db *Mongo, err error) {
+// NewWithDiscover returns new DB, the rpc connections to tmserver are upgraded to tls if rpcTLS is configured
+func NewWithDiscover(engine *backbone.Engine, rpcTLS apiutil.TLSClientConfig) (db *Mongo, err error) {
+	tlsConf, err := rpc.NewClientTLSConfig(rpcTLS)
+	if err != nil {
+		return nil, fmt.Errorf("load rpc tls config failed, err: %v", err)
+	}
+
 	var pool *rpc.Pool
 	for retry := 1; retry <= maxRetry; retry++ {
 		tmServer := engine.ServiceManageInterface.TMServer()
-		p, err := rpc.NewClientPool("tcp", tmServer.GetServers, "/txn/v3/rpc")
+		p, err := rpc.NewClientPool("tcp", tmServer.GetServers, "/txn/v3/rpc", tlsConf)
 		if err == nil {
 			pool = p
 			break
@@ -78,7 +85,7 @@ func NewWithDiscover(engine *backbone.Engine) (db *Mongo, err error) {
 	}
 	requestDuration = reg
 	return &Mongo{
-		rpc: NewPool(pool),
+		rpc: NewPool(pool, tlsConf),
 	}, nil
 }
 
@@ -156,7 +163,7 @@ func (c *Mongo) Table(collection string) dal.Table {
 	col.collection = collection
 	col.rpc = c.rpc
 
-	return &col
+	return instrument.NewTable(&col, collection)
 }
 
 // NextSequence 获取新序列号(非事务)
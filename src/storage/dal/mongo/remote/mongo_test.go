@@ -22,6 +22,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	apiutil "configcenter/src/apimachinery/util"
 	"configcenter/src/common"
 	"configcenter/src/common/util"
 	"configcenter/src/storage/dal"
@@ -29,7 +30,7 @@ import (
 
 func MBenchmarkRemoteCUD(b *testing.B) {
 
-	db, err := NewWithDiscover(getServerFunc)
+	db, err := NewWithDiscover(newTestEngine(getServerFunc), apiutil.TLSClientConfig{})
 	require.NoError(b, err)
 
 	header := http.Header{}
@@ -70,7 +71,7 @@ func MBenchmarkRemoteCUD(b *testing.B) {
 }
 
 func BenchmarkRemoteCUDParallel(b *testing.B) {
-	db, err := NewWithDiscover(func() ([]string, error) { return []string{"http://127.0.0.1:60008"}, nil })
+	db, err := NewWithDiscover(newTestEngine(func() ([]string, error) { return []string{"http://127.0.0.1:60008"}, nil }), apiutil.TLSClientConfig{})
 	require.NoError(b, err)
 	tablename := "tmptest"
 	header := http.Header{}
@@ -124,7 +125,7 @@ func BenchmarkRemoteCUDParallel(b *testing.B) {
 
 func TestDDL(t *testing.T) {
 	// 127.0.0.1:60008
-	db, err := NewWithDiscover(getServerFunc)
+	db, err := NewWithDiscover(newTestEngine(getServerFunc), apiutil.TLSClientConfig{})
 	require.NoError(t, err)
 
 	tableName := "tmp_test"
@@ -170,7 +171,7 @@ func TestDDL(t *testing.T) {
 
 func TestInsertTime(t *testing.T) {
 	// 127.0.0.1:60008
-	db, err := NewWithDiscover(getServerFunc)
+	db, err := NewWithDiscover(newTestEngine(getServerFunc), apiutil.TLSClientConfig{})
 	require.NoError(t, err)
 
 	tableName := "tmp_test_insert"
@@ -13,6 +13,7 @@
 package remote
 
 import (
+	"crypto/tls"
 	"strings"
 	"sync"
 	"time"
@@ -24,8 +25,9 @@ import (
 )
 
 type pool struct {
-	cache map[string]rpc.Client
-	conn  rpc.Client
+	cache   map[string]rpc.Client
+	conn    rpc.Client
+	tlsConf *tls.Config
 	sync.RWMutex
 }
 
@@ -34,10 +36,11 @@ type client struct {
 	opt *dal.JoinOption
 }
 
-func NewPool(client rpc.Client) *pool {
+func NewPool(client rpc.Client, tlsConf *tls.Config) *pool {
 	return &pool{
-		cache: make(map[string]rpc.Client, 0),
-		conn:  client,
+		cache:   make(map[string]rpc.Client, 0),
+		conn:    client,
+		tlsConf: tlsConf,
 	}
 }
 
@@ -127,5 +130,5 @@ func (c *client) addRPCByAddr(addr string) (rpc.Client, error) {
 	getSrvFunc := func() ([]string, error) {
 		return []string{addr}, nil
 	}
-	return rpc.NewClientPool("tcp", getSrvFunc, "/txn/v3/rpc")
+	return rpc.NewClientPool("tcp", getSrvFunc, "/txn/v3/rpc", c.p.tlsConf)
 }
@@ -53,6 +53,12 @@ func (c *Mongo) Start(ctx context.Context) (dal.Transcation, error) {
 
 // Commit 提交事务
 func (c *Mongo) Commit(ctx context.Context) error {
+	_, err := c.commit(ctx)
+	return err
+}
+
+// commit 提交事务, 并返回事务是否遇到了可重试的临时错误
+func (c *Mongo) commit(ctx context.Context) (bool, error) {
 	msg := types.OPCommitOperation{}
 	msg.OPCode = types.OPCommitCode
 	msg.RequestID = c.RequestID
@@ -67,16 +73,23 @@ func (c *Mongo) Commit(ctx context.Context) error {
 	err := c.rpc.Option(&opt).Call(types.CommandRDBOperation, &msg, &reply)
 	c.TxnID = "" // clear TxnID
 	if err != nil {
-		return err
+		return false, err
 	}
+	transient := reply.Code == types.ReplyCodeTransientTxnError
 	if !reply.Success {
-		return errors.New(reply.Message)
+		return transient, errors.New(reply.Message)
 	}
-	return nil
+	return transient, nil
 }
 
 // Abort 取消事务
 func (c *Mongo) Abort(ctx context.Context) error {
+	_, err := c.abort(ctx)
+	return err
+}
+
+// abort 取消事务, 并返回事务是否遇到了可重试的临时错误
+func (c *Mongo) abort(ctx context.Context) (bool, error) {
 	msg := types.OPAbortOperation{}
 	msg.OPCode = types.OPAbortCode
 	msg.RequestID = c.RequestID
@@ -91,12 +104,13 @@ func (c *Mongo) Abort(ctx context.Context) error {
 	err := c.rpc.Option(&opt).Call(types.CommandRDBOperation, &msg, &reply)
 	c.TxnID = "" // clear TxnID
 	if err != nil {
-		return err
+		return false, err
 	}
+	transient := reply.Code == types.ReplyCodeTransientTxnError
 	if !reply.Success {
-		return errors.New(reply.Message)
+		return transient, errors.New(reply.Message)
 	}
-	return nil
+	return transient, nil
 }
 
 // TxnInfo 当前事务信息，用于事务发起者往下传递
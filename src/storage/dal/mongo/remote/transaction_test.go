@@ -21,9 +21,13 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"configcenter/src/apimachinery/discovery"
+	apiutil "configcenter/src/apimachinery/util"
 	"configcenter/src/common"
+	"configcenter/src/common/backbone"
 	"configcenter/src/common/util"
 	"configcenter/src/storage/dal"
+	"configcenter/src/storage/types"
 )
 
 func getTMServer() []string {
@@ -39,13 +43,37 @@ func getServerFunc() ([]string, error) {
 	return getTMServer(), nil
 }
 
+// tmServerDiscovery discovers the tmservers by the function
+type tmServerDiscovery struct {
+	getServer types.GetServerFunc
+}
+
+func (d tmServerDiscovery) IsMaster() bool {
+	return true
+}
+
+func (d tmServerDiscovery) TMServer() discovery.Interface {
+	return d
+}
+
+func (d tmServerDiscovery) GetServers() ([]string, error) {
+	return d.getServer()
+}
+
+// newTestEngine returns the engine which discovers the tmservers by the function
+func newTestEngine(getServer types.GetServerFunc) *backbone.Engine {
+	engine, _ := backbone.NewMockBackbone(&backbone.Config{})
+	engine.ServiceManageInterface = tmServerDiscovery{getServer: getServer}
+	return engine
+}
+
 func TestTransactionQuery(t *testing.T) {
-	NewWithDiscover(getServerFunc)
+	NewWithDiscover(newTestEngine(getServerFunc), apiutil.TLSClientConfig{})
 }
 
 func TestTransaction(t *testing.T) {
 
-	db, err := NewWithDiscover(getServerFunc)
+	db, err := NewWithDiscover(newTestEngine(getServerFunc), apiutil.TLSClientConfig{})
 	require.NoError(t, err)
 
 	header := http.Header{}
@@ -142,7 +170,7 @@ func TestTransaction(t *testing.T) {
 }
 
 func TestInsertCommit(t *testing.T) {
-	db, err := NewWithDiscover(getServerFunc)
+	db, err := NewWithDiscover(newTestEngine(getServerFunc), apiutil.TLSClientConfig{})
 	require.NoError(t, err)
 
 	header := http.Header{}
@@ -173,7 +201,7 @@ func TestInsertCommit(t *testing.T) {
 }
 
 func TestInsertAbort(t *testing.T) {
-	db, err := NewWithDiscover(getServerFunc)
+	db, err := NewWithDiscover(newTestEngine(getServerFunc), apiutil.TLSClientConfig{})
 	require.NoError(t, err)
 
 	header := http.Header{}
@@ -215,7 +243,7 @@ func TestInsertAbort(t *testing.T) {
 	newRow = make(map[string]string, 0)
 	err = db.Table(tablename).Find(row).One(context.Background(), &newRow)
 	if !db.IsNotFoundError(err) {
-		t.Errorf(err.Error())
+		t.Error(err)
 		return
 	}
 
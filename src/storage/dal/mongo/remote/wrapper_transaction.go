@@ -15,41 +15,86 @@ package remote
 import (
 	"context"
 	"net/http"
+	"time"
 
 	"configcenter/src/common"
 	"configcenter/src/common/blog"
 	"configcenter/src/common/util"
 	"configcenter/src/storage/dal"
+	"configcenter/src/storage/dal/instrument"
+	"configcenter/src/storage/mongodb"
+)
+
+const (
+	// txnMaxRetry the max retry times of a transaction failed on transient transaction errors
+	txnMaxRetry = 3
+	// txnRetryBackoff the backoff before the first retry, it's doubled on each retry
+	txnRetryBackoff = 50 * time.Millisecond
 )
 
 // AutoRun Interface for automatic processing of encapsulated transactions
 // f func return error, abort commit, other commit transcation. transcation commit can be error.
 // f func parameter http.header, the handler must be accepted and processed. Subsequent passthrough to call subfunctions and APIs
+// the whole f is rerun in a new transaction when the transaction failed on transient errors such as write conflicts,
+// unless opt.DisableRetry is set. the error of f is returned after the transaction is aborted.
 func (c *Mongo) AutoRun(ctx context.Context, opt dal.TxnWrapperOption, f func(header http.Header) error) error {
 
 	rid := util.GetHTTPCCRequestID(opt.Header)
-	txn, err := c.Start(ctx)
+	backoff := txnRetryBackoff
+	for retry := 0; ; retry++ {
+		stage, err := c.autoRun(ctx, opt, f)
+		if stage == "" || opt.DisableRetry || retry >= txnMaxRetry {
+			return err
+		}
+
+		instrument.ObserveTxnRetry(stage)
+		blog.Warnf("wrapper stranscation failed on transient error in %s, retry it after %v. err:%v, retry:%d, rid:%s",
+			stage, backoff, err, retry+1, rid)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// autoRun runs f in a transaction once, returns the stage in which the transaction failed on a transient error,
+// empty if it succeeded or the error is not retryable.
+func (c *Mongo) autoRun(ctx context.Context, opt dal.TxnWrapperOption, f func(header http.Header) error) (string, error) {
+
+	rid := util.GetHTTPCCRequestID(opt.Header)
+	txnDal, err := c.Start(ctx)
 	if err != nil {
-		blog.ErrorfDepth(1, "wrapper stranscation start error. err:%s, rid:%s", err.Error(), rid)
-		return opt.CCErr.Errorf(common.CCErrCommStartTransactionFailed, err.Error())
+		blog.ErrorfDepth(2, "wrapper stranscation start error. err:%s, rid:%s", err.Error(), rid)
+		return "", opt.CCErr.Errorf(common.CCErrCommStartTransactionFailed, err.Error())
 	}
+	txn := txnDal.(*Mongo)
 	header := txn.TxnInfo().IntoHeader(opt.Header)
 	newCtx := util.GetDBContext(context.Background(), header)
 	err = f(header)
 	if err != nil {
 		// Abort error. mongodb session can rollback
-		if txnErr := txn.Abort(newCtx); txnErr != nil {
-			blog.ErrorfDepth(1, "wrapper stranscation start error. err:%s, txnErr:%s, rid:%s", err.Error(), txnErr.Error(), rid)
-			return txnErr
+		transient, txnErr := txn.abort(newCtx)
+		if txnErr != nil {
+			blog.ErrorfDepth(2, "wrapper stranscation abort error. err:%s, txnErr:%s, rid:%s", err.Error(), txnErr.Error(), rid)
+			return "", txnErr
+		}
+		if transient || mongodb.IsTransientTxnErrorMessage(err) {
+			return "operation", err
 		}
-		return nil
+		return "", err
 	}
 
-	err = txn.Commit(newCtx)
+	transient, err := txn.commit(newCtx)
 	if err != nil {
-		blog.ErrorfDepth(1, "wrapper stranscation commit error. err:%s, rid:%s", err.Error(), rid)
-		return opt.CCErr.Errorf(common.CCErrCommCommitTransactionFailed, err.Error())
+		blog.ErrorfDepth(2, "wrapper stranscation commit error. err:%s, rid:%s", err.Error(), rid)
+		ccErr := opt.CCErr.Errorf(common.CCErrCommCommitTransactionFailed, err.Error())
+		if transient || mongodb.IsTransientTxnErrorMessage(err) {
+			return "commit", ccErr
+		}
+		return "", ccErr
 	}
-	return nil
+	return "", nil
 
 }
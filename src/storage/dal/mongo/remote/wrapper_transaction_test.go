@@ -0,0 +1,146 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2019 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package remote
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	apiutil "configcenter/src/apimachinery/util"
+	ccErr "configcenter/src/common/errors"
+	"configcenter/src/storage/dal"
+	"configcenter/src/storage/rpc"
+	"configcenter/src/storage/types"
+
+	"github.com/stretchr/testify/require"
+)
+
+// txnServer is a tmserver which fails the commits on the transient transaction errors for the given times
+type txnServer struct {
+	sync.Mutex
+	starts          int
+	commits         int
+	aborts          int
+	transientCommit int
+}
+
+func (s *txnServer) handle(req rpc.Request) (interface{}, error) {
+	msg := types.OPCommitOperation{}
+	if err := req.Decode(&msg); err != nil {
+		return nil, err
+	}
+
+	s.Lock()
+	defer s.Unlock()
+	reply := &types.OPReply{}
+	reply.Success = true
+	switch msg.OPCode {
+	case types.OPStartTransactionCode:
+		s.starts++
+		reply.TxnID = fmt.Sprintf("txn-%d", s.starts)
+	case types.OPCommitCode:
+		s.commits++
+		if s.transientCommit > 0 {
+			s.transientCommit--
+			reply.Success = false
+			reply.Code = types.ReplyCodeTransientTxnError
+			reply.Message = "(WriteConflict) WriteConflict"
+		}
+	case types.OPAbortCode:
+		s.aborts++
+	default:
+		return nil, fmt.Errorf("unexpected operation %s", msg.OPCode)
+	}
+	return reply, nil
+}
+
+func TestAutoRunRetry(t *testing.T) {
+	server := &txnServer{}
+	srv := rpc.NewServer()
+	srv.Handle(types.CommandRDBOperation, server.handle)
+	mux := http.NewServeMux()
+	mux.Handle("/txn/v3/rpc", srv)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	db, err := NewWithDiscover(newTestEngine(func() ([]string, error) { return []string{ts.URL}, nil }), apiutil.TLSClientConfig{})
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	opt := dal.TxnWrapperOption{
+		Header: http.Header{},
+		CCErr:  ccErr.NewFromCtx(ccErr.EmptyErrorsSetting).CreateDefaultCCErrorIf("zh-cn"),
+	}
+	reset := func(transientCommit int) {
+		server.Lock()
+		defer server.Unlock()
+		server.starts, server.commits, server.aborts, server.transientCommit = 0, 0, 0, transientCommit
+	}
+
+	// the whole transaction is rerun when the commit failed on a transient error
+	reset(2)
+	runs := 0
+	err = db.AutoRun(ctx, opt, func(header http.Header) error {
+		runs++
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, runs)
+	require.Equal(t, 3, server.starts)
+	require.Equal(t, 3, server.commits)
+
+	// the transient errors of f that have passed through the http are retried up to txnMaxRetry times,
+	// and the error of f is returned after the last abort
+	reset(0)
+	runs = 0
+	conflict := errors.New("update host failed, err: (WriteConflict) WriteConflict")
+	err = db.AutoRun(ctx, opt, func(header http.Header) error {
+		runs++
+		return conflict
+	})
+	require.Equal(t, conflict, err)
+	require.Equal(t, txnMaxRetry+1, runs)
+	require.Equal(t, txnMaxRetry+1, server.aborts)
+	require.Equal(t, 0, server.commits)
+
+	// the other errors are not retried
+	reset(0)
+	runs = 0
+	failed := errors.New("host not found")
+	err = db.AutoRun(ctx, opt, func(header http.Header) error {
+		runs++
+		return failed
+	})
+	require.Equal(t, failed, err)
+	require.Equal(t, 1, runs)
+	require.Equal(t, 1, server.aborts)
+
+	// no retry if it's disabled
+	reset(1)
+	runs = 0
+	disabled := opt
+	disabled.DisableRetry = true
+	err = db.AutoRun(ctx, disabled, func(header http.Header) error {
+		runs++
+		return nil
+	})
+	require.Error(t, err)
+	require.Equal(t, 1, runs)
+	require.Equal(t, 1, server.commits)
+}
@@ -13,12 +13,17 @@
 package redis
 
 import (
+	"errors"
 	"strconv"
 	"strings"
+	"sync"
 
 	redis "gopkg.in/redis.v5"
 )
 
+// ModeCluster is the redis cluster mode, the address is the seed nodes of the cluster separated by comma
+const ModeCluster = "cluster"
+
 // Config define redis config
 type Config struct {
 	Address    string
@@ -26,6 +31,8 @@ type Config struct {
 	Password   string
 	Database   string
 	MasterName string
+	// Mode is cluster for redis cluster, otherwise it's a single node, or sentinel failover if MasterName is set
+	Mode string
 }
 
 // ParseConfigFromKV returns new config
@@ -36,11 +43,28 @@ func ParseConfigFromKV(prefix string, conifgmap map[string]string) Config {
 		Password:   conifgmap[prefix+".pwd"],
 		Database:   conifgmap[prefix+".database"],
 		MasterName: conifgmap[prefix+".mastername"],
+		Mode:       conifgmap[prefix+".mode"],
 	}
 }
 
+// Client is the redis client of a single node, sentinel failover or redis cluster.
+// the keys of a multi-key command must be in the same slot in cluster mode, use hash tags to do so.
+type Client interface {
+	redis.Cmdable
+	TxPipeline() *redis.Pipeline
+	TxPipelined(fn func(*redis.Pipeline) error) ([]redis.Cmder, error)
+	Watch(fn func(*redis.Tx) error, keys ...string) error
+	Publish(channel, message string) *redis.IntCmd
+	Subscribe(channels ...string) (*redis.PubSub, error)
+	PSubscribe(channels ...string) (*redis.PubSub, error)
+	Close() error
+}
+
+var _ Client = (*redis.Client)(nil)
+var _ Client = (*clusterClient)(nil)
+
 // NewFromConfig returns new redis client from config
-func NewFromConfig(cfg Config) (*redis.Client, error) {
+func NewFromConfig(cfg Config) (Client, error) {
 	dbNum, err := strconv.Atoi(cfg.Database)
 	if nil != err {
 		return nil, err
@@ -50,8 +74,13 @@ func NewFromConfig(cfg Config) (*redis.Client, error) {
 		cfg.Address = cfg.Address + ":" + cfg.Port
 	}
 
-	var client *redis.Client
-	if cfg.MasterName == "" {
+	var client Client
+	if cfg.Mode == ModeCluster {
+		if dbNum != 0 {
+			return nil, errors.New("redis cluster only supports database 0")
+		}
+		client = newClusterClient(strings.Split(cfg.Address, ","), cfg.Password)
+	} else if cfg.MasterName == "" {
 		option := &redis.Options{
 			Addr:     cfg.Address,
 			Password: cfg.Password,
@@ -73,12 +102,118 @@ func NewFromConfig(cfg Config) (*redis.Client, error) {
 
 	err = client.Ping().Err()
 	if err != nil {
+		client.Close()
 		return nil, err
 	}
 
 	return client, err
 }
 
+// clusterClient is the redis cluster client, the messages are published to all the nodes of the cluster,
+// so the channels are subscribed through one of the nodes.
+type clusterClient struct {
+	*redis.ClusterClient
+	subClient *redis.Client
+}
+
+func newClusterClient(addrs []string, password string) *clusterClient {
+	return &clusterClient{
+		ClusterClient: redis.NewClusterClient(&redis.ClusterOptions{
+			Addrs:    addrs,
+			Password: password,
+			PoolSize: 100,
+		}),
+		subClient: redis.NewClient(&redis.Options{
+			Addr:     addrs[0],
+			Password: password,
+			PoolSize: 10,
+		}),
+	}
+}
+
+// Subscribe subscribes the client to the specified channels
+func (c *clusterClient) Subscribe(channels ...string) (*redis.PubSub, error) {
+	return c.subClient.Subscribe(channels...)
+}
+
+// PSubscribe subscribes the client to the given patterns
+func (c *clusterClient) PSubscribe(channels ...string) (*redis.PubSub, error) {
+	return c.subClient.PSubscribe(channels...)
+}
+
+// Close closes the cluster client and the subscribe client
+func (c *clusterClient) Close() error {
+	subErr := c.subClient.Close()
+	if err := c.ClusterClient.Close(); err != nil {
+		return err
+	}
+	return subErr
+}
+
+// Keys returns the keys matching the pattern, the keys in all the master nodes are returned in cluster mode
+func Keys(client Client, pattern string) ([]string, error) {
+	cluster, ok := client.(*clusterClient)
+	if !ok {
+		return client.Keys(pattern).Result()
+	}
+
+	var lock sync.Mutex
+	keys := make([]string, 0)
+	err := cluster.ForEachMaster(func(node *redis.Client) error {
+		nodeKeys, err := node.Keys(pattern).Result()
+		if err != nil {
+			return err
+		}
+		lock.Lock()
+		keys = append(keys, nodeKeys...)
+		lock.Unlock()
+		return nil
+	})
+	return keys, err
+}
+
+// MGet returns the values of the keys, the value of a key that does not exist is nil.
+// the keys may be in different slots in cluster mode, so they are got one by one in a pipeline.
+func MGet(client Client, keys ...string) ([]interface{}, error) {
+	if _, ok := client.(*clusterClient); !ok {
+		return client.MGet(keys...).Result()
+	}
+
+	cmds := make([]*redis.StringCmd, len(keys))
+	_, err := client.Pipelined(func(pipe *redis.Pipeline) error {
+		for index, key := range keys {
+			cmds[index] = pipe.Get(key)
+		}
+		return nil
+	})
+	if err != nil && !IsNilErr(err) {
+		return nil, err
+	}
+
+	values := make([]interface{}, len(keys))
+	for index, cmd := range cmds {
+		if cmd.Err() == nil {
+			values[index] = cmd.Val()
+		}
+	}
+	return values, nil
+}
+
+// Del deletes the keys, the keys may be in different slots in cluster mode, so they are deleted one by one in a pipeline.
+func Del(client Client, keys ...string) error {
+	if _, ok := client.(*clusterClient); !ok {
+		return client.Del(keys...).Err()
+	}
+
+	_, err := client.Pipelined(func(pipe *redis.Pipeline) error {
+		for _, key := range keys {
+			pipe.Del(key)
+		}
+		return nil
+	})
+	return err
+}
+
 // IsNilErr returns whether err is nil error
 func IsNilErr(err error) bool {
 	return redis.Nil == err
@@ -0,0 +1,240 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package redis
+
+import (
+	"net"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/alicebob/miniredis/server"
+	"github.com/stretchr/testify/require"
+	redis "gopkg.in/redis.v5"
+)
+
+// keyCmds are the commands of the fake node whose first argument is a key
+var keyCmds = []string{"get", "set", "mget", "del"}
+
+// fakeNode is a redis node that serves the string commands used by the tests in memory
+type fakeNode struct {
+	*server.Server
+	lock sync.Mutex
+	data map[string]string
+}
+
+func newFakeNode(t *testing.T) *fakeNode {
+	srv, err := server.NewServer("127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(srv.Close)
+
+	node := &fakeNode{Server: srv, data: make(map[string]string)}
+	cmds := map[string]server.Cmd{
+		"PING": func(c *server.Peer, cmd string, args []string) {
+			c.WriteInline("PONG")
+		},
+		// the cluster client routes the commands by the first key position in the command infos
+		"COMMAND": func(c *server.Peer, cmd string, args []string) {
+			c.WriteLen(len(keyCmds))
+			for _, name := range keyCmds {
+				c.WriteLen(6)
+				c.WriteBulk(name)
+				c.WriteInt(-2)
+				c.WriteLen(0)
+				c.WriteInt(1)
+				c.WriteInt(1)
+				c.WriteInt(1)
+			}
+		},
+		"SET": func(c *server.Peer, cmd string, args []string) {
+			node.lock.Lock()
+			defer node.lock.Unlock()
+			node.data[args[0]] = args[1]
+			c.WriteOK()
+		},
+		"GET": func(c *server.Peer, cmd string, args []string) {
+			node.writeValues(c, args[:1])
+		},
+		"MGET": func(c *server.Peer, cmd string, args []string) {
+			c.WriteLen(len(args))
+			node.writeValues(c, args)
+		},
+		"DEL": func(c *server.Peer, cmd string, args []string) {
+			node.lock.Lock()
+			defer node.lock.Unlock()
+			deleted := 0
+			for _, key := range args {
+				if _, ok := node.data[key]; ok {
+					delete(node.data, key)
+					deleted++
+				}
+			}
+			c.WriteInt(deleted)
+		},
+		"KEYS": func(c *server.Peer, cmd string, args []string) {
+			keys := node.keys(args[0])
+			c.WriteLen(len(keys))
+			for _, key := range keys {
+				c.WriteBulk(key)
+			}
+		},
+	}
+	for name, cmd := range cmds {
+		require.NoError(t, srv.Register(name, cmd))
+	}
+	return node
+}
+
+func (n *fakeNode) writeValues(c *server.Peer, keys []string) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	for _, key := range keys {
+		if value, ok := n.data[key]; ok {
+			c.WriteBulk(value)
+		} else {
+			c.WriteNull()
+		}
+	}
+}
+
+func (n *fakeNode) keys(pattern string) []string {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	keys := make([]string, 0)
+	for key := range n.data {
+		if matched, _ := path.Match(pattern, key); matched {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// newTestCluster returns a cluster client of two fake masters, which serve the lower and upper half slots.
+// the seed node only answers the cluster info and slots, so the keys are read and written on the masters directly.
+func newTestCluster(t *testing.T) (*clusterClient, []*fakeNode) {
+	masters := []*fakeNode{newFakeNode(t), newFakeNode(t)}
+	seed := newFakeNode(t)
+	require.NoError(t, seed.Register("CLUSTER", func(c *server.Peer, cmd string, args []string) {
+		if strings.ToUpper(args[0]) == "INFO" {
+			c.WriteBulk("cluster_state:ok\r\n")
+			return
+		}
+		c.WriteLen(len(masters))
+		for index, master := range masters {
+			host, port, _ := net.SplitHostPort(master.Addr().String())
+			portNum, _ := strconv.Atoi(port)
+			c.WriteLen(3)
+			c.WriteInt(index * 8192)
+			c.WriteInt(index*8192 + 8191)
+			c.WriteLen(2)
+			c.WriteBulk(host)
+			c.WriteInt(portNum)
+		}
+	}))
+
+	client := newClusterClient([]string{seed.Addr().String()}, "")
+	t.Cleanup(func() { client.Close() })
+	return client, masters
+}
+
+func newTestClient(t *testing.T) Client {
+	node := newFakeNode(t)
+	host, port, _ := net.SplitHostPort(node.Addr().String())
+	client, err := NewFromConfig(Config{Address: host, Port: port, Database: "0"})
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestNewFromConfig(t *testing.T) {
+	_, err := NewFromConfig(Config{Address: "127.0.0.1:6379", Database: "1", Mode: ModeCluster})
+	require.Error(t, err)
+	_, err = NewFromConfig(Config{Address: "127.0.0.1:6379", Database: "db"})
+	require.Error(t, err)
+
+	client := newTestClient(t)
+	_, ok := client.(*redis.Client)
+	require.True(t, ok)
+}
+
+func TestClusterClient(t *testing.T) {
+	client, masters := newTestCluster(t)
+
+	// the key {a}:1 is in the slot 15495 of the upper half, {b}:1 and {c}:1 are in the slots 3300 and 7365
+	require.NoError(t, client.Set("{a}:1", "a1", 0).Err())
+	require.NoError(t, client.Set("{b}:1", "b1", 0).Err())
+	require.NoError(t, client.Set("{c}:1", "c1", 0).Err())
+	require.Equal(t, []string{"{b}:1", "{c}:1"}, masters[0].keys("*"))
+	require.Equal(t, []string{"{a}:1"}, masters[1].keys("*"))
+
+	val, err := client.Get("{c}:1").Result()
+	require.NoError(t, err)
+	require.Equal(t, "c1", val)
+}
+
+func TestKeys(t *testing.T) {
+	cluster, _ := newTestCluster(t)
+	for _, client := range []Client{newTestClient(t), cluster} {
+		for _, key := range []string{"{a}:1", "{b}:1", "{c}:1", "d"} {
+			require.NoError(t, client.Set(key, key, 0).Err())
+		}
+
+		// the keys in all the masters are returned
+		keys, err := Keys(client, "{*}:1")
+		require.NoError(t, err)
+		sort.Strings(keys)
+		require.Equal(t, []string{"{a}:1", "{b}:1", "{c}:1"}, keys)
+
+		keys, err = Keys(client, "none*")
+		require.NoError(t, err)
+		require.Empty(t, keys)
+	}
+}
+
+func TestMGet(t *testing.T) {
+	cluster, _ := newTestCluster(t)
+	for _, client := range []Client{newTestClient(t), cluster} {
+		require.NoError(t, client.Set("{a}:1", "a1", 0).Err())
+		require.NoError(t, client.Set("{c}:1", "c1", 0).Err())
+
+		// the keys in different slots are got, and the value of a key that does not exist is nil
+		values, err := MGet(client, "{a}:1", "{b}:1", "{c}:1")
+		require.NoError(t, err)
+		require.Equal(t, []interface{}{"a1", nil, "c1"}, values)
+	}
+}
+
+func TestDel(t *testing.T) {
+	cluster, _ := newTestCluster(t)
+	for _, client := range []Client{newTestClient(t), cluster} {
+		require.NoError(t, client.Set("{a}:1", "a1", 0).Err())
+		require.NoError(t, client.Set("{b}:1", "b1", 0).Err())
+		require.NoError(t, client.Set("{c}:1", "c1", 0).Err())
+
+		require.NoError(t, Del(client, "{a}:1", "{c}:1", "{d}:1"))
+		keys, err := Keys(client, "*")
+		require.NoError(t, err)
+		require.Equal(t, []string{"{b}:1"}, keys)
+	}
+}
+
+func TestIsNilErr(t *testing.T) {
+	client := newTestClient(t)
+	_, err := client.Get("none").Result()
+	require.True(t, IsNilErr(err))
+	require.False(t, IsNilErr(nil))
+}
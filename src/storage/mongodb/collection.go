@@ -41,9 +41,15 @@ type CollectionInterface interface {
 
 	Aggregate(ctx context.Context, pipeline interface{}, opts *aggregateopt.One, output interface{}) error
 
+	// Explain returns the query planner of the find operation with the filter in json
+	Explain(ctx context.Context, filter interface{}) (string, error)
+
 	InsertOne(ctx context.Context, document interface{}, opts *insertopt.One) error
 	InsertMany(ctx context.Context, document []interface{}, opts *insertopt.Many) error
 
+	// BulkWrite execute the write operations in one request, returns the result of every operation
+	BulkWrite(ctx context.Context, models []BulkWriteModel, ordered bool) ([]BulkWriteItemResult, error)
+
 	UpdateMany(ctx context.Context, filter interface{}, update interface{}, opts *updateopt.Many) (*UpdateResult, error)
 	UpdateOne(ctx context.Context, filter interface{}, update interface{}, opts *updateopt.One) (*UpdateResult, error)
 
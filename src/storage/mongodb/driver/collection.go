@@ -75,8 +75,12 @@ func (c *collection) CreateIndex(index mongodb.Index) error {
 	indexView := c.innerCollection.Indexes()
 
 	keys := bsonx.Doc{}
-	for key, val := range index.Keys {
-		keys = keys.Append(key, bsonx.Int32(val))
+	for _, key := range index.OrderedKeys() {
+		if strings.HasPrefix(key, mongodb.TextIndexKeyPrefix) {
+			keys = keys.Append(strings.TrimPrefix(key, mongodb.TextIndexKeyPrefix), bsonx.String("text"))
+			continue
+		}
+		keys = keys.Append(key, bsonx.Int32(index.Keys[key]))
 	}
 
 	indexOpts := &options.IndexOptions{
@@ -84,6 +88,9 @@ func (c *collection) CreateIndex(index mongodb.Index) error {
 		Background: &index.Background,
 		Unique:     &index.Unique,
 	}
+	if index.ExpireAfterSeconds > 0 {
+		indexOpts.ExpireAfterSeconds = &index.ExpireAfterSeconds
+	}
 
 	// in a session
 	if nil != c.innerSession {
@@ -427,6 +434,76 @@ func (c *collection) InsertMany(ctx context.Context, document []interface{}, opt
 	return err
 }
 
+// Explain returns the query planner of the find operation with the filter, the session is not used because
+// explain is not allowed in a transaction.
+func (c *collection) Explain(ctx context.Context, filter interface{}) (string, error) {
+	if filter == nil {
+		filter = bson.D{}
+	}
+	cmd := bson.D{
+		{Key: "explain", Value: bson.D{{Key: "find", Value: c.innerCollection.Name()}, {Key: "filter", Value: filter}}},
+		{Key: "verbosity", Value: "queryPlanner"},
+	}
+	result, err := c.innerCollection.Database().RunCommand(ctx, cmd).DecodeBytes()
+	if err != nil {
+		return "", err
+	}
+	planner, err := result.LookupErr("queryPlanner")
+	if err != nil {
+		return "", err
+	}
+	return planner.String(), nil
+}
+
+func (c *collection) BulkWrite(ctx context.Context, models []mongodb.BulkWriteModel, ordered bool) ([]mongodb.BulkWriteItemResult, error) {
+
+	if len(models) == 0 {
+		return []mongodb.BulkWriteItemResult{}, nil
+	}
+
+	writeModels := make([]mongo.WriteModel, len(models))
+	for index, model := range models {
+		switch model.Op {
+		case mongodb.BulkWriteInsert:
+			writeModels[index] = mongo.NewInsertOneModel().SetDocument(model.Doc)
+		case mongodb.BulkWriteUpdateOne:
+			writeModels[index] = mongo.NewUpdateOneModel().SetFilter(model.Filter).SetUpdate(bson.M{"$set": model.Doc})
+		case mongodb.BulkWriteUpsert:
+			writeModels[index] = mongo.NewUpdateOneModel().SetFilter(model.Filter).SetUpdate(bson.M{"$set": model.Doc}).SetUpsert(true)
+		case mongodb.BulkWriteDelete:
+			writeModels[index] = mongo.NewDeleteManyModel().SetFilter(model.Filter)
+		default:
+			return nil, errors.New("unknown bulk write operation " + model.Op)
+		}
+	}
+	bulkOption := options.BulkWrite().SetOrdered(ordered)
+
+	var err error
+	if nil != c.innerSession {
+		// in a session
+		err = mongo.WithSession(ctx, c.innerSession, func(mctx mongo.SessionContext) error {
+			_, err := c.innerCollection.BulkWrite(mctx, writeModels, bulkOption)
+			return err
+		})
+	} else {
+		// no session
+		_, err = c.innerCollection.BulkWrite(ctx, writeModels, bulkOption)
+	}
+	if nil == err {
+		return mongodb.NewBulkWriteItemResults(len(models), ordered, nil), nil
+	}
+
+	bulkErr, ok := err.(mongo.BulkWriteException)
+	if !ok || len(bulkErr.WriteErrors) == 0 {
+		return nil, err
+	}
+	failures := make(map[int]string, len(bulkErr.WriteErrors))
+	for _, writeErr := range bulkErr.WriteErrors {
+		failures[writeErr.Index] = writeErr.Message
+	}
+	return mongodb.NewBulkWriteItemResults(len(models), ordered, failures), err
+}
+
 func (c *collection) UpdateMany(ctx context.Context, filter interface{}, update interface{}, opts *updateopt.Many) (*mongodb.UpdateResult, error) {
 
 	updateOption := &options.UpdateOptions{}
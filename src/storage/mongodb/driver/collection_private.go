@@ -14,9 +14,11 @@ package driver
 
 import (
 	"context"
+	"sort"
 
 	"configcenter/src/storage/mongodb"
 
+	"github.com/mongodb/mongo-go-driver/bson"
 	"github.com/mongodb/mongo-go-driver/mongo"
 )
 
@@ -24,9 +26,13 @@ func (c *collection) parseIndexResult(ctx context.Context, cursor *mongo.Cursor)
 
 	// this struct from mongodb go driver about index
 	type index struct {
-		Key  map[string]int
-		NS   string
-		Name string
+		Key                bson.D
+		NS                 string
+		Name               string
+		Unique             bool
+		Background         bool
+		ExpireAfterSeconds int32 `bson:"expireAfterSeconds"`
+		Weights            map[string]interface{}
 	}
 
 	returnIndexResult := &mongodb.QueryIndexResult{}
@@ -36,9 +42,33 @@ func (c *collection) parseIndexResult(ctx context.Context, cursor *mongo.Cursor)
 		if err := cursor.Decode(&elem); err != nil {
 			return returnIndexResult, err
 		}
-		idxResult := mongodb.IndexResult{Name: elem.Name, Namespace: elem.NS}
-		for name := range elem.Key {
-			idxResult.Key = append(idxResult.Key, name)
+		idxResult := mongodb.IndexResult{
+			Name:               elem.Name,
+			Namespace:          elem.NS,
+			Unique:             elem.Unique,
+			Background:         elem.Background,
+			ExpireAfterSeconds: elem.ExpireAfterSeconds,
+		}
+		for _, key := range elem.Key {
+			switch key.Key {
+			case textIndexKey:
+				// the text index fields are in the weights, they are sorted by name like mgo does
+				fields := make([]string, 0, len(elem.Weights))
+				for field := range elem.Weights {
+					fields = append(fields, field)
+				}
+				sort.Strings(fields)
+				for _, field := range fields {
+					idxResult.Key = append(idxResult.Key, mongodb.TextIndexKeyPrefix+field)
+				}
+			case textIndexTermKey:
+			default:
+				if isDescendingIndexKey(key.Value) {
+					idxResult.Key = append(idxResult.Key, "-"+key.Key)
+				} else {
+					idxResult.Key = append(idxResult.Key, key.Key)
+				}
+			}
 		}
 		returnIndexResult.Indexes = append(returnIndexResult.Indexes, idxResult)
 	}
@@ -49,3 +79,21 @@ func (c *collection) parseIndexResult(ctx context.Context, cursor *mongo.Cursor)
 
 	return returnIndexResult, nil
 }
+
+// the keys of a text index in the index specification, the text fields are in the weights
+const (
+	textIndexKey     = "_fts"
+	textIndexTermKey = "_ftsx"
+)
+
+func isDescendingIndexKey(value interface{}) bool {
+	switch v := value.(type) {
+	case int32:
+		return v < 0
+	case int64:
+		return v < 0
+	case float64:
+		return v < 0
+	}
+	return false
+}
@@ -131,6 +131,50 @@ func TestCRUD(t *testing.T) {
 
 }
 
+func TestBulkWrite(t *testing.T) {
+
+	executeCommand(t, func(dbClient mongodb.CommonClient) {
+
+		coll := dbClient.Collection("cc_tmp")
+		val := xid.New().String()
+		defer coll.DeleteMany(context.TODO(), bson.M{"key": bson.M{"$regex": val}}, nil)
+		err := coll.InsertOne(context.TODO(), bson.M{"_id": "bulk_" + val, "key": "value_" + val}, nil)
+		require.NoError(t, err)
+
+		models := []mongodb.BulkWriteModel{
+			{Op: mongodb.BulkWriteUpdateOne, Filter: bson.M{"_id": "bulk_" + val}, Doc: bson.M{"key": "value_update_" + val}},
+			{Op: mongodb.BulkWriteInsert, Doc: bson.M{"_id": "bulk_" + val, "key": "value_dup_" + val}},
+			{Op: mongodb.BulkWriteUpsert, Filter: bson.M{"key": "value_upsert_" + val}, Doc: bson.M{"count": 1}},
+			{Op: mongodb.BulkWriteDelete, Filter: bson.M{"key": "value_update_" + val}},
+		}
+
+		// the operations after the duplicated insert are not executed in ordered mode
+		results, err := coll.BulkWrite(context.TODO(), models, true)
+		require.Error(t, err)
+		require.Len(t, results, 4)
+		require.Equal(t, mongodb.BulkWriteItemResult{Index: 0, Executed: true}, results[0])
+		require.True(t, results[1].Executed)
+		require.NotEmpty(t, results[1].Error)
+		require.False(t, results[2].Executed)
+		require.False(t, results[3].Executed)
+		count, err := coll.Count(context.TODO(), bson.M{"key": "value_upsert_" + val})
+		require.NoError(t, err)
+		require.EqualValues(t, 0, count)
+
+		// all the operations are executed in unordered mode
+		results, err = coll.BulkWrite(context.TODO(), models, false)
+		require.Error(t, err)
+		require.Len(t, results, 4)
+		for index, result := range results {
+			require.True(t, result.Executed)
+			require.Equal(t, index == 1, result.Error != "")
+		}
+		count, err = coll.Count(context.TODO(), bson.M{"key": "value_upsert_" + val})
+		require.NoError(t, err)
+		require.EqualValues(t, 1, count)
+	})
+}
+
 func TestIndexCRUD(t *testing.T) {
 	executeCommand(t, func(dbClient mongodb.CommonClient) {
 		coll := dbClient.Collection("cc_index")
@@ -32,12 +32,65 @@ type ReplaceOneResult struct {
 
 // IndexResult get collection index result
 type IndexResult struct {
-	Namespace string   `json:"ns"`
-	Name      string   `json:"name"`
-	Key       []string `json:"key"`
+	Namespace string `json:"ns"`
+	Name      string `json:"name"`
+	// Key is the ordered keys of the index, a descending key is prefixed with "-" and a text key with
+	// TextIndexKeyPrefix, which is the same as mgo does
+	Key                []string `json:"key"`
+	Unique             bool     `json:"unique"`
+	Background         bool     `json:"background"`
+	ExpireAfterSeconds int32    `json:"expire_after_seconds"`
 }
 
 // QueryIndexResult get the indexex result
 type QueryIndexResult struct {
 	Indexes []IndexResult
 }
+
+// bulk write operations
+const (
+	BulkWriteInsert    = "insert"
+	BulkWriteUpdateOne = "update_one"
+	BulkWriteUpsert    = "upsert"
+	BulkWriteDelete    = "delete"
+)
+
+// BulkWriteModel is a write operation of a bulk write
+type BulkWriteModel struct {
+	// Op is one of insert, update_one, upsert and delete
+	Op string
+	// Filter selects the documents to update or delete, it's not used by insert
+	Filter interface{}
+	// Doc is the document to insert, or the fields to set for update_one and upsert
+	Doc interface{}
+}
+
+// BulkWriteItemResult is the result of a write operation in a bulk write
+type BulkWriteItemResult struct {
+	Index int `json:"index"`
+	// Executed is false if the operation is not executed, e.g. an earlier operation failed in ordered mode
+	Executed bool   `json:"executed"`
+	Error    string `json:"error"`
+}
+
+// NewBulkWriteItemResults build the results of count bulk write operations, failures are the errors of the
+// failed operations by index. in ordered mode the operations after the first failed one are not executed.
+func NewBulkWriteItemResults(count int, ordered bool, failures map[int]string) []BulkWriteItemResult {
+	firstFailed := count
+	for index := range failures {
+		if index < firstFailed {
+			firstFailed = index
+		}
+	}
+
+	results := make([]BulkWriteItemResult, count)
+	for index := range results {
+		results[index] = BulkWriteItemResult{Index: index, Executed: true}
+		if errMsg, failed := failures[index]; failed {
+			results[index].Error = errMsg
+		} else if ordered && index > firstFailed {
+			results[index].Executed = false
+		}
+	}
+	return results
+}
@@ -0,0 +1,54 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mongodb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewBulkWriteItemResults(t *testing.T) {
+	testCases := []struct {
+		name     string
+		ordered  bool
+		failures map[int]string
+		expect   []BulkWriteItemResult
+	}{
+		{
+			name: "all succeeded",
+			expect: []BulkWriteItemResult{
+				{Index: 0, Executed: true}, {Index: 1, Executed: true}, {Index: 2, Executed: true},
+			},
+		},
+		{
+			name:     "ordered stops at the first failure",
+			ordered:  true,
+			failures: map[int]string{1: "duplicate key"},
+			expect: []BulkWriteItemResult{
+				{Index: 0, Executed: true}, {Index: 1, Executed: true, Error: "duplicate key"}, {Index: 2, Executed: false},
+			},
+		},
+		{
+			name:     "unordered executes all",
+			failures: map[int]string{0: "duplicate key", 2: "invalid doc"},
+			expect: []BulkWriteItemResult{
+				{Index: 0, Executed: true, Error: "duplicate key"}, {Index: 1, Executed: true}, {Index: 2, Executed: true, Error: "invalid doc"},
+			},
+		},
+	}
+	for _, c := range testCases {
+		require.Equal(t, c.expect, NewBulkWriteItemResults(3, c.ordered, c.failures), c.name)
+	}
+	require.Empty(t, NewBulkWriteItemResults(0, true, nil))
+}
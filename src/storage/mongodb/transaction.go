@@ -12,6 +12,13 @@
 
 package mongodb
 
+import (
+	"strings"
+
+	"github.com/mongodb/mongo-go-driver/mongo"
+	"github.com/mongodb/mongo-go-driver/x/network/command"
+)
+
 // Transaction transaction operation methods
 type Transaction interface {
 	StartTransaction() error
@@ -19,3 +26,41 @@ type Transaction interface {
 	CommitTransaction() error
 	Collection(collName string) CollectionInterface
 }
+
+// TransientTransactionErrorLabel is the error label of the transient transaction errors,
+// such as write conflicts, the whole transaction could be retried on them
+const TransientTransactionErrorLabel = "TransientTransactionError"
+
+// WriteConflictCode is the error code of the write conflicts
+const WriteConflictCode = 112
+
+// writeConflictErrorName is the code name of the write conflicts in the message of the driver errors
+const writeConflictErrorName = "(WriteConflict)"
+
+// IsTransientTxnError returns whether err returned by the mongodb driver is a transient transaction error,
+// that is it has the TransientTransactionError label or it's a write conflict.
+func IsTransientTxnError(err error) bool {
+	switch e := err.(type) {
+	case command.Error:
+		return e.HasErrorLabel(TransientTransactionErrorLabel) || e.Code == WriteConflictCode
+	case mongo.WriteException:
+		for _, writeErr := range e.WriteErrors {
+			if writeErr.Code == WriteConflictCode {
+				return true
+			}
+		}
+	case mongo.BulkWriteException:
+		for _, writeErr := range e.WriteErrors {
+			if writeErr.Code == WriteConflictCode {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// IsTransientTxnErrorMessage returns whether the error is a write conflict judging by its message. it's only for
+// the errors that have been passed through the rpc or http, which lost the labels and codes of the driver errors.
+func IsTransientTxnErrorMessage(err error) bool {
+	return err != nil && strings.Contains(err.Error(), writeConflictErrorName)
+}
@@ -0,0 +1,50 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mongodb
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mongodb/mongo-go-driver/mongo"
+	"github.com/mongodb/mongo-go-driver/x/network/command"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsTransientTxnError(t *testing.T) {
+	testCases := []struct {
+		err    error
+		expect bool
+	}{
+		{nil, false},
+		{command.Error{Code: 251, Name: "NoSuchTransaction", Labels: []string{TransientTransactionErrorLabel}}, true},
+		{command.Error{Code: WriteConflictCode, Name: "WriteConflict", Message: "WriteConflict"}, true},
+		{command.Error{Code: 11000, Message: "E11000 duplicate key error, WriteConflict"}, false},
+		{mongo.WriteException{WriteErrors: mongo.WriteErrors{{Code: WriteConflictCode}}}, true},
+		{mongo.WriteException{WriteErrors: mongo.WriteErrors{{Code: 11000}}}, false},
+		{mongo.BulkWriteException{WriteErrors: []mongo.BulkWriteError{{WriteError: mongo.WriteError{Code: WriteConflictCode}}}}, true},
+		// the message is not trusted for the driver errors
+		{errors.New("(WriteConflict) WriteConflict"), false},
+		{errors.New(TransientTransactionErrorLabel), false},
+	}
+	for _, c := range testCases {
+		require.Equal(t, c.expect, IsTransientTxnError(c.err), "%v", c.err)
+	}
+}
+
+func TestIsTransientTxnErrorMessage(t *testing.T) {
+	require.False(t, IsTransientTxnErrorMessage(nil))
+	require.True(t, IsTransientTxnErrorMessage(errors.New(command.Error{Code: WriteConflictCode, Name: "WriteConflict", Message: "WriteConflict"}.Error())))
+	require.False(t, IsTransientTxnErrorMessage(errors.New("update host WriteConflict_field failed")))
+	require.False(t, IsTransientTxnErrorMessage(errors.New("E11000 duplicate key error")))
+}
@@ -12,6 +12,8 @@
 
 package mongodb
 
+import "sort"
+
 // Opener open method
 type Opener interface {
 	Open() error
@@ -28,10 +30,40 @@ type OpenCloser interface {
 	Closer
 }
 
+// TextIndexKeyPrefix is the prefix of the text index keys, the key "$text:$**" is the text index of all the string fields
+const TextIndexKeyPrefix = "$text:"
+
 // Index the collection index definition
 type Index struct {
 	Keys       map[string]int32 `json:"keys"`
 	Name       string           `json:"name"`
 	Unique     bool             `json:"unique"`
 	Background bool             `json:"background"`
+	// ExpireAfterSeconds makes a ttl index when it's greater than 0, the keys should be a single date field
+	ExpireAfterSeconds int32 `json:"expire_after_seconds"`
+	// KeyOrder is the order of the keys of a compound index, the keys are ordered by name if it's not set
+	KeyOrder []string `json:"key_order,omitempty"`
+}
+
+// OrderedKeys returns the keys in KeyOrder, or ordered by name if KeyOrder is not the same set of the keys
+func (i Index) OrderedKeys() []string {
+	if len(i.KeyOrder) == len(i.Keys) {
+		ordered := true
+		for _, key := range i.KeyOrder {
+			if _, ok := i.Keys[key]; !ok {
+				ordered = false
+				break
+			}
+		}
+		if ordered {
+			return i.KeyOrder
+		}
+	}
+
+	keys := make([]string, 0, len(i.Keys))
+	for key := range i.Keys {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
 }
@@ -14,12 +14,14 @@ package rpc
 
 import (
 	"bufio"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
 	"net/url"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -79,7 +81,7 @@ func NewClient(conn net.Conn, compress string) (*client, error) {
 		codec:     BSONCodec,
 		stream:    newStreamStore(),
 	}
-	blog.V(3).Infof("connected to rpc server %s", c.TargetID())
+	blog.V(3).Infof("connected to rpc server %s, compress: %s", c.TargetID(), compress)
 	go c.write()
 	go c.read()
 	return c, nil
@@ -100,18 +102,41 @@ func Dial(connect string) (*client, error) {
 // DialHTTPPath connects to an HTTP RPC server
 // at the specified network address and path.
 func DialHTTPPath(network, address, path string) (*client, error) {
+	return DialHTTPPathWithTLS(network, address, path, nil)
+}
+
+// DialHTTPPathWithTLS connects to an HTTP RPC server at the specified network address and path,
+// the connection is upgraded to tls after the CONNECT handshake if tlsConf is not nil.
+func DialHTTPPathWithTLS(network, address, path string, tlsConf *tls.Config) (*client, error) {
 	var err error
 	conn, err := net.Dial("tcp", address)
 	if err != nil {
 		return nil, fmt.Errorf("[rpc] dail tcp error: %v", err)
 	}
-	io.WriteString(conn, "CONNECT "+path+" HTTP/1.0\n\n")
+	// offer the supported compress algorithms, the server answers the chosen one
+	handshake := "CONNECT " + path + " HTTP/1.0\n" + compressHeader + ": " + strings.Join(SupportedCompress, ",") + "\n"
+	if tlsConf != nil {
+		handshake += tlsHeader + ": true\n"
+	}
+	io.WriteString(conn, handshake+"\n")
 
 	// Require successful HTTP response
 	// before switching to RPC protocol.
 	resp, err := http.ReadResponse(bufio.NewReader(conn), &http.Request{Method: "CONNECT"})
 	if err == nil && resp.Status == connected {
-		return NewClient(conn, "deflate")
+		if tlsConf == nil {
+			return NewClient(conn, resp.Header.Get(compressHeader))
+		}
+		if resp.Header.Get(tlsHeader) != "true" {
+			conn.Close()
+			return nil, fmt.Errorf("[rpc] rpc server %s does not support tls", address)
+		}
+		tlsConn, err := tlsClient(conn, address, tlsConf)
+		if err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("[rpc] tls handshake with %s failed: %v", address, err)
+		}
+		return NewClient(tlsConn, resp.Header.Get(compressHeader))
 	}
 	if err == nil {
 		err = errors.New("unexpected HTTP response: " + resp.Status)
@@ -128,13 +153,21 @@ func DialHTTPPath(network, address, path string) (*client, error) {
 //Close replica client
 func (c *client) Close() error {
 	if c.done.SetIfNotSet() {
-		blog.V(3).Infof("rpc connection %s -> %s close", c.localAddr, c.peerAddr)
+		blog.V(3).Infof("rpc connection %s -> %s close, %s", c.localAddr, c.peerAddr, c.Stats())
 		close(c.send)
 		c.wire.Close()
 	}
 	return nil
 }
 
+// Stats returns the byte counters of the connection
+func (c *client) Stats() WireStats {
+	if wire, ok := c.wire.(*BinaryWire); ok {
+		return wire.Stats()
+	}
+	return WireStats{}
+}
+
 // TargetID operation target ID
 func (c *client) TargetID() string {
 	return c.peerAddr
@@ -18,6 +18,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	gorpc "net/rpc"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -57,6 +58,89 @@ func OK(msg Request) (interface{}, error) {
 	return Reply{OK: true}, nil
 }
 
+func Echo(msg Request) (interface{}, error) {
+	req := Req{}
+	if err := msg.Decode(&req); err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+func TestRPCCompress(t *testing.T) {
+	rpc := NewServer()
+
+	mux := http.NewServeMux()
+	rpc.Handle("echo", Echo)
+	mux.Handle("/rpc", rpc)
+
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	address, err := util.GetDailAddress(ts.URL)
+	require.NoError(t, err)
+	cli, err := DialHTTPPath("tcp", address, "/rpc")
+	require.NoError(t, err)
+	defer cli.Close()
+	require.Equal(t, CompressSnappy, cli.wire.(*BinaryWire).Compress())
+
+	// the small message is not compressed
+	reply := Req{}
+	require.NoError(t, cli.Call("echo", &Req{Name: "ok"}, &reply))
+	require.Equal(t, "ok", reply.Name)
+	stats := cli.Stats()
+	require.Equal(t, stats.WriteRawBytes, stats.WriteWireBytes)
+
+	name := strings.Repeat("compress", 1000)
+	require.NoError(t, cli.Call("echo", &Req{Name: name}, &reply))
+	require.Equal(t, name, reply.Name)
+	stats = cli.Stats()
+	require.True(t, stats.WriteRawBytes > stats.WriteWireBytes)
+	require.True(t, stats.ReadRawBytes > stats.ReadWireBytes)
+	require.True(t, stats.Ratio() > 1)
+}
+
+func TestNegotiateCompress(t *testing.T) {
+	require.Equal(t, CompressDeflate, negotiateCompress("gzip, deflate,snappy"))
+	require.Equal(t, CompressSnappy, negotiateCompress("snappy,deflate"))
+	require.Equal(t, CompressNone, negotiateCompress(""))
+	require.Equal(t, CompressNone, negotiateCompress("gzip"))
+}
+
+func TestDeflateCompressor(t *testing.T) {
+	c, err := newCompressor(CompressDeflate)
+	require.NoError(t, err)
+	for _, data := range []string{strings.Repeat("a", 2048), strings.Repeat("bc", 4096)} {
+		compressed, err := c.Compress([]byte(data))
+		require.NoError(t, err)
+		decompressed, err := c.Decompress(compressed)
+		require.NoError(t, err)
+		require.Equal(t, data, string(decompressed))
+	}
+}
+
+func TestDecompressLimit(t *testing.T) {
+	defer func(size int) { maxDecompressedSize = size }(maxDecompressedSize)
+	maxDecompressedSize = 4096
+
+	for _, compress := range SupportedCompress {
+		c, err := newCompressor(compress)
+		require.NoError(t, err)
+
+		compressed, err := c.Compress([]byte(strings.Repeat("a", maxDecompressedSize)))
+		require.NoError(t, err)
+		decompressed, err := c.Decompress(compressed)
+		require.NoError(t, err, compress)
+		require.Len(t, decompressed, maxDecompressedSize, compress)
+
+		// the small message decompresses to more than the limit
+		compressed, err = c.Compress([]byte(strings.Repeat("a", maxDecompressedSize+1)))
+		require.NoError(t, err)
+		require.True(t, len(compressed) < compressThreshold, compress)
+		_, err = c.Decompress(compressed)
+		require.Error(t, err, compress)
+	}
+}
+
 func BenchmarkGORPC(b *testing.B) {
 
 	rpc := gorpc.NewServer()
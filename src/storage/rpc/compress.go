@@ -13,81 +13,124 @@
 package rpc
 
 import (
-	"bufio"
+	"bytes"
 	"compress/flate"
+	"fmt"
 	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/golang/snappy"
 )
 
-type compressor interface {
-	flushWriter
-	io.Reader
-}
+// the compress algorithms
+const (
+	CompressNone    = ""
+	CompressSnappy  = "snappy"
+	CompressDeflate = "deflate"
+)
 
-type flushWriter interface {
-	io.Writer
-	Flush() error
-}
+// SupportedCompress is the supported compress algorithms in order of preference
+var SupportedCompress = []string{CompressSnappy, CompressDeflate}
 
-type Compressor struct {
-	zr io.Reader
-	zw flushWriter
-}
+// compressHeader is the handshake header that the client offers the compress algorithms in
+// and the server answers the chosen one in, old peers ignore it and the connection is not compressed.
+const compressHeader = "X-CC-RPC-Compress"
 
-type flushWraper struct {
-	zw    flushWriter
-	flush func() error
-}
+// compressThreshold is the min size of the message data to be compressed
+const compressThreshold = 1024
 
-func (f *flushWraper) Flush() error {
-	if err := f.zw.Flush(); err != nil {
-		return err
-	}
-	return f.flush()
-}
-func (f *flushWraper) Write(p []byte) (n int, err error) {
-	return f.zw.Write(p)
-}
+// maxDecompressedSize is the max size of the compressed message data after decompression, so that a small
+// message from the peer never decompresses to gigabytes. the larger data is sent without compression.
+var maxDecompressedSize = 64 << 20
 
-func newFlushWraper(w flushWriter, flush func() error) flushWriter {
-	return &flushWraper{
-		zw:    w,
-		flush: flush,
+// negotiateCompress returns the first algorithm offered by the client that the server supports
+func negotiateCompress(offered string) string {
+	for _, name := range strings.Split(offered, ",") {
+		name = strings.TrimSpace(name)
+		for _, supported := range SupportedCompress {
+			if name == supported {
+				return name
+			}
+		}
 	}
+	return CompressNone
 }
 
-func newCompressor(r io.Reader, w io.Writer, compress string) (*Compressor, error) {
-	var zr io.Reader
-	var zw flushWriter
-	var err error
+// compressor compresses the message data of a wire, the writer compresses and the reader decompresses,
+// so Compress and Decompress could be called concurrently, but each of them is not concurrent safe.
+type compressor interface {
+	Compress(data []byte) ([]byte, error)
+	Decompress(data []byte) ([]byte, error)
+}
 
-	bw := bufio.NewWriterSize(w, writeBufferSize)
-	compress = ""
+// newCompressor returns the compressor of the algorithm, nil for CompressNone
+func newCompressor(compress string) (compressor, error) {
 	switch compress {
-	case "deflate":
-		zr = flate.NewReader(r)
-		zw, err = flate.NewWriter(bw, flate.DefaultCompression)
+	case CompressNone:
+		return nil, nil
+	case CompressSnappy:
+		return snappyCompressor{}, nil
+	case CompressDeflate:
+		zw, err := flate.NewWriter(nil, flate.DefaultCompression)
 		if err != nil {
 			return nil, err
 		}
-		zw = newFlushWraper(zw, bw.Flush)
+		return &deflateCompressor{zw: zw}, nil
 	default:
-		br := bufio.NewReaderSize(r, readBufferSize)
-		zr = br
-		zw = bw
+		return nil, fmt.Errorf("unsupported compress algorithm %s", compress)
 	}
+}
+
+type snappyCompressor struct{}
 
-	return &Compressor{
-		zr: zr,
-		zw: zw,
-	}, nil
+func (snappyCompressor) Compress(data []byte) ([]byte, error) {
+	return snappy.Encode(nil, data), nil
 }
 
-func (c *Compressor) Read(p []byte) (n int, err error) {
-	return c.zr.Read(p)
+func (snappyCompressor) Decompress(data []byte) ([]byte, error) {
+	size, err := snappy.DecodedLen(data)
+	if err != nil {
+		return nil, err
+	}
+	if size > maxDecompressedSize {
+		return nil, fmt.Errorf("decompressed size %d exceeds the limit %d", size, maxDecompressedSize)
+	}
+	return snappy.Decode(nil, data)
 }
-func (c *Compressor) Write(p []byte) (n int, err error) {
-	return c.zw.Write(p)
+
+type deflateCompressor struct {
+	zw  *flate.Writer
+	zr  io.ReadCloser
+	buf bytes.Buffer
 }
-func (c *Compressor) Flush() (err error) {
-	return c.zw.Flush()
+
+func (c *deflateCompressor) Compress(data []byte) ([]byte, error) {
+	c.buf.Reset()
+	c.zw.Reset(&c.buf)
+	if _, err := c.zw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := c.zw.Close(); err != nil {
+		return nil, err
+	}
+	compressed := make([]byte, c.buf.Len())
+	copy(compressed, c.buf.Bytes())
+	return compressed, nil
+}
+
+func (c *deflateCompressor) Decompress(data []byte) ([]byte, error) {
+	if c.zr == nil {
+		c.zr = flate.NewReader(bytes.NewReader(data))
+	} else if err := c.zr.(flate.Resetter).Reset(bytes.NewReader(data), nil); err != nil {
+		return nil, err
+	}
+	decompressed, err := ioutil.ReadAll(io.LimitReader(c.zr, int64(maxDecompressedSize)+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(decompressed) > maxDecompressedSize {
+		return nil, fmt.Errorf("decompressed size exceeds the limit %d", maxDecompressedSize)
+	}
+	return decompressed, nil
 }
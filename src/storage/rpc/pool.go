@@ -13,6 +13,7 @@
 package rpc
 
 import (
+	"crypto/tls"
 	"fmt"
 	"sort"
 	"sync"
@@ -29,12 +30,15 @@ type Pool struct {
 
 	getServer types.GetServerFunc
 	lastIndex int
+	tlsConf   *tls.Config
 }
 
-func NewClientPool(network string, getServer types.GetServerFunc, path string) (*Pool, error) {
+// NewClientPool returns a new client pool, the connections are upgraded to tls if tlsConf is not nil
+func NewClientPool(network string, getServer types.GetServerFunc, path string, tlsConf *tls.Config) (*Pool, error) {
 	pool := &Pool{
 		conns:     make(chan Client, 40),
 		getServer: getServer,
+		tlsConf:   tlsConf,
 	}
 	var err error
 	var conn Client
@@ -92,7 +96,7 @@ func (p *Pool) new() (Client, error) {
 		return nil, fmt.Errorf("GetDailAddress %s, failed: %v", servers[p.lastIndex], err)
 	}
 
-	return DialHTTPPath("tcp", address, "/txn/v3/rpc")
+	return DialHTTPPathWithTLS("tcp", address, "/txn/v3/rpc", p.tlsConf)
 }
 
 func (p *Pool) pop() Client {
@@ -14,6 +14,7 @@ package rpc
 
 import (
 	"context"
+	"crypto/tls"
 	"io"
 	"net/http"
 	"runtime/debug"
@@ -26,6 +27,7 @@ import (
 type Server struct {
 	ctx            context.Context
 	codec          Codec
+	tlsConf        *tls.Config
 	handlers       map[string]HandlerFunc
 	streamHandlers map[string]HandlerStreamFunc
 }
@@ -54,6 +56,17 @@ func (s *Server) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	useTLS := req.Header.Get(tlsHeader) == "true"
+	if s.tlsConf != nil && !useTLS {
+		resp.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		resp.WriteHeader(http.StatusForbidden)
+		io.WriteString(resp, "403 "+ErrTLSRequired.Error()+"\n")
+		blog.Errorf("rpc client %s connects without tls, but tls is required", req.RemoteAddr)
+		return
+	}
+	// the client requests tls, but the server does not support it, the client closes the connection itself
+	useTLS = useTLS && s.tlsConf != nil
+
 	hijacked, ok := resp.(http.Hijacker)
 	if !ok {
 		resp.Header().Set("Content-Type", "text/plain; charset=utf-8")
@@ -70,25 +83,42 @@ func (s *Server) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
 		blog.Errorf("rpc hijack failed %s: %s", req.RemoteAddr, err.Error())
 		return
 	}
-	session, err := NewServerSession(s, conn, "deflate")
-	if err != nil {
-		blog.Errorf("rpc new server session faile %s: %s", req.RemoteAddr, err.Error())
-		if _, err = io.WriteString(conn, "HTTP/1.0 "+connectfaile+"\n\n"); err != nil {
-			blog.Errorf("write string failed %s: %v", req.RemoteAddr, err)
-		}
+	compress := negotiateCompress(req.Header.Get(compressHeader))
+	handshake := "HTTP/1.0 " + connected + "\n"
+	if compress != CompressNone {
+		handshake += compressHeader + ": " + compress + "\n"
+	}
+	if useTLS {
+		handshake += tlsHeader + ": true\n"
+	}
+	if _, err = io.WriteString(conn, handshake+"\n"); err != nil {
+		blog.Errorf("write string failed %s: %v", req.RemoteAddr, err)
 		return
 	}
 
-	if _, err = io.WriteString(conn, "HTTP/1.0 "+connected+"\n\n"); err != nil {
-		blog.Errorf("write string failed %s: %v", req.RemoteAddr, err)
+	// upgrade the connection to tls after the handshake
+	rwc := io.ReadWriteCloser(conn)
+	if useTLS {
+		tlsConn := tls.Server(conn, s.tlsConf)
+		if err = tlsHandshake(tlsConn); err != nil {
+			blog.Errorf("rpc tls handshake failed %s: %v", req.RemoteAddr, err)
+			conn.Close()
+			return
+		}
+		rwc = tlsConn
+	}
+	session, err := NewServerSession(s, rwc, compress)
+	if err != nil {
+		blog.Errorf("rpc new server session faile %s: %s", req.RemoteAddr, err.Error())
+		rwc.Close()
 		return
 	}
-	blog.V(3).Infof("connected from rpc client %s", req.RemoteAddr)
+	blog.V(3).Infof("connected from rpc client %s, compress: %s, tls: %v", req.RemoteAddr, compress, useTLS)
 	if err = session.Run(); err != nil {
-		blog.Errorf("disconnect from rpc client %s with error: %s ", req.RemoteAddr, err.Error())
+		blog.Errorf("disconnect from rpc client %s with error: %s, %s", req.RemoteAddr, err.Error(), session.Stats())
 		return
 	}
-	blog.V(3).Infof("disconnect from rpc client %s", req.RemoteAddr)
+	blog.V(3).Infof("disconnect from rpc client %s, %s", req.RemoteAddr, session.Stats())
 }
 
 // Handle regist new handler
@@ -101,6 +131,11 @@ func (s *Server) HandleStream(name string, f HandlerStreamFunc) {
 	s.streamHandlers[name] = f
 }
 
+// SetTLSConfig set server tls config, the clients are required to connect with tls if it's set
+func (s *Server) SetTLSConfig(conf *tls.Config) {
+	s.tlsConf = conf
+}
+
 // SetCodec set server codec
 func (s *Server) SetCodec(codec Codec) {
 	s.codec = codec
@@ -138,6 +173,14 @@ func (s *ServerSession) Run() error {
 	return s.readloop()
 }
 
+// Stats returns the byte counters of the session connection
+func (s *ServerSession) Stats() WireStats {
+	if wire, ok := s.wire.(*BinaryWire); ok {
+		return wire.Stats()
+	}
+	return WireStats{}
+}
+
 // Stop stop the server session
 func (s *ServerSession) Stop() {
 	s.done.Set()
@@ -0,0 +1,100 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rpc
+
+import (
+	"crypto/tls"
+	"errors"
+	"net"
+	"time"
+
+	"configcenter/src/apimachinery/util"
+	"configcenter/src/common/ssl"
+)
+
+// TLSConfigPrefix is the config prefix of the rpc tls, e.g. rpc.ca_file, rpc.cert_file, rpc.key_file, rpc.password
+const TLSConfigPrefix = "rpc"
+
+// tlsHeader is the handshake header that the client requests the tls upgrade with and the server accepts it with,
+// the connection is upgraded to tls after the CONNECT handshake.
+const tlsHeader = "X-CC-RPC-TLS"
+
+// tlsHandshakeTimeout is the timeout of the tls handshake after the CONNECT handshake
+const tlsHandshakeTimeout = 5 * time.Second
+
+// ErrTLSRequired the rpc server requires the client to connect with tls
+var ErrTLSRequired = errors.New("rpc server requires tls")
+
+// isTLSConfigured returns whether the tls is configured, the ca, certificate and key are all required
+// to verify each other, otherwise it's an error if any of them is set.
+func isTLSConfigured(c util.TLSClientConfig) (bool, error) {
+	if c.CAFile == "" && c.CertFile == "" && c.KeyFile == "" {
+		return false, nil
+	}
+	if c.CAFile == "" || c.CertFile == "" || c.KeyFile == "" {
+		return false, errors.New("rpc tls requires ca_file, cert_file and key_file")
+	}
+	return true, nil
+}
+
+// NewClientTLSConfig returns the tls config of the rpc client, which verifies the server and presents
+// the client certificate to the server, nil if tls is not configured.
+func NewClientTLSConfig(c util.TLSClientConfig) (*tls.Config, error) {
+	configured, err := isTLSConfigured(c)
+	if err != nil || !configured {
+		return nil, err
+	}
+	conf, err := ssl.ClientTLSConfVerity(c.CAFile, c.CertFile, c.KeyFile, c.Password)
+	if err != nil {
+		return nil, err
+	}
+	conf.InsecureSkipVerify = c.InsecureSkipVerify
+	return conf, nil
+}
+
+// NewServerTLSConfig returns the tls config of the rpc server, which requires and verifies the client
+// certificate, nil if tls is not configured.
+func NewServerTLSConfig(c util.TLSClientConfig) (*tls.Config, error) {
+	configured, err := isTLSConfigured(c)
+	if err != nil || !configured {
+		return nil, err
+	}
+	return ssl.ServerTslConfVerityClient(c.CAFile, c.CertFile, c.KeyFile, c.Password)
+}
+
+// tlsClient upgrades the connection to tls as client, the server name is the host of the address if not set
+func tlsClient(conn net.Conn, address string, conf *tls.Config) (net.Conn, error) {
+	if conf.ServerName == "" && !conf.InsecureSkipVerify {
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			return nil, err
+		}
+		conf = conf.Clone()
+		conf.ServerName = host
+	}
+	tlsConn := tls.Client(conn, conf)
+	if err := tlsHandshake(tlsConn); err != nil {
+		return nil, err
+	}
+	return tlsConn, nil
+}
+
+func tlsHandshake(conn *tls.Conn) error {
+	if err := conn.SetDeadline(time.Now().Add(tlsHandshakeTimeout)); err != nil {
+		return err
+	}
+	if err := conn.Handshake(); err != nil {
+		return err
+	}
+	return conn.SetDeadline(time.Time{})
+}
@@ -0,0 +1,137 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rpc
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	apiutil "configcenter/src/apimachinery/util"
+	"configcenter/src/common/util"
+)
+
+// writeCert generates a certificate signed by the parent, and writes the certificate and key files to the dir
+func writeCert(t *testing.T, dir, name string, tmpl, parent *x509.Certificate, parentKey *rsa.PrivateKey) (*x509.Certificate, *rsa.PrivateKey) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	if parent == nil {
+		parent, parentKey = tmpl, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, &key.PublicKey, parentKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	certPem := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name+".crt"), certPem, 0600))
+	keyPem := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name+".key"), keyPem, 0600))
+	return cert, key
+}
+
+func newTLSConfigs(t *testing.T, dir string) (server, client apiutil.TLSClientConfig) {
+	notAfter := time.Now().Add(time.Hour)
+	ca, caKey := writeCert(t, dir, "ca", &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "cc rpc ca"},
+		NotAfter:              notAfter,
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}, nil, nil)
+	writeCert(t, dir, "server", &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "tmserver"},
+		NotAfter:     notAfter,
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, ca, caKey)
+	writeCert(t, dir, "client", &x509.Certificate{
+		SerialNumber: big.NewInt(3),
+		Subject:      pkix.Name{CommonName: "coreservice"},
+		NotAfter:     notAfter,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, ca, caKey)
+
+	caFile := filepath.Join(dir, "ca.crt")
+	server = apiutil.TLSClientConfig{CAFile: caFile, CertFile: filepath.Join(dir, "server.crt"), KeyFile: filepath.Join(dir, "server.key")}
+	client = apiutil.TLSClientConfig{CAFile: caFile, CertFile: filepath.Join(dir, "client.crt"), KeyFile: filepath.Join(dir, "client.key")}
+	return server, client
+}
+
+func TestRPCTLS(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rpc_tls")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	serverConf, clientConf := newTLSConfigs(t, dir)
+
+	serverTLS, err := NewServerTLSConfig(serverConf)
+	require.NoError(t, err)
+	rpc := NewServer()
+	rpc.SetTLSConfig(serverTLS)
+	rpc.Handle("ok", OK)
+
+	mux := http.NewServeMux()
+	mux.Handle("/rpc", rpc)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+	address, err := util.GetDailAddress(ts.URL)
+	require.NoError(t, err)
+
+	// the client with the certificate signed by the ca is accepted
+	clientTLS, err := NewClientTLSConfig(clientConf)
+	require.NoError(t, err)
+	cli, err := DialHTTPPathWithTLS("tcp", address, "/rpc", clientTLS)
+	require.NoError(t, err)
+	defer cli.Close()
+	reply := Reply{}
+	require.NoError(t, cli.Call("ok", &Req{Name: "ok"}, &reply))
+	require.True(t, reply.OK)
+
+	// the client without tls is rejected
+	_, err = DialHTTPPath("tcp", address, "/rpc")
+	require.Error(t, err)
+
+	// the client without the certificate is rejected
+	noCertTLS, err := NewClientTLSConfig(clientConf)
+	require.NoError(t, err)
+	noCertTLS.Certificates = nil
+	if cli, err := DialHTTPPathWithTLS("tcp", address, "/rpc", noCertTLS); err == nil {
+		// the server may verify the client certificate after the client finished the handshake
+		require.Error(t, cli.Ping())
+		cli.Close()
+	}
+}
+
+func TestTLSConfigRequiresAllFiles(t *testing.T) {
+	conf, err := NewClientTLSConfig(apiutil.TLSClientConfig{})
+	require.NoError(t, err)
+	require.Nil(t, conf)
+
+	_, err = NewServerTLSConfig(apiutil.TLSClientConfig{CertFile: "server.crt", KeyFile: "server.key"})
+	require.Error(t, err)
+}
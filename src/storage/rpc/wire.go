@@ -13,10 +13,12 @@
 package rpc
 
 import (
+	"bufio"
 	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
+	"sync/atomic"
 )
 
 // Wire define a wire
@@ -26,26 +28,75 @@ type Wire interface {
 	Close() error
 }
 
+// WireStats is the byte counters of the message data through a wire,
+// the raw bytes are before compression and the wire bytes are after that.
+type WireStats struct {
+	WriteRawBytes  uint64
+	WriteWireBytes uint64
+	ReadRawBytes   uint64
+	ReadWireBytes  uint64
+}
+
+// Ratio returns the compression ratio of the written and read data, 1 if it's not compressed
+func (s WireStats) Ratio() float64 {
+	if s.WriteWireBytes+s.ReadWireBytes == 0 {
+		return 1
+	}
+	return float64(s.WriteRawBytes+s.ReadRawBytes) / float64(s.WriteWireBytes+s.ReadWireBytes)
+}
+
+func (s WireStats) String() string {
+	return fmt.Sprintf("write %d/%d bytes, read %d/%d bytes (raw/wire), compression ratio %.2f",
+		s.WriteRawBytes, s.WriteWireBytes, s.ReadRawBytes, s.ReadWireBytes, s.Ratio())
+}
+
+// the flags before the message data of a compressed wire
+const (
+	dataFlagRaw        = uint8(0)
+	dataFlagCompressed = uint8(1)
+)
+
 // BinaryWire implements Wire interface
 type BinaryWire struct {
-	conn   io.ReadWriteCloser
-	writer flushWriter
-	reader io.Reader
+	conn       io.ReadWriteCloser
+	writer     *bufio.Writer
+	reader     io.Reader
+	compress   string
+	compressor compressor
+	stats      WireStats
 }
 
-// NewBinaryWire returns a new BinaryWire
+// NewBinaryWire returns a new BinaryWire, the message data no less than the threshold
+// is compressed by the compress algorithm, CompressNone means not to compress.
 func NewBinaryWire(rwc io.ReadWriteCloser, compress string) (*BinaryWire, error) {
-	compressor, err := newCompressor(rwc, rwc, compress)
+	compressor, err := newCompressor(compress)
 	if err != nil {
 		return nil, err
 	}
 	return &BinaryWire{
-		conn:   rwc,
-		writer: compressor,
-		reader: compressor,
+		conn:       rwc,
+		writer:     bufio.NewWriterSize(rwc, writeBufferSize),
+		reader:     bufio.NewReaderSize(rwc, readBufferSize),
+		compress:   compress,
+		compressor: compressor,
 	}, nil
 }
 
+// Compress returns the compress algorithm of the wire
+func (w *BinaryWire) Compress() string {
+	return w.compress
+}
+
+// Stats returns the byte counters of the wire
+func (w *BinaryWire) Stats() WireStats {
+	return WireStats{
+		WriteRawBytes:  atomic.LoadUint64(&w.stats.WriteRawBytes),
+		WriteWireBytes: atomic.LoadUint64(&w.stats.WriteWireBytes),
+		ReadRawBytes:   atomic.LoadUint64(&w.stats.ReadRawBytes),
+		ReadWireBytes:  atomic.LoadUint64(&w.stats.ReadWireBytes),
+	}
+}
+
 func (w *BinaryWire) Write(msg *Message) error {
 	if msg == nil {
 		return errors.New("wire could not write empty message")
@@ -65,9 +116,30 @@ func (w *BinaryWire) Write(msg *Message) error {
 	if err = writeString(w.writer, msg.cmd); err != nil {
 		return err
 	}
-	if err = writeBytes(w.writer, msg.Data); err != nil {
+
+	data := msg.Data
+	if w.compressor != nil {
+		flag := dataFlagRaw
+		if len(data) >= compressThreshold && len(data) <= maxDecompressedSize {
+			compressed, err := w.compressor.Compress(data)
+			if err != nil {
+				return err
+			}
+			// the incompressible data is sent as it is
+			if len(compressed) < len(data) {
+				data = compressed
+				flag = dataFlagCompressed
+			}
+		}
+		if err = binary.Write(w.writer, binary.LittleEndian, flag); err != nil {
+			return err
+		}
+	}
+	if err = writeBytes(w.writer, data); err != nil {
 		return err
 	}
+	atomic.AddUint64(&w.stats.WriteRawBytes, uint64(len(msg.Data)))
+	atomic.AddUint64(&w.stats.WriteWireBytes, uint64(len(data)))
 	return w.writer.Flush()
 }
 
@@ -93,9 +165,28 @@ func (w *BinaryWire) Read(msg *Message) error {
 	if msg.cmd, err = readString(w.reader); err != nil {
 		return err
 	}
+
+	flag := dataFlagRaw
+	if w.compressor != nil {
+		if err = binary.Read(w.reader, binary.LittleEndian, &flag); err != nil {
+			return err
+		}
+	}
 	if msg.Data, err = readBytes(w.reader); err != nil {
 		return err
 	}
+	wireBytes := len(msg.Data)
+	switch flag {
+	case dataFlagRaw:
+	case dataFlagCompressed:
+		if msg.Data, err = w.compressor.Decompress(msg.Data); err != nil {
+			return fmt.Errorf("decompress message data failed: %v", err)
+		}
+	default:
+		return fmt.Errorf("unknown message data flag %d", flag)
+	}
+	atomic.AddUint64(&w.stats.ReadRawBytes, uint64(len(msg.Data)))
+	atomic.AddUint64(&w.stats.ReadWireBytes, uint64(wireBytes))
 	return nil
 }
 
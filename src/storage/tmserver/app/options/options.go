@@ -15,6 +15,7 @@ package options
 import (
 	"strconv"
 
+	apiutil "configcenter/src/apimachinery/util"
 	"configcenter/src/common/core/cc/config"
 	"configcenter/src/storage/dal/mongo"
 
@@ -46,6 +47,8 @@ func (s *ServerOption) AddFlags(fs *pflag.FlagSet) {
 type Config struct {
 	MongoDB     mongo.Config
 	Transaction TransactionConfig
+	// RPCTLS is the tls config of the rpc server, the clients are required to connect with tls if it's configured
+	RPCTLS apiutil.TLSClientConfig
 }
 
 // TransactionConfig transaction config structure
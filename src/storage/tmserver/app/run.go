@@ -20,6 +20,7 @@ import (
 	"configcenter/src/common/backbone"
 	"configcenter/src/common/blog"
 	mgo "configcenter/src/storage/mongodb/driver"
+	"configcenter/src/storage/rpc"
 	"configcenter/src/storage/tmserver/app/options"
 	"configcenter/src/storage/tmserver/service"
 
@@ -72,8 +73,13 @@ func Run(ctx context.Context, cancel context.CancelFunc, op *options.ServerOptio
 
 		blog.Infof("connected to mongo %v", tmServer.config.MongoDB.BuildURI())
 
+		tlsConf, err := rpc.NewServerTLSConfig(tmServer.config.RPCTLS)
+		if err != nil {
+			return fmt.Errorf("load rpc tls config failed, err: %v", err)
+		}
+
 		// set logics service
-		coreService.SetConfig(engine, db, tmServer.config.Transaction)
+		coreService.SetConfig(engine, db, tmServer.config.Transaction, tlsConf)
 		break
 	}
 	tmServer.engin = engine
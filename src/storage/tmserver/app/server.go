@@ -16,11 +16,14 @@ import (
 	"os"
 	"sync"
 
+	apiutil "configcenter/src/apimachinery/util"
 	"configcenter/src/common/backbone"
 	cc "configcenter/src/common/backbone/configcenter"
+	"configcenter/src/common/blog"
 	"configcenter/src/common/types"
 	"configcenter/src/common/version"
 	"configcenter/src/storage/dal/mongo"
+	"configcenter/src/storage/rpc"
 	"configcenter/src/storage/tmserver/app/options"
 	"configcenter/src/storage/tmserver/service"
 )
@@ -43,10 +46,21 @@ func (s *Server) onConfigUpdate(previous, current cc.ProcessConfig) {
 			s.config = &options.Config{}
 		}
 
-		s.config.MongoDB = mongo.ParseConfigFromKV("mongodb", current.ConfigMap)
+		mongoConf, err := mongo.ParseConfigFromKV("mongodb", current.ConfigMap)
+		if err != nil {
+			blog.Errorf("parse mongodb config failed, err: %v", err)
+			return
+		}
+		rpcTLS, err := apiutil.NewTLSClientConfigFromConfig(rpc.TLSConfigPrefix, current.ConfigMap)
+		if err != nil {
+			blog.Errorf("parse rpc tls config failed, err: %v", err)
+			return
+		}
 
+		s.config.MongoDB = mongoConf
 		s.config.Transaction.Enable = current.ConfigMap["transaction.enable"]
 		s.config.Transaction.TransactionLifetimeSecond = current.ConfigMap["transaction.transactionLifetimeSecond"]
+		s.config.RPCTLS = rpcTLS
 	}
 }
 
@@ -38,6 +38,9 @@ func (d *abortTransaction) Execute(ctx core.ContextParams, decoder rpc.Request)
 	blog.V(4).Infof("[MONGO OPERATION] %+v", &ctx.Header)
 	reply := &types.OPReply{}
 	reply.RequestID = ctx.Header.RequestID
+	if session := d.txn.GetSession(ctx.Header.TxnID); session != nil && session.IsTransient() {
+		reply.Code = types.ReplyCodeTransientTxnError
+	}
 	err := d.txn.Abort(ctx.Header.TxnID)
 	if nil != err {
 		reply.Message = err.Error()
@@ -0,0 +1,78 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.,
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the ",License",); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an ",AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"configcenter/src/common/blog"
+	"configcenter/src/storage/mongodb"
+	"configcenter/src/storage/rpc"
+	"configcenter/src/storage/tmserver/core"
+	"configcenter/src/storage/types"
+)
+
+func init() {
+	core.GCommands.SetCommand(types.OPBulkWriteCode, &bulkWrite{})
+}
+
+var _ core.SetDBProxy = (*bulkWrite)(nil)
+
+type bulkWrite struct {
+	dbProxy mongodb.Client
+}
+
+func (d *bulkWrite) SetDBProxy(db mongodb.Client) {
+	d.dbProxy = db
+}
+
+// Execute execute the bulk write, the results of the operations are returned in the docs of the reply
+func (d *bulkWrite) Execute(ctx core.ContextParams, decoder rpc.Request) (*types.OPReply, error) {
+
+	msg := types.OPBulkWriteOperation{}
+	reply := &types.OPReply{}
+	reply.RequestID = ctx.Header.RequestID
+	if err := decoder.Decode(&msg); nil != err {
+		reply.Message = err.Error()
+		return reply, err
+	}
+	blog.V(4).Infof("[MONGO OPERATION] db bulk write, collection: %s, ordered: %v, count: %d, rid:%s",
+		msg.Collection, msg.Ordered, len(msg.Models), msg.RequestID)
+
+	var targetCol mongodb.CollectionInterface
+	if nil != ctx.Session {
+		targetCol = ctx.Session.Collection(msg.Collection)
+	} else {
+		targetCol = d.dbProxy.Collection(msg.Collection)
+	}
+
+	models := make([]mongodb.BulkWriteModel, len(msg.Models))
+	for index, model := range msg.Models {
+		models[index] = mongodb.BulkWriteModel{Op: model.Op, Filter: model.Selector, Doc: model.DOC}
+	}
+
+	results, err := targetCol.BulkWrite(ctx, models, msg.Ordered)
+	reply.Docs = make(types.Documents, len(results))
+	for index, result := range results {
+		reply.Docs[index] = types.Document{}
+		if encodeErr := reply.Docs[index].Encode(result); nil != encodeErr {
+			reply.Message = encodeErr.Error()
+			return reply, encodeErr
+		}
+	}
+	if nil == err {
+		reply.Success = true
+	} else {
+		blog.ErrorJSON("bulk write execute error.  errr: %s, raw data: %s, rid:%s", err.Error(), msg, msg.RequestID)
+		reply.Message = err.Error()
+	}
+	return reply, err
+}
@@ -0,0 +1,102 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.,
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the ",License",); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an ",AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"configcenter/src/storage/mongodb"
+	"configcenter/src/storage/tmserver/core"
+	"configcenter/src/storage/types"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRequest struct {
+	msg types.OPBulkWriteOperation
+}
+
+func (r *fakeRequest) Decode(value interface{}) error {
+	*value.(*types.OPBulkWriteOperation) = r.msg
+	return nil
+}
+
+type fakeClient struct {
+	mongodb.Client
+	collection *fakeCollection
+}
+
+func (c *fakeClient) Collection(collName string) mongodb.CollectionInterface {
+	c.collection.name = collName
+	return c.collection
+}
+
+type fakeCollection struct {
+	mongodb.CollectionInterface
+	name    string
+	models  []mongodb.BulkWriteModel
+	ordered bool
+	results []mongodb.BulkWriteItemResult
+	err     error
+}
+
+func (c *fakeCollection) BulkWrite(ctx context.Context, models []mongodb.BulkWriteModel, ordered bool) ([]mongodb.BulkWriteItemResult, error) {
+	c.models, c.ordered = models, ordered
+	return c.results, c.err
+}
+
+func TestBulkWriteExecute(t *testing.T) {
+	req := &fakeRequest{}
+	req.msg.Collection = "cc_HostBase"
+	req.msg.Ordered = true
+	req.msg.Models = make([]types.OPBulkWriteModel, 2)
+	req.msg.Models[0].Op = mongodb.BulkWriteInsert
+	require.NoError(t, req.msg.Models[0].DOC.Encode(map[string]interface{}{"bk_host_id": 1}))
+	req.msg.Models[1].Op = mongodb.BulkWriteDelete
+	require.NoError(t, req.msg.Models[1].Selector.Encode(map[string]interface{}{"bk_host_id": 2}))
+
+	coll := &fakeCollection{
+		results: mongodb.NewBulkWriteItemResults(2, true, map[int]string{0: "duplicate key"}),
+		err:     errors.New("duplicate key"),
+	}
+	cmd := &bulkWrite{}
+	cmd.SetDBProxy(&fakeClient{collection: coll})
+	ctx := core.ContextParams{Context: context.Background()}
+
+	// the results are replied with the error when some of the operations failed
+	reply, err := cmd.Execute(ctx, req)
+	require.Equal(t, coll.err, err)
+	require.False(t, reply.Success)
+	require.Equal(t, "duplicate key", reply.Message)
+	require.Equal(t, "cc_HostBase", coll.name)
+	require.True(t, coll.ordered)
+	require.Len(t, coll.models, 2)
+	require.Equal(t, mongodb.BulkWriteInsert, coll.models[0].Op)
+	require.Equal(t, req.msg.Models[0].DOC, coll.models[0].Doc)
+	require.Equal(t, mongodb.BulkWriteDelete, coll.models[1].Op)
+	require.Equal(t, req.msg.Models[1].Selector, coll.models[1].Filter)
+
+	results := make([]mongodb.BulkWriteItemResult, 0)
+	require.NoError(t, reply.Docs.Decode(&results))
+	require.Equal(t, coll.results, results)
+
+	coll.results, coll.err = mongodb.NewBulkWriteItemResults(2, true, nil), nil
+	reply, err = cmd.Execute(ctx, req)
+	require.NoError(t, err)
+	require.True(t, reply.Success)
+	results = make([]mongodb.BulkWriteItemResult, 0)
+	require.NoError(t, reply.Docs.Decode(&results))
+	require.Equal(t, coll.results, results)
+}
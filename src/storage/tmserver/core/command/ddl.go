@@ -76,8 +76,12 @@ func (m *ddl) Execute(ctx core.ContextParams, decoder rpc.Request) (*types.OPRep
 		// new version mongodb driver, name not support name
 		if msg.Index.Name == "" {
 			var name string
-			for key, val := range msg.Index.Keys {
-				name = name + fmt.Sprintf("_%s_%d", key, val)
+			for _, key := range msg.Index.OrderedKeys() {
+				if strings.HasPrefix(key, mongodb.TextIndexKeyPrefix) {
+					name = name + fmt.Sprintf("_%s_text", strings.TrimPrefix(key, mongodb.TextIndexKeyPrefix))
+					continue
+				}
+				name = name + fmt.Sprintf("_%s_%d", key, msg.Index.Keys[key])
 			}
 			msg.Index.Name = strings.Trim(name, "_")
 		}
@@ -88,6 +92,7 @@ func (m *ddl) Execute(ctx core.ContextParams, decoder rpc.Request) (*types.OPRep
 		if execErr == nil {
 			for _, dbIndex := range dbIndexs.Indexes {
 				keys := map[string]int32{}
+				keyOrder := make([]string, 0, len(dbIndex.Key))
 				for _, key := range dbIndex.Key {
 					if strings.HasPrefix(key, "-") {
 						key = strings.TrimLeft(key, "-")
@@ -95,10 +100,16 @@ func (m *ddl) Execute(ctx core.ContextParams, decoder rpc.Request) (*types.OPRep
 					} else {
 						keys[key] = 1
 					}
+					keyOrder = append(keyOrder, key)
 				}
+				// the fields are the bson names of mongodb.Index
 				reply.Docs = append(reply.Docs, types.Document{
-					"name": dbIndex.Name,
-					"keys": keys,
+					"name":               dbIndex.Name,
+					"keys":               keys,
+					"keyorder":           keyOrder,
+					"unique":             dbIndex.Unique,
+					"background":         dbIndex.Background,
+					"expireafterseconds": dbIndex.ExpireAfterSeconds,
 				})
 			}
 		}
@@ -0,0 +1,99 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.,
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the ",License",); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an ",AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"context"
+	"testing"
+
+	"configcenter/src/storage/mongodb"
+	"configcenter/src/storage/tmserver/core"
+	"configcenter/src/storage/types"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeDDLRequest struct {
+	msg types.OPDDLOperation
+}
+
+func (r *fakeDDLRequest) Decode(value interface{}) error {
+	*value.(*types.OPDDLOperation) = r.msg
+	return nil
+}
+
+type fakeIndexClient struct {
+	mongodb.Client
+	collection *fakeIndexCollection
+}
+
+func (c *fakeIndexClient) Database() mongodb.Database {
+	return nil
+}
+
+func (c *fakeIndexClient) Collection(collName string) mongodb.CollectionInterface {
+	return c.collection
+}
+
+type fakeIndexCollection struct {
+	mongodb.CollectionInterface
+	indexes []mongodb.IndexResult
+	created []mongodb.Index
+}
+
+func (c *fakeIndexCollection) GetIndexes() (*mongodb.QueryIndexResult, error) {
+	return &mongodb.QueryIndexResult{Indexes: c.indexes}, nil
+}
+
+func (c *fakeIndexCollection) CreateIndex(index mongodb.Index) error {
+	c.created = append(c.created, index)
+	return nil
+}
+
+func TestDDLIndexes(t *testing.T) {
+	coll := &fakeIndexCollection{indexes: []mongodb.IndexResult{
+		{Name: "op_target_1_op_time_-1", Key: []string{"op_target", "-op_time"}, Background: true},
+		{Name: "idx_fullText", Key: []string{"$text:$**"}},
+		{Name: "idx_id", Key: []string{"id"}, Unique: true},
+		{Name: "idx_createTime_ttl", Key: []string{"create_time"}, ExpireAfterSeconds: 60},
+	}}
+	cmd := &ddl{}
+	cmd.SetDBProxy(&fakeIndexClient{collection: coll})
+	ctx := core.ContextParams{Context: context.Background()}
+
+	req := &fakeDDLRequest{}
+	req.msg.Command = types.OPDDLIndexCommand
+	reply, err := cmd.Execute(ctx, req)
+	require.NoError(t, err)
+	require.True(t, reply.Success)
+
+	indexes := make([]mongodb.Index, 0)
+	require.NoError(t, reply.Docs.Decode(&indexes))
+	require.Equal(t, []mongodb.Index{
+		{Name: "op_target_1_op_time_-1", Keys: map[string]int32{"op_target": 1, "op_time": -1},
+			KeyOrder: []string{"op_target", "op_time"}, Background: true},
+		{Name: "idx_fullText", Keys: map[string]int32{"$text:$**": 1}, KeyOrder: []string{"$text:$**"}},
+		{Name: "idx_id", Keys: map[string]int32{"id": 1}, KeyOrder: []string{"id"}, Unique: true},
+		{Name: "idx_createTime_ttl", Keys: map[string]int32{"create_time": 1}, KeyOrder: []string{"create_time"},
+			ExpireAfterSeconds: 60},
+	}, indexes)
+
+	// the default name is in the key order
+	req.msg.Command = types.OPDDLCreateIndexCommand
+	req.msg.Index = mongodb.Index{Keys: map[string]int32{"op_time": -1, "op_target": 1}, KeyOrder: []string{"op_target", "op_time"}}
+	reply, err = cmd.Execute(ctx, req)
+	require.NoError(t, err)
+	require.True(t, reply.Success)
+	require.Len(t, coll.created, 1)
+	require.Equal(t, "op_target_1_op_time_-1", coll.created[0].Name)
+}
@@ -21,6 +21,8 @@ import (
 	"configcenter/src/storage/rpc"
 	"configcenter/src/storage/tmserver/core"
 	"configcenter/src/storage/types"
+
+	"github.com/mongodb/mongo-go-driver/bson/primitive"
 )
 
 func init() {
@@ -57,9 +59,34 @@ func (d *find) Execute(ctx core.ContextParams, decoder rpc.Request) (*types.OPRe
 		}
 	}
 
-	opt.Fields = append(opt.Fields, findopt.FieldItem{Name: "_id", Hide: true})
+	selector := interface{}(msg.Selector)
+	if msg.ObjectIDCursor {
+		if len(opt.Fields) != 0 {
+			opt.Fields = append(opt.Fields, findopt.FieldItem{Name: "_id", Hide: false})
+		}
+		opt.Sort = append(opt.Sort, findopt.SortItem{Name: "_id", Descending: false})
+		if msg.AfterObjectID != "" {
+			// only the _id replied as an ObjectID is compared as an ObjectID, the others are strings
+			var afterID interface{} = msg.AfterObjectID
+			if msg.AfterIDIsObjectID {
+				objectID, err := primitive.ObjectIDFromHex(msg.AfterObjectID)
+				if err != nil {
+					blog.Errorf("find execute error, invalid ObjectID %s, err: %v, rid: %s", msg.AfterObjectID, err, msg.RequestID)
+					reply.Message = err.Error()
+					return reply, err
+				}
+				afterID = objectID
+			}
+			selector = types.Document{"$and": []interface{}{
+				msg.Selector,
+				types.Document{"_id": types.Document{"$gt": afterID}},
+			}}
+		}
+	} else {
+		opt.Fields = append(opt.Fields, findopt.FieldItem{Name: "_id", Hide: true})
+	}
 
-	if msg.Sort != "" {
+	if msg.Sort != "" && !msg.ObjectIDCursor {
 		itemArr := strings.Split(msg.Sort, ",")
 		for _, item := range itemArr {
 			sortKV := strings.Split(item, ":")
@@ -91,8 +118,15 @@ func (d *find) Execute(ctx core.ContextParams, decoder rpc.Request) (*types.OPRe
 		targetCol = d.dbProxy.Collection(msg.Collection)
 	}
 
-	err := targetCol.Find(ctx, msg.Selector, &opt, &reply.Docs)
+	err := targetCol.Find(ctx, selector, &opt, &reply.Docs)
 	if nil == err {
+		if msg.ObjectIDCursor {
+			for _, doc := range reply.Docs {
+				if id, ok := doc["_id"].(primitive.ObjectID); ok {
+					doc["_id"] = types.Document{types.ObjectIDCursorKey: id.Hex()}
+				}
+			}
+		}
 		reply.Success = true
 	} else {
 		blog.ErrorJSON("find execute error.  errr: %s, raw data: %s, rid:%s", err.Error(), msg, msg.RequestID)
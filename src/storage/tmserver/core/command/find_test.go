@@ -0,0 +1,129 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.,
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the ",License",); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an ",AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"context"
+	"testing"
+
+	"configcenter/src/storage/mongodb"
+	"configcenter/src/storage/mongodb/options/findopt"
+	"configcenter/src/storage/tmserver/core"
+	"configcenter/src/storage/types"
+
+	"github.com/mongodb/mongo-go-driver/bson/primitive"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeFindRequest struct {
+	msg types.OPFindOperation
+}
+
+func (r *fakeFindRequest) Decode(value interface{}) error {
+	*value.(*types.OPFindOperation) = r.msg
+	return nil
+}
+
+type fakeFindClient struct {
+	mongodb.Client
+	collection *fakeFindCollection
+}
+
+func (c *fakeFindClient) Collection(collName string) mongodb.CollectionInterface {
+	return c.collection
+}
+
+type fakeFindCollection struct {
+	mongodb.CollectionInterface
+	filter interface{}
+	opts   *findopt.Many
+	docs   types.Documents
+}
+
+func (c *fakeFindCollection) Find(ctx context.Context, filter interface{}, opts *findopt.Many, output interface{}) error {
+	c.filter, c.opts = filter, opts
+	*output.(*types.Documents) = c.docs
+	return nil
+}
+
+func TestFindObjectIDCursor(t *testing.T) {
+	objectID := primitive.NewObjectID()
+	hexID := primitive.NewObjectID().Hex()
+	tests := []struct {
+		name       string
+		afterID    string
+		isObjectID bool
+		wantAfter  interface{}
+	}{
+		{name: "first batch"},
+		{name: "ObjectID _id", afterID: hexID, isObjectID: true, wantAfter: mustObjectID(t, hexID)},
+		{name: "string _id looks like an ObjectID", afterID: hexID, wantAfter: hexID},
+		{name: "string _id", afterID: "host_1", wantAfter: "host_1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &fakeFindRequest{}
+			req.msg.Collection = "cc_HostBase"
+			req.msg.Selector = types.Document{"bk_biz_id": 1}
+			req.msg.ObjectIDCursor = true
+			req.msg.AfterObjectID = tt.afterID
+			req.msg.AfterIDIsObjectID = tt.isObjectID
+
+			coll := &fakeFindCollection{docs: types.Documents{
+				{"_id": objectID, "bk_host_id": 1},
+				{"_id": "host_2", "bk_host_id": 2},
+			}}
+			cmd := &find{}
+			cmd.SetDBProxy(&fakeFindClient{collection: coll})
+			reply, err := cmd.Execute(core.ContextParams{Context: context.Background()}, req)
+			require.NoError(t, err)
+			require.True(t, reply.Success)
+
+			// the ObjectID _id is replied as a marker, the string _id is kept
+			require.Equal(t, types.Document{types.ObjectIDCursorKey: objectID.Hex()}, reply.Docs[0]["_id"])
+			require.Equal(t, "host_2", reply.Docs[1]["_id"])
+			require.Equal(t, []findopt.SortItem{{Name: "_id", Descending: false}}, coll.opts.Sort)
+
+			if tt.afterID == "" {
+				require.Equal(t, req.msg.Selector, coll.filter)
+				return
+			}
+			require.Equal(t, types.Document{"$and": []interface{}{
+				req.msg.Selector,
+				types.Document{"_id": types.Document{"$gt": tt.wantAfter}},
+			}}, coll.filter)
+		})
+	}
+}
+
+func TestFindInvalidObjectID(t *testing.T) {
+	req := &fakeFindRequest{}
+	req.msg.Collection = "cc_HostBase"
+	req.msg.ObjectIDCursor = true
+	req.msg.AfterObjectID = "host_1"
+	req.msg.AfterIDIsObjectID = true
+
+	coll := &fakeFindCollection{}
+	cmd := &find{}
+	cmd.SetDBProxy(&fakeFindClient{collection: coll})
+	reply, err := cmd.Execute(core.ContextParams{Context: context.Background()}, req)
+	require.Error(t, err)
+	require.False(t, reply.Success)
+	require.Nil(t, coll.opts)
+}
+
+func mustObjectID(t *testing.T, hex string) primitive.ObjectID {
+	objectID, err := primitive.ObjectIDFromHex(hex)
+	require.NoError(t, err)
+	return objectID
+}
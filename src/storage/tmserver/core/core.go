@@ -82,6 +82,12 @@ func (c *core) ExecuteCommand(ctx ContextParams, input rpc.Request) (*types.OPRe
 	reply, err := cmd.Execute(ctx, input)
 	if err != nil {
 		blog.Errorf("[MONGO OPERATION] failed: %v, cmd: %s", err, input)
+		if ctx.Header.TxnID != "" && reply != nil && mongodb.IsTransientTxnError(err) {
+			// reply the code instead of the error, so that the transaction starter could retry it
+			session.MarkTransient()
+			reply.Code = types.ReplyCodeTransientTxnError
+			return reply, nil
+		}
 	}
 	return reply, err
 
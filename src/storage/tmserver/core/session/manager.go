@@ -267,6 +267,7 @@ func (tm *Manager) newTxnID() string {
 	return tm.processor + "-" + xid.New().String()
 }
 
+// Commit commits the transaction, the commit error is returned so that the starter could retry the transient ones
 func (tm *Manager) Commit(txnID string) error {
 	if !tm.enable || txnID == "" {
 		// not start transaction, return
@@ -300,7 +301,7 @@ func (tm *Manager) Commit(txnID string) error {
 		// the reconcile will handle this error, so we will not return this error
 		blog.Errorf("save transaction [%s] status to %#v faile: %s", txnID, session.Txninst.Status, err.Error())
 	}
-	return nil
+	return txnerr
 }
 
 func (tm *Manager) Abort(txnID string) error {
@@ -13,6 +13,8 @@
 package session
 
 import (
+	"sync/atomic"
+
 	"configcenter/src/storage/mongodb"
 	"configcenter/src/storage/types"
 )
@@ -21,4 +23,16 @@ import (
 type Session struct {
 	mongodb.Session
 	Txninst *types.Transaction
+	// transient is set when an operation of the transaction failed on a transient transaction error
+	transient int32
+}
+
+// MarkTransient marks that the transaction failed on a transient transaction error
+func (s *Session) MarkTransient() {
+	atomic.StoreInt32(&s.transient, 1)
+}
+
+// IsTransient returns whether the transaction failed on a transient transaction error
+func (s *Session) IsTransient() bool {
+	return atomic.LoadInt32(&s.transient) == 1
 }
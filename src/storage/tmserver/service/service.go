@@ -14,6 +14,7 @@ package service
 
 import (
 	"context"
+	"crypto/tls"
 	"net/http"
 
 	restful "github.com/emicklei/go-restful"
@@ -31,7 +32,7 @@ import (
 // Service service methods
 type Service interface {
 	WebService() *restful.WebService
-	SetConfig(engin *backbone.Engine, db mongodb.Client, txnCfg options.TransactionConfig) error
+	SetConfig(engin *backbone.Engine, db mongodb.Client, txnCfg options.TransactionConfig, tlsConf *tls.Config) error
 }
 
 // New create a new service instance
@@ -52,12 +53,13 @@ type coreService struct {
 	listenPort uint
 }
 
-func (s *coreService) SetConfig(engin *backbone.Engine, db mongodb.Client, txnCfg options.TransactionConfig) error {
+func (s *coreService) SetConfig(engin *backbone.Engine, db mongodb.Client, txnCfg options.TransactionConfig, tlsConf *tls.Config) error {
 
 	// set config
 	s.engine = engin
 	s.dbProxy = db
 	s.rpc = rpc.NewServer()
+	s.rpc.SetTLSConfig(tlsConf)
 
 	// init all handlers
 	s.rpc.Handle(types.CommandRDBOperation, s.DBOperation)
@@ -76,7 +76,7 @@ func startCoreService(t *testing.T, ip string, port uint) {
 
 	// set logics service
 	_ = coreService
-	coreService.SetConfig(engine, db, options.TransactionConfig{})
+	coreService.SetConfig(engine, db, options.TransactionConfig{}, nil)
 
 	return
 }
@@ -47,6 +47,10 @@ const (
 	OPUpdateUnsetCode
 	// OPUpdateByOperatorCode update can use user operator
 	OPUpdateByOperatorCode
+	// OPBulkWriteCode bulk write operation code
+	OPBulkWriteCode
+	// OPExplainCode explain the query plan of a find operation code
+	OPExplainCode
 	// OPStartTransactionCode start a transaction code
 	OPStartTransactionCode OPCode = 666
 	// OPCommitCode transaction commit operation code
@@ -79,6 +83,10 @@ func (c OPCode) String() string {
 		return "OPAggregate"
 	case OPDDLCode:
 		return "OPDDL"
+	case OPBulkWriteCode:
+		return "OPBulkWrite"
+	case OPExplainCode:
+		return "OPExplain"
 	default:
 		return "UNKNOW"
 	}
@@ -122,6 +130,37 @@ type OPFindOperation struct {
 	Start      uint64   // start index
 	Limit      uint64   // limit index
 	Sort       string   // sort string
+	// ObjectIDCursor sorts the documents by _id and returns an ObjectID _id as {ObjectIDCursorKey: hex},
+	// only the documents after AfterObjectID are returned, so that a collection could be iterated in batches
+	// without skip scans. AfterObjectID is compared as an ObjectID only if AfterIDIsObjectID is set.
+	ObjectIDCursor    bool
+	AfterObjectID     string
+	AfterIDIsObjectID bool
+}
+
+// ObjectIDCursorKey is the key of the hex of an ObjectID _id replied to an ObjectIDCursor find operation
+const ObjectIDCursorKey = "$oid"
+
+// OPBulkWriteOperation bulk write operation request structure
+type OPBulkWriteOperation struct {
+	MsgHeader                     // 标准报文头
+	Collection string             // "dbname.collectionname"
+	Ordered    bool               // 有序执行, 遇到失败时停止执行后续操作
+	Models     []OPBulkWriteModel // 批量执行的写操作
+}
+
+// OPBulkWriteModel a write operation of the bulk write operation
+type OPBulkWriteModel struct {
+	Op       string   // insert, update_one, upsert 或 delete
+	DOC      Document // 插入的文档或要更新的字段
+	Selector Document // 文档查询条件
+}
+
+// OPExplainOperation explain operation request structure
+type OPExplainOperation struct {
+	MsgHeader           // 标准报文头
+	Collection string   // "dbname.collectionname"
+	Selector   Document // 文档查询条件
 }
 
 // OPCountOperation count operation request structure
@@ -216,6 +255,10 @@ type ReplyHeader struct {
 	Message   string
 }
 
+// ReplyCodeTransientTxnError the reply code of the operations failed on a transient transaction error,
+// the transaction is also marked so that the abort reply has the code too, the transaction could be retried on it.
+const ReplyCodeTransientTxnError = 1
+
 // OPReply the operation reply message header structure
 type OPReply struct {
 	ReplyHeader           // 标准报文头
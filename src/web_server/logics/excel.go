@@ -79,18 +79,46 @@ func (lgc *Logics) BuildExcelFromData(ctx context.Context, objID string, fields
 	return nil
 }
 
+// extFieldsTopoID is the extra field of the host sheet which is the topo of the host
+const extFieldsTopoID = "cc_ext_field_topo"
+
 // BuildHostExcelFromData product excel from data
 func (lgc *Logics) BuildHostExcelFromData(ctx context.Context, objID string, fields map[string]Property, filter []string, data []mapstr.MapStr, xlsxFile *xlsx.File, header http.Header, meta *metadata.Metadata) error {
+	writer, err := lgc.NewHostExcelWriter(ctx, objID, fields, filter, xlsxFile, header)
+	if err != nil {
+		return err
+	}
+	if err := writer.WriteHosts(data); err != nil {
+		return err
+	}
+	return writer.Close(meta)
+}
+
+// HostExcelWriter writes the hosts to the excel page by page, so that the hosts of a large export are
+// not held in memory all together
+type HostExcelWriter struct {
+	lgc      *Logics
+	ctx      context.Context
+	objID    string
+	fields   map[string]Property
+	xlsxFile *xlsx.File
+	sheet    *xlsx.Sheet
+	header   http.Header
+	rowIndex int
+	// instPrimaryKeyValMap is the primary values of the written hosts, which are used to write their associations
+	instPrimaryKeyValMap map[int64][]PropertyPrimaryVal
+}
+
+// NewHostExcelWriter adds the host sheet with its header to the excel
+func (lgc *Logics) NewHostExcelWriter(ctx context.Context, objID string, fields map[string]Property, filter []string, xlsxFile *xlsx.File, header http.Header) (*HostExcelWriter, error) {
 	rid := util.ExtractRequestIDFromContext(ctx)
 	ccLang := lgc.Language.CreateDefaultCCLanguageIf(util.GetLanguage(header))
-	ccErr := lgc.CCErr.CreateDefaultCCErrorIf(util.GetLanguage(header))
 
 	sheet, err := xlsxFile.AddSheet("host")
 	if err != nil {
 		blog.Errorf("BuildHostExcelFromData add excel sheet error, err:%s, rid:%s", err.Error(), rid)
-		return err
+		return nil, err
 	}
-	extFieldsTopoID := "cc_ext_field_topo"
 	extFields := map[string]string{
 		extFieldsTopoID: ccLang.Language("web_ext_field_topo"),
 	}
@@ -99,9 +127,24 @@ func (lgc *Logics) BuildHostExcelFromData(ctx context.Context, objID string, fie
 
 	productExcelHealer(ctx, fields, filter, sheet, ccLang)
 
-	instPrimaryKeyValMap := make(map[int64][]PropertyPrimaryVal)
-	// indexID := getFieldsIDIndexMap(fields)
-	rowIndex := common.HostAddMethodExcelIndexOffset
+	return &HostExcelWriter{
+		lgc:                  lgc,
+		ctx:                  ctx,
+		objID:                objID,
+		fields:               fields,
+		xlsxFile:             xlsxFile,
+		sheet:                sheet,
+		header:               header,
+		rowIndex:             common.HostAddMethodExcelIndexOffset,
+		instPrimaryKeyValMap: make(map[int64][]PropertyPrimaryVal),
+	}, nil
+}
+
+// WriteHosts writes a page of the hosts after the written ones
+func (w *HostExcelWriter) WriteHosts(data []mapstr.MapStr) error {
+	rid := util.ExtractRequestIDFromContext(w.ctx)
+	ccErr := w.lgc.CCErr.CreateDefaultCCErrorIf(util.GetLanguage(w.header))
+
 	for _, hostData := range data {
 
 		rowMap, err := mapstr.NewFromInterface(hostData[common.BKInnerObjIDHost])
@@ -116,24 +159,24 @@ func (lgc *Logics) BuildHostExcelFromData(ctx context.Context, objID string, fie
 			rowMap[extFieldsTopoID] = strings.Join(topo, "\n")
 		}
 
-		instIDKey := metadata.GetInstIDFieldByObjID(objID)
+		instIDKey := metadata.GetInstIDFieldByObjID(w.objID)
 		instID, err := rowMap.Int64(instIDKey)
 		if err != nil {
-			blog.Errorf("setExcelRowDataByIndex inst:%+v, not inst id key:%s, objID:%s, rid:%s", rowMap, instIDKey, objID, rid)
-			return ccErr.Errorf(common.CCErrCommInstFieldNotFound, "instIDKey", objID)
+			blog.Errorf("setExcelRowDataByIndex inst:%+v, not inst id key:%s, objID:%s, rid:%s", rowMap, instIDKey, w.objID, rid)
+			return ccErr.Errorf(common.CCErrCommInstFieldNotFound, "instIDKey", w.objID)
 		}
-		primaryKeyArr := setExcelRowDataByIndex(rowMap, sheet, rowIndex, fields)
-		instPrimaryKeyValMap[instID] = primaryKeyArr
-		rowIndex++
-	}
-
-	err = lgc.BuildAssociationExcelFromData(ctx, objID, instPrimaryKeyValMap, xlsxFile, header, meta)
-	if err != nil {
-		return err
+		primaryKeyArr := setExcelRowDataByIndex(rowMap, w.sheet, w.rowIndex, w.fields)
+		w.instPrimaryKeyValMap[instID] = primaryKeyArr
+		w.rowIndex++
 	}
 	return nil
 }
 
+// Close writes the associations of all the written hosts
+func (w *HostExcelWriter) Close(meta *metadata.Metadata) error {
+	return w.lgc.BuildAssociationExcelFromData(w.ctx, w.objID, w.instPrimaryKeyValMap, w.xlsxFile, w.header, meta)
+}
+
 func (lgc *Logics) BuildAssociationExcelFromData(ctx context.Context, objID string, instPrimaryInfo map[int64][]PropertyPrimaryVal, xlsxFile *xlsx.File, header http.Header, meta *metadata.Metadata) error {
 	rid := util.ExtractRequestIDFromContext(ctx)
 	var instIDArr []int64
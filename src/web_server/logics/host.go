@@ -29,29 +29,40 @@ import (
 	"github.com/rentiansheng/xlsx"
 )
 
-// GetHostData get host data from excel
-func (lgc *Logics) GetHostData(appIDStr, hostIDStr string, header http.Header) ([]mapstr.MapStr, error) {
+// GetHostData get host data page by page, each page is handled before the next one is fetched,
+// so that the hosts are not held in memory all together
+func (lgc *Logics) GetHostData(appIDStr, hostIDStr string, header http.Header, handle func(hostInfo []mapstr.MapStr) error) error {
 	rid := util.GetHTTPCCRequestID(header)
-	hostInfo := make([]mapstr.MapStr, 0)
 	sHostCond := make(map[string]interface{})
 	appID, err := strconv.ParseInt(appIDStr, 10, 64)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	hostIDArr := strings.Split(hostIDStr, ",")
 	iHostIDArr := make([]int64, 0)
 	for _, j := range hostIDArr {
 		hostID, err := strconv.ParseInt(j, 10, 64)
 		if err != nil {
-			return nil, err
+			return err
 		}
 		iHostIDArr = append(iHostIDArr, hostID)
 	}
+
+	// the hosts are fetched in pages sorted by the host id, each page starts after the last host of
+	// the previous one, so that a large export neither loads the hosts in one request nor skip scans
+	afterHostCond := map[string]interface{}{
+		"field":    common.BKHostIDField,
+		"operator": common.BKDBGT,
+		"value":    int64(0),
+	}
 	if -1 != appID {
 		sHostCond[common.BKAppIDField] = appID
 		sHostCond["ip"] = make(map[string]interface{})
-		sHostCond["condition"] = make([]interface{}, 0)
-		sHostCond["page"] = make(map[string]interface{})
+		condition := make(map[string]interface{})
+		condition[common.BKObjIDField] = common.BKInnerObjIDHost
+		condition["fields"] = make([]string, 0)
+		condition["condition"] = []interface{}{afterHostCond}
+		sHostCond["condition"] = []interface{}{condition}
 	} else {
 		sHostCond[common.BKAppIDField] = -1
 		sHostCond["ip"] = make(map[string]interface{})
@@ -64,7 +75,7 @@ func (lgc *Logics) GetHostData(appIDStr, hostIDStr string, header http.Header) (
 		hostCond["field"] = common.BKHostIDField
 		hostCond["operator"] = common.BKDBIN
 		hostCond["value"] = iHostIDArr
-		hostCondArr = append(hostCondArr, hostCond)
+		hostCondArr = append(hostCondArr, hostCond, afterHostCond)
 		condition[common.BKObjIDField] = common.BKInnerObjIDHost
 		condition["fields"] = make([]string, 0)
 		condition["condition"] = hostCondArr
@@ -92,21 +103,39 @@ func (lgc *Logics) GetHostData(appIDStr, hostIDStr string, header http.Header) (
 		condArr = append(condArr, condition)
 
 		sHostCond["condition"] = condArr
-		sHostCond["page"] = make(map[string]interface{})
-
-	}
-	result, err := lgc.Engine.CoreAPI.ApiServer().GetHostData(context.Background(), header, sHostCond)
-	if nil != err {
-		blog.Errorf("GetHostData failed, search condition: %+v, err: %+v, rid: %s", sHostCond, err, rid)
-		return hostInfo, err
 	}
+	sHostCond["page"] = map[string]interface{}{"start": 0, "limit": common.BKMaxPageSize, "sort": common.BKHostIDField}
 
-	if !result.Result {
-		blog.Errorf("GetHostData failed, search condition: %+v, result: %+v, rid: %s", sHostCond, result, rid)
-		return nil, lgc.CCErr.CreateDefaultCCErrorIf(util.GetLanguage(header)).New(result.Code, result.ErrMsg)
-	}
+	for {
+		result, err := lgc.Engine.CoreAPI.ApiServer().GetHostData(context.Background(), header, sHostCond)
+		if nil != err {
+			blog.Errorf("GetHostData failed, search condition: %+v, err: %+v, rid: %s", sHostCond, err, rid)
+			return err
+		}
+
+		if !result.Result {
+			blog.Errorf("GetHostData failed, search condition: %+v, result: %+v, rid: %s", sHostCond, result, rid)
+			return lgc.CCErr.CreateDefaultCCErrorIf(util.GetLanguage(header)).New(result.Code, result.ErrMsg)
+		}
 
-	return result.Data.Info, nil
+		if err := handle(result.Data.Info); err != nil {
+			return err
+		}
+		if len(result.Data.Info) < common.BKMaxPageSize {
+			return nil
+		}
+
+		lastHost, err := result.Data.Info[len(result.Data.Info)-1].MapStr(common.BKInnerObjIDHost)
+		if nil != err {
+			blog.Errorf("GetHostData failed, get the last host error, err: %+v, rid: %s", err, rid)
+			return err
+		}
+		afterHostCond["value"], err = lastHost.Int64(common.BKHostIDField)
+		if nil != err {
+			blog.Errorf("GetHostData failed, get the last host id error, err: %+v, rid: %s", err, rid)
+			return err
+		}
+	}
 }
 
 // GetImportHosts get import hosts
@@ -25,13 +25,12 @@ import (
 	"configcenter/src/common/metadata"
 	"configcenter/src/common/util"
 	validator "configcenter/src/source_controller/coreservice/core/instances"
-
-	"gopkg.in/redis.v5"
+	"configcenter/src/storage/dal/redis"
 )
 
 type OwnerManager struct {
 	Engine   *backbone.Engine
-	CacheCli *redis.Client
+	CacheCli redis.Client
 	OwnerID  string
 	UserName string
 	header   http.Header
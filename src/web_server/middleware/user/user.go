@@ -16,10 +16,10 @@ import (
 	"plugin"
 
 	"configcenter/src/common/backbone"
+	"configcenter/src/storage/dal/redis"
 	"configcenter/src/web_server/app/options"
 
 	"github.com/gin-gonic/gin"
-	"gopkg.in/redis.v5"
 )
 
 type User interface {
@@ -29,6 +29,6 @@ type User interface {
 }
 
 // NewUser return user instance by type
-func NewUser(config options.Config, engine *backbone.Engine, cacheCli *redis.Client, loginPlg *plugin.Plugin) User {
+func NewUser(config options.Config, engine *backbone.Engine, cacheCli redis.Client, loginPlg *plugin.Plugin) User {
 	return &publicUser{config, engine, cacheCli, loginPlg}
 }
@@ -103,13 +103,6 @@ func (s *Service) ExportHost(c *gin.Context) {
 	defErr := s.CCErr.CreateDefaultCCErrorIf(util.GetLanguage(header))
 	customFieldsStr := c.PostForm(common.ExportCustomFields)
 
-	hostInfo, err := s.Logics.GetHostData(appIDStr, hostIDStr, header)
-	if err != nil {
-		blog.Errorf("ExportHost failed, get hosts by id [%+v] failed, err: %v, rid: %s", hostIDStr, err, rid)
-		msg := getReturnStr(common.CCErrWebGetHostFail, defErr.Errorf(common.CCErrWebGetHostFail, err.Error()).Error(), nil)
-		c.String(http.StatusInternalServerError, msg)
-		return
-	}
 	var file *xlsx.File
 	file = xlsx.NewFile()
 
@@ -123,9 +116,23 @@ func (s *Service) ExportHost(c *gin.Context) {
 		_, _ = c.Writer.Write([]byte(reply))
 		return
 	}
-	err = s.Logics.BuildHostExcelFromData(context.Background(), objID, fields, nil, hostInfo, file, header, &metadata.Metadata{})
+	// the hosts are written to the excel page by page as they are fetched
+	writer, err := s.Logics.NewHostExcelWriter(context.Background(), objID, fields, nil, file, header)
 	if nil != err {
-		blog.Errorf("ExportHost failed, BuildHostExcelFromData failed, object:%s, err:%+v, rid:%s", objID, err, rid)
+		blog.Errorf("ExportHost failed, NewHostExcelWriter failed, object:%s, err:%+v, rid:%s", objID, err, rid)
+		reply := getReturnStr(common.CCErrCommExcelTemplateFailed, defErr.Errorf(common.CCErrCommExcelTemplateFailed, objID).Error(), nil)
+		_, _ = c.Writer.Write([]byte(reply))
+		return
+	}
+	err = s.Logics.GetHostData(appIDStr, hostIDStr, header, writer.WriteHosts)
+	if err != nil {
+		blog.Errorf("ExportHost failed, get hosts by id [%+v] failed, err: %v, rid: %s", hostIDStr, err, rid)
+		msg := getReturnStr(common.CCErrWebGetHostFail, defErr.Errorf(common.CCErrWebGetHostFail, err.Error()).Error(), nil)
+		c.String(http.StatusInternalServerError, msg)
+		return
+	}
+	if err := writer.Close(&metadata.Metadata{}); nil != err {
+		blog.Errorf("ExportHost failed, BuildAssociationExcelFromData failed, object:%s, err:%+v, rid:%s", objID, err, rid)
 		reply := getReturnStr(common.CCErrCommExcelTemplateFailed, defErr.Errorf(common.CCErrCommExcelTemplateFailed, objID).Error(), nil)
 		_, _ = c.Writer.Write([]byte(reply))
 		return